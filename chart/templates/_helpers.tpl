@@ -4,3 +4,13 @@ Container port
 {{- define "container_port" -}}
 {{- 8080 }}
 {{- end }}
+
+{{/*
+Non-empty if any storage backend uses the generic provider
+*/}}
+{{- define "uses_generic_storage" -}}
+{{- if eq (toString .Values.storage.provider) "generic" }}true{{ end }}
+{{- range .Values.storage.backends }}
+{{- if eq (toString .provider) "generic" }}true{{ end }}
+{{- end }}
+{{- end }}
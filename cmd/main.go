@@ -3,11 +3,13 @@ package main
 import (
 	"net/http"
 
+	"github.com/rs/zerolog/log"
 	"github.com/ucl-arc-tre/egress/internal/config"
 	"github.com/ucl-arc-tre/egress/internal/handler"
 	"github.com/ucl-arc-tre/egress/internal/middleware"
 	"github.com/ucl-arc-tre/egress/internal/openapi"
 	"github.com/ucl-arc-tre/egress/internal/router"
+	"github.com/ucl-arc-tre/egress/internal/server"
 
 	"github.com/ucl-arc-tre/x/pkg/graceful"
 )
@@ -20,13 +22,23 @@ func main() {
 	openapi.RegisterHandlersWithOptions(router, handler,
 		openapi.GinServerOptions{
 			BaseURL:     config.BaseURL,
-			Middlewares: middleware.All(),
+			Middlewares: middleware.All(handler.DB()),
 		})
 
-	server := &http.Server{
+	httpServer := &http.Server{
 		Addr:              config.ServerAddress(),
 		Handler:           router.Handler(),
 		ReadHeaderTimeout: config.ReadHeaderTimeout,
 	}
-	graceful.Serve(server, config.ServerShutdownDuration)
+	tlsConfig := config.ServerTLSConfig()
+	if !tlsConfig.Enabled {
+		graceful.Serve(httpServer, config.ServerShutdownDuration)
+		return
+	}
+	tlsCfg, err := server.TLSConfig(tlsConfig)
+	if err != nil {
+		log.Fatal().Err(err).Str("dir", tlsConfig.CertDir).Msg("Failed to configure TLS")
+	}
+	httpServer.TLSConfig = tlsCfg
+	server.ServeTLS(httpServer, config.ServerShutdownDuration)
 }
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	azBlob "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+const (
+	azuriteEndpoint  = "http://localhost:10000/devstoreaccount1"
+	azuriteAccount   = "devstoreaccount1"
+	azuriteKey       = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==" // pragma: allowlist secret
+	azuriteContainer = "container1"
+)
+
+type AzBlobProvider struct{}
+
+func (p *AzBlobProvider) FilesLocation() string {
+	return fmt.Sprintf("azblob://%s/%s", azuriteAccount, azuriteContainer)
+}
+
+func (p *AzBlobProvider) PutFile(key, content string) error {
+	client := newAzBlobClient()
+	_, err := client.CreateContainer(context.Background(), azuriteContainer, nil)
+	if err != nil && !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+		return err
+	}
+	_, err = client.UploadBuffer(context.Background(), azuriteContainer, key, []byte(content), nil)
+	return err
+}
+
+func newAzBlobClient() *azBlob.Client {
+	cred := must(azBlob.NewSharedKeyCredential(azuriteAccount, azuriteKey))
+	return must(azBlob.NewClientWithSharedKeyCredential(azuriteEndpoint, cred, nil))
+}
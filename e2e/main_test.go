@@ -537,6 +537,35 @@ func TestBearerAuth(t *testing.T) {
 	}
 }
 
+func TestBearerAuthIntrospection(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{
+			name:           "Valid opaque token",
+			token:          mintBearerToken(t, tokenRequest{Audience: audience, Opaque: true}),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Wrong audience",
+			token:          mintBearerToken(t, tokenRequest{Audience: "not-egress", Opaque: true}),
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newHTTPClient()
+			req := makeRequest(t)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			res := must(client.Do(req))
+
+			assert.Equal(t, tc.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
 func TestBearerAuthUserIdMismatch(t *testing.T) {
 	fileId := "f1234" // Non-existent file
 	client := newHTTPClient()
@@ -763,6 +792,7 @@ func makeRequestBodyF(format string, objs ...any) io.Reader {
 type tokenRequest struct {
 	Subject  string `json:"sub,omitempty"`
 	Audience string `json:"aud,omitempty"`
+	Opaque   bool   `json:"opaque,omitempty"`
 }
 
 type tokenResponse struct {
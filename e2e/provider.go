@@ -13,6 +13,8 @@ func newStorageProviderFromEnv() StorageProvider {
 		return &S3Provider{}
 	case "generic":
 		return &GenericProvider{}
+	case "azblob":
+		return &AzBlobProvider{}
 	}
 	panic("STORAGE_PROVIDER not defined or has an invalid value")
 }
@@ -0,0 +1,376 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ucl-arc-tre/egress/internal/cache"
+	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/filetype"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Members totalling up to this many bytes are read whatever the ratio to
+// the size of the archive, so that small archives of text are not blocked
+const minRatioBudget = 1024 * 1024
+
+var errLimitExceeded = errors.New("archive limit exceeded") // Stops an inspection once a limit is exceeded
+
+// Limits of archives, which bound what is read to inspect them
+type Limits struct {
+	MaxRatio   float64 // Of the total size of the members to the size of the archive
+	MaxDepth   int     // Of members, 1 being the members of the archive itself
+	MaxMembers int
+}
+
+// Opens the content of an archive file
+type OpenFunc func(ctx context.Context) (io.ReadCloser, error)
+
+// Called with each member that is not itself an archive, and its content
+// e.g. to scan it
+type VisitFunc func(ctx context.Context, member *types.ArchiveMember, content io.Reader) error
+
+// Called with the content of an Office document, which is a zip but is
+// not treated as an archive other than for the objects embedded in it
+type documentFunc func(content io.Reader, size int64) error
+
+// Format of an archive from its leading bytes, if it is one. Office
+// documents are zip files, so are told apart once their members are known
+func DetectFormat(head []byte) (Format, bool) {
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return FormatZip, true
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return FormatGzip, true
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return FormatTar, true
+	}
+	return "", false
+}
+
+// Inspector lists the members of archive files, which are cached by the
+// location and id of the file
+type Inspector struct {
+	limits      Limits
+	inspections *cache.FIFO[cache.FileKey, types.ArchiveInspection]
+}
+
+// Inspector of archives, or nil if inspection is not enabled
+func Provider(cfg config.ArchivesConfigBundle) *Inspector {
+	if !cfg.Enabled {
+		return nil
+	}
+	return NewInspector(cfg.CacheSize, Limits{
+		MaxRatio:   cfg.MaxRatio,
+		MaxDepth:   cfg.MaxDepth,
+		MaxMembers: cfg.MaxMembers,
+	})
+}
+
+func NewInspector(cacheSize int, limits Limits) *Inspector {
+	return &Inspector{
+		limits:      limits,
+		inspections: cache.NewFIFO[cache.FileKey, types.ArchiveInspection](cacheSize),
+	}
+}
+
+// Inspect the file in the location, or nil if it is not an archive. The
+// members of archives within it are listed after their archive, and the
+// visit is called for each other member. An Office document is an archive
+// of the objects embedded in it, if it has any. Once a limit is exceeded no
+// more members are read. A failed inspection is not cached
+func (i *Inspector) Inspect(
+	ctx context.Context,
+	location types.LocationURI,
+	metadata *types.FileMetadata,
+	open OpenFunc,
+	visit VisitFunc,
+) (inspection *types.ArchiveInspection, cached bool, err error) {
+	key := cache.KeyOf(location, metadata.Id)
+	if result, exists := i.inspections.Get(key); exists {
+		if result.Format == "" {
+			return nil, true, nil
+		}
+		return &result, true, nil
+	}
+	content, err := open(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer content.Close()
+
+	r := bufio.NewReaderSize(content, filetype.SniffLength)
+	head, err := r.Peek(filetype.SniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, false, types.NewErrServerF("failed to read file: %v", err)
+	}
+	result := types.ArchiveInspection{FileId: metadata.Id, Members: []types.ArchiveMember{}}
+	format, isArchive := DetectFormat(head)
+	if isArchive {
+		result.Format = string(format)
+		in := &inspecting{
+			ctx:       ctx,
+			limits:    i.limits,
+			visit:     visit,
+			result:    &result,
+			remaining: max(int64(i.limits.MaxRatio*float64(metadata.Size)), minRatioBudget),
+		}
+		// A limit exceeded while visiting a member may be wrapped by the visit.
+		// An Office document within the limits without embedded objects is
+		// not an archive
+		isDocument := false
+		err := in.archive(format, r, metadata.Name, "", 1, func(io.Reader, int64) error {
+			isDocument = true
+			return nil
+		})
+		if err != nil && result.Exceeded == "" {
+			return nil, false, err
+		}
+		if isDocument && result.Exceeded == "" && len(result.Members) == 0 {
+			isArchive = false
+			result.Format = ""
+		}
+	}
+	i.inspections.Put(key, result)
+	if !isArchive {
+		return nil, false, nil
+	}
+	return &result, false, nil
+}
+
+// State of an inspection of one archive file
+type inspecting struct {
+	ctx       context.Context
+	limits    Limits
+	visit     VisitFunc
+	result    *types.ArchiveInspection
+	remaining int64 // Bytes of members that may still be read
+}
+
+func (in *inspecting) exceed(limit string) error {
+	in.result.Exceeded = limit
+	return errLimitExceeded
+}
+
+// Inspect the archive with the name, whose members have the path prefix
+// and depth. A zip that is an Office document is passed to the document
+// func, and only the objects embedded in it are inspected as members
+func (in *inspecting) archive(format Format, r io.Reader, name string, prefix string, depth int, document documentFunc) error {
+	if format == FormatZip {
+		return in.zip(r, prefix, depth, document)
+	}
+	if depth > in.limits.MaxDepth {
+		return in.exceed(types.ArchiveLimitDepth)
+	}
+	switch format {
+	case FormatTar:
+		return in.tar(r, prefix, depth, false)
+	default:
+		return in.gzip(r, name, prefix, depth)
+	}
+}
+
+// A zip is spooled to a temporary file, as its directory is at the end.
+// The declared sizes of its members are checked before any is read, as
+// are the number of members of an Office document, of which only the
+// embedded objects are read
+func (in *inspecting) zip(r io.Reader, prefix string, depth int, document documentFunc) error {
+	spool, err := os.CreateTemp("", "egress-archive-*")
+	if err != nil {
+		return types.NewErrServerF("failed to create temporary file: %v", err)
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	size, err := io.Copy(spool, r)
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(spool, size)
+	if err != nil {
+		return types.NewErrInvalidObjectF("failed to read zip archive: %v", err)
+	}
+	total := uint64(0)
+	for _, file := range zr.File {
+		total += file.UncompressedSize64
+		if total > uint64(in.remaining) {
+			return in.exceed(types.ArchiveLimitRatio)
+		}
+	}
+	files := zr.File
+	if format, isDocument := filetype.OfficeDocument(zr); isDocument {
+		if len(zr.File) > in.limits.MaxMembers {
+			return in.exceed(types.ArchiveLimitMembers)
+		}
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := document(spool, size); err != nil {
+			return err
+		}
+		files = filetype.EmbeddedObjects(zr, format)
+		if len(files) == 0 {
+			return nil
+		}
+	}
+	if depth > in.limits.MaxDepth {
+		return in.exceed(types.ArchiveLimitDepth)
+	}
+	for _, file := range files {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		content, err := file.Open()
+		if err != nil {
+			return types.NewErrInvalidObjectF("failed to read zip archive member %s: %v", file.Name, err)
+		}
+		err = in.member(&types.ArchiveMember{
+			Path:           prefix + file.Name,
+			Size:           int64(file.UncompressedSize64),
+			CompressedSize: int64(file.CompressedSize64),
+			Depth:          depth,
+		}, content)
+		_ = content.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *inspecting) tar(r io.Reader, prefix string, depth int, compressed bool) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return types.NewErrInvalidObjectF("failed to read tar archive: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		member := &types.ArchiveMember{
+			Path:           prefix + header.Name,
+			Size:           header.Size,
+			CompressedSize: header.Size,
+			Depth:          depth,
+		}
+		if compressed {
+			member.CompressedSize = -1
+		}
+		if err := in.member(member, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// A gzip file is a compressed tar, or otherwise has a single member named
+// as in its header or as the file without its .gz extension
+func (in *inspecting) gzip(r io.Reader, name string, prefix string, depth int) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return types.NewErrInvalidObjectF("failed to read gzip file: %v", err)
+	}
+	defer gr.Close()
+	br := bufio.NewReaderSize(gr, filetype.SniffLength)
+	head, err := br.Peek(filetype.SniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return types.NewErrInvalidObjectF("failed to read gzip file: %v", err)
+	}
+	if format, _ := DetectFormat(head); format == FormatTar {
+		return in.tar(br, prefix, depth, true)
+	}
+	memberName := gr.Name
+	if memberName == "" {
+		memberName = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+	return in.member(&types.ArchiveMember{
+		Path:           prefix + memberName,
+		Size:           -1, // Set once read
+		CompressedSize: -1,
+		Depth:          depth,
+	}, br)
+}
+
+// Add the member, recursing into it if it is an archive and otherwise
+// visiting it. Its whole content is read, counting towards the ratio
+func (in *inspecting) member(member *types.ArchiveMember, content io.Reader) error {
+	if err := in.ctx.Err(); err != nil {
+		return err
+	}
+	if len(in.result.Members) >= in.limits.MaxMembers {
+		return in.exceed(types.ArchiveLimitMembers)
+	}
+	if member.Size > in.remaining {
+		return in.exceed(types.ArchiveLimitRatio)
+	}
+	counted := &countingReader{r: content, in: in}
+	r := bufio.NewReaderSize(counted, filetype.SniffLength)
+	head, err := r.Peek(filetype.SniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	member.MediaType = filetype.Detect(member.Path, head)
+
+	if format, isArchive := DetectFormat(head); isArchive {
+		if member.Size < 0 {
+			member.Size = 0 // Not known without reading the members
+		}
+		index := len(in.result.Members)
+		in.result.Members = append(in.result.Members, *member)
+		return in.archive(format, r, member.Path, member.Path+"/", member.Depth+1, func(content io.Reader, size int64) error {
+			member.Size = size
+			err := in.visitMember(member, content)
+			in.result.Members[index] = *member
+			return err
+		})
+	}
+
+	if err := in.visitMember(member, r); err != nil {
+		return err
+	}
+	if member.Size < 0 {
+		member.Size = counted.n
+	}
+	in.result.Members = append(in.result.Members, *member)
+	return nil
+}
+
+// Visit a member that is not an archive, reading its whole content
+func (in *inspecting) visitMember(member *types.ArchiveMember, content io.Reader) error {
+	if in.visit != nil {
+		if err := in.visit(in.ctx, member, content); err != nil {
+			return err
+		}
+	}
+	_, err := io.Copy(io.Discard, content)
+	return err
+}
+
+// Counts the bytes read from a member against those remaining
+type countingReader struct {
+	r  io.Reader
+	in *inspecting
+	n  int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	c.in.remaining -= int64(n)
+	if c.in.remaining < 0 {
+		return n, c.in.exceed(types.ArchiveLimitRatio)
+	}
+	return n, err
+}
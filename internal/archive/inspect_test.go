@@ -0,0 +1,287 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+var (
+	limits   = Limits{MaxRatio: 100, MaxDepth: 3, MaxMembers: 100}
+	location = types.LocationURI{Scheme: "s3", Host: "bucket"}
+)
+
+func TestDetectFormat(t *testing.T) {
+	format, isArchive := DetectFormat(makeArchive(t, FormatZip, map[string]string{"a.txt": "a"}))
+	assert.True(t, isArchive)
+	assert.Equal(t, FormatZip, format)
+	format, _ = DetectFormat(makeArchive(t, FormatTar, map[string]string{"a.txt": "a"}))
+	assert.Equal(t, FormatTar, format)
+	format, _ = DetectFormat(gzipped(t, "a,b\n"))
+	assert.Equal(t, FormatGzip, format)
+
+	_, isArchive = DetectFormat([]byte("a,b\n"))
+	assert.False(t, isArchive)
+}
+
+func TestInspect(t *testing.T) {
+	inner := makeArchive(t, FormatTar, map[string]string{"table.csv": "a,b\n1,2\n"})
+	outer := makeArchive(t, FormatZip, map[string]string{
+		"data/inner.tar": string(inner),
+		"figure.png":     "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+	})
+	visited := []string{}
+	visit := func(ctx context.Context, member *types.ArchiveMember, content io.Reader) error {
+		visited = append(visited, member.Path)
+		member.Scan = &types.ScanResult{Verdict: types.ScanVerdictClean, Scanner: "test"}
+		return nil
+	}
+	inspector := NewInspector(10, limits)
+	opened := 0
+	metadata := &types.FileMetadata{Id: "id1", Name: "outputs.zip", Size: int64(len(outer))}
+
+	for range 2 {
+		inspection, _, err := inspector.Inspect(context.Background(), location, metadata, openBytes(outer, &opened), visit)
+		require.NoError(t, err)
+		require.NotNil(t, inspection)
+		assert.Equal(t, "zip", inspection.Format)
+		assert.Empty(t, inspection.Exceeded)
+		require.Len(t, inspection.Members, 3)
+		assert.Equal(t, "data/inner.tar", inspection.Members[0].Path)
+		assert.Equal(t, 1, inspection.Members[0].Depth)
+		assert.Equal(t, "data/inner.tar/table.csv", inspection.Members[1].Path)
+		assert.Equal(t, 2, inspection.Members[1].Depth)
+		assert.Equal(t, "text/csv", inspection.Members[1].MediaType)
+		assert.Equal(t, int64(8), inspection.Members[1].Size)
+		assert.Equal(t, int64(8), inspection.Members[1].CompressedSize)
+		assert.NotNil(t, inspection.Members[1].Scan)
+		assert.Equal(t, "figure.png", inspection.Members[2].Path)
+		assert.Equal(t, "image/png", inspection.Members[2].MediaType)
+	}
+	assert.Equal(t, 1, opened) // Cached
+	assert.Equal(t, []string{"data/inner.tar/table.csv", "figure.png"}, visited)
+
+	// The same id in another location is another file
+	other := types.LocationURI{Scheme: "s3", Host: "other-bucket"}
+	inspection, _, err := inspector.Inspect(context.Background(), other, metadata, openBytes([]byte("a,b\n"), &opened), nil)
+	assert.NoError(t, err)
+	assert.Nil(t, inspection)
+	assert.Equal(t, 2, opened)
+
+	// A file that is not an archive has no inspection
+	inspection, _, err = inspector.Inspect(context.Background(), location,
+		&types.FileMetadata{Id: "id2", Name: "a.csv", Size: 4}, openBytes([]byte("a,b\n"), &opened), nil)
+	assert.NoError(t, err)
+	assert.Nil(t, inspection)
+}
+
+func TestInspectGzip(t *testing.T) {
+	tarGz := gzipped(t, string(makeArchive(t, FormatTar, map[string]string{"a.txt": "hello"})))
+	inspection := inspect(t, "outputs.tar.gz", tarGz, limits)
+	assert.Equal(t, "gzip", inspection.Format)
+	require.Len(t, inspection.Members, 1)
+	assert.Equal(t, "a.txt", inspection.Members[0].Path)
+	assert.Equal(t, int64(-1), inspection.Members[0].CompressedSize)
+
+	inspection = inspect(t, "table.csv.gz", gzipped(t, "a,b\n1,2\n"), limits)
+	require.Len(t, inspection.Members, 1)
+	assert.Equal(t, "table.csv", inspection.Members[0].Path)
+	assert.Equal(t, int64(8), inspection.Members[0].Size)
+	assert.Equal(t, "text/csv", inspection.Members[0].MediaType)
+}
+
+func TestInspectLimits(t *testing.T) {
+	// Zeros compress far beyond the ratio
+	bomb := gzipped(t, strings.Repeat("0", 4*1024*1024))
+	inspection := inspect(t, "zeros.gz", bomb, limits)
+	assert.Equal(t, types.ArchiveLimitRatio, inspection.Exceeded)
+	inspection = inspect(t, "zeros.zip", makeArchive(t, FormatZip, map[string]string{"zeros": strings.Repeat("0", 4*1024*1024)}), limits)
+	assert.Equal(t, types.ArchiveLimitRatio, inspection.Exceeded)
+	assert.Empty(t, inspection.Members) // Declared sizes are checked first
+
+	nested := makeArchive(t, FormatZip, map[string]string{
+		"1.zip": string(makeArchive(t, FormatZip, map[string]string{"2.txt": "hello"})),
+	})
+	inspection = inspect(t, "nested.zip", nested, Limits{MaxRatio: 100, MaxDepth: 1, MaxMembers: 100})
+	assert.Equal(t, types.ArchiveLimitDepth, inspection.Exceeded)
+	assert.Len(t, inspection.Members, 1)
+
+	many := makeArchive(t, FormatTar, map[string]string{"a": "a", "b": "b", "c": "c"})
+	inspection = inspect(t, "many.tar", many, Limits{MaxRatio: 100, MaxDepth: 3, MaxMembers: 2})
+	assert.Equal(t, types.ArchiveLimitMembers, inspection.Exceeded)
+	assert.Len(t, inspection.Members, 2)
+}
+
+func TestInspectOfficeDocuments(t *testing.T) {
+	docx := makeDocx(t, nil)
+	odt := makeZip(t, [][2]string{
+		{"mimetype", "application/vnd.oasis.opendocument.text"},
+		{"META-INF/manifest.xml", "<manifest/>"},
+		{"content.xml", "<content/>"},
+	})
+	inspector := NewInspector(10, limits)
+	opened := 0
+
+	// Office documents are told apart by their content, whatever their name
+	for i, content := range [][]byte{docx, odt} {
+		inspection, _, err := inspector.Inspect(context.Background(), location,
+			&types.FileMetadata{Id: types.FileId(fmt.Sprint(i)), Name: "outputs.zip", Size: int64(len(content))},
+			openBytes(content, &opened), nil)
+		assert.NoError(t, err)
+		assert.Nil(t, inspection)
+	}
+	inspection := inspect(t, "report.docx", makeArchive(t, FormatZip, map[string]string{"a.txt": "a"}), limits)
+	assert.Equal(t, "zip", inspection.Format)
+
+	// A zip is not a document just for having content types
+	inspection = inspect(t, "report.docx", makeArchive(t, FormatZip, map[string]string{
+		"[Content_Types].xml": "<Types/>",
+		"payload.exe":         "MZ",
+	}), limits)
+	require.Len(t, inspection.Members, 2)
+	assert.Equal(t, "payload.exe", inspection.Members[1].Path)
+
+	// They are still limited
+	inspection = inspect(t, "report.docx", docx, Limits{MaxRatio: 100, MaxDepth: 3, MaxMembers: 1})
+	assert.Equal(t, types.ArchiveLimitMembers, inspection.Exceeded)
+	bomb := makeDocx(t, [][2]string{{"word/media/zeros", strings.Repeat("0", 4*1024*1024)}})
+	inspection = inspect(t, "report.docx", bomb, limits)
+	assert.Equal(t, types.ArchiveLimitRatio, inspection.Exceeded)
+
+	// The objects embedded in a document are its members
+	embedding := makeDocx(t, [][2]string{{"word/embeddings/oleObject1.bin", "MZ\x90\x00"}})
+	inspection = inspect(t, "report.docx", embedding, limits)
+	assert.Equal(t, "zip", inspection.Format)
+	require.Len(t, inspection.Members, 1)
+	assert.Equal(t, "word/embeddings/oleObject1.bin", inspection.Members[0].Path)
+	assert.Equal(t, 1, inspection.Members[0].Depth)
+	inspection = inspect(t, "report.odt", makeZip(t, [][2]string{
+		{"mimetype", "application/vnd.oasis.opendocument.text"},
+		{"META-INF/manifest.xml", "<manifest/>"},
+		{"content.xml", "<content/>"},
+		{"Object 1", "MZ\x90\x00"},
+		{"Object 2/content.xml", "<content/>"},
+	}), limits)
+	require.Len(t, inspection.Members, 1)
+	assert.Equal(t, "Object 1", inspection.Members[0].Path)
+
+	// A document within an archive is visited as a whole, as any other
+	// member, then its embedded objects
+	visited := []string{}
+	visit := func(ctx context.Context, member *types.ArchiveMember, content io.Reader) error {
+		data, err := io.ReadAll(content)
+		if member.Path == "report.docx" {
+			assert.Equal(t, embedding, data)
+		}
+		visited = append(visited, member.Path)
+		member.Scan = &types.ScanResult{Verdict: types.ScanVerdictClean, Scanner: "test"}
+		return err
+	}
+	outer := makeArchive(t, FormatZip, map[string]string{"report.docx": string(embedding)})
+	inspection, _, err := inspector.Inspect(context.Background(), location,
+		&types.FileMetadata{Id: "outer", Name: "outputs.zip", Size: int64(len(outer))}, openBytes(outer, &opened), visit)
+	require.NoError(t, err)
+	require.Len(t, inspection.Members, 2)
+	assert.Equal(t, int64(len(embedding)), inspection.Members[0].Size)
+	assert.NotNil(t, inspection.Members[0].Scan)
+	assert.Equal(t, "report.docx/word/embeddings/oleObject1.bin", inspection.Members[1].Path)
+	assert.Equal(t, 2, inspection.Members[1].Depth)
+	assert.Equal(t, []string{"report.docx", "report.docx/word/embeddings/oleObject1.bin"}, visited)
+}
+
+func TestInspectInvalid(t *testing.T) {
+	inspector := NewInspector(10, limits)
+	opened := 0
+	_, _, err := inspector.Inspect(context.Background(), location,
+		&types.FileMetadata{Id: "id1", Name: "a.zip", Size: 10}, openBytes([]byte("PK\x03\x04truncated"), &opened), nil)
+	assert.ErrorIs(t, err, types.ErrInvalidObject)
+}
+
+func inspect(t *testing.T, name string, content []byte, limits Limits) *types.ArchiveInspection {
+	t.Helper()
+	opened := 0
+	inspection, _, err := NewInspector(10, limits).Inspect(context.Background(), location,
+		&types.FileMetadata{Id: "id", Name: name, Size: int64(len(content))}, openBytes(content, &opened), nil)
+	require.NoError(t, err)
+	require.NotNil(t, inspection)
+	return inspection
+}
+
+func openBytes(content []byte, opened *int) OpenFunc {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		*opened++
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+}
+
+// Archive of the files, in the order of their names
+func makeArchive(t *testing.T, format Format, files map[string]string) []byte {
+	t.Helper()
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	buf := &bytes.Buffer{}
+	w := NewWriter(format, buf)
+	for _, name := range names {
+		require.NoError(t, w.Add(name, int64(len(files[name])), modified, strings.NewReader(files[name])))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// Zip of the entries in order, with a mimetype stored uncompressed as in
+// an OpenDocument
+func makeZip(t *testing.T, entries [][2]string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, entry := range entries {
+		method := zip.Deflate
+		if entry[0] == "mimetype" {
+			method = zip.Store
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: entry[0], Method: method})
+		require.NoError(t, err)
+		_, err = w.Write([]byte(entry[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// Word document with the other parts
+func makeDocx(t *testing.T, parts [][2]string) []byte {
+	t.Helper()
+	return makeZip(t, append([][2]string{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+			`</Relationships>`},
+		{"word/document.xml", "<w:document/>"},
+	}, parts...))
+}
+
+func gzipped(t *testing.T, content string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	_, err := gw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,133 @@
+// Package archive builds archives of several files as they are streamed,
+// and inspects the members of archive files
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+type Format string
+
+const (
+	FormatZip = Format("zip")
+	FormatTar = Format("tar")
+	// A gzip file is inspected, as a tar or a single member, but not written
+	FormatGzip = Format("gzip")
+)
+
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(value); format {
+	case FormatZip, FormatTar:
+		return format, nil
+	default:
+		return "", types.NewErrInvalidObjectF("unsupported archive format [%s]", value)
+	}
+}
+
+// Media type of an archive of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatTar:
+		return "application/x-tar"
+	case FormatGzip:
+		return "application/gzip"
+	default:
+		return "application/zip"
+	}
+}
+
+// Writer adds files to an archive written to an underlying stream
+type Writer interface {
+	// Add a file of the given size to the archive, with its content read from r
+	Add(name string, size int64, modified time.Time, r io.Reader) error
+	// Write the trailer of the archive. The archive is invalid without it
+	Close() error
+}
+
+func NewWriter(format Format, w io.Writer) Writer {
+	if format == FormatTar {
+		return &tarWriter{tw: tar.NewWriter(w)}
+	}
+	return &zipWriter{zw: zip.NewWriter(w)}
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipWriter) Add(name string, size int64, modified time.Time, r io.Reader) error {
+	entry, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, r)
+	return err
+}
+
+func (w *zipWriter) Close() error {
+	return w.zw.Close()
+}
+
+type tarWriter struct {
+	tw *tar.Writer
+}
+
+func (w *tarWriter) Add(name string, size int64, modified time.Time, r io.Reader) error {
+	err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+		ModTime:  modified,
+		Format:   tar.FormatPAX,
+	})
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(w.tw, r)
+	if err == nil && n != size {
+		return fmt.Errorf("wrote %d bytes of %s but expected %d", n, name, size)
+	}
+	return err
+}
+
+func (w *tarWriter) Close() error {
+	return w.tw.Close()
+}
+
+// Names of the entries of files in an archive. Names are relative paths
+// without any ".." elements, so an archive cannot be extracted outside of
+// its directory, and are unique
+type EntryNames struct {
+	used map[string]bool
+}
+
+func NewEntryNames() *EntryNames {
+	return &EntryNames{used: map[string]bool{}}
+}
+
+// Entry name of a file with the name in storage. A name already used is
+// placed in a directory named by the file id
+func (n *EntryNames) Name(name string, fileId types.FileId) string {
+	entry := path.Clean("/" + name)[1:]
+	if !fs.ValidPath(entry) || entry == "." {
+		entry = string(fileId)
+	}
+	if n.used[entry] {
+		entry = path.Join(string(fileId), entry)
+	}
+	n.used[entry] = true
+	return entry
+}
@@ -0,0 +1,78 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+var modified = time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+
+func TestZipWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(FormatZip, buf)
+	require.NoError(t, w.Add("a/file1.txt", 5, modified, strings.NewReader("hello")))
+	require.NoError(t, w.Add("file2.txt", 5, modified, strings.NewReader("world")))
+	require.NoError(t, w.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 2)
+	assert.Equal(t, "a/file1.txt", zr.File[0].Name)
+	assert.True(t, zr.File[0].Modified.Equal(modified))
+	f, err := zr.File[1].Open()
+	require.NoError(t, err)
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(content))
+}
+
+func TestTarWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(FormatTar, buf)
+	require.NoError(t, w.Add("a/file1.txt", 5, modified, strings.NewReader("hello")))
+	require.NoError(t, w.Close())
+
+	tr := tar.NewReader(buf)
+	header, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "a/file1.txt", header.Name)
+	assert.Equal(t, int64(5), header.Size)
+	content, err := io.ReadAll(tr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+	_, err = tr.Next()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// A file shorter than its size is an error
+	w = NewWriter(FormatTar, &bytes.Buffer{})
+	assert.Error(t, w.Add("short.txt", 10, modified, strings.NewReader("hello")))
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("tar")
+	require.NoError(t, err)
+	assert.Equal(t, FormatTar, format)
+	assert.Equal(t, "application/x-tar", format.ContentType())
+
+	_, err = ParseFormat("rar")
+	assert.ErrorIs(t, err, types.ErrInvalidObject)
+}
+
+func TestEntryNames(t *testing.T) {
+	names := NewEntryNames()
+	assert.Equal(t, "a/file1.txt", names.Name("a/file1.txt", "id1"))
+	assert.Equal(t, "id2/a/file1.txt", names.Name("a/file1.txt", "id2"))
+	assert.Equal(t, "etc/passwd", names.Name("../../etc/passwd", "id3"))
+	assert.Equal(t, "b/file.txt", names.Name("/b/file.txt", "id4"))
+	assert.Equal(t, "id5", names.Name("", "id5"))
+}
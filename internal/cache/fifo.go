@@ -0,0 +1,47 @@
+// Package cache holds results computed from the content of files, which
+// are keyed by the location and id of the file, as ids are unique only
+// within a store. Most file ids change with the content, e.g. an S3
+// checksum or version ID, but the default ETag of generic storage is a hash
+// of the size and modification time, so content rewritten with the same
+// size and time keeps the results of the old content
+package cache
+
+import "sync"
+
+// FIFO caches at most its capacity of values, evicting the oldest first.
+// It is safe for concurrent use
+type FIFO[K comparable, V any] struct {
+	mu       sync.Mutex
+	values   map[K]V
+	order    []K // Keys, oldest first
+	capacity int
+}
+
+// New cache of at most capacity values, which is at least 1
+func NewFIFO[K comparable, V any](capacity int) *FIFO[K, V] {
+	return &FIFO[K, V]{
+		values:   map[K]V{},
+		capacity: max(capacity, 1),
+	}
+}
+
+func (c *FIFO[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, exists := c.values[key]
+	return value, exists
+}
+
+// Cache the value, evicting the oldest once at capacity
+func (c *FIFO[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.values[key]; !exists {
+		if len(c.order) >= c.capacity {
+			delete(c.values, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, key)
+	}
+	c.values[key] = value
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFIFO(t *testing.T) {
+	c := NewFIFO[string, int](2)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("a", 3) // Replacing a value does not evict
+	value, exists := c.Get("a")
+	assert.True(t, exists)
+	assert.Equal(t, 3, value)
+
+	c.Put("c", 4)
+	_, exists = c.Get("a")
+	assert.False(t, exists)
+	value, exists = c.Get("b")
+	assert.True(t, exists)
+	assert.Equal(t, 2, value)
+}
@@ -0,0 +1,14 @@
+package cache
+
+import "github.com/ucl-arc-tre/egress/internal/types"
+
+// Key of the results of a file, whose id is unique only within its
+// location e.g. two buckets may have objects with the same version ID
+type FileKey struct {
+	Location string
+	FileId   types.FileId
+}
+
+func KeyOf(location types.LocationURI, fileId types.FileId) FileKey {
+	return FileKey{Location: location.String(), FileId: fileId}
+}
@@ -0,0 +1,162 @@
+// Package checks runs output checks on the content of files, reporting
+// their findings for checkers and optionally blocking downloads
+package checks
+
+import (
+	"context"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/ucl-arc-tre/egress/internal/cache"
+	"github.com/ucl-arc-tre/egress/internal/checks/pii"
+	"github.com/ucl-arc-tre/egress/internal/checks/sdc"
+	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Checker inspects the content of a file for findings
+type Checker interface {
+	Name() string
+	// Whether the file is checked e.g. by its media type or extension
+	Applies(metadata types.FileMetadata) bool
+	Check(ctx context.Context, name string, content io.Reader) ([]types.Finding, error)
+}
+
+// Checker with the destinations that a file with any of its findings
+// cannot be downloaded to
+type Check struct {
+	Checker
+	BlockDestinations []types.Destination
+}
+
+// Opens the content of the file to be checked
+type OpenFunc func(ctx context.Context) (io.ReadCloser, error)
+
+// Pipeline runs each check that applies to a file in turn. Reports are
+// cached by the location and id of the file, with the checks that apply
+type Pipeline struct {
+	checks  []Check
+	maxSize int64 // Bytes of the largest file that is checked
+	reports *cache.FIFO[reportKey, types.FileReport]
+}
+
+// Which checks apply depends on the name and media type of the file, and
+// how a file is read on its extension e.g. .tsv, so the same content may
+// have different reports under different names
+type reportKey struct {
+	file      cache.FileKey
+	checks    string // Names of the checks that apply
+	extension string
+}
+
+// Pipeline of the enabled checks, or nil if none are enabled
+func Provider(cfg config.ChecksConfigBundle) *Pipeline {
+	checks := []Check{}
+	if cfg.SDC.Enabled {
+		checks = append(checks, Check{
+			Checker:           sdc.New(cfg.SDC.Threshold, cfg.SDC.RoundingBase, cfg.SDC.IgnoreColumns),
+			BlockDestinations: destinations(cfg.SDC.BlockDestinations),
+		})
+	}
+	if cfg.PII.Enabled {
+		checks = append(checks, Check{
+			Checker:           pii.New(cfg.PII.Types),
+			BlockDestinations: destinations(cfg.PII.BlockDestinations),
+		})
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+	return NewPipeline(cfg.CacheSize, cfg.MaxSize, checks...)
+}
+
+func NewPipeline(cacheSize int, maxSize int64, checks ...Check) *Pipeline {
+	return &Pipeline{
+		checks:  checks,
+		maxSize: maxSize,
+		reports: cache.NewFIFO[reportKey, types.FileReport](cacheSize),
+	}
+}
+
+// Report of the checks that apply to the file, opening its content for
+// each. A cached report is not rechecked, so the metadata should include
+// the media type of the file if it is detected. A file larger than the max
+// size that checks apply to is an ErrInvalidObject, as it cannot be checked
+func (p *Pipeline) Report(
+	ctx context.Context,
+	location types.LocationURI,
+	metadata *types.FileMetadata,
+	open OpenFunc,
+) (types.FileReport, error) {
+	applicable := []Check{}
+	names := []string{}
+	for _, check := range p.checks {
+		if check.Applies(*metadata) {
+			applicable = append(applicable, check)
+			names = append(names, check.Name())
+		}
+	}
+	key := reportKey{
+		file:      cache.KeyOf(location, metadata.Id),
+		checks:    strings.Join(names, ","),
+		extension: strings.ToLower(path.Ext(metadata.Name)),
+	}
+	if report, exists := p.reports.Get(key); exists {
+		return report, nil
+	}
+	if len(applicable) > 0 && metadata.Size > p.maxSize {
+		return types.FileReport{}, types.NewErrInvalidObjectF("file %s size %d is greater than the max size checked %d",
+			metadata.Id, metadata.Size, p.maxSize)
+	}
+
+	report := types.FileReport{FileId: metadata.Id, Checks: []string{}, Findings: []types.Finding{}}
+	for _, check := range applicable {
+		findings, err := checkWith(ctx, check, metadata.Name, open)
+		if err != nil {
+			return types.FileReport{}, err
+		}
+		report.Checks = append(report.Checks, check.Name())
+		report.Findings = append(report.Findings, findings...)
+	}
+	p.reports.Put(key, report)
+	return report, nil
+}
+
+// Findings of the report that block a download to the destination
+func (p *Pipeline) Blocking(report types.FileReport, destination types.Destination) []types.Finding {
+	blocking := []types.Finding{}
+	for _, finding := range report.Findings {
+		if p.blocks(finding.Check, destination) {
+			blocking = append(blocking, finding)
+		}
+	}
+	return blocking
+}
+
+func (p *Pipeline) blocks(checkName string, destination types.Destination) bool {
+	for _, check := range p.checks {
+		if check.Name() == checkName && slices.Contains(check.BlockDestinations, destination) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkWith(ctx context.Context, checker Checker, name string, open OpenFunc) ([]types.Finding, error) {
+	content, err := open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+	return checker.Check(ctx, name, content)
+}
+
+func destinations(values []string) []types.Destination {
+	result := []types.Destination{}
+	for _, value := range values {
+		result = append(result, types.Destination(value))
+	}
+	return result
+}
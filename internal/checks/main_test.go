@@ -0,0 +1,96 @@
+package checks
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+type mockChecker struct {
+	name     string
+	findings []types.Finding
+	calls    int
+}
+
+func (c *mockChecker) Name() string {
+	return c.name
+}
+
+func (c *mockChecker) Applies(metadata types.FileMetadata) bool {
+	return strings.HasSuffix(metadata.Name, ".csv")
+}
+
+func (c *mockChecker) Check(ctx context.Context, name string, content io.Reader) ([]types.Finding, error) {
+	c.calls++
+	_, err := io.ReadAll(content)
+	return c.findings, err
+}
+
+var location = types.LocationURI{Scheme: "s3", Host: "bucket"}
+
+func open(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("a,b\n1,2\n")), nil
+}
+
+func TestPipelineReport(t *testing.T) {
+	finding := types.Finding{Check: "mock", Type: "small_count", Line: 2, Column: 1, Count: 1}
+	checker := &mockChecker{name: "mock", findings: []types.Finding{finding}}
+	pipeline := NewPipeline(10, 100, Check{Checker: checker, BlockDestinations: []types.Destination{"public"}})
+
+	report, err := pipeline.Report(context.Background(), location, &types.FileMetadata{Id: "id1", Name: "a.csv"}, open)
+	require.NoError(t, err)
+	assert.Equal(t, types.FileReport{FileId: "id1", Checks: []string{"mock"}, Findings: []types.Finding{finding}}, report)
+	assert.Equal(t, []types.Finding{finding}, pipeline.Blocking(report, "public"))
+	assert.Empty(t, pipeline.Blocking(report, "trusted"))
+
+	// Reports are cached
+	_, err = pipeline.Report(context.Background(), location, &types.FileMetadata{Id: "id1", Name: "a.csv"}, open)
+	require.NoError(t, err)
+	assert.Equal(t, 1, checker.calls)
+
+	// Checks only run on the files they apply to
+	report, err = pipeline.Report(context.Background(), location, &types.FileMetadata{Id: "id2", Name: "a.txt"}, open)
+	require.NoError(t, err)
+	assert.Empty(t, report.Checks)
+	assert.Empty(t, report.Findings)
+	assert.Equal(t, 1, checker.calls)
+
+	// The same file under a name that checks apply to is checked
+	report, err = pipeline.Report(context.Background(), location, &types.FileMetadata{Id: "id2", Name: "a.csv"}, open)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mock"}, report.Checks)
+	assert.Equal(t, 2, checker.calls)
+	_, err = pipeline.Report(context.Background(), types.LocationURI{Scheme: "s3", Host: "other-bucket"},
+		&types.FileMetadata{Id: "id2", Name: "a.csv"}, open)
+	require.NoError(t, err)
+	assert.Equal(t, 3, checker.calls)
+
+	// Files larger than the max size are not checked, unless no checks apply
+	_, err = pipeline.Report(context.Background(), location, &types.FileMetadata{Id: "id3", Name: "b.csv", Size: 101}, open)
+	assert.ErrorIs(t, err, types.ErrInvalidObject)
+	_, err = pipeline.Report(context.Background(), location, &types.FileMetadata{Id: "id4", Name: "b.txt", Size: 101}, open)
+	require.NoError(t, err)
+	assert.Equal(t, 3, checker.calls)
+}
+
+func TestProvider(t *testing.T) {
+	assert.Nil(t, Provider(config.ChecksConfigBundle{CacheSize: 10}))
+
+	pipeline := Provider(config.ChecksConfigBundle{
+		CacheSize: 10,
+		SDC:       config.SDCCheckConfig{Enabled: true, Threshold: 10, BlockDestinations: []string{"public"}},
+		PII:       config.PIICheckConfig{Enabled: true},
+	})
+	require.Len(t, pipeline.checks, 2)
+	assert.Equal(t, "sdc", pipeline.checks[0].Name())
+	assert.Equal(t, []types.Destination{"public"}, pipeline.checks[0].BlockDestinations)
+	assert.Equal(t, "pii", pipeline.checks[1].Name())
+	assert.Empty(t, pipeline.checks[1].BlockDestinations)
+}
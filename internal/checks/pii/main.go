@@ -0,0 +1,190 @@
+// Package pii checks text for likely personal identifiers, which suggest
+// that row-level data is leaving the TRE
+package pii
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+const (
+	Name = "pii"
+
+	FindingEmail       = "email"
+	FindingNHSNumber   = "nhs_number"
+	FindingNINumber    = "ni_number"
+	FindingPostcode    = "postcode"
+	FindingDateOfBirth = "date_of_birth"
+
+	maxChunk = 64 * 1024 // Longer lines are checked in chunks of this many bytes
+)
+
+// Types of finding, in the order they are checked
+var FindingTypes = []string{FindingEmail, FindingNHSNumber, FindingNINumber, FindingPostcode, FindingDateOfBirth}
+
+var (
+	emailRegex     = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+	nhsNumberRegex = regexp.MustCompile(`\b(\d{3} \d{3} \d{4}|\d{3}-\d{3}-\d{4}|\d{10})\b`)
+	// Excludes the prefixes that are not allocated
+	niNumberRegex = regexp.MustCompile(`\b([A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z]) ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`)
+	postcodeRegex = regexp.MustCompile(`\b(GIR ?0AA|[A-PR-UWYZ][A-HK-Y]?\d[A-Z\d]? ?\d[ABD-HJLNP-UW-Z]{2})\b`)
+	dateRegex     = regexp.MustCompile(`\b(\d{1,2}[/.-]\d{1,2}[/.-]\d{4}|\d{4}-\d{2}-\d{2})\b`)
+	birthRegex    = regexp.MustCompile(`(?i)\b(dob|d\.o\.b|date[ _-]?of[ _-]?birth|birth[ _-]?date|born)\b`)
+
+	unallocatedNIPrefixes = map[string]bool{"BG": true, "GB": true, "KN": true, "NK": true, "NT": true, "TN": true, "ZZ": true}
+	dateLayouts           = []string{"2/1/2006", "1/2/2006", "2-1-2006", "2.1.2006", "2006-01-02"}
+)
+
+var descriptions = map[string]string{
+	FindingEmail:       "Email addresses",
+	FindingNHSNumber:   "NHS numbers",
+	FindingNINumber:    "National Insurance numbers",
+	FindingPostcode:    "Postcodes",
+	FindingDateOfBirth: "Dates of birth",
+}
+
+// Checker finds likely personal identifiers in text files
+type Checker struct {
+	types map[string]bool
+}
+
+// New checker finding the types of personal identifier, or all types if
+// none are given
+func New(findingTypes []string) *Checker {
+	if len(findingTypes) == 0 {
+		findingTypes = FindingTypes
+	}
+	c := &Checker{types: map[string]bool{}}
+	for _, findingType := range findingTypes {
+		c.types[findingType] = true
+	}
+	return c
+}
+
+func (c *Checker) Name() string {
+	return Name
+}
+
+// Text files are checked, by their detected media type if known and
+// otherwise by their extension
+func (c *Checker) Applies(metadata types.FileMetadata) bool {
+	if metadata.MediaType != "" {
+		return strings.HasPrefix(metadata.MediaType, "text/")
+	}
+	switch strings.ToLower(path.Ext(metadata.Name)) {
+	case ".txt", ".csv", ".tsv", ".tab", ".json", ".md", ".log", ".xml", ".html":
+		return true
+	default:
+		return false
+	}
+}
+
+// Check the text, with one finding per type giving the first line and
+// number of occurrences. A date is a date of birth if it is on a line, or
+// in a file whose first line (e.g. a table header), mentions birth
+func (c *Checker) Check(ctx context.Context, name string, content io.Reader) ([]types.Finding, error) {
+	findings := map[string]*types.Finding{}
+	add := func(findingType string, line int, count int) {
+		if count == 0 || !c.types[findingType] {
+			return
+		}
+		if finding, exists := findings[findingType]; exists {
+			finding.Count += count
+			return
+		}
+		findings[findingType] = &types.Finding{
+			Check:   Name,
+			Type:    findingType,
+			Line:    line,
+			Count:   count,
+			Message: fmt.Sprintf("%s found", descriptions[findingType]),
+		}
+	}
+
+	r := bufio.NewReaderSize(content, maxChunk)
+	headerMentionsBirth := false
+	for line := 1; ; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunk, isPrefix, err := r.ReadLine()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, types.NewErrServerF("[pii] failed to read file: %v", err)
+		}
+		text := string(chunk)
+		mentionsBirth := birthRegex.MatchString(text)
+		if line == 1 {
+			headerMentionsBirth = mentionsBirth
+		}
+
+		add(FindingEmail, line, len(emailRegex.FindAllString(text, -1)))
+		add(FindingNHSNumber, line, countMatches(nhsNumberRegex, text, isNHSNumber))
+		add(FindingNINumber, line, countMatches(niNumberRegex, text, isNINumber))
+		add(FindingPostcode, line, len(postcodeRegex.FindAllString(text, -1)))
+		if mentionsBirth || headerMentionsBirth {
+			add(FindingDateOfBirth, line, countMatches(dateRegex, text, isDate))
+		}
+		if !isPrefix {
+			line++
+		}
+	}
+
+	result := []types.Finding{}
+	for _, findingType := range FindingTypes {
+		if finding, exists := findings[findingType]; exists {
+			result = append(result, *finding)
+		}
+	}
+	return result, nil
+}
+
+func countMatches(regex *regexp.Regexp, text string, valid func(string) bool) int {
+	count := 0
+	for _, match := range regex.FindAllString(text, -1) {
+		if valid(match) {
+			count++
+		}
+	}
+	return count
+}
+
+// Whether the NHS number has a valid modulus 11 check digit
+func isNHSNumber(match string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(match)
+	if len(digits) != 10 {
+		return false
+	}
+	sum := 0
+	for i := range 9 {
+		sum += int(digits[i]-'0') * (10 - i)
+	}
+	check := 11 - sum%11
+	if check == 11 {
+		check = 0
+	}
+	return check != 10 && check == int(digits[9]-'0')
+}
+
+func isNINumber(match string) bool {
+	return !unallocatedNIPrefixes[match[:2]]
+}
+
+func isDate(match string) bool {
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, match); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package pii
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+func TestCheck(t *testing.T) {
+	content := `Summary of results
+Contact jane.doe@example.ac.uk or data@example.com for queries
+Patient 943 476 5919 was excluded, unlike 943 476 5918
+NI number AB 12 34 56 C, not GB123456A
+Recruited at WC1E 6BT
+Run on 2024-01-31
+DOB 31/01/1980, born 1980-13-01
+`
+	findings, err := New(nil).Check(context.Background(), "results.txt", strings.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, []types.Finding{
+		{Check: Name, Type: FindingEmail, Line: 2, Count: 2, Message: "Email addresses found"},
+		{Check: Name, Type: FindingNHSNumber, Line: 3, Count: 1, Message: "NHS numbers found"},
+		{Check: Name, Type: FindingNINumber, Line: 4, Count: 1, Message: "National Insurance numbers found"},
+		{Check: Name, Type: FindingPostcode, Line: 5, Count: 1, Message: "Postcodes found"},
+		{Check: Name, Type: FindingDateOfBirth, Line: 7, Count: 1, Message: "Dates of birth found"},
+	}, findings)
+}
+
+func TestCheckTable(t *testing.T) {
+	// Dates are dates of birth if the header mentions birth
+	content := "id,date_of_birth,nhs_number\n1,1980-01-31,9434765919\n2,1981-02-28,4010232137\n"
+	findings, err := New([]string{FindingDateOfBirth}).Check(context.Background(), "cohort.csv", strings.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, []types.Finding{
+		{Check: Name, Type: FindingDateOfBirth, Line: 2, Count: 2, Message: "Dates of birth found"},
+	}, findings)
+
+	findings, err = New(nil).Check(context.Background(), "cohort.csv", strings.NewReader("year,count\n2024,10\n"))
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestCheckLongLine(t *testing.T) {
+	content := strings.Repeat("a", 2*maxChunk) + " jane@example.com\nsecond line data@example.com\n"
+	findings, err := New(nil).Check(context.Background(), "log.txt", strings.NewReader(content))
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, 1, findings[0].Line)
+	assert.Equal(t, 2, findings[0].Count)
+}
+
+func TestIsNHSNumber(t *testing.T) {
+	assert.True(t, isNHSNumber("9434765919"))
+	assert.True(t, isNHSNumber("401-023-2137"))
+	assert.False(t, isNHSNumber("9434765918"))
+	assert.False(t, isNHSNumber("1234567890")) // Check digit of 10 is invalid
+}
+
+func TestApplies(t *testing.T) {
+	checker := New(nil)
+	assert.True(t, checker.Applies(types.FileMetadata{Name: "a.bin", MediaType: "text/plain"}))
+	assert.False(t, checker.Applies(types.FileMetadata{Name: "a.txt", MediaType: "image/png"}))
+	assert.True(t, checker.Applies(types.FileMetadata{Name: "a.json"}))
+	assert.False(t, checker.Applies(types.FileMetadata{Name: "a.parquet"}))
+}
@@ -0,0 +1,191 @@
+// Package sdc checks tables for statistical disclosure control, i.e. that
+// the counts in them cannot identify individuals
+package sdc
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+const (
+	Name = "sdc"
+
+	FindingSmallCount = "small_count"
+	FindingUnrounded  = "unrounded"
+	FindingUnparsable = "unparsable"
+)
+
+// Checker flags the small and unrounded counts in CSV and TSV files. A
+// count is a cell holding a whole number; other cells are not checked
+type Checker struct {
+	threshold     float64
+	roundingBase  int64
+	ignoreColumns map[string]bool
+}
+
+// New checker flagging non-zero counts below the threshold, and counts
+// that are not a multiple of the rounding base unless it is 0. Columns with
+// the ignored header names e.g. year, are not checked
+func New(threshold int, roundingBase int, ignoreColumns []string) *Checker {
+	c := &Checker{
+		threshold:     float64(threshold),
+		roundingBase:  int64(roundingBase),
+		ignoreColumns: map[string]bool{},
+	}
+	for _, column := range ignoreColumns {
+		c.ignoreColumns[column] = true
+	}
+	return c
+}
+
+func (c *Checker) Name() string {
+	return Name
+}
+
+// Files are checked if they have a .csv, .tsv or .tab extension
+func (c *Checker) Applies(metadata types.FileMetadata) bool {
+	_, ok := delimiter(metadata.Name)
+	return ok
+}
+
+// Check the table, with one finding per kind and column giving the first
+// line and number of cells found. A file that fails to be parsed has an
+// unparsable finding, since it cannot be checked
+func (c *Checker) Check(ctx context.Context, name string, content io.Reader) ([]types.Finding, error) {
+	comma, _ := delimiter(name)
+	r := csv.NewReader(content)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = comma == '\t' // TSV files rarely escape quotes as CSV files do
+	r.ReuseRecord = true
+
+	t := table{checker: c, findings: map[findingKey]*types.Finding{}}
+	for isFirst := true; ; isFirst = false {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			t.add(FindingUnparsable, parseErr.StartLine, 0, parseErr.Err.Error())
+			break
+		} else if err != nil {
+			return nil, types.NewErrServerF("[sdc] failed to read file: %v", err)
+		}
+		if isFirst && isHeader(record) {
+			t.header = append([]string{}, record...)
+			continue
+		}
+		for i, cell := range record {
+			line, _ := r.FieldPos(i)
+			t.checkCell(line, i, cell)
+		}
+	}
+	return t.orderedFindings(), nil
+}
+
+type findingKey struct {
+	kind   string
+	column int
+}
+
+type table struct {
+	checker  *Checker
+	header   []string
+	findings map[findingKey]*types.Finding
+	order    []findingKey // In the order first found
+}
+
+func (t *table) checkCell(line int, index int, cell string) {
+	if index < len(t.header) && t.checker.ignoreColumns[t.header[index]] {
+		return
+	}
+	value, ok := parseCount(cell)
+	if !ok || value == 0 {
+		return
+	}
+	if value > 0 && value < t.checker.threshold {
+		t.add(FindingSmallCount, line, index+1,
+			fmt.Sprintf("Counts in %s are below %d", t.columnName(index), int(t.checker.threshold)))
+	}
+	if base := t.checker.roundingBase; base > 0 && math.Abs(value) < 1<<53 && int64(value)%base != 0 {
+		t.add(FindingUnrounded, line, index+1,
+			fmt.Sprintf("Counts in %s are not rounded to a multiple of %d", t.columnName(index), base))
+	}
+}
+
+func (t *table) add(kind string, line int, column int, message string) {
+	key := findingKey{kind: kind, column: column}
+	if finding, exists := t.findings[key]; exists {
+		finding.Count++
+		return
+	}
+	t.findings[key] = &types.Finding{
+		Check:   Name,
+		Type:    kind,
+		Line:    line,
+		Column:  column,
+		Count:   1,
+		Message: message,
+	}
+	t.order = append(t.order, key)
+}
+
+func (t *table) orderedFindings() []types.Finding {
+	findings := []types.Finding{}
+	for _, key := range t.order {
+		findings = append(findings, *t.findings[key])
+	}
+	return findings
+}
+
+// Name of the column in messages e.g. column 3 "count"
+func (t *table) columnName(index int) string {
+	if index < len(t.header) && t.header[index] != "" {
+		return fmt.Sprintf("column %d %q", index+1, t.header[index])
+	}
+	return fmt.Sprintf("column %d", index+1)
+}
+
+// Value of a cell holding a whole number e.g. "12" or "12.0"
+func parseCount(cell string) (float64, bool) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value, value == math.Trunc(value)
+}
+
+// The first record is a header if any of its cells is not empty or a number
+func isHeader(record []string) bool {
+	for _, cell := range record {
+		cell = strings.TrimSpace(cell)
+		if _, err := strconv.ParseFloat(cell, 64); err != nil && cell != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func delimiter(name string) (rune, bool) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return ',', true
+	case ".tsv", ".tab":
+		return '\t', true
+	default:
+		return 0, false
+	}
+}
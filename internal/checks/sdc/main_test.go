@@ -0,0 +1,64 @@
+package sdc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+func TestCheck(t *testing.T) {
+	content := `region,year,count,mean
+north,2024,15,3.2
+south,2024,4,1.5
+east,2024,0,0
+west,2024,7,2.25
+`
+	checker := New(10, 5, []string{"year"})
+	findings, err := checker.Check(context.Background(), "results.csv", strings.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, []types.Finding{
+		{Check: Name, Type: FindingSmallCount, Line: 3, Column: 3, Count: 2, Message: `Counts in column 3 "count" are below 10`},
+		{Check: Name, Type: FindingUnrounded, Line: 3, Column: 3, Count: 2, Message: `Counts in column 3 "count" are not rounded to a multiple of 5`},
+	}, findings)
+}
+
+func TestCheckTSV(t *testing.T) {
+	// A header may have empty cells, and rounding is not checked with a base of 0
+	content := "group\tcount\t\na\t20\t30.0\nb\t5\t\n"
+	checker := New(10, 0, nil)
+	findings, err := checker.Check(context.Background(), "results.TSV", strings.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, []types.Finding{
+		{Check: Name, Type: FindingSmallCount, Line: 3, Column: 2, Count: 1, Message: `Counts in column 2 "count" are below 10`},
+	}, findings)
+
+	// Without a header every row is checked
+	content = "20\t30\n5\t40\n"
+	findings, err = checker.Check(context.Background(), "results.tab", strings.NewReader(content))
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, 2, findings[0].Line)
+	assert.Equal(t, "Counts in column 1 are below 10", findings[0].Message)
+}
+
+func TestCheckUnparsable(t *testing.T) {
+	checker := New(10, 0, nil)
+	findings, err := checker.Check(context.Background(), "results.csv", strings.NewReader("a,count\n\"b,5\n"))
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, FindingUnparsable, findings[0].Type)
+	assert.Equal(t, 2, findings[0].Line)
+}
+
+func TestApplies(t *testing.T) {
+	checker := New(10, 0, nil)
+	assert.True(t, checker.Applies(types.FileMetadata{Name: "a/results.csv"}))
+	assert.True(t, checker.Applies(types.FileMetadata{Name: "results.TSV"}))
+	assert.False(t, checker.Applies(types.FileMetadata{Name: "results.txt"}))
+	assert.False(t, checker.Applies(types.FileMetadata{Name: "csv"}))
+}
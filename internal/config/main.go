@@ -2,8 +2,10 @@ package config
 
 import (
 	"fmt"
+	"mime"
 	"net/url"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/knadh/koanf/parsers/yaml"
@@ -15,9 +17,15 @@ import (
 )
 
 const (
-	configPath  = "/etc/egress/config.yaml"
-	tlsCertDir  = "/etc/egress/tls"
-	defaultPort = "8080"
+	configPath       = "/etc/egress/config.yaml"
+	tlsCertDir       = "/etc/egress/tls"
+	serverTLSCertDir = "/etc/egress/server-tls"
+	defaultPort      = "8080"
+	defaultAdminRole = "admin"
+
+	defaultBasicLockoutMaxFailures = 5
+	defaultBasicLockoutDuration    = 15 * time.Minute
+	defaultSnapshotRetention       = 30 * 24 * time.Hour
 
 	BaseURL                = "/v1"
 	ServerShutdownDuration = 30 * time.Second
@@ -56,22 +64,260 @@ func IsDebug() bool {
 	return k.Bool("debug")
 }
 
+// Config of the single storage backend defined by storage.provider
 func StorageConfig() StorageConfigBundle {
-	provider := k.String("storage.provider")
+	return storageConfigFrom(k.Cut("storage"))
+}
+
+// Configs of all storage backends. Either the list of named backends in
+// storage.backends or, if not defined, the single storage.provider backend
+func StorageConfigs() []StorageConfigBundle {
+	if !k.Exists("storage.backends") {
+		return []StorageConfigBundle{StorageConfig()}
+	}
+	cfgs := []StorageConfigBundle{}
+	for _, bk := range k.Slices("storage.backends") {
+		cfgs = append(cfgs, storageConfigFrom(bk))
+	}
+	return cfgs
+}
+
+// Storage backend config from keys relative to the backend
+func storageConfigFrom(sk *koanf.Koanf) StorageConfigBundle {
+	provider := sk.String("provider")
 	cfg := StorageConfigBundle{
+		Name:       sk.String("name"),
 		Provider:   provider,
+		Hosts:      sk.Strings("hosts"),
 		TLSCertDir: tlsCertDir,
 	}
+	if cfg.Name == "" {
+		cfg.Name = provider
+	}
 	if provider == string(types.StorageProviderS3) {
 		cfg.S3 = S3StorageConfig{
-			Region:          k.String("storage.s3.region"),
-			AccessKeyId:     k.String("storage.s3.access_key_id"),
-			SecretAccessKey: k.String("storage.s3.secret_access_key"),
+			Region:          sk.String("s3.region"),
+			AccessKeyId:     sk.String("s3.access_key_id"),
+			SecretAccessKey: sk.String("s3.secret_access_key"),
+			Buckets:         s3BucketConfigsFrom(sk.Slices("s3.buckets")),
+			FileId:          sk.String("s3.file_id"),
+		}
+	}
+	if provider == string(types.StorageProviderAzBlob) {
+		cfg.AzBlob = AzBlobStorageConfig{
+			AccountName: sk.String("azblob.account_name"),
+			AccountKey:  sk.String("azblob.account_key"),
+			SASToken:    sk.String("azblob.sas_token"),
+			ServiceURL:  sk.String("azblob.service_url"),
+			FileId:      sk.String("azblob.file_id"),
 		}
 	}
 	return cfg
 }
 
+func s3BucketConfigsFrom(bks []*koanf.Koanf) []S3BucketConfig {
+	cfgs := []S3BucketConfig{}
+	for _, bk := range bks {
+		cfgs = append(cfgs, S3BucketConfig{
+			Bucket:          bk.String("bucket"),
+			Endpoint:        bk.String("endpoint"),
+			Region:          bk.String("region"),
+			UsePathStyle:    bk.Bool("path_style"),
+			CABundle:        bk.String("ca_bundle"),
+			AccessKeyId:     bk.String("access_key_id"),
+			SecretAccessKey: bk.String("secret_access_key"),
+			RoleARN:         bk.String("role_arn"),
+		})
+	}
+	return cfgs
+}
+
+// Retry config of all storage backends, with defaults for unset values
+func StorageRetryConfig() StorageRetryConfigBundle {
+	cfg := StorageRetryConfigBundle{
+		MaxAttempts:      3,
+		InitialBackoff:   100 * time.Millisecond,
+		MaxBackoff:       2 * time.Second,
+		FailureThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+	if k.Exists("storage.retry.max_attempts") {
+		cfg.MaxAttempts = k.Int("storage.retry.max_attempts")
+	}
+	if k.Exists("storage.retry.initial_backoff") {
+		cfg.InitialBackoff = k.Duration("storage.retry.initial_backoff")
+	}
+	if k.Exists("storage.retry.max_backoff") {
+		cfg.MaxBackoff = k.Duration("storage.retry.max_backoff")
+	}
+	if k.Exists("storage.retry.failure_threshold") {
+		cfg.FailureThreshold = k.Int("storage.retry.failure_threshold")
+	}
+	if k.Exists("storage.retry.breaker_cooldown") {
+		cfg.BreakerCooldown = k.Duration("storage.retry.breaker_cooldown")
+	}
+	return cfg
+}
+
+// Actions taken on files in storage after a download or rejection, in order
+func StorageActions() []StorageActionConfig {
+	cfgs := []StorageActionConfig{}
+	for _, ak := range k.Slices("storage.actions") {
+		cfgs = append(cfgs, StorageActionConfig{
+			Destination: ak.String("destination"),
+			On:          ak.String("on"),
+			Type:        ak.String("type"),
+			Tags:        ak.StringMap("tags"),
+			Prefix:      ak.String("prefix"),
+		})
+	}
+	return cfgs
+}
+
+// Malware scanning config, with defaults for unset values
+func ScanConfig() ScanConfigBundle {
+	cfg := ScanConfigBundle{
+		OnList:    k.Bool("scan.on_list"),
+		CacheSize: 10000,
+		Clamd: ClamdScanConfig{
+			Address: k.String("scan.clamd.address"),
+			Timeout: 5 * time.Minute,
+		},
+	}
+	if k.Exists("scan.cache_size") {
+		cfg.CacheSize = k.Int("scan.cache_size")
+	}
+	if k.Exists("scan.clamd.timeout") {
+		cfg.Clamd.Timeout = k.Duration("scan.clamd.timeout")
+	}
+	return cfg
+}
+
+// Output checks config, with defaults for unset values
+func ChecksConfig() ChecksConfigBundle {
+	cfg := ChecksConfigBundle{
+		CacheSize: 10000,
+		MaxSize:   100 * 1024 * 1024,
+		SDC: SDCCheckConfig{
+			Enabled:           k.Bool("checks.sdc.enabled"),
+			Threshold:         10,
+			RoundingBase:      k.Int("checks.sdc.rounding_base"),
+			IgnoreColumns:     k.Strings("checks.sdc.ignore_columns"),
+			BlockDestinations: k.Strings("checks.sdc.block_destinations"),
+		},
+		PII: PIICheckConfig{
+			Enabled:           k.Bool("checks.pii.enabled"),
+			Types:             k.Strings("checks.pii.types"),
+			BlockDestinations: k.Strings("checks.pii.block_destinations"),
+		},
+	}
+	if k.Exists("checks.cache_size") {
+		cfg.CacheSize = k.Int("checks.cache_size")
+	}
+	if k.Exists("checks.max_size") {
+		cfg.MaxSize = k.Int64("checks.max_size")
+	}
+	if k.Exists("checks.sdc.threshold") {
+		cfg.SDC.Threshold = k.Int("checks.sdc.threshold")
+	}
+	return cfg
+}
+
+// File type detection config, with defaults for unset values
+func FileTypesConfig() FileTypesConfigBundle {
+	cfg := FileTypesConfigBundle{
+		CacheSize:  10000,
+		Allowlists: []FileTypeAllowlistConfig{},
+	}
+	if k.Exists("file_types.cache_size") {
+		cfg.CacheSize = k.Int("file_types.cache_size")
+	}
+	for _, ak := range k.Slices("file_types.allowlists") {
+		cfg.Allowlists = append(cfg.Allowlists, FileTypeAllowlistConfig{
+			Destination: ak.String("destination"),
+			MediaTypes:  ak.Strings("media_types"),
+		})
+	}
+	return cfg
+}
+
+// Archive inspection config, with defaults for unset values
+func ArchivesConfig() ArchivesConfigBundle {
+	cfg := ArchivesConfigBundle{
+		Enabled:    k.Bool("archives.enabled"),
+		CacheSize:  10000,
+		MaxRatio:   100,
+		MaxDepth:   3,
+		MaxMembers: 10000,
+	}
+	if k.Exists("archives.cache_size") {
+		cfg.CacheSize = k.Int("archives.cache_size")
+	}
+	if k.Exists("archives.max_ratio") {
+		cfg.MaxRatio = k.Float64("archives.max_ratio")
+	}
+	if k.Exists("archives.max_depth") {
+		cfg.MaxDepth = k.Int("archives.max_depth")
+	}
+	if k.Exists("archives.max_members") {
+		cfg.MaxMembers = k.Int("archives.max_members")
+	}
+	return cfg
+}
+
+// Preview config, with defaults for unset values
+func PreviewConfig() PreviewConfigBundle {
+	cfg := PreviewConfigBundle{
+		MaxLines:      100,
+		HexBytes:      512,
+		ThumbnailSize: 256,
+		MaxImageSize:  20 * 1024 * 1024,
+	}
+	if k.Exists("preview.max_lines") {
+		cfg.MaxLines = k.Int("preview.max_lines")
+	}
+	if k.Exists("preview.hex_bytes") {
+		cfg.HexBytes = k.Int("preview.hex_bytes")
+	}
+	if k.Exists("preview.thumbnail_size") {
+		cfg.ThumbnailSize = k.Int("preview.thumbnail_size")
+	}
+	if k.Exists("preview.max_image_size") {
+		cfg.MaxImageSize = k.Int64("preview.max_image_size")
+	}
+	return cfg
+}
+
+// Diff config, with defaults for unset values
+func DiffConfig() DiffConfigBundle {
+	cfg := DiffConfigBundle{
+		MaxSize:    10 * 1024 * 1024,
+		MaxChanges: 1000,
+		Snapshots: DiffSnapshotsConfig{
+			Enabled:   k.Bool("diff.snapshots.enabled"),
+			Retention: defaultSnapshotRetention,
+		},
+	}
+	if k.Exists("diff.max_size") {
+		cfg.MaxSize = k.Int64("diff.max_size")
+	}
+	if k.Exists("diff.max_changes") {
+		cfg.MaxChanges = k.Int("diff.max_changes")
+	}
+	if k.Exists("diff.snapshots.retention") {
+		cfg.Snapshots.Retention = k.Duration("diff.snapshots.retention")
+	}
+	return cfg
+}
+
+func TransformsConfig() TransformsConfigBundle {
+	return TransformsConfigBundle{
+		StripMetadata: StripMetadataTransformConfig{
+			Destinations: k.Strings("transforms.strip_metadata.destinations"),
+		},
+	}
+}
+
 func DBConfig() DBConfigBundle {
 	provider := k.String("db.provider")
 	cfg := DBConfigBundle{Provider: provider}
@@ -86,18 +332,124 @@ func DBConfig() DBConfigBundle {
 	return cfg
 }
 
+// Basic auth config. Users may be given by an htpasswd file, alongside
+// or instead of the single username and password
 func BasicAuthConfig() BasicAuthConfigBundle {
-	return BasicAuthConfigBundle{
-		Username: k.String("auth.basic.username"),
-		Password: k.String("auth.basic.password"),
+	cfg := BasicAuthConfigBundle{
+		Username:     k.String("auth.basic.username"),
+		Password:     k.String("auth.basic.password"),
+		HtpasswdFile: k.String("auth.basic.htpasswd_file"),
+		Lockout: BasicLockoutConfig{
+			MaxFailures: defaultBasicLockoutMaxFailures,
+			Duration:    defaultBasicLockoutDuration,
+		},
+	}
+	if k.Exists("auth.basic.lockout.max_failures") {
+		cfg.Lockout.MaxFailures = k.Int("auth.basic.lockout.max_failures")
 	}
+	if k.Exists("auth.basic.lockout.duration") {
+		cfg.Lockout.Duration = k.Duration("auth.basic.lockout.duration")
+	}
+	return cfg
 }
 
+// Bearer auth config. A single issuer may be configured by
+// auth.bearer.issuer_url and audience, before those in auth.bearer.issuers
 func BearerAuthConfig() BearerAuthConfigBundle {
-	return BearerAuthConfigBundle{
-		IssuerURL: k.String("auth.bearer.issuer_url"),
-		Audience:  k.String("auth.bearer.audience"),
+	cfg := BearerAuthConfigBundle{Issuers: []BearerIssuerConfig{}}
+	if k.String("auth.bearer.issuer_url") != "" {
+		cfg.Issuers = append(cfg.Issuers, bearerIssuerConfigFrom(k.Cut("auth.bearer")))
+	}
+	for _, ik := range k.Slices("auth.bearer.issuers") {
+		cfg.Issuers = append(cfg.Issuers, bearerIssuerConfigFrom(ik))
+	}
+	return cfg
+}
+
+// Bearer issuer config from keys relative to the issuer, with defaults
+// for unset values
+func bearerIssuerConfigFrom(ik *koanf.Koanf) BearerIssuerConfig {
+	cfg := BearerIssuerConfig{
+		IssuerURL:  ik.String("issuer_url"),
+		Audience:   ik.String("audience"),
+		Algorithms: []string{"RS256"},
+		Leeway:     ik.Duration("leeway"),
+		Claims: BearerClaimsConfig{
+			UserId: "sub",
+			Roles:  ik.String("claims.roles"),
+		},
+	}
+	if ik.Exists("algorithms") {
+		cfg.Algorithms = ik.Strings("algorithms")
+	}
+	if ik.Exists("claims.user_id") {
+		cfg.Claims.UserId = ik.String("claims.user_id")
 	}
+	return cfg
+}
+
+// Trusted services that may make requests on behalf of users
+func DelegationConfig() DelegationConfigBundle {
+	cfg := DelegationConfigBundle{Delegators: []DelegatorConfig{}}
+	for _, dk := range k.Slices("auth.delegation.delegators") {
+		cfg.Delegators = append(cfg.Delegators, DelegatorConfig{
+			Method:   dk.String("method"),
+			Issuer:   dk.String("issuer"),
+			Identity: dk.String("identity"),
+		})
+	}
+	return cfg
+}
+
+// Role of identities that may manage API keys, as mapped from bearer
+// token claims or client certificates
+func AdminRole() string {
+	if k.Exists("auth.admin_role") {
+		return k.String("auth.admin_role")
+	}
+	return defaultAdminRole
+}
+
+func IntrospectionAuthConfig() IntrospectionAuthConfigBundle {
+	cfg := IntrospectionAuthConfigBundle{
+		URL:           k.String("auth.introspection.url"),
+		ClientId:      k.String("auth.introspection.client_id"),
+		ClientSecret:  k.String("auth.introspection.client_secret"),
+		Audience:      k.String("auth.introspection.audience"),
+		CacheDuration: time.Minute,
+		Claims: BearerClaimsConfig{
+			UserId: "sub",
+			Roles:  k.String("auth.introspection.claims.roles"),
+		},
+	}
+	if k.Exists("auth.introspection.cache_duration") {
+		cfg.CacheDuration = k.Duration("auth.introspection.cache_duration")
+	}
+	if k.Exists("auth.introspection.claims.user_id") {
+		cfg.Claims.UserId = k.String("auth.introspection.claims.user_id")
+	}
+	return cfg
+}
+
+func ServerTLSConfig() ServerTLSConfigBundle {
+	return ServerTLSConfigBundle{
+		Enabled:           k.Bool("server.tls.enabled"),
+		CertDir:           serverTLSCertDir,
+		RequireClientCert: k.Bool("server.tls.require_client_cert"),
+	}
+}
+
+func ClientCertAuthConfig() ClientCertAuthConfigBundle {
+	cfg := ClientCertAuthConfigBundle{Identities: []ClientCertIdentityConfig{}}
+	for _, ik := range k.Slices("auth.client_cert.identities") {
+		cfg.Identities = append(cfg.Identities, ClientCertIdentityConfig{
+			Subject: ik.String("subject"),
+			SAN:     ik.String("san"),
+			UserId:  ik.String("user_id"),
+			Roles:   ik.Strings("roles"),
+		})
+	}
+	return cfg
 }
 
 func DevS3URL() string {
@@ -116,9 +468,258 @@ func IsDevS3() bool {
 func validateConfig() {
 	validateURL("db.rqlite.baseUrl")
 	validateURL("auth.bearer.issuer_url")
+	validateBasicAuthConfig()
+	validateBearerAuthConfig()
+	validateIntrospectionAuthConfig()
+	validateAdminRole()
+	validateDelegationConfig()
+	validateClientCertAuthConfig()
+	validateStorageConfig(k.Cut("storage"))
+	validateStorageRetryConfig()
+	validateStorageActions()
+	validateScanConfig()
+	validateChecksConfig()
+	validateFileTypesConfig()
+	validateArchivesConfig()
+	validatePreviewConfig()
+	validateDiffConfig()
+	validateTransformsConfig()
+	for _, bk := range k.Slices("storage.backends") {
+		validateStorageConfig(bk)
+	}
+}
+
+func validateStorageConfig(sk *koanf.Koanf) {
+	validateURLOf(sk, "azblob.service_url")
+	for _, bk := range sk.Slices("s3.buckets") {
+		if bk.String("bucket") == "" {
+			log.Fatal().Msg("s3.buckets[].bucket is required")
+		}
+		validateURLOf(bk, "endpoint")
+	}
+}
+
+func validateStorageRetryConfig() {
+	cfg := StorageRetryConfig()
+	if cfg.MaxAttempts < 1 {
+		log.Fatal().Msg("storage.retry.max_attempts must be at least 1")
+	}
+	if cfg.FailureThreshold < 1 {
+		log.Fatal().Msg("storage.retry.failure_threshold must be at least 1")
+	}
+	if cfg.InitialBackoff < 0 || cfg.MaxBackoff < cfg.InitialBackoff || cfg.BreakerCooldown < 0 {
+		log.Fatal().Msg("storage.retry backoffs and cooldown must not be negative, and max_backoff must be at least initial_backoff")
+	}
+}
+
+func validateStorageActions() {
+	for _, action := range StorageActions() {
+		if action.Destination == "" {
+			log.Fatal().Msg("storage.actions[].destination is required")
+		}
+		if action.On != "download" && action.On != "rejection" {
+			log.Fatal().Str("on", action.On).Msg("storage.actions[].on must be download or rejection")
+		}
+		switch action.Type {
+		case "tag":
+			if len(action.Tags) == 0 {
+				log.Fatal().Msg("storage.actions[].tags is required for a tag action")
+			}
+		case "copy":
+			if action.Prefix == "" {
+				log.Fatal().Msg("storage.actions[].prefix is required for a copy action")
+			}
+		case "delete":
+		default:
+			log.Fatal().Str("type", action.Type).Msg("storage.actions[].type must be tag, copy or delete")
+		}
+	}
+}
+
+func validateScanConfig() {
+	cfg := ScanConfig()
+	if cfg.CacheSize < 1 {
+		log.Fatal().Msg("scan.cache_size must be at least 1")
+	}
+	if cfg.Clamd.Address != "" {
+		u, err := url.Parse(cfg.Clamd.Address)
+		if err != nil || (u.Scheme != "tcp" && u.Scheme != "unix") {
+			log.Fatal().Str("address", cfg.Clamd.Address).Msg("scan.clamd.address must be a tcp:// or unix:// URL")
+		}
+		if cfg.Clamd.Timeout <= 0 {
+			log.Fatal().Msg("scan.clamd.timeout must be positive")
+		}
+	}
+}
+
+func validateChecksConfig() {
+	cfg := ChecksConfig()
+	if cfg.CacheSize < 1 || cfg.MaxSize < 1 {
+		log.Fatal().Msg("checks.cache_size and checks.max_size must be at least 1")
+	}
+	if cfg.SDC.Threshold < 0 || cfg.SDC.RoundingBase < 0 {
+		log.Fatal().Msg("checks.sdc.threshold and checks.sdc.rounding_base must not be negative")
+	}
+	for _, piiType := range cfg.PII.Types {
+		switch piiType {
+		case "email", "nhs_number", "ni_number", "postcode", "date_of_birth":
+		default:
+			log.Fatal().Str("type", piiType).Msg("checks.pii.types must be email, nhs_number, ni_number, postcode or date_of_birth")
+		}
+	}
+}
+
+func validateFileTypesConfig() {
+	cfg := FileTypesConfig()
+	if cfg.CacheSize < 1 {
+		log.Fatal().Msg("file_types.cache_size must be at least 1")
+	}
+	destinations := map[string]bool{}
+	for _, allowlist := range cfg.Allowlists {
+		if allowlist.Destination == "" {
+			log.Fatal().Msg("file_types.allowlists[].destination is required")
+		}
+		if destinations[allowlist.Destination] {
+			log.Fatal().Str("destination", allowlist.Destination).Msg("file_types.allowlists[].destination must be unique")
+		}
+		destinations[allowlist.Destination] = true
+		for _, mediaType := range allowlist.MediaTypes {
+			if _, _, err := mime.ParseMediaType(mediaType); err != nil {
+				log.Fatal().Str("media_type", mediaType).Msg("file_types.allowlists[].media_types must be media types e.g. text/csv")
+			}
+		}
+	}
+}
+
+func validateArchivesConfig() {
+	cfg := ArchivesConfig()
+	if cfg.CacheSize < 1 || cfg.MaxDepth < 1 || cfg.MaxMembers < 1 {
+		log.Fatal().Msg("archives.cache_size, archives.max_depth and archives.max_members must be at least 1")
+	}
+	if cfg.MaxRatio < 1 {
+		log.Fatal().Msg("archives.max_ratio must be at least 1")
+	}
+}
+
+func validatePreviewConfig() {
+	cfg := PreviewConfig()
+	if cfg.MaxLines < 1 || cfg.HexBytes < 1 || cfg.ThumbnailSize < 1 {
+		log.Fatal().Msg("preview.max_lines, preview.hex_bytes and preview.thumbnail_size must be at least 1")
+	}
+	if cfg.MaxImageSize < 0 {
+		log.Fatal().Msg("preview.max_image_size must not be negative")
+	}
+}
+
+func validateBearerAuthConfig() {
+	for _, ik := range k.Slices("auth.bearer.issuers") {
+		validateURLOf(ik, "issuer_url")
+	}
+	issuers := map[string]bool{}
+	for _, issuer := range BearerAuthConfig().Issuers {
+		if issuer.IssuerURL == "" || issuer.Audience == "" {
+			log.Fatal().Msg("auth.bearer.issuers[] must have an issuer_url and audience")
+		}
+		if issuers[issuer.IssuerURL] {
+			log.Fatal().Str("issuer_url", issuer.IssuerURL).Msg("auth.bearer.issuers[].issuer_url must be unique")
+		}
+		issuers[issuer.IssuerURL] = true
+		if len(issuer.Algorithms) == 0 {
+			log.Fatal().Msg("auth.bearer.issuers[].algorithms must not be empty")
+		}
+		for _, algorithm := range issuer.Algorithms {
+			if !slices.Contains([]string{"RS256", "PS256", "ES256", "EdDSA"}, algorithm) {
+				log.Fatal().Str("algorithm", algorithm).Msg("auth.bearer.issuers[].algorithms must be RS256, PS256, ES256 or EdDSA")
+			}
+		}
+		if issuer.Leeway < 0 || issuer.Claims.UserId == "" {
+			log.Fatal().Msg("auth.bearer.issuers[].leeway must not be negative and claims.user_id must not be empty")
+		}
+	}
+}
+
+func validateDelegationConfig() {
+	if !k.Exists("auth.delegation") {
+		return
+	}
+	delegators := DelegationConfig().Delegators
+	if len(delegators) == 0 {
+		log.Fatal().Msg("auth.delegation.delegators must be a list of delegators")
+	}
+	for _, delegator := range delegators {
+		if !slices.Contains([]string{"basic", "bearer", "client_cert"}, delegator.Method) {
+			log.Fatal().Str("method", delegator.Method).Msg("auth.delegation.delegators[].method must be basic, bearer or client_cert")
+		}
+		if delegator.Identity == "" {
+			log.Fatal().Msg("auth.delegation.delegators[].identity must not be empty")
+		}
+		if (delegator.Method == "bearer") != (delegator.Issuer != "") {
+			log.Fatal().Msg("auth.delegation.delegators[].issuer is required for, and only for, the bearer method")
+		}
+	}
+}
+
+func validateAdminRole() {
+	if AdminRole() == "" {
+		log.Fatal().Msg("auth.admin_role must not be empty")
+	}
+}
+
+func validateBasicAuthConfig() {
+	cfg := BasicAuthConfig()
+	if cfg.Lockout.MaxFailures < 0 || cfg.Lockout.Duration < 0 {
+		log.Fatal().Msg("auth.basic.lockout.max_failures and duration must not be negative")
+	}
+}
+
+func validateIntrospectionAuthConfig() {
+	if !k.Exists("auth.introspection") {
+		return
+	}
+	validateURL("auth.introspection.url")
+	cfg := IntrospectionAuthConfig()
+	if cfg.URL == "" {
+		log.Fatal().Msg("auth.introspection.url is required")
+	}
+	if cfg.CacheDuration < 0 || cfg.Claims.UserId == "" {
+		log.Fatal().Msg("auth.introspection.cache_duration must not be negative and claims.user_id must not be empty")
+	}
+}
+
+func validateClientCertAuthConfig() {
+	for _, identity := range ClientCertAuthConfig().Identities {
+		if (identity.Subject == "") == (identity.SAN == "") {
+			log.Fatal().Msg("auth.client_cert.identities[] must have one of subject or san")
+		}
+		if identity.UserId == "" {
+			log.Fatal().Msg("auth.client_cert.identities[].user_id is required")
+		}
+	}
+}
+
+func validateDiffConfig() {
+	cfg := DiffConfig()
+	if cfg.MaxSize < 1 || cfg.MaxChanges < 1 {
+		log.Fatal().Msg("diff.max_size and diff.max_changes must be at least 1")
+	}
+	if cfg.Snapshots.Enabled && cfg.Snapshots.Retention <= 0 {
+		log.Fatal().Msg("diff.snapshots.retention must be positive")
+	}
+}
+
+func validateTransformsConfig() {
+	for _, destination := range TransformsConfig().StripMetadata.Destinations {
+		if destination == "" {
+			log.Fatal().Msg("transforms.strip_metadata.destinations[] must not be empty")
+		}
+	}
 }
 
 func validateURL(key string) {
+	validateURLOf(k, key)
+}
+
+func validateURLOf(k *koanf.Koanf, key string) {
 	if k.Exists(key) {
 		value := k.String(key)
 		if u, err := url.ParseRequestURI(value); err != nil || u.Scheme == "" || u.Host == "" {
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -36,6 +37,7 @@ storage:
     region: "us-east-1"
     access_key_id: "s3-access-key-123"
     secret_access_key: "s3-secret-key-123"
+    file_id: "checksum_sha256"
 `
 	cf := makeConfig(t, "storage-s3.yaml", yaml)
 	InitWithPath(cf)
@@ -45,6 +47,46 @@ storage:
 	assert.Equal(t, "us-east-1", storage.S3.Region)
 	assert.Equal(t, "s3-access-key-123", storage.S3.AccessKeyId)
 	assert.Equal(t, "s3-secret-key-123", storage.S3.SecretAccessKey)
+	assert.Empty(t, storage.S3.Buckets)
+	assert.Equal(t, "checksum_sha256", storage.S3.FileId)
+}
+
+func TestStorageConfigS3Buckets(t *testing.T) {
+	yaml := `
+storage:
+  provider: s3
+  s3:
+    region: "eu-west-2"
+    buckets:
+      - bucket: "ceph-*"
+        endpoint: "https://rgw.example.com"
+        region: "default"
+        path_style: true
+        ca_bundle: "/etc/egress/ca/rgw.pem"
+        access_key_id: "rgw-key"
+        secret_access_key: "rgw-secret"
+      - bucket: "shared.example.bucket"
+        role_arn: "arn:aws:iam::123456789012:role/egress"
+`
+	cf := makeConfig(t, "storage-s3-buckets.yaml", yaml)
+	InitWithPath(cf)
+
+	buckets := StorageConfig().S3.Buckets
+	assert.Equal(t, []S3BucketConfig{
+		{
+			Bucket:          "ceph-*",
+			Endpoint:        "https://rgw.example.com",
+			Region:          "default",
+			UsePathStyle:    true,
+			CABundle:        "/etc/egress/ca/rgw.pem",
+			AccessKeyId:     "rgw-key",
+			SecretAccessKey: "rgw-secret",
+		},
+		{
+			Bucket:  "shared.example.bucket",
+			RoleARN: "arn:aws:iam::123456789012:role/egress",
+		},
+	}, buckets)
 }
 
 func TestStorageConfigGeneric(t *testing.T) {
@@ -60,6 +102,266 @@ storage:
 	assert.Equal(t, string(types.StorageProviderGeneric), storage.Provider)
 }
 
+func TestStorageConfigAzBlob(t *testing.T) {
+	yaml := `
+storage:
+  provider: azblob
+  azblob:
+    account_name: "devstoreaccount1"
+    account_key: "azblob-key-123"
+    service_url: "http://127.0.0.1:10000/devstoreaccount1"
+    file_id: content_md5
+`
+	cf := makeConfig(t, "storage-azblob.yaml", yaml)
+	InitWithPath(cf)
+
+	storage := StorageConfig()
+	assert.Equal(t, string(types.StorageProviderAzBlob), storage.Provider)
+	assert.Equal(t, "devstoreaccount1", storage.AzBlob.AccountName)
+	assert.Equal(t, "azblob-key-123", storage.AzBlob.AccountKey)
+	assert.Equal(t, "", storage.AzBlob.SASToken)
+	assert.Equal(t, "http://127.0.0.1:10000/devstoreaccount1", storage.AzBlob.ServiceURL)
+	assert.Equal(t, "content_md5", storage.AzBlob.FileId)
+}
+
+func TestStorageConfigsSingleProvider(t *testing.T) {
+	yaml := `
+storage:
+  provider: s3
+  s3:
+    region: "us-east-1"
+`
+	cf := makeConfig(t, "storage-single.yaml", yaml)
+	InitWithPath(cf)
+
+	storages := StorageConfigs()
+	assert.Len(t, storages, 1)
+	assert.Equal(t, "s3", storages[0].Name)
+	assert.Equal(t, "us-east-1", storages[0].S3.Region)
+}
+
+func TestStorageConfigsBackends(t *testing.T) {
+	yaml := `
+storage:
+  backends:
+    - name: aws
+      provider: s3
+      s3:
+        region: "eu-west-2"
+    - name: tre
+      provider: generic
+      hosts:
+        - "storage.tre.local"
+        - "*.storage.tre.local"
+`
+	cf := makeConfig(t, "storage-backends.yaml", yaml)
+	InitWithPath(cf)
+
+	storages := StorageConfigs()
+	assert.Len(t, storages, 2)
+	assert.Equal(t, "aws", storages[0].Name)
+	assert.Equal(t, string(types.StorageProviderS3), storages[0].Provider)
+	assert.Equal(t, "eu-west-2", storages[0].S3.Region)
+	assert.Empty(t, storages[0].Hosts)
+	assert.Equal(t, "tre", storages[1].Name)
+	assert.Equal(t, string(types.StorageProviderGeneric), storages[1].Provider)
+	assert.Equal(t, []string{"storage.tre.local", "*.storage.tre.local"}, storages[1].Hosts)
+}
+
+func TestStorageRetryConfig(t *testing.T) {
+	yaml := `
+storage:
+  provider: generic
+  retry:
+    max_attempts: 5
+    initial_backoff: 50ms
+    breaker_cooldown: 1m
+`
+	cf := makeConfig(t, "storage-retry.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, StorageRetryConfigBundle{
+		MaxAttempts:      5,
+		InitialBackoff:   50 * time.Millisecond,
+		MaxBackoff:       2 * time.Second,
+		FailureThreshold: 5,
+		BreakerCooldown:  time.Minute,
+	}, StorageRetryConfig())
+}
+
+func TestStorageActions(t *testing.T) {
+	yaml := `
+storage:
+  provider: s3
+  actions:
+    - destination: trusted
+      on: download
+      type: tag
+      tags:
+        egress-status: downloaded
+    - destination: trusted
+      on: download
+      type: copy
+      prefix: archive/
+    - destination: trusted
+      on: rejection
+      type: delete
+`
+	cf := makeConfig(t, "storage-actions.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, []StorageActionConfig{
+		{Destination: "trusted", On: "download", Type: "tag", Tags: map[string]string{"egress-status": "downloaded"}},
+		{Destination: "trusted", On: "download", Type: "copy", Tags: map[string]string{}, Prefix: "archive/"},
+		{Destination: "trusted", On: "rejection", Type: "delete", Tags: map[string]string{}},
+	}, StorageActions())
+}
+
+func TestScanConfig(t *testing.T) {
+	yaml := `
+scan:
+  on_list: true
+  clamd:
+    address: tcp://clamav:3310
+    timeout: 30s
+`
+	cf := makeConfig(t, "scan.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, ScanConfigBundle{
+		OnList:    true,
+		CacheSize: 10000,
+		Clamd: ClamdScanConfig{
+			Address: "tcp://clamav:3310",
+			Timeout: 30 * time.Second,
+		},
+	}, ScanConfig())
+}
+
+func TestChecksConfig(t *testing.T) {
+	yaml := `
+checks:
+  sdc:
+    enabled: true
+    rounding_base: 5
+    ignore_columns: [year]
+    block_destinations: [public]
+  pii:
+    enabled: true
+    types: [email, nhs_number]
+`
+	cf := makeConfig(t, "checks.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, ChecksConfigBundle{
+		CacheSize: 10000,
+		MaxSize:   100 * 1024 * 1024,
+		SDC: SDCCheckConfig{
+			Enabled:           true,
+			Threshold:         10,
+			RoundingBase:      5,
+			IgnoreColumns:     []string{"year"},
+			BlockDestinations: []string{"public"},
+		},
+		PII: PIICheckConfig{
+			Enabled:           true,
+			Types:             []string{"email", "nhs_number"},
+			BlockDestinations: []string{},
+		},
+	}, ChecksConfig())
+}
+
+func TestFileTypesConfig(t *testing.T) {
+	yaml := `
+file_types:
+  allowlists:
+    - destination: public-repository
+      media_types: [text/csv, image/png]
+`
+	cf := makeConfig(t, "file_types.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, FileTypesConfigBundle{
+		CacheSize: 10000,
+		Allowlists: []FileTypeAllowlistConfig{
+			{Destination: "public-repository", MediaTypes: []string{"text/csv", "image/png"}},
+		},
+	}, FileTypesConfig())
+}
+
+func TestArchivesConfig(t *testing.T) {
+	yaml := `
+archives:
+  enabled: true
+  max_ratio: 50.5
+`
+	cf := makeConfig(t, "archives.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, ArchivesConfigBundle{
+		Enabled:    true,
+		CacheSize:  10000,
+		MaxRatio:   50.5,
+		MaxDepth:   3,
+		MaxMembers: 10000,
+	}, ArchivesConfig())
+}
+
+func TestPreviewConfig(t *testing.T) {
+	yaml := `
+preview:
+  max_lines: 20
+  max_image_size: 1024
+`
+	cf := makeConfig(t, "preview.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, PreviewConfigBundle{
+		MaxLines:      20,
+		HexBytes:      512,
+		ThumbnailSize: 256,
+		MaxImageSize:  1024,
+	}, PreviewConfig())
+}
+
+func TestDiffConfig(t *testing.T) {
+	yaml := `
+diff:
+  max_changes: 50
+`
+	cf := makeConfig(t, "diff.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, DiffConfigBundle{
+		MaxSize:    10 * 1024 * 1024,
+		MaxChanges: 50,
+		Snapshots:  DiffSnapshotsConfig{Enabled: false, Retention: 30 * 24 * time.Hour},
+	}, DiffConfig())
+
+	yaml = `
+diff:
+  snapshots:
+    enabled: true
+    retention: 168h
+`
+	cf = makeConfig(t, "diff-snapshots.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, DiffSnapshotsConfig{Enabled: true, Retention: 7 * 24 * time.Hour}, DiffConfig().Snapshots)
+}
+
+func TestTransformsConfig(t *testing.T) {
+	yaml := `
+transforms:
+  strip_metadata:
+    destinations: [public-repository]
+`
+	cf := makeConfig(t, "transforms.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, []string{"public-repository"}, TransformsConfig().StripMetadata.Destinations)
+}
+
 func TestDBConfig(t *testing.T) {
 	yaml := `
 db:
@@ -92,6 +394,22 @@ auth:
 	auth := BasicAuthConfig()
 	assert.Equal(t, "username123", auth.Username)
 	assert.Equal(t, "password123", auth.Password)
+	assert.Equal(t, "", auth.HtpasswdFile)
+	assert.Equal(t, BasicLockoutConfig{MaxFailures: 5, Duration: 15 * time.Minute}, auth.Lockout)
+
+	cf = makeConfig(t, "basic-auth-htpasswd.yaml", `
+auth:
+  basic:
+    htpasswd_file: /etc/egress/htpasswd/htpasswd
+    lockout:
+      max_failures: 3
+      duration: 1m
+`)
+	InitWithPath(cf)
+
+	auth = BasicAuthConfig()
+	assert.Equal(t, "/etc/egress/htpasswd/htpasswd", auth.HtpasswdFile)
+	assert.Equal(t, BasicLockoutConfig{MaxFailures: 3, Duration: time.Minute}, auth.Lockout)
 }
 
 func TestBearerAuthConfig(t *testing.T) {
@@ -105,8 +423,133 @@ auth:
 	InitWithPath(cf)
 
 	auth := BearerAuthConfig()
-	assert.Equal(t, "http://example.com", auth.IssuerURL)
-	assert.Equal(t, "egress", auth.Audience)
+	assert.Len(t, auth.Issuers, 1)
+	assert.Equal(t, "http://example.com", auth.Issuers[0].IssuerURL)
+	assert.Equal(t, "egress", auth.Issuers[0].Audience)
+	assert.Equal(t, []string{"RS256"}, auth.Issuers[0].Algorithms)
+	assert.Equal(t, "sub", auth.Issuers[0].Claims.UserId)
+}
+
+func TestBearerAuthConfigIssuers(t *testing.T) {
+	yaml := `
+auth:
+  bearer:
+    issuer_url: "http://idp1.example.com"
+    audience: "egress"
+    issuers:
+      - issuer_url: "http://idp2.example.com"
+        audience: "egress-api"
+        algorithms: [ES256, EdDSA]
+        leeway: 30s
+        claims:
+          user_id: preferred_username
+          roles: realm_access.roles
+`
+	cf := makeConfig(t, "bearer-auth-issuers.yaml", yaml)
+	InitWithPath(cf)
+
+	auth := BearerAuthConfig()
+	assert.Len(t, auth.Issuers, 2)
+	assert.Equal(t, "http://idp1.example.com", auth.Issuers[0].IssuerURL)
+	assert.Equal(t, BearerIssuerConfig{
+		IssuerURL:  "http://idp2.example.com",
+		Audience:   "egress-api",
+		Algorithms: []string{"ES256", "EdDSA"},
+		Leeway:     30 * time.Second,
+		Claims:     BearerClaimsConfig{UserId: "preferred_username", Roles: "realm_access.roles"},
+	}, auth.Issuers[1])
+}
+
+func TestIntrospectionAuthConfig(t *testing.T) {
+	yaml := `
+auth:
+  introspection:
+    url: "http://idp.example.com/introspect"
+    client_id: egress
+    client_secret: secret
+    claims:
+      roles: scope
+`
+	cf := makeConfig(t, "introspection-auth.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, IntrospectionAuthConfigBundle{
+		URL:           "http://idp.example.com/introspect",
+		ClientId:      "egress",
+		ClientSecret:  "secret",
+		CacheDuration: time.Minute,
+		Claims:        BearerClaimsConfig{UserId: "sub", Roles: "scope"},
+	}, IntrospectionAuthConfig())
+}
+
+func TestDelegationConfig(t *testing.T) {
+	cf := makeConfig(t, "delegation-default.yaml", "")
+	InitWithPath(cf)
+	assert.Empty(t, DelegationConfig().Delegators)
+
+	cf = makeConfig(t, "delegation.yaml", `
+auth:
+  delegation:
+    delegators:
+      - method: bearer
+        issuer: https://idp.example.com
+        identity: portal
+      - method: client_cert
+        identity: portal-client
+`)
+	InitWithPath(cf)
+	assert.Equal(t, []DelegatorConfig{
+		{Method: "bearer", Issuer: "https://idp.example.com", Identity: "portal"},
+		{Method: "client_cert", Identity: "portal-client"},
+	}, DelegationConfig().Delegators)
+}
+
+func TestAdminRole(t *testing.T) {
+	cf := makeConfig(t, "admin-role-default.yaml", "")
+	InitWithPath(cf)
+	assert.Equal(t, "admin", AdminRole())
+
+	cf = makeConfig(t, "admin-role.yaml", `
+auth:
+  admin_role: egress-admin
+`)
+	InitWithPath(cf)
+	assert.Equal(t, "egress-admin", AdminRole())
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	yaml := `
+server:
+  tls:
+    enabled: true
+`
+	cf := makeConfig(t, "server-tls.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, ServerTLSConfigBundle{
+		Enabled: true,
+		CertDir: "/etc/egress/server-tls",
+	}, ServerTLSConfig())
+}
+
+func TestClientCertAuthConfig(t *testing.T) {
+	yaml := `
+auth:
+  client_cert:
+    identities:
+      - subject: "CN=portal,O=UCL"
+        user_id: portal
+        roles: [checker]
+      - san: "spiffe://tre/airlock"
+        user_id: airlock
+`
+	cf := makeConfig(t, "client-cert-auth.yaml", yaml)
+	InitWithPath(cf)
+
+	assert.Equal(t, ClientCertAuthConfigBundle{Identities: []ClientCertIdentityConfig{
+		{Subject: "CN=portal,O=UCL", UserId: "portal", Roles: []string{"checker"}},
+		{SAN: "spiffe://tre/airlock", UserId: "airlock", Roles: []string{}},
+	}}, ClientCertAuthConfig())
 }
 
 func makeConfig(t *testing.T, fileName string, yaml string) string {
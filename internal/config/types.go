@@ -1,15 +1,158 @@
 package config
 
+import "time"
+
 type StorageConfigBundle struct {
+	Name       string
 	Provider   string
+	Hosts      []string // Location hosts served by this backend; any host if empty
 	TLSCertDir string
 	S3         S3StorageConfig
+	AzBlob     AzBlobStorageConfig
 }
 
 type S3StorageConfig struct {
 	Region          string
 	AccessKeyId     string
 	SecretAccessKey string
+	Buckets         []S3BucketConfig // Overrides for matching buckets; first match wins
+	FileId          string           // Source of the FileId; one of: etag, checksum_sha256, version_id
+}
+
+// Client config for buckets matching a name or glob e.g. "ceph-*". Region
+// and credentials default to those of the enclosing S3StorageConfig
+type S3BucketConfig struct {
+	Bucket          string
+	Endpoint        string // e.g. https://rgw.example.com; AWS if empty
+	Region          string
+	UsePathStyle    bool
+	CABundle        string // Path to a PEM bundle of CAs trusted by the endpoint
+	AccessKeyId     string
+	SecretAccessKey string
+	RoleARN         string // Role assumed with the above credentials
+}
+
+type AzBlobStorageConfig struct {
+	AccountName string
+	AccountKey  string // #nosec G117 -- read only from k8s Secret
+	SASToken    string // #nosec G117 -- read only from k8s Secret
+	ServiceURL  string // Overrides the default endpoint e.g. for Azurite
+	FileId      string // Source of the FileId; one of: etag, content_md5
+}
+
+// Retries of failed storage requests and circuit breaking of locations
+// whose requests keep failing
+type StorageRetryConfigBundle struct {
+	MaxAttempts      int           // Attempts of each request, including the first
+	InitialBackoff   time.Duration // Maximum wait before the first retry, doubling for each retry
+	MaxBackoff       time.Duration
+	FailureThreshold int           // Consecutive failures of a location before its breaker opens
+	BreakerCooldown  time.Duration // Time the breaker is open before a trial request is allowed
+}
+
+// Action taken on a file in storage after it is downloaded to, or rejected
+// for, a destination
+type StorageActionConfig struct {
+	Destination string
+	On          string            // Event triggering the action; one of: download, rejection
+	Type        string            // One of: tag, copy, delete
+	Tags        map[string]string // Tags added by a tag action
+	Prefix      string            // Key prefix of the copy made by a copy action e.g. "archive/"
+}
+
+// Malware scanning of files before they are downloaded. Disabled if no
+// scanner is configured
+type ScanConfigBundle struct {
+	OnList    bool // Scan listed files in the background, so results are cached before download
+	CacheSize int  // Maximum number of results cached by file id
+	Clamd     ClamdScanConfig
+}
+
+type ClamdScanConfig struct {
+	Address string        // e.g. tcp://clamav:3310 or unix:///run/clamav/clamd.sock; disabled if empty
+	Timeout time.Duration // Of scanning one file
+}
+
+// Output checks of the content of files, reported per file. A file with
+// findings of a check cannot be downloaded to its block destinations
+type ChecksConfigBundle struct {
+	CacheSize int   // Maximum number of reports cached by file id
+	MaxSize   int64 // Bytes of the largest file that is checked
+	SDC       SDCCheckConfig
+	PII       PIICheckConfig
+}
+
+// Statistical disclosure control of CSV and TSV files
+type SDCCheckConfig struct {
+	Enabled           bool
+	Threshold         int      // Non-zero counts below this are flagged e.g. 10
+	RoundingBase      int      // Counts not a multiple of this are flagged as unrounded; not checked if 0
+	IgnoreColumns     []string // Header names of columns that are not checked e.g. year
+	BlockDestinations []string
+}
+
+// Personal identifiers in text files
+type PIICheckConfig struct {
+	Enabled           bool
+	Types             []string // Found types of identifier; all if empty. Any of: email, nhs_number, ni_number, postcode, date_of_birth
+	BlockDestinations []string
+}
+
+// Detection of the media types of files from their content, with the
+// types allowed to be downloaded to some destinations
+type FileTypesConfigBundle struct {
+	CacheSize  int // Maximum number of media types cached by file id
+	Allowlists []FileTypeAllowlistConfig
+}
+
+// Media types allowed for a destination. Destinations without an
+// allowlist allow any type
+type FileTypeAllowlistConfig struct {
+	Destination string
+	MediaTypes  []string // e.g. text/csv, image/png, application/pdf
+}
+
+// Inspection of the members of zip, tar and gzip archive files, recursing
+// into archives within them. An archive exceeding a limit is blocked
+type ArchivesConfigBundle struct {
+	Enabled    bool
+	CacheSize  int     // Maximum number of inspections cached by file id
+	MaxRatio   float64 // Of the total size of the members to the size of the archive
+	MaxDepth   int     // Of members, 1 being the members of the archive itself
+	MaxMembers int
+}
+
+// Bounded previews of the content of files
+type PreviewConfigBundle struct {
+	MaxLines      int   // Of text, or rows of a table, and the default number previewed
+	HexBytes      int   // Dumped of files that are not text or images
+	ThumbnailSize int   // In pixels of the larger dimension of image thumbnails
+	MaxImageSize  int64 // In bytes of images that are thumbnailed; larger images are dumped as hex
+}
+
+// Diffs between versions of text and CSV files
+type DiffConfigBundle struct {
+	MaxSize    int64 // In bytes of each version that is diffed
+	MaxChanges int   // Of lines or rows inserted or deleted, beyond which versions are not diffed
+	Snapshots  DiffSnapshotsConfig
+}
+
+// Snapshots of rejected files in the database, so that they can be diffed
+// after a resubmission overwrites them in storage
+type DiffSnapshotsConfig struct {
+	Enabled   bool
+	Retention time.Duration // After which snapshots are deleted
+}
+
+// Transforms of the content of files as they are downloaded
+type TransformsConfigBundle struct {
+	StripMetadata StripMetadataTransformConfig
+}
+
+// Stripping of metadata e.g. EXIF from JPEG and PNG images, and document
+// properties from PDF and Office documents
+type StripMetadataTransformConfig struct {
+	Destinations []string // Stripped from files downloaded to these destinations
 }
 
 type DBConfigBundle struct {
@@ -24,11 +167,83 @@ type RqliteConfig struct {
 }
 
 type BasicAuthConfigBundle struct {
-	Username string
-	Password string // #nosec G117 -- read only from k8s Secret
+	Username     string
+	Password     string // #nosec G117 -- read only from k8s Secret
+	HtpasswdFile string // Optional; of users with bcrypt or argon2 hashes
+	Lockout      BasicLockoutConfig
 }
 
+// Lockout of a user after consecutive failed attempts. Disabled if the
+// max failures is zero
+type BasicLockoutConfig struct {
+	MaxFailures int
+	Duration    time.Duration
+}
+
+// Issuers of trusted bearer tokens, to which tokens are dispatched by
+// their iss claim
 type BearerAuthConfigBundle struct {
-	IssuerURL string
-	Audience  string
+	Issuers []BearerIssuerConfig
+}
+
+type BearerIssuerConfig struct {
+	IssuerURL  string
+	Audience   string
+	Algorithms []string      // Allowed signing algorithms e.g. RS256
+	Leeway     time.Duration // Of clock skew when checking the times of tokens
+	Claims     BearerClaimsConfig
+}
+
+// Claims of tokens mapped to the identity. Nested claims are given by
+// paths separated by dots e.g. "realm_access.roles"
+type BearerClaimsConfig struct {
+	UserId string
+	Roles  string // Optional; an array of strings or a space separated string
+}
+
+// RFC 7662 introspection of bearer tokens that are not JWTs of a trusted
+// issuer e.g. opaque tokens. Enabled if the URL is set
+type IntrospectionAuthConfigBundle struct {
+	URL           string
+	ClientId      string
+	ClientSecret  string // #nosec G117 -- read only from k8s Secret
+	Audience      string // Optional; if set, must be in the aud of active tokens
+	CacheDuration time.Duration
+	Claims        BearerClaimsConfig
+}
+
+// Trusted services that may make requests on behalf of users
+type DelegationConfigBundle struct {
+	Delegators []DelegatorConfig
+}
+
+// Service given by how it authenticates and its identity when it does: the
+// user ID (i.e. sub) or the Basic auth username. Identities of different
+// methods and issuers are distinct, so a delegator matches only its own
+type DelegatorConfig struct {
+	Method   string // One of: basic, bearer, client_cert
+	Issuer   string // Of bearer tokens: the iss of a JWT, or the introspection url
+	Identity string
+}
+
+// TLS termination by the server, with client certificates verified
+// against the CA in the cert dir
+type ServerTLSConfigBundle struct {
+	Enabled           bool
+	CertDir           string
+	RequireClientCert bool // Otherwise a client certificate is verified only if given
+}
+
+// Identities of verified client certificates
+type ClientCertAuthConfigBundle struct {
+	Identities []ClientCertIdentityConfig
+}
+
+// Identity of a client certificate with the subject, or with the subject
+// alternative name (SAN) e.g. a DNS name or URI
+type ClientCertIdentityConfig struct {
+	Subject string // e.g. "CN=portal,O=UCL"
+	SAN     string
+	UserId  string
+	Roles   []string
 }
@@ -1,6 +1,8 @@
 package inmemory
 
 import (
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
@@ -8,53 +10,195 @@ import (
 )
 
 func New() *DB {
-	return &DB{state: map[types.ProjectId]types.ProjectEvents{}}
+	return &DB{
+		state:     map[types.ProjectId]types.ProjectEvents{},
+		versions:  map[projectLocation]types.ProjectVersions{},
+		snapshots: map[projectLocation]map[types.FileId]snapshot{},
+		apiKeys:   map[types.ApiKeyId]types.ApiKey{},
+	}
 }
 
 type DB struct {
-	mu    sync.RWMutex
-	state map[types.ProjectId]types.ProjectEvents
+	mu        sync.RWMutex
+	state     map[types.ProjectId]types.ProjectEvents
+	versions  map[projectLocation]types.ProjectVersions
+	snapshots map[projectLocation]map[types.FileId]snapshot
+	apiKeys   map[types.ApiKeyId]types.ApiKey
+}
+
+type snapshot struct {
+	content   []byte
+	createdAt time.Time
+}
+
+// File ids are unique only within a location
+type projectLocation struct {
+	projectId types.ProjectId
+	location  string
 }
 
 func (db *DB) ApproveFile(
 	projectId types.ProjectId,
 	fileId types.FileId,
-	userId types.UserId,
+	actor types.Actor,
 	destination types.Destination,
 	comment string,
 ) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	db.appendEvent(types.EventActionApproval, projectId, fileId, userId, destination, comment)
+	db.appendEvent(types.EventActionApproval, projectId, fileId, types.EventDetails{
+		UserId:      actor.UserId,
+		ApiKeyId:    actor.ApiKeyId,
+		DelegatorId: actor.DelegatorId,
+		Destination: destination,
+		Comment:     comment,
+	})
 	return nil
 }
 
 func (db *DB) RejectFile(
 	projectId types.ProjectId,
 	fileId types.FileId,
-	userId types.UserId,
+	actor types.Actor,
 	destination types.Destination,
 	comment string,
 ) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	db.appendEvent(types.EventActionRejection, projectId, fileId, userId, destination, comment)
+	db.appendEvent(types.EventActionRejection, projectId, fileId, types.EventDetails{
+		UserId:      actor.UserId,
+		ApiKeyId:    actor.ApiKeyId,
+		DelegatorId: actor.DelegatorId,
+		Destination: destination,
+		Comment:     comment,
+	})
 	return nil
 }
 
 func (db *DB) DownloadFile(
 	projectId types.ProjectId,
 	fileId types.FileId,
-	userId types.UserId,
+	actor types.Actor,
+	destination types.Destination,
+	comment string,
+) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.appendEvent(types.EventActionDownload, projectId, fileId, types.EventDetails{
+		UserId:      actor.UserId,
+		ApiKeyId:    actor.ApiKeyId,
+		DelegatorId: actor.DelegatorId,
+		Destination: destination,
+		Comment:     comment,
+	})
+	return nil
+}
+
+func (db *DB) PreviewFile(
+	projectId types.ProjectId,
+	fileId types.FileId,
+	actor types.Actor,
+	comment string,
+) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.appendEvent(types.EventActionPreview, projectId, fileId, types.EventDetails{
+		UserId:      actor.UserId,
+		ApiKeyId:    actor.ApiKeyId,
+		DelegatorId: actor.DelegatorId,
+		Comment:     comment,
+	})
+	return nil
+}
+
+func (db *DB) DownloadTransformedFile(
+	projectId types.ProjectId,
+	fileId types.FileId,
+	actor types.Actor,
 	destination types.Destination,
 	comment string,
+	hashes types.TransformHashes,
 ) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	db.appendEvent(types.EventActionDownload, projectId, fileId, userId, destination, comment)
+	db.appendEvent(types.EventActionDownload, projectId, fileId, types.EventDetails{
+		UserId:      actor.UserId,
+		ApiKeyId:    actor.ApiKeyId,
+		DelegatorId: actor.DelegatorId,
+		Destination: destination,
+		Comment:     comment,
+		Hashes:      hashes,
+	})
+	return nil
+}
+
+func (db *DB) DownloadBundle(
+	projectId types.ProjectId,
+	fileIds []types.FileId,
+	bundleId types.BundleId,
+	actor types.Actor,
+	destination types.Destination,
+	comment string,
+) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, fileId := range fileIds {
+		db.appendEvent(types.EventActionDownload, projectId, fileId, types.EventDetails{
+			UserId:      actor.UserId,
+			ApiKeyId:    actor.ApiKeyId,
+			DelegatorId: actor.DelegatorId,
+			Destination: destination,
+			Comment:     comment,
+			BundleId:    bundleId,
+		})
+	}
+	return nil
+}
+
+func (db *DB) RecordScan(
+	projectId types.ProjectId,
+	fileId types.FileId,
+	actor types.Actor,
+	destination types.Destination,
+	comment string,
+) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.appendEvent(types.EventActionScan, projectId, fileId, types.EventDetails{
+		UserId:      actor.UserId,
+		ApiKeyId:    actor.ApiKeyId,
+		DelegatorId: actor.DelegatorId,
+		Destination: destination,
+		Comment:     comment,
+	})
+	return nil
+}
+
+func (db *DB) RecordStorageAction(
+	projectId types.ProjectId,
+	fileId types.FileId,
+	actor types.Actor,
+	destination types.Destination,
+	action types.EventAction,
+	comment string,
+) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.appendEvent(action, projectId, fileId, types.EventDetails{
+		UserId:      actor.UserId,
+		ApiKeyId:    actor.ApiKeyId,
+		DelegatorId: actor.DelegatorId,
+		Destination: destination,
+		Comment:     comment,
+	})
 	return nil
 }
 
@@ -86,6 +230,138 @@ func (db *DB) FileEvents(
 	return events, nil
 }
 
+func (db *DB) RecordFileVersions(
+	projectId types.ProjectId,
+	location types.LocationURI,
+	files []types.FileMetadata,
+) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key := projectLocation{projectId: projectId, location: location.String()}
+	if _, exists := db.versions[key]; !exists {
+		db.versions[key] = types.ProjectVersions{}
+	}
+	versions := db.versions[key]
+	for _, file := range versions.Unrecorded(files) {
+		versions[file.Name] = append(versions[file.Name], types.FileVersion{
+			FileId:    file.Id,
+			FirstSeen: time.Now(),
+		})
+	}
+	return nil
+}
+
+func (db *DB) FileVersions(
+	projectId types.ProjectId,
+	location types.LocationURI,
+) (types.ProjectVersions, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	versions, exists := db.versions[projectLocation{projectId: projectId, location: location.String()}]
+	if !exists {
+		return types.ProjectVersions{}, nil
+	}
+	// Copied as versions are appended to in place
+	result := types.ProjectVersions{}
+	for name, fileVersions := range versions {
+		result[name] = slices.Clone(fileVersions)
+	}
+	return result, nil
+}
+
+func (db *DB) RecordFileSnapshot(
+	projectId types.ProjectId,
+	location types.LocationURI,
+	fileId types.FileId,
+	content []byte,
+) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key := projectLocation{projectId: projectId, location: location.String()}
+	if _, exists := db.snapshots[key]; !exists {
+		db.snapshots[key] = map[types.FileId]snapshot{}
+	}
+	db.snapshots[key][fileId] = snapshot{content: slices.Clone(content), createdAt: time.Now()}
+	return nil
+}
+
+func (db *DB) FileSnapshot(
+	projectId types.ProjectId,
+	location types.LocationURI,
+	fileId types.FileId,
+) ([]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	snapshot, exists := db.snapshots[projectLocation{projectId: projectId, location: location.String()}][fileId]
+	if !exists {
+		return nil, types.NewErrNotFoundF("snapshot of file %s not found", fileId)
+	}
+	return slices.Clone(snapshot.content), nil
+}
+
+func (db *DB) DeleteFileSnapshots(before time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, snapshots := range db.snapshots {
+		maps.DeleteFunc(snapshots, func(_ types.FileId, snapshot snapshot) bool {
+			return snapshot.createdAt.Before(before)
+		})
+	}
+	return nil
+}
+
+func (db *DB) CreateApiKey(key types.ApiKey) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.apiKeys[key.Id]; exists {
+		return types.NewErrInvalidObjectF("api key %s already exists", key.Id)
+	}
+	db.apiKeys[key.Id] = key
+	return nil
+}
+
+func (db *DB) ApiKeys() ([]types.ApiKey, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	keys := slices.Collect(maps.Values(db.apiKeys))
+	slices.SortFunc(keys, func(a, b types.ApiKey) int {
+		return a.Created.Compare(b.Created)
+	})
+	return keys, nil
+}
+
+func (db *DB) ApiKey(id types.ApiKeyId) (types.ApiKey, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	key, exists := db.apiKeys[id]
+	if !exists {
+		return types.ApiKey{}, types.NewErrNotFoundF("api key %s not found", id)
+	}
+	return key, nil
+}
+
+func (db *DB) RevokeApiKey(id types.ApiKeyId) error {
+	return db.updateApiKey(id, func(key *types.ApiKey) {
+		if key.Revoked.IsZero() {
+			key.Revoked = time.Now()
+		}
+	})
+}
+
+func (db *DB) ExpireApiKey(id types.ApiKeyId, expires time.Time) error {
+	return db.updateApiKey(id, func(key *types.ApiKey) {
+		key.Expires = expires
+	})
+}
+
 func (db *DB) Migrate() error {
 	// NO-OP for inmemory database
 	return nil
@@ -95,14 +371,25 @@ func (db *DB) IsReady() bool {
 	return true
 }
 
+func (db *DB) updateApiKey(id types.ApiKeyId, update func(*types.ApiKey)) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key, exists := db.apiKeys[id]
+	if !exists {
+		return types.NewErrNotFoundF("api key %s not found", id)
+	}
+	update(&key)
+	db.apiKeys[id] = key
+	return nil
+}
+
 // Timestamp and append event to the in-memory store
 func (db *DB) appendEvent(
 	action types.EventAction,
 	projectId types.ProjectId,
 	fileId types.FileId,
-	userId types.UserId,
-	destination types.Destination,
-	comment string,
+	details types.EventDetails,
 ) {
 	if _, exists := db.state[projectId]; !exists {
 		db.state[projectId] = types.ProjectEvents{}
@@ -111,13 +398,9 @@ func (db *DB) appendEvent(
 		db.state[projectId][fileId] = types.FileEvents{}
 	}
 	event := types.Event{
-		Time:   time.Now(),
-		Action: action,
-		EventDetails: types.EventDetails{
-			UserId:      userId,
-			Destination: destination,
-			Comment:     comment,
-		},
+		Time:         time.Now(),
+		Action:       action,
+		EventDetails: details,
 	}
 	db.state[projectId][fileId] = append(db.state[projectId][fileId], event)
 }
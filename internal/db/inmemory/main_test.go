@@ -2,6 +2,7 @@ package inmemory
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/ucl-arc-tre/egress/internal/types"
@@ -20,10 +21,15 @@ const (
 	commentDownload = "results"
 )
 
+var (
+	actor1 = types.Actor{UserId: userId1}
+	actor2 = types.Actor{UserId: userId2}
+)
+
 func TestApproveThenList(t *testing.T) {
 	db := New()
 
-	err := db.ApproveFile(projectId, fileId, userId1, destTrusted, commentApprove1)
+	err := db.ApproveFile(projectId, fileId, actor1, destTrusted, commentApprove1)
 	assert.NoError(t, err)
 	approvals, err := db.FileApprovals(projectId)
 	assert.NoError(t, err)
@@ -31,7 +37,7 @@ func TestApproveThenList(t *testing.T) {
 	assert.Equal(t, userId1, approvals[fileId][0].UserId)
 	assert.Equal(t, destTrusted, approvals[fileId][0].Destination)
 
-	assert.NoError(t, db.ApproveFile(projectId, fileId, userId2, destPublic, commentApprove2))
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor2, destPublic, commentApprove2))
 	approvals, err = db.FileApprovals(projectId)
 	assert.NoError(t, err)
 	assert.Len(t, approvals[fileId], 2)
@@ -48,8 +54,8 @@ func TestListNoApprovals(t *testing.T) {
 func TestMultipleApprovals(t *testing.T) {
 	db := New()
 
-	assert.NoError(t, db.ApproveFile(projectId, fileId, userId1, destTrusted, commentApprove1))
-	assert.NoError(t, db.ApproveFile(projectId, fileId, userId1, destTrusted, commentApprove2))
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor1, destTrusted, commentApprove1))
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor1, destTrusted, commentApprove2))
 
 	// Approvals deduped on {userId,destination}, so only 1 approval returned
 	approvals, err := db.FileApprovals(projectId)
@@ -65,8 +71,8 @@ func TestMultipleApprovals(t *testing.T) {
 func TestApproveToMultipleDestinations(t *testing.T) {
 	db := New()
 
-	assert.NoError(t, db.ApproveFile(projectId, fileId, userId1, destTrusted, commentApprove1))
-	assert.NoError(t, db.ApproveFile(projectId, fileId, userId1, destPublic, commentApprove2))
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor1, destTrusted, commentApprove1))
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor1, destPublic, commentApprove2))
 
 	// Should have two approvals for the two different destinations
 	approvals, err := db.FileApprovals(projectId)
@@ -86,12 +92,12 @@ func TestMultipleApprovalsToMultipleDestinations(t *testing.T) {
 	db := New()
 
 	// Approvals for 2 different destinations
-	assert.NoError(t, db.ApproveFile(projectId, fileId, userId1, destTrusted, commentApprove1))
-	assert.NoError(t, db.ApproveFile(projectId, fileId, userId1, destPublic, commentApprove2))
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor1, destTrusted, commentApprove1))
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor1, destPublic, commentApprove2))
 
 	// Duplicate approvals for both destinations
-	assert.NoError(t, db.ApproveFile(projectId, fileId, userId1, destTrusted, commentApprove1))
-	assert.NoError(t, db.ApproveFile(projectId, fileId, userId1, destPublic, commentApprove2))
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor1, destTrusted, commentApprove1))
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor1, destPublic, commentApprove2))
 
 	// Approvals deduped on {userId,destination}, so only 2 approval returned
 	approvals, err := db.FileApprovals(projectId)
@@ -102,7 +108,7 @@ func TestMultipleApprovalsToMultipleDestinations(t *testing.T) {
 func TestRejectThenList(t *testing.T) {
 	db := New()
 
-	assert.NoError(t, db.RejectFile(projectId, fileId, userId1, destTrusted, commentReject))
+	assert.NoError(t, db.RejectFile(projectId, fileId, actor1, destTrusted, commentReject))
 
 	events, err := db.FileEvents(projectId)
 	assert.NoError(t, err)
@@ -116,8 +122,8 @@ func TestApproveThenReject(t *testing.T) {
 	db := New()
 
 	// Approve and then reject same file
-	assert.NoError(t, db.ApproveFile(projectId, fileId, userId1, destTrusted, commentApprove1))
-	assert.NoError(t, db.RejectFile(projectId, fileId, userId1, destTrusted, commentReject))
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor1, destTrusted, commentApprove1))
+	assert.NoError(t, db.RejectFile(projectId, fileId, actor1, destTrusted, commentReject))
 
 	// Reject cancels prior approval, so no approvals
 	approvals, err := db.FileApprovals(projectId)
@@ -135,7 +141,7 @@ func TestApproveThenReject(t *testing.T) {
 func TestDownloadThenList(t *testing.T) {
 	db := New()
 
-	assert.NoError(t, db.DownloadFile(projectId, fileId, userId1, destTrusted, commentDownload))
+	assert.NoError(t, db.DownloadFile(projectId, fileId, actor1, destTrusted, commentDownload))
 
 	events, err := db.FileEvents(projectId)
 	assert.NoError(t, err)
@@ -146,13 +152,39 @@ func TestDownloadThenList(t *testing.T) {
 	assert.Equal(t, commentDownload, events[fileId][0].Comment)
 }
 
+func TestDownloadByApiKey(t *testing.T) {
+	db := New()
+	actor := types.Actor{UserId: userId1, ApiKeyId: "key-1"}
+
+	assert.NoError(t, db.DownloadFile(projectId, fileId, actor, destTrusted, commentDownload))
+
+	events, err := db.FileEvents(projectId)
+	assert.NoError(t, err)
+	assert.Len(t, events[fileId], 1)
+	assert.Equal(t, userId1, events[fileId][0].UserId)
+	assert.Equal(t, types.ApiKeyId("key-1"), events[fileId][0].ApiKeyId)
+}
+
+func TestApproveByDelegator(t *testing.T) {
+	db := New()
+	actor := types.Actor{UserId: userId1, DelegatorId: "portal"}
+
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor, destTrusted, commentApprove1))
+
+	events, err := db.FileEvents(projectId)
+	assert.NoError(t, err)
+	assert.Len(t, events[fileId], 1)
+	assert.Equal(t, userId1, events[fileId][0].UserId)
+	assert.Equal(t, types.UserId("portal"), events[fileId][0].DelegatorId)
+}
+
 func TestListEvents(t *testing.T) {
 	db := New()
 
 	// Add three events
-	assert.NoError(t, db.RejectFile(projectId, fileId, userId1, destTrusted, commentApprove1))
-	assert.NoError(t, db.ApproveFile(projectId, fileId, userId1, destTrusted, commentReject))
-	assert.NoError(t, db.DownloadFile(projectId, fileId, userId1, destTrusted, commentDownload))
+	assert.NoError(t, db.RejectFile(projectId, fileId, actor1, destTrusted, commentApprove1))
+	assert.NoError(t, db.ApproveFile(projectId, fileId, actor1, destTrusted, commentReject))
+	assert.NoError(t, db.DownloadFile(projectId, fileId, actor1, destTrusted, commentDownload))
 
 	events, err := db.FileEvents(projectId)
 	assert.NoError(t, err)
@@ -161,3 +193,124 @@ func TestListEvents(t *testing.T) {
 	assert.Equal(t, types.EventActionApproval, events[fileId][1].Action)
 	assert.Equal(t, types.EventActionDownload, events[fileId][2].Action)
 }
+
+func TestPreviewFile(t *testing.T) {
+	db := New()
+
+	assert.NoError(t, db.PreviewFile(projectId, fileId, actor1, "text"))
+
+	events, err := db.FileEvents(projectId)
+	assert.NoError(t, err)
+	assert.Len(t, events[fileId], 1)
+	assert.Equal(t, types.EventActionPreview, events[fileId][0].Action)
+	assert.False(t, events[fileId].HasDownload(userId1, ""))
+}
+
+func TestRecordScan(t *testing.T) {
+	db := New()
+
+	assert.NoError(t, db.RecordScan(projectId, fileId, actor1, destTrusted, "clean (clamav)"))
+
+	events, err := db.FileEvents(projectId)
+	assert.NoError(t, err)
+	assert.Len(t, events[fileId], 1)
+	assert.Equal(t, types.EventActionScan, events[fileId][0].Action)
+	assert.Equal(t, destTrusted, events[fileId][0].Destination)
+	assert.Equal(t, "clean (clamav)", events[fileId][0].Comment)
+	assert.False(t, events[fileId].HasDownload(userId1, destTrusted))
+}
+
+func TestDownloadTransformedFile(t *testing.T) {
+	db := New()
+	hashes := types.TransformHashes{Original: "abc", Transformed: "def"}
+
+	assert.NoError(t, db.DownloadTransformedFile(projectId, fileId, actor1, destTrusted, commentDownload, hashes))
+
+	events, err := db.FileEvents(projectId)
+	assert.NoError(t, err)
+	assert.Len(t, events[fileId], 1)
+	assert.Equal(t, types.EventActionDownload, events[fileId][0].Action)
+	assert.Equal(t, hashes, events[fileId][0].Hashes)
+}
+
+func TestDownloadBundle(t *testing.T) {
+	db := New()
+	fileId2 := types.FileId("file-2")
+
+	assert.NoError(t, db.DownloadBundle(projectId, []types.FileId{fileId, fileId2}, "bundle-1", actor1, destTrusted, commentDownload))
+
+	events, err := db.FileEvents(projectId)
+	assert.NoError(t, err)
+	for _, id := range []types.FileId{fileId, fileId2} {
+		assert.Len(t, events[id], 1)
+		assert.Equal(t, types.EventActionDownload, events[id][0].Action)
+		assert.Equal(t, types.BundleId("bundle-1"), events[id][0].BundleId)
+		assert.Equal(t, commentDownload, events[id][0].Comment)
+	}
+}
+
+func TestRecordFileVersions(t *testing.T) {
+	db := New()
+	location := types.LocationURI{Scheme: "s3", Host: "bucket"}
+	other := types.LocationURI{Scheme: "s3", Host: "other-bucket"}
+
+	assert.NoError(t, db.RecordFileVersions(projectId, location, []types.FileMetadata{{Name: "results.csv", Id: "v1"}}))
+	assert.NoError(t, db.RecordFileVersions(projectId, location, []types.FileMetadata{{Name: "results.csv", Id: "v2"}}))
+	assert.NoError(t, db.RecordFileVersions(projectId, location, []types.FileMetadata{{Name: "results.csv", Id: "v1"}}))
+	assert.NoError(t, db.RecordFileVersions(projectId, other, []types.FileMetadata{{Name: "results.csv", Id: "v3"}}))
+
+	versions, err := db.FileVersions(projectId, location)
+	assert.NoError(t, err)
+	assert.Len(t, versions["results.csv"], 2)
+	previous, exists := versions.Previous("results.csv", "v2")
+	assert.True(t, exists)
+	assert.Equal(t, types.FileId("v1"), previous)
+
+	versions, err = db.FileVersions(projectId, other)
+	assert.NoError(t, err)
+	assert.Len(t, versions["results.csv"], 1)
+}
+
+func TestRecordFileSnapshot(t *testing.T) {
+	db := New()
+	location := types.LocationURI{Scheme: "s3", Host: "bucket"}
+
+	assert.NoError(t, db.RecordFileSnapshot(projectId, location, "v1", []byte("a,b\n")))
+	content, err := db.FileSnapshot(projectId, location, "v1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a,b\n"), content)
+
+	_, err = db.FileSnapshot(projectId, types.LocationURI{Scheme: "s3", Host: "other-bucket"}, "v1")
+	assert.ErrorIs(t, err, types.ErrNotFound)
+
+	assert.NoError(t, db.DeleteFileSnapshots(time.Now().Add(-time.Hour)))
+	_, err = db.FileSnapshot(projectId, location, "v1")
+	assert.NoError(t, err)
+	assert.NoError(t, db.DeleteFileSnapshots(time.Now().Add(time.Second)))
+	_, err = db.FileSnapshot(projectId, location, "v1")
+	assert.ErrorIs(t, err, types.ErrNotFound)
+}
+
+func TestApiKeys(t *testing.T) {
+	db := New()
+	key, _ := types.NewApiKey("portal", []types.ProjectId{projectId}, []types.ApiKeyScope{types.ApiKeyScopeRead}, time.Time{})
+
+	assert.NoError(t, db.CreateApiKey(key))
+	assert.Error(t, db.CreateApiKey(key))
+	keys, err := db.ApiKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, []types.ApiKey{key}, keys)
+
+	expires := time.Now().Add(time.Hour)
+	assert.NoError(t, db.ExpireApiKey(key.Id, expires))
+	assert.NoError(t, db.RevokeApiKey(key.Id))
+	stored, err := db.ApiKey(key.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, expires, stored.Expires)
+	assert.False(t, stored.Revoked.IsZero())
+	assert.False(t, stored.IsActive(time.Now()))
+
+	_, err = db.ApiKey("missing")
+	assert.ErrorIs(t, err, types.ErrNotFound)
+	assert.ErrorIs(t, db.RevokeApiKey("missing"), types.ErrNotFound)
+}
@@ -1,31 +1,98 @@
 package db
 
-import "github.com/ucl-arc-tre/egress/internal/types"
+import (
+	"time"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
 
 type Interface interface {
 	ApproveFile(
 		projectId types.ProjectId,
 		fileId types.FileId,
-		userId types.UserId,
+		actor types.Actor,
 		destination types.Destination,
 		comment string,
 	) error
 	RejectFile(
 		projectId types.ProjectId,
 		fileId types.FileId,
-		userId types.UserId,
+		actor types.Actor,
 		destination types.Destination,
 		comment string,
 	) error
 	DownloadFile(
 		projectId types.ProjectId,
 		fileId types.FileId,
-		userId types.UserId,
+		actor types.Actor,
 		destination types.Destination,
 		comment string,
 	) error
+	// Record a preview of the file's content, which is not egress
+	PreviewFile(
+		projectId types.ProjectId,
+		fileId types.FileId,
+		actor types.Actor,
+		comment string,
+	) error
+	// Record a download of the file transformed for the destination, with
+	// the hashes of its content before and after
+	DownloadTransformedFile(
+		projectId types.ProjectId,
+		fileId types.FileId,
+		actor types.Actor,
+		destination types.Destination,
+		comment string,
+		hashes types.TransformHashes,
+	) error
+	// Record a download of each of the files in an archive, all with the
+	// bundle id of the archive
+	DownloadBundle(
+		projectId types.ProjectId,
+		fileIds []types.FileId,
+		bundleId types.BundleId,
+		actor types.Actor,
+		destination types.Destination,
+		comment string,
+	) error
+	// Record a malware scan of the file for a download to the destination,
+	// with the verdict in the comment
+	RecordScan(
+		projectId types.ProjectId,
+		fileId types.FileId,
+		actor types.Actor,
+		destination types.Destination,
+		comment string,
+	) error
+	// Record an action taken on the file in storage e.g. EventActionTag
+	RecordStorageAction(
+		projectId types.ProjectId,
+		fileId types.FileId,
+		actor types.Actor,
+		destination types.Destination,
+		action types.EventAction,
+		comment string,
+	) error
 	FileApprovals(projectId types.ProjectId) (types.ProjectApprovals, error)
 	FileEvents(projectId types.ProjectId) (types.ProjectEvents, error)
+	// Record each of the files in the location as the latest version of its name
+	RecordFileVersions(projectId types.ProjectId, location types.LocationURI, files []types.FileMetadata) error
+	FileVersions(projectId types.ProjectId, location types.LocationURI) (types.ProjectVersions, error)
+	// Record the content of the file, so that it can be diffed after it is
+	// removed or overwritten in storage
+	RecordFileSnapshot(projectId types.ProjectId, location types.LocationURI, fileId types.FileId, content []byte) error
+	// The recorded content of the file, or an ErrNotFound
+	FileSnapshot(projectId types.ProjectId, location types.LocationURI, fileId types.FileId) ([]byte, error)
+	// Delete the content of files recorded before the time, which is no longer kept
+	DeleteFileSnapshots(before time.Time) error
+
+	CreateApiKey(key types.ApiKey) error
+	ApiKeys() ([]types.ApiKey, error)
+	// The key with the id, or an ErrNotFound
+	ApiKey(id types.ApiKeyId) (types.ApiKey, error)
+	RevokeApiKey(id types.ApiKeyId) error
+	// Set the time the key expires, which may be now
+	ExpireApiKey(id types.ApiKeyId, expires time.Time) error
 
 	Migrate() error
 	IsReady() bool
@@ -1,8 +1,11 @@
 package rqlite
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	rq "github.com/rqlite/gorqlite"
@@ -12,6 +15,8 @@ import (
 const (
 	datetimeLegacyFormat = time.DateTime // To parse old events timestamped within rqlite
 	datetimeSubsecFormat = time.DateTime + ".000"
+
+	maxStatementParams = 999 // SQLITE_MAX_VARIABLE_NUMBER before SQLite 3.32
 )
 
 type DB struct {
@@ -38,31 +43,100 @@ func New(baseURL, username, password string) (*DB, error) {
 func (db *DB) ApproveFile(
 	projectId types.ProjectId,
 	fileId types.FileId,
-	userId types.UserId,
+	actor types.Actor,
 	destination types.Destination,
 	comment string,
 ) error {
-	return db.insertEvent(types.EventActionApproval, projectId, fileId, userId, destination, comment)
+	return db.insertEvent(types.EventActionApproval, projectId, fileId, actor, destination, comment)
 }
 
 func (db *DB) RejectFile(
 	projectId types.ProjectId,
 	fileId types.FileId,
-	userId types.UserId,
+	actor types.Actor,
 	destination types.Destination,
 	comment string,
 ) error {
-	return db.insertEvent(types.EventActionRejection, projectId, fileId, userId, destination, comment)
+	return db.insertEvent(types.EventActionRejection, projectId, fileId, actor, destination, comment)
 }
 
 func (db *DB) DownloadFile(
 	projectId types.ProjectId,
 	fileId types.FileId,
-	userId types.UserId,
+	actor types.Actor,
+	destination types.Destination,
+	comment string,
+) error {
+	return db.insertEvent(types.EventActionDownload, projectId, fileId, actor, destination, comment)
+}
+
+func (db *DB) PreviewFile(
+	projectId types.ProjectId,
+	fileId types.FileId,
+	actor types.Actor,
+	comment string,
+) error {
+	return db.insertEvent(types.EventActionPreview, projectId, fileId, actor, "", comment)
+}
+
+func (db *DB) DownloadTransformedFile(
+	projectId types.ProjectId,
+	fileId types.FileId,
+	actor types.Actor,
+	destination types.Destination,
+	comment string,
+	hashes types.TransformHashes,
+) error {
+	sqlInsert := `INSERT INTO events (project_id, file_id, user_id, api_key_id, delegator_id, destination, action, comment, original_hash, transformed_hash, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	createdAt := time.Now().UTC().Format(datetimeSubsecFormat)
+	stmt := rq.ParameterizedStatement{
+		Query:     sqlInsert,
+		Arguments: []any{projectId, fileId, actor.UserId, actor.ApiKeyId, actor.DelegatorId, destination, types.EventActionDownload, comment, hashes.Original, hashes.Transformed, createdAt},
+	}
+
+	wr, operr := db.conn.WriteOneParameterized(stmt)
+	return unifyErrors("[rqlite] failed to insert event", operr, wr.Err)
+}
+
+// The events of the bundle are inserted in a single transaction, so either
+// all or none are recorded
+func (db *DB) DownloadBundle(
+	projectId types.ProjectId,
+	fileIds []types.FileId,
+	bundleId types.BundleId,
+	actor types.Actor,
+	destination types.Destination,
+	comment string,
+) error {
+	createdAt := time.Now().UTC().Format(datetimeSubsecFormat)
+	rows := [][]any{}
+	for _, fileId := range fileIds {
+		rows = append(rows, []any{projectId, fileId, actor.UserId, actor.ApiKeyId, actor.DelegatorId, destination, types.EventActionDownload, comment, bundleId, createdAt})
+	}
+	sqlInsert := `INSERT INTO events (project_id, file_id, user_id, api_key_id, delegator_id, destination, action, comment, bundle_id, created_at) VALUES `
+	return db.writeBatches("[rqlite] failed to insert bundle events", batchInsert(sqlInsert, rows))
+}
+
+func (db *DB) RecordScan(
+	projectId types.ProjectId,
+	fileId types.FileId,
+	actor types.Actor,
+	destination types.Destination,
+	comment string,
+) error {
+	return db.insertEvent(types.EventActionScan, projectId, fileId, actor, destination, comment)
+}
+
+func (db *DB) RecordStorageAction(
+	projectId types.ProjectId,
+	fileId types.FileId,
+	actor types.Actor,
 	destination types.Destination,
+	action types.EventAction,
 	comment string,
 ) error {
-	return db.insertEvent(types.EventActionDownload, projectId, fileId, userId, destination, comment)
+	return db.insertEvent(action, projectId, fileId, actor, destination, comment)
 }
 
 func (db *DB) FileApprovals(projectId types.ProjectId) (types.ProjectApprovals, error) {
@@ -74,7 +148,7 @@ func (db *DB) FileApprovals(projectId types.ProjectId) (types.ProjectApprovals,
 }
 
 func (db *DB) FileEvents(projectId types.ProjectId) (types.ProjectEvents, error) {
-	sqlFileEvents := `SELECT file_id, user_id, destination, action, comment, created_at FROM events WHERE project_id = ? ORDER BY id ASC`
+	sqlFileEvents := `SELECT file_id, user_id, api_key_id, delegator_id, destination, action, comment, bundle_id, original_hash, transformed_hash, created_at FROM events WHERE project_id = ? ORDER BY id ASC`
 
 	stmt := rq.ParameterizedStatement{
 		Query:     sqlFileEvents,
@@ -89,8 +163,8 @@ func (db *DB) FileEvents(projectId types.ProjectId) (types.ProjectEvents, error)
 
 	projectEvents := make(types.ProjectEvents)
 	for qr.Next() {
-		var fileId, userId, destination, action, comment, createdAt string
-		if err := qr.Scan(&fileId, &userId, &destination, &action, &comment, &createdAt); err != nil {
+		var fileId, userId, apiKeyId, delegatorId, destination, action, comment, bundleId, originalHash, transformedHash, createdAt string
+		if err := qr.Scan(&fileId, &userId, &apiKeyId, &delegatorId, &destination, &action, &comment, &bundleId, &originalHash, &transformedHash, &createdAt); err != nil {
 			return nil, types.NewErrServerF("[rqlite] failed to scan row: %w", err)
 		}
 		dt, err := parseDatetime(createdAt)
@@ -102,8 +176,15 @@ func (db *DB) FileEvents(projectId types.ProjectId) (types.ProjectEvents, error)
 			Action: types.EventAction(action),
 			EventDetails: types.EventDetails{
 				UserId:      types.UserId(userId),
+				ApiKeyId:    types.ApiKeyId(apiKeyId),
+				DelegatorId: types.UserId(delegatorId),
 				Destination: types.Destination(destination),
 				Comment:     comment,
+				BundleId:    types.BundleId(bundleId),
+				Hashes: types.TransformHashes{
+					Original:    originalHash,
+					Transformed: transformedHash,
+				},
 			},
 		}
 		fid := types.FileId(fileId)
@@ -112,6 +193,148 @@ func (db *DB) FileEvents(projectId types.ProjectId) (types.ProjectEvents, error)
 	return projectEvents, nil
 }
 
+// Versions that are already recorded are ignored, so that listing the same
+// files again does not change when they were first seen
+func (db *DB) RecordFileVersions(projectId types.ProjectId, location types.LocationURI, files []types.FileMetadata) error {
+	createdAt := time.Now().UTC().Format(datetimeSubsecFormat)
+	rows := [][]any{}
+	for _, file := range files {
+		rows = append(rows, []any{projectId, location.String(), file.Name, file.Id, createdAt})
+	}
+	sqlInsert := `INSERT OR IGNORE INTO file_versions (project_id, location, name, file_id, created_at) VALUES `
+	return db.writeBatches("[rqlite] failed to insert file versions", batchInsert(sqlInsert, rows))
+}
+
+func (db *DB) FileVersions(projectId types.ProjectId, location types.LocationURI) (types.ProjectVersions, error) {
+	sqlFileVersions := `SELECT name, file_id, created_at FROM file_versions WHERE project_id = ? AND location = ? ORDER BY id ASC`
+
+	stmt := rq.ParameterizedStatement{
+		Query:     sqlFileVersions,
+		Arguments: []any{projectId, location.String()},
+	}
+
+	qr, operr := db.conn.QueryOneParameterized(stmt)
+	err := unifyErrors("[rqlite] failed to execute file versions query", operr, qr.Err)
+	if err != nil {
+		return nil, err
+	}
+
+	projectVersions := make(types.ProjectVersions)
+	for qr.Next() {
+		var name, fileId, createdAt string
+		if err := qr.Scan(&name, &fileId, &createdAt); err != nil {
+			return nil, types.NewErrServerF("[rqlite] failed to scan row: %w", err)
+		}
+		dt, err := parseDatetime(createdAt)
+		if err != nil {
+			return nil, types.NewErrServerF("[rqlite] failed to parse timestamp %q: %w", createdAt, err)
+		}
+		projectVersions[name] = append(projectVersions[name], types.FileVersion{
+			FileId:    types.FileId(fileId),
+			FirstSeen: dt,
+		})
+	}
+	return projectVersions, nil
+}
+
+// Content is stored base64 encoded, as it need not be valid UTF-8
+func (db *DB) RecordFileSnapshot(projectId types.ProjectId, location types.LocationURI, fileId types.FileId, content []byte) error {
+	sqlInsert := `INSERT OR REPLACE INTO file_snapshots (project_id, location, file_id, content, created_at) VALUES (?, ?, ?, ?, ?)`
+
+	stmt := rq.ParameterizedStatement{
+		Query: sqlInsert,
+		Arguments: []any{
+			projectId, location.String(), fileId,
+			base64.StdEncoding.EncodeToString(content), formatDatetime(time.Now()),
+		},
+	}
+	wr, operr := db.conn.WriteOneParameterized(stmt)
+	return unifyErrors("[rqlite] failed to insert file snapshot", operr, wr.Err)
+}
+
+func (db *DB) FileSnapshot(projectId types.ProjectId, location types.LocationURI, fileId types.FileId) ([]byte, error) {
+	sqlFileSnapshot := `SELECT content FROM file_snapshots WHERE project_id = ? AND location = ? AND file_id = ?`
+
+	stmt := rq.ParameterizedStatement{
+		Query:     sqlFileSnapshot,
+		Arguments: []any{projectId, location.String(), fileId},
+	}
+
+	qr, operr := db.conn.QueryOneParameterized(stmt)
+	err := unifyErrors("[rqlite] failed to execute file snapshot query", operr, qr.Err)
+	if err != nil {
+		return nil, err
+	}
+	if !qr.Next() {
+		return nil, types.NewErrNotFoundF("[rqlite] snapshot of file %s not found", fileId)
+	}
+	var encoded string
+	if err := qr.Scan(&encoded); err != nil {
+		return nil, types.NewErrServerF("[rqlite] failed to scan row: %w", err)
+	}
+	content, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, types.NewErrServerF("[rqlite] failed to decode file snapshot: %w", err)
+	}
+	return content, nil
+}
+
+func (db *DB) DeleteFileSnapshots(before time.Time) error {
+	sqlDelete := `DELETE FROM file_snapshots WHERE created_at < ?`
+
+	stmt := rq.ParameterizedStatement{
+		Query:     sqlDelete,
+		Arguments: []any{formatDatetime(before)},
+	}
+	wr, operr := db.conn.WriteOneParameterized(stmt)
+	return unifyErrors("[rqlite] failed to delete file snapshots", operr, wr.Err)
+}
+
+func (db *DB) CreateApiKey(key types.ApiKey) error {
+	projects, err := json.Marshal(key.Projects)
+	if err != nil {
+		return types.NewErrServerF("[rqlite] failed to encode api key projects: %w", err)
+	}
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return types.NewErrServerF("[rqlite] failed to encode api key scopes: %w", err)
+	}
+	sqlInsert := `INSERT INTO api_keys (id, label, projects, scopes, hash, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	stmt := rq.ParameterizedStatement{
+		Query:     sqlInsert,
+		Arguments: []any{key.Id, key.Label, string(projects), string(scopes), key.Hash, formatDatetime(key.Created), formatDatetime(key.Expires)},
+	}
+	wr, operr := db.conn.WriteOneParameterized(stmt)
+	return unifyErrors("[rqlite] failed to insert api key", operr, wr.Err)
+}
+
+func (db *DB) ApiKeys() ([]types.ApiKey, error) {
+	return db.queryApiKeys(`SELECT id, label, projects, scopes, hash, created_at, expires_at, revoked_at FROM api_keys ORDER BY created_at ASC`)
+}
+
+func (db *DB) ApiKey(id types.ApiKeyId) (types.ApiKey, error) {
+	keys, err := db.queryApiKeys(`SELECT id, label, projects, scopes, hash, created_at, expires_at, revoked_at FROM api_keys WHERE id = ?`, id)
+	if err != nil {
+		return types.ApiKey{}, err
+	}
+	if len(keys) == 0 {
+		return types.ApiKey{}, types.NewErrNotFoundF("[rqlite] api key %s not found", id)
+	}
+	return keys[0], nil
+}
+
+// The time the key was first revoked is kept if it is revoked again
+func (db *DB) RevokeApiKey(id types.ApiKeyId) error {
+	sqlUpdate := `UPDATE api_keys SET revoked_at = CASE WHEN revoked_at = '' THEN ? ELSE revoked_at END WHERE id = ?`
+	return db.updateApiKey(sqlUpdate, id, formatDatetime(time.Now()), id)
+}
+
+func (db *DB) ExpireApiKey(id types.ApiKeyId, expires time.Time) error {
+	sqlUpdate := `UPDATE api_keys SET expires_at = ? WHERE id = ?`
+	return db.updateApiKey(sqlUpdate, id, formatDatetime(expires), id)
+}
+
 func (db *DB) IsReady() bool {
 	sqlIsReady := `SELECT 1 FROM events LIMIT 1`
 
@@ -119,26 +342,89 @@ func (db *DB) IsReady() bool {
 	return operr == nil && qr.Err == nil
 }
 
+func (db *DB) queryApiKeys(query string, args ...any) ([]types.ApiKey, error) {
+	stmt := rq.ParameterizedStatement{
+		Query:     query,
+		Arguments: args,
+	}
+	qr, operr := db.conn.QueryOneParameterized(stmt)
+	err := unifyErrors("[rqlite] failed to execute api keys query", operr, qr.Err)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []types.ApiKey{}
+	for qr.Next() {
+		var id, label, projects, scopes, hash, createdAt, expiresAt, revokedAt string
+		if err := qr.Scan(&id, &label, &projects, &scopes, &hash, &createdAt, &expiresAt, &revokedAt); err != nil {
+			return nil, types.NewErrServerF("[rqlite] failed to scan row: %w", err)
+		}
+		key := types.ApiKey{Id: types.ApiKeyId(id), Label: label, Hash: hash}
+		if err := json.Unmarshal([]byte(projects), &key.Projects); err != nil {
+			return nil, types.NewErrServerF("[rqlite] failed to decode api key projects: %w", err)
+		}
+		if err := json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+			return nil, types.NewErrServerF("[rqlite] failed to decode api key scopes: %w", err)
+		}
+		for _, dt := range []struct {
+			value string
+			time  *time.Time
+		}{{createdAt, &key.Created}, {expiresAt, &key.Expires}, {revokedAt, &key.Revoked}} {
+			if dt.value == "" {
+				continue
+			}
+			if *dt.time, err = parseDatetime(dt.value); err != nil {
+				return nil, types.NewErrServerF("[rqlite] failed to parse timestamp %q: %w", dt.value, err)
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (db *DB) updateApiKey(query string, id types.ApiKeyId, args ...any) error {
+	stmt := rq.ParameterizedStatement{
+		Query:     query,
+		Arguments: args,
+	}
+	wr, operr := db.conn.WriteOneParameterized(stmt)
+	if err := unifyErrors("[rqlite] failed to update api key", operr, wr.Err); err != nil {
+		return err
+	}
+	if wr.RowsAffected == 0 {
+		return types.NewErrNotFoundF("[rqlite] api key %s not found", id)
+	}
+	return nil
+}
+
 func (db *DB) insertEvent(
 	action types.EventAction,
 	projectId types.ProjectId,
 	fileId types.FileId,
-	userId types.UserId,
+	actor types.Actor,
 	destination types.Destination,
 	comment string,
 ) error {
-	sqlInsert := `INSERT INTO events (project_id, file_id, user_id, destination, action, comment, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	sqlInsert := `INSERT INTO events (project_id, file_id, user_id, api_key_id, delegator_id, destination, action, comment, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	createdAt := time.Now().UTC().Format(datetimeSubsecFormat)
 	stmt := rq.ParameterizedStatement{
 		Query:     sqlInsert,
-		Arguments: []any{projectId, fileId, userId, destination, action, comment, createdAt},
+		Arguments: []any{projectId, fileId, actor.UserId, actor.ApiKeyId, actor.DelegatorId, destination, action, comment, createdAt},
 	}
 
 	wr, operr := db.conn.WriteOneParameterized(stmt)
 	return unifyErrors("[rqlite] failed to insert event", operr, wr.Err)
 }
 
+// Format the time in UTC as stored, or "" if it is zero
+func formatDatetime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(datetimeSubsecFormat)
+}
+
 // Parse datetime strings while accommodating for the non-subsecond
 // precision of the default values for 'created_at' column
 func parseDatetime(s string) (time.Time, error) {
@@ -158,6 +444,41 @@ func buildAuthURL(baseURL, username, password string) (string, error) {
 	return u.String(), nil
 }
 
+// Statements inserting the rows in batches, each with no more parameters
+// than the lowest limit of SQLite builds
+func batchInsert(sqlInsert string, rows [][]any) []rq.ParameterizedStatement {
+	stmts := []rq.ParameterizedStatement{}
+	for len(rows) > 0 {
+		n := min(len(rows), max(maxStatementParams/len(rows[0]), 1))
+		values := []string{}
+		args := []any{}
+		for _, row := range rows[:n] {
+			values = append(values, "("+strings.TrimSuffix(strings.Repeat("?, ", len(row)), ", ")+")")
+			args = append(args, row...)
+		}
+		stmts = append(stmts, rq.ParameterizedStatement{
+			Query:     sqlInsert + strings.Join(values, ", "),
+			Arguments: args,
+		})
+		rows = rows[n:]
+	}
+	return stmts
+}
+
+// Write the statements in a single transaction
+func (db *DB) writeBatches(msg string, stmts []rq.ParameterizedStatement) error {
+	if len(stmts) == 0 {
+		return nil
+	}
+	results, operr := db.conn.WriteParameterized(stmts)
+	for _, wr := range results {
+		if wr.Err != nil {
+			return unifyErrors(msg, nil, wr.Err)
+		}
+	}
+	return unifyErrors(msg, operr, nil)
+}
+
 func unifyErrors(msg string, operr, dberr error) error {
 	if operr != nil { // First check for connection errors..
 		return types.NewErrServerF("%s: %w", msg, operr)
@@ -49,3 +49,18 @@ func TestParseDatetimeLegacyFormat(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, time.Date(2025, 12, 4, 22, 8, 4, 0, time.UTC), dt)
 }
+
+func TestBatchInsert(t *testing.T) {
+	rows := [][]any{}
+	for i := range 250 {
+		rows = append(rows, []any{"project", i, "name", "file", "2026-04-24 10:44:48.442"})
+	}
+	stmts := batchInsert("INSERT INTO t (a, b, c, d, e) VALUES ", rows)
+
+	assert.Len(t, stmts, 2)
+	assert.Len(t, stmts[0].Arguments, 995)
+	assert.Len(t, stmts[1].Arguments, 5*51)
+	assert.Equal(t, "INSERT INTO t (a, b, c, d, e) VALUES (?, ?, ?, ?, ?), (?, ?, ?, ?, ?)", stmts[1].Query[:69])
+	assert.Equal(t, 199, stmts[1].Arguments[1])
+	assert.Empty(t, batchInsert("INSERT INTO t (a) VALUES ", nil))
+}
@@ -0,0 +1 @@
+ALTER TABLE events ADD COLUMN bundle_id TEXT NOT NULL DEFAULT '';
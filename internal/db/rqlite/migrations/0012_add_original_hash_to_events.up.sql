@@ -0,0 +1 @@
+ALTER TABLE events ADD COLUMN original_hash TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE events ADD COLUMN transformed_hash TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS file_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id TEXT NOT NULL,
+    location TEXT NOT NULL,
+    name TEXT NOT NULL,
+    file_id TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    UNIQUE (project_id, location, name, file_id)
+);
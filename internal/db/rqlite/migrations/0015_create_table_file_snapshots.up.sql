@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS file_snapshots (
+    project_id TEXT NOT NULL,
+    location TEXT NOT NULL,
+    file_id TEXT NOT NULL,
+    content TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (project_id, location, file_id)
+);
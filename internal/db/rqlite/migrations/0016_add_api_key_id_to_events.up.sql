@@ -0,0 +1 @@
+ALTER TABLE events ADD COLUMN api_key_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE events ADD COLUMN delegator_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,158 @@
+// Package diff finds the changes between two versions of a text or CSV
+// file, so that only what changed needs to be reviewed
+package diff
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/ucl-arc-tre/egress/internal/preview"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// The versions differ by more than the maximum number of changes
+var ErrTooManyChanges = errors.New("too many changes")
+
+// Lines inserted into and deleted from the base text, in order
+func Text(base, other []byte, maxChanges int) ([]types.LineChange, error) {
+	a, b := lines(base), lines(other)
+	edits, err := script(a, b, maxChanges)
+	if err != nil {
+		return nil, err
+	}
+	changes := []types.LineChange{}
+	for _, e := range edits {
+		switch e.op {
+		case types.DiffOpDelete:
+			changes = append(changes, types.LineChange{Op: e.op, BaseLine: e.a + 1, Text: a[e.a]})
+		case types.DiffOpInsert:
+			changes = append(changes, types.LineChange{Op: e.op, Line: e.b + 1, Text: b[e.b]})
+		}
+	}
+	return changes, nil
+}
+
+// Rows inserted into, deleted from and modified in the base version of the
+// CSV or TSV file with the name. Within a run of changed rows, deleted and
+// inserted rows are paired in order as modified rows, whose cells are
+// compared by column
+func Table(base, other []byte, name string, maxChanges int) ([]types.RowChange, error) {
+	a, err := rows(base, name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := rows(other, name)
+	if err != nil {
+		return nil, err
+	}
+	edits, err := script(rowKeys(a), rowKeys(b), maxChanges)
+	if err != nil {
+		return nil, err
+	}
+	header := []string{}
+	if len(a) > 0 && len(b) > 0 && rowKeys(a[:1])[0] == rowKeys(b[:1])[0] {
+		header = a[0]
+	}
+
+	changes := []types.RowChange{}
+	deleted, inserted := []edit{}, []edit{}
+	flush := func() {
+		paired := min(len(deleted), len(inserted))
+		for i := range paired {
+			changes = append(changes, types.RowChange{
+				Op:      types.DiffOpModify,
+				BaseRow: deleted[i].a + 1,
+				Row:     inserted[i].b + 1,
+				Changes: cellChanges(a[deleted[i].a], b[inserted[i].b], header),
+			})
+		}
+		for _, e := range deleted[paired:] {
+			changes = append(changes, types.RowChange{Op: types.DiffOpDelete, BaseRow: e.a + 1, Cells: a[e.a]})
+		}
+		for _, e := range inserted[paired:] {
+			changes = append(changes, types.RowChange{Op: types.DiffOpInsert, Row: e.b + 1, Cells: b[e.b]})
+		}
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+	for _, e := range edits {
+		switch e.op {
+		case types.DiffOpDelete:
+			deleted = append(deleted, e)
+		case types.DiffOpInsert:
+			inserted = append(inserted, e)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return changes, nil
+}
+
+// Cells of the row that differ from the base row, including cells that
+// are in only one of them
+func cellChanges(base, row []string, header []string) []types.CellChange {
+	changes := []types.CellChange{}
+	for column := range max(len(base), len(row)) {
+		baseValue, inBase := cell(base, column)
+		value, inRow := cell(row, column)
+		if inBase == inRow && baseValue == value {
+			continue
+		}
+		change := types.CellChange{Column: column + 1, Base: baseValue, Value: value}
+		change.Header, _ = cell(header, column)
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func cell(row []string, column int) (string, bool) {
+	if column < len(row) {
+		return row[column], true
+	}
+	return "", false
+}
+
+// Lines of the content, without line endings
+func lines(content []byte) []string {
+	text := strings.ToValidUTF8(string(content), "�")
+	if text == "" {
+		return []string{}
+	}
+	result := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range result {
+		result[i] = strings.TrimSuffix(line, "\r")
+	}
+	return result
+}
+
+func rows(content []byte, name string) ([][]string, error) {
+	cr := csv.NewReader(bytes.NewReader(content))
+	cr.Comma = preview.Delimiter(name)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	result := [][]string{}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		} else if err != nil {
+			return nil, types.NewErrInvalidObjectF("failed to parse table: %v", err)
+		}
+		for i, value := range record {
+			record[i] = strings.ToValidUTF8(value, "�")
+		}
+		result = append(result, record)
+	}
+}
+
+// Rows as strings that are equal only if the rows are
+func rowKeys(rows [][]string) []string {
+	keys := make([]string, len(rows))
+	for i, row := range rows {
+		keys[i] = strings.Join(row, "\x00") + "\x00"
+	}
+	return keys
+}
@@ -0,0 +1,58 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+func TestText(t *testing.T) {
+	changes, err := Text([]byte("a\nb\nc\n"), []byte("a\r\nc\nd"), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []types.LineChange{
+		{Op: types.DiffOpDelete, BaseLine: 2, Text: "b"},
+		{Op: types.DiffOpInsert, Line: 3, Text: "d"},
+	}, changes)
+
+	changes, err = Text([]byte("same\n"), []byte("same\n"), 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	changes, err = Text([]byte(""), []byte("a\nb\n"), 10)
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+}
+
+func TestTextTooManyChanges(t *testing.T) {
+	_, err := Text([]byte("a\nb\nc\n"), []byte("d\ne\nf\n"), 5)
+	assert.ErrorIs(t, err, ErrTooManyChanges)
+	_, err = Text([]byte("a\nb\nc\n"), []byte("d\ne\nf\n"), 6)
+	assert.NoError(t, err)
+}
+
+func TestTable(t *testing.T) {
+	base := "id,count\n1,10\n2,20\n3,30\n"
+	other := "id,count\n1,10\n2,25\n4,40\n5,50\n"
+	changes, err := Table([]byte(base), []byte(other), "results.csv", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []types.RowChange{
+		{Op: types.DiffOpModify, BaseRow: 3, Row: 3, Changes: []types.CellChange{
+			{Column: 2, Header: "count", Base: "20", Value: "25"},
+		}},
+		{Op: types.DiffOpModify, BaseRow: 4, Row: 4, Changes: []types.CellChange{
+			{Column: 1, Header: "id", Base: "3", Value: "4"},
+			{Column: 2, Header: "count", Base: "30", Value: "40"},
+		}},
+		{Op: types.DiffOpInsert, Row: 5, Cells: []string{"5", "50"}},
+	}, changes)
+
+	// Without the same header row, columns have no header
+	changes, err = Table([]byte("a\tb\n1\t2\n"), []byte("a\tc\n1\t2\t3\n"), "results.tsv", 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, []types.CellChange{{Column: 2, Base: "b", Value: "c"}}, changes[0].Changes)
+	assert.Equal(t, []types.CellChange{{Column: 3, Value: "3"}}, changes[1].Changes)
+}
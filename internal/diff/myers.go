@@ -0,0 +1,88 @@
+package diff
+
+import (
+	"slices"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Keeps an element of both sequences
+const opEqual = types.DiffOp("equal")
+
+// Edit of the element at index a of the first sequence and/or index b of
+// the second
+type edit struct {
+	op   types.DiffOp
+	a, b int
+}
+
+// Shortest edit script from a to b, by Myers' algorithm. The furthest
+// reaching path along each diagonal is kept for each number of changes,
+// so memory grows with the square of the number of changes, which is
+// bounded by maxChanges
+func script(a, b []string, maxChanges int) ([]edit, error) {
+	n, m := len(a), len(b)
+	trace := [][]int{}
+	prev := []int{}
+	for d := 0; d <= maxChanges; d++ {
+		v := make([]int, 2*d+1) // Indexed by diagonal k+d
+		for k := -d; k <= d; k += 2 {
+			x := 0
+			if d > 0 {
+				if isInsert(prev, k, d) {
+					x = prev[k+1+d-1]
+				} else {
+					x = prev[k-1+d-1] + 1
+				}
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[k+d] = x
+			if x >= n && y >= m {
+				return backtrack(append(trace, v), n, m), nil
+			}
+		}
+		trace = append(trace, v)
+		prev = v
+	}
+	return nil, ErrTooManyChanges
+}
+
+// Whether the path to diagonal k with d changes comes from diagonal k+1,
+// by an insertion, rather than from k-1 by a deletion
+func isInsert(prev []int, k, d int) bool {
+	return k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1])
+}
+
+func backtrack(trace [][]int, n, m int) []edit {
+	edits := []edit{}
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		prevX, prevY := 0, 0
+		if d > 0 {
+			k := x - y
+			prevK := k - 1
+			if isInsert(trace[d-1], k, d) {
+				prevK = k + 1
+			}
+			prevX = trace[d-1][prevK+d-1]
+			prevY = prevX - prevK
+		}
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			edits = append(edits, edit{op: opEqual, a: x, b: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{op: types.DiffOpInsert, a: x, b: prevY})
+			} else {
+				edits = append(edits, edit{op: types.DiffOpDelete, a: prevX, b: y})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	slices.Reverse(edits)
+	return edits
+}
@@ -0,0 +1,87 @@
+// Package filetype detects the media type of files from their leading
+// bytes, whatever their extension
+package filetype
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/ucl-arc-tre/egress/internal/cache"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Number of leading bytes of a file used to detect its type
+const SniffLength = 512
+
+// Opens the range of the file
+type OpenFunc func(ctx context.Context, byteRange *types.ByteRange) (io.ReadCloser, error)
+
+// Media type of content from its leading bytes, without parameters e.g.
+// image/png. Plain text is refined by the extension of the file name, as
+// tables cannot be told apart from other text by their bytes
+func Detect(name string, head []byte) string {
+	return refine(name, sniff(head))
+}
+
+// Media type of content from its leading bytes alone
+func sniff(head []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// Media type refined by the extension of the file name
+func refine(name string, mediaType string) string {
+	if mediaType == "text/plain" {
+		switch strings.ToLower(path.Ext(name)) {
+		case ".csv":
+			return "text/csv"
+		case ".tsv", ".tab":
+			return "text/tab-separated-values"
+		}
+	}
+	return mediaType
+}
+
+// Detector detects the media types of files. The types sniffed from their
+// content are cached by the location and id of the file, and refined by the
+// name of each file, as the same content may have different names
+type Detector struct {
+	mediaTypes *cache.FIFO[cache.FileKey, string]
+}
+
+func NewDetector(cacheSize int) *Detector {
+	return &Detector{mediaTypes: cache.NewFIFO[cache.FileKey, string](cacheSize)}
+}
+
+// Media type of the file in the location, reading only its leading bytes
+func (d *Detector) Detect(ctx context.Context, location types.LocationURI, metadata *types.FileMetadata, open OpenFunc) (string, error) {
+	key := cache.KeyOf(location, metadata.Id)
+	if mediaType, exists := d.mediaTypes.Get(key); exists {
+		return refine(metadata.Name, mediaType), nil
+	}
+	head := []byte{}
+	if metadata.Size > 0 { // A range of an empty file is not satisfiable
+		content, err := open(ctx, &types.ByteRange{Start: 0, Length: SniffLength})
+		if err != nil {
+			return "", err
+		}
+		defer content.Close()
+		head = make([]byte, SniffLength)
+		n, err := io.ReadFull(content, head)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return "", err
+		}
+		head = head[:n]
+	}
+	mediaType := sniff(head)
+	d.mediaTypes.Put(key, mediaType)
+	return refine(metadata.Name, mediaType), nil
+}
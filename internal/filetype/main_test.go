@@ -0,0 +1,106 @@
+package filetype
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+func TestDetect(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	assert.Equal(t, "image/png", Detect("figure.png", []byte(png)))
+	assert.Equal(t, "image/png", Detect("results.csv", []byte(png)))
+	assert.Equal(t, "application/pdf", Detect("report", []byte("%PDF-1.7\n")))
+	assert.Equal(t, "text/csv", Detect("results.CSV", []byte("a,b\n1,2\n")))
+	assert.Equal(t, "text/tab-separated-values", Detect("results.tsv", []byte("a\tb\n")))
+	assert.Equal(t, "text/plain", Detect("notes.txt", []byte("hello")))
+	assert.Equal(t, "application/zip", Detect("results.csv", []byte("PK\x03\x04")))
+}
+
+var location = types.LocationURI{Scheme: "s3", Host: "bucket"}
+
+func TestDetector(t *testing.T) {
+	detector := NewDetector(10)
+	opened := 0
+	open := func(ctx context.Context, byteRange *types.ByteRange) (io.ReadCloser, error) {
+		opened++
+		assert.Equal(t, &types.ByteRange{Start: 0, Length: SniffLength}, byteRange)
+		return io.NopCloser(strings.NewReader("%PDF-1.7\n")), nil
+	}
+	for range 2 {
+		mediaType, err := detector.Detect(context.Background(), location, &types.FileMetadata{Id: "id1", Name: "a.txt", Size: 9}, open)
+		require.NoError(t, err)
+		assert.Equal(t, "application/pdf", mediaType)
+	}
+	assert.Equal(t, 1, opened)
+
+	// An empty file is not read
+	mediaType, err := detector.Detect(context.Background(), location, &types.FileMetadata{Id: "id2", Name: "a.csv"}, open)
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv", mediaType)
+	assert.Equal(t, 1, opened)
+
+	// The same content with another name is refined by that name
+	mediaType, err = detector.Detect(context.Background(), location, &types.FileMetadata{Id: "id2", Name: "a.txt"}, open)
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", mediaType)
+	assert.Equal(t, 1, opened)
+
+	// The same id in another location is another file
+	other := types.LocationURI{Scheme: "s3", Host: "other-bucket"}
+	mediaType, err = detector.Detect(context.Background(), other, &types.FileMetadata{Id: "id1", Name: "a.txt", Size: 9}, open)
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", mediaType)
+	assert.Equal(t, 2, opened)
+}
+
+func TestOfficeDocument(t *testing.T) {
+	contentTypes := `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+		`</Types>`
+	rels := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+		`</Relationships>`
+	testCases := []struct {
+		name    string
+		entries [][2]string
+		format  OfficeFormat
+	}{
+		{"docx", [][2]string{{"[Content_Types].xml", contentTypes}, {"_rels/.rels", rels}, {"word/document.xml", "<w:document/>"}}, OfficeFormatOOXML},
+		{"odt", [][2]string{{"mimetype", "application/vnd.oasis.opendocument.text"}, {"META-INF/manifest.xml", "<manifest/>"}, {"content.xml", "<content/>"}}, OfficeFormatODF},
+		{"content types only", [][2]string{{"[Content_Types].xml", ""}, {"payload.exe", "MZ"}}, ""},
+		{"no main part", [][2]string{{"[Content_Types].xml", contentTypes}, {"_rels/.rels", rels}}, ""},
+		{"main part not a document", [][2]string{{"[Content_Types].xml", strings.ReplaceAll(contentTypes, "wordprocessingml.document.main", "x")}, {"_rels/.rels", rels}, {"word/document.xml", ""}}, ""},
+		{"mimetype only", [][2]string{{"mimetype", "application/vnd.oasis.opendocument.text"}, {"payload.exe", "MZ"}}, ""},
+	}
+	for _, tc := range testCases {
+		buffer := bytes.Buffer{}
+		zw := zip.NewWriter(&buffer)
+		for _, entry := range tc.entries {
+			method := zip.Deflate
+			if entry[0] == "mimetype" {
+				method = zip.Store
+			}
+			w, err := zw.CreateHeader(&zip.FileHeader{Name: entry[0], Method: method})
+			require.NoError(t, err)
+			_, err = w.Write([]byte(entry[1]))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+		zr, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		require.NoError(t, err)
+
+		format, isDocument := OfficeDocument(zr)
+		assert.Equal(t, tc.format, format, tc.name)
+		assert.Equal(t, tc.format != "", isDocument, tc.name)
+	}
+}
@@ -0,0 +1,186 @@
+package filetype
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Format of an Office document, which is a zip file
+type OfficeFormat string
+
+const (
+	OfficeFormatOOXML = OfficeFormat("ooxml") // e.g. .docx
+	OfficeFormatODF   = OfficeFormat("odf")   // e.g. .odt
+)
+
+// Bytes of the parts read to tell whether a zip is an Office document
+const maxOfficePartSize = 1024 * 1024
+
+// Content types of the main parts of OOXML documents, templates and their
+// macro-enabled variants
+var ooxmlMainContentTypes = []string{
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.template.main+xml",
+	"application/vnd.ms-word.document.macroEnabled.main+xml",
+	"application/vnd.ms-word.template.macroEnabledTemplate.main+xml",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.template.main+xml",
+	"application/vnd.ms-excel.sheet.macroEnabled.main+xml",
+	"application/vnd.ms-excel.template.macroEnabled.main+xml",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation.main+xml",
+	"application/vnd.openxmlformats-officedocument.presentationml.template.main+xml",
+	"application/vnd.openxmlformats-officedocument.presentationml.slideshow.main+xml",
+	"application/vnd.ms-powerpoint.presentation.macroEnabled.main+xml",
+	"application/vnd.ms-powerpoint.slideshow.macroEnabled.main+xml",
+}
+
+// Relationship types of the main part, in transitional and strict OOXML
+var ooxmlOfficeDocumentRelationships = []string{
+	"http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument",
+	"http://purl.oclc.org/ooxml/officeDocument/relationships/officeDocument",
+}
+
+// Media types of OpenDocument documents and templates
+var odfMediaTypes = []string{
+	"application/vnd.oasis.opendocument.text",
+	"application/vnd.oasis.opendocument.text-template",
+	"application/vnd.oasis.opendocument.text-master",
+	"application/vnd.oasis.opendocument.spreadsheet",
+	"application/vnd.oasis.opendocument.spreadsheet-template",
+	"application/vnd.oasis.opendocument.presentation",
+	"application/vnd.oasis.opendocument.presentation-template",
+	"application/vnd.oasis.opendocument.graphics",
+	"application/vnd.oasis.opendocument.graphics-template",
+	"application/vnd.oasis.opendocument.formula",
+	"application/vnd.oasis.opendocument.chart",
+}
+
+// Format of a zip that is an Office document, if it is one. An OOXML
+// document has a package relationship to a main part of a document content
+// type, and an OpenDocument its media type stored first with a manifest and
+// content, so that a zip is not a document just for having one such member
+func OfficeDocument(zr *zip.Reader) (OfficeFormat, bool) {
+	if isOOXML(zr) {
+		return OfficeFormatOOXML, true
+	}
+	if isODF(zr) {
+		return OfficeFormatODF, true
+	}
+	return "", false
+}
+
+// Members of an Office document that are objects embedded in it e.g. a
+// spreadsheet or executable in a .docx, which are not parts of the document
+// itself. OOXML stores them in embeddings directories, and OpenDocument as
+// top-level "Object N" files; an embedded OpenDocument is instead a directory
+// of parts like those of the document
+func EmbeddedObjects(zr *zip.Reader, format OfficeFormat) []*zip.File {
+	objects := []*zip.File{}
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		switch format {
+		case OfficeFormatOOXML:
+			if slices.Contains(strings.Split(path.Dir(file.Name), "/"), "embeddings") {
+				objects = append(objects, file)
+			}
+		case OfficeFormatODF:
+			if strings.HasPrefix(file.Name, "Object ") && !strings.Contains(file.Name, "/") {
+				objects = append(objects, file)
+			}
+		}
+	}
+	return objects
+}
+
+func isOOXML(zr *zip.Reader) bool {
+	relationships := struct {
+		Relationships []struct {
+			Type   string `xml:"Type,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}{}
+	if !readZipXML(zr, "_rels/.rels", &relationships) {
+		return false
+	}
+	contentTypes := struct {
+		Defaults []struct {
+			Extension   string `xml:"Extension,attr"`
+			ContentType string `xml:"ContentType,attr"`
+		} `xml:"Default"`
+		Overrides []struct {
+			PartName    string `xml:"PartName,attr"`
+			ContentType string `xml:"ContentType,attr"`
+		} `xml:"Override"`
+	}{}
+	if !readZipXML(zr, "[Content_Types].xml", &contentTypes) {
+		return false
+	}
+	for _, relationship := range relationships.Relationships {
+		if !slices.Contains(ooxmlOfficeDocumentRelationships, relationship.Type) {
+			continue
+		}
+		partName := "/" + strings.TrimPrefix(relationship.Target, "/")
+		if zipFile(zr, partName[1:]) == nil {
+			continue
+		}
+		contentType := ""
+		for _, d := range contentTypes.Defaults {
+			if strings.EqualFold("."+d.Extension, path.Ext(partName)) {
+				contentType = d.ContentType
+			}
+		}
+		for _, o := range contentTypes.Overrides {
+			if strings.EqualFold(o.PartName, partName) {
+				contentType = o.ContentType
+			}
+		}
+		if slices.Contains(ooxmlMainContentTypes, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+func isODF(zr *zip.Reader) bool {
+	if len(zr.File) == 0 || zr.File[0].Name != "mimetype" || zr.File[0].Method != zip.Store {
+		return false
+	}
+	if zipFile(zr, "META-INF/manifest.xml") == nil || zipFile(zr, "content.xml") == nil {
+		return false
+	}
+	content, err := zr.File[0].Open()
+	if err != nil {
+		return false
+	}
+	defer content.Close()
+	mediaType, err := io.ReadAll(io.LimitReader(content, 128))
+	return err == nil && slices.Contains(odfMediaTypes, string(mediaType))
+}
+
+// Decode the member with the name, which is read only up to the max size
+func readZipXML(zr *zip.Reader, name string, v any) bool {
+	file := zipFile(zr, name)
+	if file == nil || file.UncompressedSize64 > maxOfficePartSize {
+		return false
+	}
+	content, err := file.Open()
+	if err != nil {
+		return false
+	}
+	defer content.Close()
+	return xml.NewDecoder(io.LimitReader(content, maxOfficePartSize)).Decode(v) == nil
+}
+
+func zipFile(zr *zip.Reader, name string) *zip.File {
+	i := slices.IndexFunc(zr.File, func(file *zip.File) bool { return file.Name == name })
+	if i < 0 {
+		return nil
+	}
+	return zr.File[i]
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Events after which storage actions are taken
+const (
+	actionOnDownload  = "download"
+	actionOnRejection = "rejection"
+)
+
+// Take the storage actions configured for the event and destination in
+// order, recording an event for each. The actions stop at the first
+// failure, so that e.g. a file is not deleted if copying it failed
+func (h *Handler) takeStorageActions(
+	ctx context.Context,
+	on string,
+	projectId types.ProjectId,
+	location types.LocationURI,
+	fileId types.FileId,
+	actor types.Actor,
+	destination types.Destination,
+) error {
+	for _, action := range h.storageActions {
+		if action.On != on || types.Destination(action.Destination) != destination {
+			continue
+		}
+		eventAction, comment, err := h.takeStorageAction(ctx, action, location, fileId)
+		if err != nil {
+			return err
+		}
+		err = h.db.RecordStorageAction(projectId, fileId, actor, destination, eventAction, comment)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Handler) takeStorageAction(
+	ctx context.Context,
+	action config.StorageActionConfig,
+	location types.LocationURI,
+	fileId types.FileId,
+) (types.EventAction, string, error) {
+	switch action.Type {
+	case "tag":
+		pairs := []string{}
+		for _, key := range slices.Sorted(maps.Keys(action.Tags)) {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", key, action.Tags[key]))
+		}
+		comment := fmt.Sprintf("Tagged %s after %s", strings.Join(pairs, ","), action.On)
+		return types.EventActionTag, comment, h.storage.Tag(ctx, location, fileId, action.Tags)
+	case "copy":
+		comment := fmt.Sprintf("Copied to prefix %s after %s", action.Prefix, action.On)
+		return types.EventActionCopy, comment, h.storage.Copy(ctx, location, fileId, action.Prefix)
+	case "delete":
+		comment := fmt.Sprintf("Deleted after %s", action.On)
+		return types.EventActionDelete, comment, h.storage.Delete(ctx, location, fileId)
+	default:
+		return "", "", types.NewErrServerF("unknown storage action type [%s]", action.Type)
+	}
+}
+
+// Take the storage actions after a download. The download has already been
+// served, so failures are logged rather than returned
+func (h *Handler) takeDownloadActions(
+	ctx context.Context,
+	projectId types.ProjectId,
+	location types.LocationURI,
+	fileId types.FileId,
+	actor types.Actor,
+	destination types.Destination,
+) {
+	err := h.takeStorageActions(context.WithoutCancel(ctx), actionOnDownload, projectId, location, fileId, actor, destination)
+	if err != nil {
+		log.Err(err).
+			Any("projectId", projectId).
+			Any("fileId", fileId).
+			Msg("Failed to take storage actions after download")
+	}
+}
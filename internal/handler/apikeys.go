@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/openapi"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+func (h *Handler) GetApiKeys(ctx *gin.Context) {
+	keys, err := h.db.ApiKeys()
+	if err != nil {
+		setError(ctx, "", err, "Failed to get API keys")
+		return
+	}
+	response := openapi.ApiKeyListResponse{}
+	for _, key := range keys {
+		response = append(response, openapi.MakeApiKey(key))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) PostApiKeys(ctx *gin.Context) {
+	data := openapi.CreateApiKeyRequest{}
+	if err := ctx.BindJSON(&data); err != nil {
+		setBadRequest(ctx, "", err, "Failed to parse request body")
+		return
+	}
+	if data.Label == "" || len(data.Projects) == 0 || len(data.Scopes) == 0 {
+		setBadRequest(ctx, "", nil, "An API key must have a label, and at least one project and scope")
+		return
+	}
+	projects := []types.ProjectId{}
+	for _, projectId := range data.Projects {
+		if projectId == "" {
+			setBadRequest(ctx, "", nil, "Projects of an API key must not be empty")
+			return
+		}
+		projects = append(projects, types.ProjectId(projectId))
+	}
+	scopes := []types.ApiKeyScope{}
+	for _, scope := range data.Scopes {
+		if !scope.Valid() {
+			setBadRequest(ctx, "", nil, "Scopes of an API key must be read, review or download")
+			return
+		}
+		scopes = append(scopes, types.ApiKeyScope(scope))
+	}
+	expires := time.Time{}
+	if data.ExpiresAt != nil {
+		if !data.ExpiresAt.After(time.Now()) {
+			setBadRequest(ctx, "", nil, "An API key must expire in the future")
+			return
+		}
+		expires = *data.ExpiresAt
+	}
+
+	key, secret := types.NewApiKey(data.Label, projects, scopes, expires)
+	if err := h.db.CreateApiKey(key); err != nil {
+		setError(ctx, "", err, "Failed to create API key")
+		return
+	}
+	log.Info().
+		Str("apiKeyId", string(key.Id)).
+		Str("label", key.Label).
+		Str("by", ctx.GetString("sub")).
+		Msg("Created API key")
+	ctx.JSON(http.StatusCreated, openapi.CreateApiKeyResponse{
+		ApiKey: openapi.MakeApiKey(key),
+		Key:    secret,
+	})
+}
+
+func (h *Handler) PutApiKeysApiKeyIdRevoke(ctx *gin.Context, apiKeyId openapi.ApiKeyIdParam) {
+	if err := h.db.RevokeApiKey(types.ApiKeyId(apiKeyId)); err != nil {
+		setError(ctx, "", err, "Failed to revoke API key")
+		return
+	}
+	log.Info().
+		Str("apiKeyId", apiKeyId).
+		Str("by", ctx.GetString("sub")).
+		Msg("Revoked API key")
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *Handler) PutApiKeysApiKeyIdExpire(ctx *gin.Context, apiKeyId openapi.ApiKeyIdParam) {
+	data := openapi.ExpireApiKeyRequest{}
+	if err := ctx.BindJSON(&data); err != nil {
+		setBadRequest(ctx, "", err, "Failed to parse request body")
+		return
+	}
+	expires := time.Now()
+	if data.ExpiresAt != nil {
+		expires = *data.ExpiresAt
+	}
+	if err := h.db.ExpireApiKey(types.ApiKeyId(apiKeyId), expires); err != nil {
+		setError(ctx, "", err, "Failed to expire API key")
+		return
+	}
+	log.Info().
+		Str("apiKeyId", apiKeyId).
+		Time("expires", expires).
+		Str("by", ctx.GetString("sub")).
+		Msg("Set API key expiry")
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/archive"
+	"github.com/ucl-arc-tre/egress/internal/openapi"
+	"github.com/ucl-arc-tre/egress/internal/storage"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Should match the maximum number of file_ids in ../../api/api.yaml
+const maxArchiveFiles = 1000
+
+func (h *Handler) GetProjectIdArchive(ctx *gin.Context, projectId openapi.ProjectIdParam) {
+	data := openapi.DownloadArchiveRequest{}
+	if err := ctx.BindJSON(&data); err != nil {
+		setBadRequest(ctx, projectId, err, "Failed to parse request body")
+		return
+	}
+	userId := optional(data.UserId)
+	if err := matchUserIdWithBearerSub(ctx, &userId); err != nil {
+		setError(ctx, projectId, err, "The user_id field does not match token subject")
+		return
+	}
+	actor := actorOf(ctx, userId)
+	fileIds, err := archiveFileIds(data.FileIds)
+	if err != nil {
+		setBadRequest(ctx, projectId, nil, err.Error())
+		return
+	}
+	format := archive.FormatZip
+	if data.Format != nil {
+		if format, err = archive.ParseFormat(string(*data.Format)); err != nil {
+			setError(ctx, projectId, err, "Failed to parse archive format")
+			return
+		}
+	}
+
+	// Every file is checked before any is streamed, so that the archive
+	// is not cut short by a file that cannot be egressed
+	projectApprovals, err := h.db.FileApprovals(types.ProjectId(projectId))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to get approved files")
+		return
+	}
+	destination := types.Destination(data.Destination)
+	for _, fileId := range fileIds {
+		destApprovals := projectApprovals.FileApprovals(fileId).ForDestination(destination)
+		if numApprovals := len(destApprovals); numApprovals < data.RequiredApprovals {
+			setBadRequest(ctx, projectId, nil,
+				fmt.Sprintf("Required %d approvals for destination %s but file %s only had %d",
+					data.RequiredApprovals, data.Destination, fileId, numApprovals))
+			return
+		}
+	}
+
+	location, err := storage.ParseLocation(data.FilesLocation)
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to parse file location")
+		return
+	}
+
+	files := []*types.FileMetadata{}
+	for _, fileId := range fileIds {
+		metadata, err := h.storage.Stat(ctx, *location, fileId)
+		if err != nil {
+			setError(ctx, projectId, err, fmt.Sprintf("Failed to get file %s from storage", fileId))
+			return
+		}
+		if metadata.Size > int64(data.MaxFileSize) {
+			setBadRequest(ctx, projectId, nil,
+				fmt.Sprintf("File %s size %d is greater than max_file_size %d",
+					fileId, metadata.Size, data.MaxFileSize))
+			return
+		}
+		mediaType, allowed, err := h.allowedMediaType(ctx, *location, metadata, destination)
+		if err != nil {
+			setError(ctx, projectId, err, fmt.Sprintf("Failed to detect media type of file %s", fileId))
+			return
+		}
+		if !allowed {
+			setBadRequest(ctx, projectId, nil,
+				fmt.Sprintf("File %s media type %s is not allowed for destination %s",
+					fileId, mediaType, destination))
+			return
+		}
+		transformFunc, err := h.transformFor(ctx, *location, metadata, destination)
+		if err != nil {
+			setError(ctx, projectId, err, fmt.Sprintf("Failed to detect media type of file %s", fileId))
+			return
+		}
+		if transformFunc != nil {
+			setBadRequest(ctx, projectId, nil,
+				fmt.Sprintf("File %s has metadata stripped for destination %s so must be downloaded on its own",
+					fileId, destination))
+			return
+		}
+		files = append(files, metadata)
+	}
+	for _, fileId := range fileIds {
+		err := h.scanFile(ctx, types.ProjectId(projectId), *location, fileId, actor, destination)
+		if err != nil {
+			setError(ctx, projectId, err, fmt.Sprintf("Failed to scan file %s for malware", fileId))
+			return
+		}
+	}
+	for _, metadata := range files {
+		reason, err := h.archiveBlocked(ctx, types.ProjectId(projectId), *location, metadata, actor, destination)
+		if err != nil {
+			setError(ctx, projectId, err, fmt.Sprintf("Failed to inspect archive %s", metadata.Id))
+			return
+		}
+		if reason != "" {
+			setBadRequest(ctx, projectId, nil, fmt.Sprintf("File %s %s", metadata.Id, reason))
+			return
+		}
+	}
+	for _, metadata := range files {
+		blocking, err := h.blockingFindings(ctx, *location, metadata, destination)
+		if err != nil {
+			setError(ctx, projectId, err, fmt.Sprintf("Failed to check file %s", metadata.Id))
+			return
+		}
+		if len(blocking) > 0 {
+			setBadRequest(ctx, projectId, nil,
+				fmt.Sprintf("File %s has %d findings of output checks blocking destination %s",
+					metadata.Id, len(blocking), destination))
+			return
+		}
+	}
+
+	bundleId := types.BundleId(uuid.NewString())
+	err = h.db.DownloadBundle(
+		types.ProjectId(projectId),
+		fileIds,
+		bundleId,
+		actor,
+		destination,
+		optional(data.Comment),
+	)
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to write download file events")
+		return
+	}
+
+	ctx.Header("Content-Type", format.ContentType())
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, bundleId, format))
+	ctx.Header("X-Bundle-Id", string(bundleId))
+	ctx.Status(http.StatusOK)
+
+	writer := archive.NewWriter(format, ctx.Writer)
+	names := archive.NewEntryNames()
+	for _, metadata := range files {
+		if err := h.addToArchive(ctx, writer, names, *location, metadata); err != nil {
+			// Without the trailer the client sees the archive is invalid
+			log.Err(err).
+				Any("projectId", projectId).
+				Any("bundleId", bundleId).
+				Any("fileId", metadata.Id).
+				Msg("Failed to add file to archive")
+			return
+		}
+	}
+	if err := writer.Close(); err != nil {
+		log.Err(err).
+			Any("projectId", projectId).
+			Any("bundleId", bundleId).
+			Msg("Failed to write archive trailer")
+		return
+	}
+
+	for _, fileId := range fileIds {
+		h.takeDownloadActions(ctx, types.ProjectId(projectId), *location, fileId, actor, destination)
+	}
+}
+
+func (h *Handler) addToArchive(
+	ctx *gin.Context,
+	writer archive.Writer,
+	names *archive.EntryNames,
+	location types.LocationURI,
+	metadata *types.FileMetadata,
+) error {
+	file, err := h.storage.Get(ctx, location, metadata.Id, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Content.Close(); err != nil {
+			log.Err(err).Msg("Failed to close stream")
+		}
+	}()
+	name := names.Name(metadata.Name, metadata.Id)
+	return writer.Add(name, file.Size, metadata.LastModifiedAt, file.Content)
+}
+
+// File ids of an archive, which must be unique. Errors are messages for
+// the response
+func archiveFileIds(ids []string) ([]types.FileId, error) {
+	if len(ids) == 0 || len(ids) > maxArchiveFiles {
+		return nil, fmt.Errorf("Number of file_ids must be between 1 and %d", maxArchiveFiles)
+	}
+	fileIds := []types.FileId{}
+	seen := map[types.FileId]bool{}
+	for _, id := range ids {
+		fileId := types.FileId(id)
+		if seen[fileId] {
+			return nil, fmt.Errorf("File %s is in file_ids more than once", fileId)
+		}
+		seen[fileId] = true
+		fileIds = append(fileIds, fileId)
+	}
+	return fileIds, nil
+}
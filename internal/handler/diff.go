@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/diff"
+	"github.com/ucl-arc-tre/egress/internal/filetype"
+	"github.com/ucl-arc-tre/egress/internal/openapi"
+	"github.com/ucl-arc-tre/egress/internal/preview"
+	"github.com/ucl-arc-tre/egress/internal/storage"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+func (h *Handler) GetProjectIdFilesFileIdDiff(ctx *gin.Context, projectId openapi.ProjectIdParam, fileId openapi.FileIdParam) {
+	data := openapi.DiffFileRequest{}
+	if err := ctx.BindJSON(&data); err != nil {
+		setBadRequest(ctx, projectId, err, "Failed to parse request body")
+		return
+	}
+	location, err := storage.ParseLocation(data.FilesLocation)
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to parse file location")
+		return
+	}
+	metadata, err := h.storage.Stat(ctx, *location, types.FileId(fileId))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to get file from storage")
+		return
+	}
+	if message := h.tooLargeToDiff(metadata); message != "" {
+		setBadRequest(ctx, projectId, nil, message)
+		return
+	}
+	baseFileId := types.FileId(optional(data.BaseFileId))
+	if baseFileId == "" {
+		versions, err := h.db.FileVersions(types.ProjectId(projectId), *location)
+		if err != nil {
+			setError(ctx, projectId, err, "Failed to get file versions")
+			return
+		}
+		previous, exists := versions.Previous(metadata.Name, metadata.Id)
+		if !exists {
+			setError(ctx, projectId, types.NewErrNotFoundF("no previous version of %s", metadata.Name),
+				"File has no previous version")
+			return
+		}
+		baseFileId = previous
+	}
+	// The base version is no longer in storage once it is overwritten by a
+	// resubmission, so is then read from the snapshot taken on rejection,
+	// if it is still kept
+	var baseContent []byte
+	baseMetadata, err := h.storage.Stat(ctx, *location, baseFileId)
+	if errors.Is(err, types.ErrNotFound) {
+		baseContent, err = h.fileSnapshot(types.ProjectId(projectId), *location, baseFileId)
+		if errors.Is(err, types.ErrNotFound) {
+			setError(ctx, projectId, err, "Base version of file is not in storage and has no snapshot")
+			return
+		}
+	} else if err == nil {
+		if message := h.tooLargeToDiff(baseMetadata); message != "" {
+			setBadRequest(ctx, projectId, nil, message)
+			return
+		}
+		baseContent, err = h.readFile(ctx, *location, baseMetadata)
+	}
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to get base version of file")
+		return
+	}
+
+	fileDiff, err := h.diffFiles(ctx, *location, baseFileId, baseContent, metadata)
+	if errors.Is(err, errNotDiffable) {
+		setBadRequest(ctx, projectId, err, "File is not text, CSV or TSV so cannot be diffed")
+		return
+	} else if errors.Is(err, diff.ErrTooManyChanges) {
+		setBadRequest(ctx, projectId, err,
+			fmt.Sprintf("Versions differ by more than %d lines or rows", h.diff.MaxChanges))
+		return
+	} else if err != nil {
+		setError(ctx, projectId, err, "Failed to diff file")
+		return
+	}
+	ctx.JSON(http.StatusOK, openapi.MakeFileDiff(fileDiff))
+}
+
+var errNotDiffable = errors.New("file is not text")
+
+// Message for a file that is too large to diff, or "" if it is not
+func (h *Handler) tooLargeToDiff(metadata *types.FileMetadata) string {
+	if metadata.Size <= h.diff.MaxSize {
+		return ""
+	}
+	return fmt.Sprintf("File %s size %d is greater than the maximum size %d of files that are diffed",
+		metadata.Id, metadata.Size, h.diff.MaxSize)
+}
+
+// Changes from the base version to the file, which is read in full. The kind of diff follows from the media type of the file
+func (h *Handler) diffFiles(
+	ctx context.Context,
+	location types.LocationURI,
+	baseFileId types.FileId,
+	baseContent []byte,
+	metadata *types.FileMetadata,
+) (types.FileDiff, error) {
+	result := types.FileDiff{BaseFileId: baseFileId, FileId: metadata.Id}
+	content, err := h.readFile(ctx, location, metadata)
+	if err != nil {
+		return types.FileDiff{}, err
+	}
+	result.Kind, err = diffKindOf(metadata, content)
+	if err != nil {
+		return types.FileDiff{}, err
+	}
+	if result.Kind == types.DiffKindTable {
+		result.Rows, err = diff.Table(baseContent, content, metadata.Name, h.diff.MaxChanges)
+	} else {
+		result.Lines, err = diff.Text(baseContent, content, h.diff.MaxChanges)
+	}
+	return result, err
+}
+
+func diffKindOf(metadata *types.FileMetadata, content []byte) (types.DiffKind, error) {
+	mediaType := filetype.Detect(metadata.Name, content[:min(len(content), filetype.SniffLength)])
+	switch preview.KindOf(mediaType, metadata.Size, -1) {
+	case types.PreviewKindTable:
+		return types.DiffKindTable, nil
+	case types.PreviewKindText:
+		return types.DiffKindText, nil
+	default:
+		return "", errNotDiffable
+	}
+}
+
+// Keep the content of a file that is rejected, so that it can be diffed
+// against a resubmission that overwrites it, if snapshots are enabled.
+// Files that could not be diffed are skipped, and expired snapshots deleted
+func (h *Handler) snapshotFile(
+	ctx context.Context,
+	projectId types.ProjectId,
+	location types.LocationURI,
+	fileId types.FileId,
+) error {
+	if !h.diff.Snapshots.Enabled {
+		return nil
+	}
+	if err := h.db.DeleteFileSnapshots(time.Now().Add(-h.diff.Snapshots.Retention)); err != nil {
+		return err
+	}
+	metadata, err := h.storage.Stat(ctx, location, fileId)
+	if err != nil {
+		return err
+	}
+	if h.tooLargeToDiff(metadata) != "" {
+		return nil
+	}
+	content, err := h.readFile(ctx, location, metadata)
+	if err != nil {
+		return err
+	}
+	if _, err := diffKindOf(metadata, content); err != nil {
+		return nil
+	}
+	return h.db.RecordFileSnapshot(projectId, location, fileId, content)
+}
+
+// Snapshot of the file, or an ErrNotFound if snapshots are not enabled or
+// it has expired
+func (h *Handler) fileSnapshot(projectId types.ProjectId, location types.LocationURI, fileId types.FileId) ([]byte, error) {
+	if !h.diff.Snapshots.Enabled {
+		return nil, types.NewErrNotFoundF("snapshots of files are not enabled")
+	}
+	if err := h.db.DeleteFileSnapshots(time.Now().Add(-h.diff.Snapshots.Retention)); err != nil {
+		return nil, err
+	}
+	return h.db.FileSnapshot(projectId, location, fileId)
+}
+
+func (h *Handler) readFile(ctx context.Context, location types.LocationURI, metadata *types.FileMetadata) ([]byte, error) {
+	if metadata.Size == 0 {
+		return []byte{}, nil
+	}
+	file, err := h.storage.Get(ctx, location, metadata.Id, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := file.Content.Close(); err != nil {
+			log.Err(err).Msg("Failed to close stream")
+		}
+	}()
+	content, err := io.ReadAll(io.LimitReader(file.Content, h.diff.MaxSize))
+	if err != nil {
+		return nil, types.NewErrServerF("failed to read file: %v", err)
+	}
+	return content, nil
+}
+
+// Record the listed files as versions of their names, returning the
+// versions of all names. A failure is logged, so that the files are still
+// listed, though without their previous versions
+func (h *Handler) recordVersions(
+	projectId types.ProjectId,
+	location types.LocationURI,
+	files []types.FileMetadata,
+) types.ProjectVersions {
+	versions, err := h.db.FileVersions(projectId, location)
+	if err != nil {
+		log.Err(err).Any("projectId", projectId).Msg("Failed to get file versions")
+		return types.ProjectVersions{}
+	}
+	unrecorded := versions.Unrecorded(files)
+	if len(unrecorded) == 0 {
+		return versions
+	}
+	if err := h.db.RecordFileVersions(projectId, location, unrecorded); err != nil {
+		log.Err(err).Any("projectId", projectId).Msg("Failed to record file versions")
+		return versions
+	}
+	versions, err = h.db.FileVersions(projectId, location)
+	if err != nil {
+		log.Err(err).Any("projectId", projectId).Msg("Failed to get file versions")
+		return types.ProjectVersions{}
+	}
+	return versions
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Maximum number of listed files whose media types are detected at once
+const detectConcurrency = 8
+
+// Detect the media types of the listed files, if any destination has an
+// allowlist of them. Otherwise listing would read the head of every file,
+// and types are only detected as files are downloaded, previewed or checked.
+// A type that fails to be detected is left unknown, so that the files are
+// still listed
+func (h *Handler) detectMediaTypes(ctx context.Context, location types.LocationURI, files []types.FileMetadata) {
+	if h.fileTypes == nil || len(h.fileTypeAllowlists) == 0 {
+		return
+	}
+	wg := sync.WaitGroup{}
+	limit := make(chan struct{}, detectConcurrency)
+	for i := range files {
+		limit <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-limit }()
+			mediaType, err := h.mediaType(ctx, location, &files[i])
+			if err != nil {
+				log.Err(err).Any("fileId", files[i].Id).Msg("Failed to detect media type")
+				return
+			}
+			files[i].MediaType = mediaType
+		})
+	}
+	wg.Wait()
+}
+
+func (h *Handler) mediaType(ctx context.Context, location types.LocationURI, metadata *types.FileMetadata) (string, error) {
+	return h.fileTypes.Detect(ctx, location, metadata, func(ctx context.Context, byteRange *types.ByteRange) (io.ReadCloser, error) {
+		file, err := h.storage.Get(ctx, location, metadata.Id, byteRange)
+		if err != nil {
+			return nil, err
+		}
+		return file.Content, nil
+	})
+}
+
+// Media type of the file, and whether it is allowed to be downloaded to
+// the destination. Any type is allowed for a destination without an allowlist
+func (h *Handler) allowedMediaType(
+	ctx context.Context,
+	location types.LocationURI,
+	metadata *types.FileMetadata,
+	destination types.Destination,
+) (string, bool, error) {
+	allowlist, exists := h.fileTypeAllowlists[destination]
+	if !exists || h.fileTypes == nil {
+		return "", true, nil
+	}
+	mediaType, err := h.mediaType(ctx, location, metadata)
+	if err != nil {
+		return "", false, err
+	}
+	return mediaType, slices.Contains(allowlist, mediaType), nil
+}
+
+func fileTypeAllowlists(cfgs []config.FileTypeAllowlistConfig) map[types.Destination][]string {
+	allowlists := map[types.Destination][]string{}
+	for _, cfg := range cfgs {
+		allowlists[types.Destination(cfg.Destination)] = cfg.MediaTypes
+	}
+	return allowlists
+}
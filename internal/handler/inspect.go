@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ucl-arc-tre/egress/internal/archive"
+	"github.com/ucl-arc-tre/egress/internal/openapi"
+	"github.com/ucl-arc-tre/egress/internal/storage"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+func (h *Handler) GetProjectIdFilesFileIdMembers(ctx *gin.Context, projectId openapi.ProjectIdParam, fileId openapi.FileIdParam) {
+	if h.archives == nil {
+		setBadRequest(ctx, projectId, nil, "Archive inspection is not enabled")
+		return
+	}
+	data := openapi.ArchiveMembersRequest{}
+	if err := ctx.BindJSON(&data); err != nil {
+		setBadRequest(ctx, projectId, err, "Failed to parse request body")
+		return
+	}
+	location, err := storage.ParseLocation(data.FilesLocation)
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to parse file location")
+		return
+	}
+	metadata, err := h.storage.Stat(ctx, *location, types.FileId(fileId))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to get file from storage")
+		return
+	}
+	inspection, err := h.inspectArchive(ctx, types.ProjectId(projectId), *location, metadata, actorOf(ctx, ""), "")
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to inspect archive")
+		return
+	}
+	if inspection == nil {
+		setBadRequest(ctx, projectId, nil, "File is not a zip, tar or gzip archive")
+		return
+	}
+	ctx.JSON(http.StatusOK, openapi.MakeArchiveInspection(*inspection))
+}
+
+// Inspection of the file, or nil if it is not an archive or archives are
+// not inspected. Members are scanned if a scanner is configured, and a Scan
+// event is recorded for each infected member found by a new inspection
+func (h *Handler) inspectArchive(
+	ctx context.Context,
+	projectId types.ProjectId,
+	location types.LocationURI,
+	metadata *types.FileMetadata,
+	actor types.Actor,
+	destination types.Destination,
+) (*types.ArchiveInspection, error) {
+	if h.archives == nil {
+		return nil, nil
+	}
+	var visit archive.VisitFunc
+	if h.scanner != nil {
+		visit = func(ctx context.Context, member *types.ArchiveMember, content io.Reader) error {
+			result, err := h.scanner.ScanContent(ctx, content)
+			if err != nil {
+				return err
+			}
+			member.Scan = &result
+			return nil
+		}
+	}
+	inspection, cached, err := h.archives.Inspect(ctx, location, metadata, func(ctx context.Context) (io.ReadCloser, error) {
+		file, err := h.storage.Get(ctx, location, metadata.Id, nil)
+		if err != nil {
+			return nil, err
+		}
+		return file.Content, nil
+	}, visit)
+	if err != nil || inspection == nil || cached {
+		return inspection, err
+	}
+	for _, member := range inspection.Members {
+		if member.Scan == nil || member.Scan.Verdict != types.ScanVerdictInfected {
+			continue
+		}
+		err := h.db.RecordScan(projectId, metadata.Id, actor, destination,
+			fmt.Sprintf("%s: %s", member.Path, member.Scan))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inspection, nil
+}
+
+// Why the file, if it is an archive, cannot be downloaded to the
+// destination e.g. "exceeds the ratio limit of archives", or "" if it can
+func (h *Handler) archiveBlocked(
+	ctx context.Context,
+	projectId types.ProjectId,
+	location types.LocationURI,
+	metadata *types.FileMetadata,
+	actor types.Actor,
+	destination types.Destination,
+) (string, error) {
+	inspection, err := h.inspectArchive(ctx, projectId, location, metadata, actor, destination)
+	if err != nil || inspection == nil {
+		return "", err
+	}
+	if inspection.Exceeded != "" {
+		return fmt.Sprintf("exceeds the %s limit of archives", inspection.Exceeded), nil
+	}
+	allowlist, hasAllowlist := h.fileTypeAllowlists[destination]
+	for _, member := range inspection.Members {
+		if member.Scan != nil && member.Scan.Verdict == types.ScanVerdictInfected {
+			return fmt.Sprintf("has member %s infected with %s", member.Path, member.Scan.Signature), nil
+		}
+		if hasAllowlist && !slices.Contains(allowlist, member.MediaType) {
+			return fmt.Sprintf("has member %s of media type %s not allowed for destination %s",
+				member.Path, member.MediaType, destination), nil
+		}
+	}
+	return "", nil
+}
@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
@@ -8,10 +12,20 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/ucl-arc-tre/egress/internal/archive"
+	"github.com/ucl-arc-tre/egress/internal/checks"
+	"github.com/ucl-arc-tre/egress/internal/checks/pii"
+	"github.com/ucl-arc-tre/egress/internal/checks/sdc"
+	"github.com/ucl-arc-tre/egress/internal/config"
 	"github.com/ucl-arc-tre/egress/internal/db/inmemory"
+	"github.com/ucl-arc-tre/egress/internal/filetype"
+	"github.com/ucl-arc-tre/egress/internal/openapi"
+	"github.com/ucl-arc-tre/egress/internal/scan"
+	"github.com/ucl-arc-tre/egress/internal/storage"
 	"github.com/ucl-arc-tre/egress/internal/storage/generic"
 	"github.com/ucl-arc-tre/egress/internal/types"
 )
@@ -85,7 +99,7 @@ func TestGetFilesGeneric(t *testing.T) {
 				err := handler.db.ApproveFile(
 					types.ProjectId(projectId),
 					fileId,
-					approval.UserId,
+					types.Actor{UserId: approval.UserId},
 					approval.Destination,
 					approval.Comment,
 				)
@@ -174,6 +188,17 @@ func TestGetFileIdGeneric(t *testing.T) {
 			expectedStatusCode: http.StatusBadRequest,
 			expectedBody:       `{"message":"File size 11 is greater than max_file_size 1"}`,
 		},
+		{
+			name:   "above max file size without getting content",
+			body:   `{"files_location":"http://storage.local","max_file_size":1,"destination":"trusted","required_approvals":0}`,
+			fileId: fileId1,
+			genericClient: generic.MockClient{
+				Files:       []generic.MockFile{genericFile1},
+				ForceGetErr: errors.New("server error"),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"message":"File size 11 is greater than max_file_size 1"}`,
+		},
 		{
 			name:   "ok",
 			body:   `{"files_location":"http://storage.local","max_file_size":100,"destination":"trusted","required_approvals":1}`,
@@ -199,13 +224,13 @@ func TestGetFileIdGeneric(t *testing.T) {
 				db:      inmemory.New(),
 			}
 			for fileId, approval := range tc.approvals {
-				err := handler.db.ApproveFile(types.ProjectId(projectId), fileId, approval.UserId, approval.Destination, "")
+				err := handler.db.ApproveFile(types.ProjectId(projectId), fileId, types.Actor{UserId: approval.UserId}, approval.Destination, "")
 				assert.NoError(t, err)
 			}
 			writer := httptest.NewRecorder()
 			ctx, router := gin.CreateTestContext(writer)
 			router.GET("/", func(ctx *gin.Context) {
-				handler.GetProjectIdFilesFileId(ctx, projectId, tc.fileId)
+				handler.GetProjectIdFilesFileId(ctx, projectId, tc.fileId, openapi.GetProjectIdFilesFileIdParams{})
 			})
 			ctx.Request, _ = http.NewRequest(http.MethodGet, "/", strings.NewReader(tc.body))
 			router.ServeHTTP(writer, ctx.Request)
@@ -217,3 +242,677 @@ func TestGetFileIdGeneric(t *testing.T) {
 		})
 	}
 }
+
+func TestReadyReportsStorageBreakers(t *testing.T) {
+	genericStorage := generic.NewWithMock(&generic.MockClient{ForceListErr: errors.New("connection reset")})
+	handler := &Handler{
+		storage: storage.NewResilient(genericStorage, config.StorageRetryConfigBundle{
+			MaxAttempts:      1,
+			FailureThreshold: 1,
+			BreakerCooldown:  time.Minute,
+		}),
+		db: inmemory.New(),
+	}
+	location, err := storage.ParseLocation("https://storage.local")
+	assert.NoError(t, err)
+	_, err = handler.storage.List(context.Background(), *location)
+	assert.ErrorIs(t, err, types.ErrServer)
+
+	writer := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(writer)
+	router.GET("/ready", handler.Ready)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/ready", nil)
+	router.ServeHTTP(writer, ctx.Request)
+
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{"storage_breakers":{"closed":0,"open":1,"half-open":0}}`, writer.Body.String())
+}
+
+// Router of a handler test, to which requests with a body are served
+type testRouter struct {
+	*gin.Engine
+}
+
+func newTestRouter() testRouter {
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	return testRouter{router}
+}
+
+// Serve a request with a Range header, if one is given
+func (r testRouter) serve(method string, url string, body string, rangeHeader string) *httptest.ResponseRecorder {
+	writer := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+	r.ServeHTTP(writer, req)
+	return writer
+}
+
+func (r testRouter) get(url string, body string) *httptest.ResponseRecorder {
+	return r.serve(http.MethodGet, url, body, "")
+}
+
+// Handler of downloads of a file, with the range given by the Range and
+// If-Range headers
+func downloadRoute(handler *Handler) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		handler.GetProjectIdFilesFileId(ctx, projectId, ctx.Param("fileId"), openapi.GetProjectIdFilesFileIdParams{
+			Range:   optionalPtr(ctx.GetHeader("Range")),
+			IfRange: optionalPtr(ctx.GetHeader("If-Range")),
+		})
+	}
+}
+
+// Body of a download by user1 of a file of the mock storage location
+func downloadBody(destination string) string {
+	return fmt.Sprintf(`{"files_location":"http://storage.local","max_file_size":1000,"destination":%q,"required_approvals":0,"user_id":"user1"}`, destination)
+}
+
+func TestStorageActionsGeneric(t *testing.T) {
+	client := &generic.MockClient{
+		Files: []generic.MockFile{
+			{Key: "file1", ETag: `"abc100"`, Content: "hello world"},
+			{Key: "file2", ETag: `"abc200"`, Content: "rejected"},
+		},
+	}
+	handler := &Handler{
+		storage: generic.NewWithMock(client),
+		db:      inmemory.New(),
+		storageActions: []config.StorageActionConfig{
+			{Destination: "trusted", On: "download", Type: "tag", Tags: map[string]string{"egress-status": "downloaded"}},
+			{Destination: "trusted", On: "download", Type: "copy", Prefix: "archive/"},
+			{Destination: "world", On: "download", Type: "delete"},
+			{Destination: "trusted", On: "rejection", Type: "delete"},
+		},
+	}
+	router := newTestRouter()
+	router.GET("/:fileId", downloadRoute(handler))
+	router.PUT("/:fileId/reject", func(ctx *gin.Context) {
+		handler.PutProjectIdFilesFileIdReject(ctx, projectId, ctx.Param("fileId"))
+	})
+	actions := func(fileId types.FileId) []types.EventAction {
+		events, err := handler.db.FileEvents(types.ProjectId(projectId))
+		assert.NoError(t, err)
+		result := []types.EventAction{}
+		for _, e := range events[fileId] {
+			result = append(result, e.Action)
+		}
+		return result
+	}
+
+	// The first part of a download does not take actions, nor does the tail
+	// of a file that has not been downloaded
+	assert.Equal(t, http.StatusPartialContent, router.serve(http.MethodGet, "/abc200", downloadBody("world"), "bytes=-1").Code)
+	assert.Len(t, client.Files, 2)
+	assert.Equal(t, http.StatusPartialContent, router.serve(http.MethodGet, "/abc100", downloadBody("trusted"), "bytes=0-4").Code)
+	assert.Equal(t, []types.EventAction{types.EventActionDownload}, actions("abc100"))
+	assert.Nil(t, client.Files[0].Tags)
+
+	// Resuming to the end of the file does
+	assert.Equal(t, http.StatusPartialContent, router.serve(http.MethodGet, "/abc100", downloadBody("trusted"), "bytes=5-").Code)
+	assert.Equal(t, []types.EventAction{
+		types.EventActionDownload, types.EventActionTag, types.EventActionCopy,
+	}, actions("abc100"))
+	assert.Equal(t, map[string]string{"egress-status": "downloaded"}, client.Files[0].Tags)
+	assert.Equal(t, "archive/file1", client.Files[2].Key)
+
+	// Rejection actions are taken only if the location is given
+	rejectBody := `{"user_id":"user1","destination":"trusted"}`
+	assert.Equal(t, http.StatusNoContent, router.serve(http.MethodPut, "/abc200/reject", rejectBody, "").Code)
+	assert.Len(t, client.Files, 3)
+	rejectBody = `{"user_id":"user1","destination":"trusted","files_location":"http://storage.local"}`
+	assert.Equal(t, http.StatusNoContent, router.serve(http.MethodPut, "/abc200/reject", rejectBody, "").Code)
+	assert.Len(t, client.Files, 2)
+
+	// They are taken before the rejection is recorded, so it is not recorded
+	// if they fail
+	assert.Equal(t, http.StatusNotFound, router.serve(http.MethodPut, "/abc200/reject", rejectBody, "").Code)
+	assert.Equal(t, []types.EventAction{
+		types.EventActionDownload, types.EventActionRejection, types.EventActionDelete, types.EventActionRejection,
+	}, actions("abc200"))
+}
+
+func TestGetArchiveGeneric(t *testing.T) {
+	files := []generic.MockFile{
+		{Key: "results/file1.csv", ETag: `"abc100"`, Content: "id,value\n1,2\n"},
+		{Key: "results/file2.txt", ETag: `"abc200"`, Content: "hello world"},
+	}
+	approvals := map[types.FileId]types.Destination{"abc100": "trusted", "abc200": "trusted"}
+	testCases := []struct {
+		name      string
+		body      string
+		approvals map[types.FileId]types.Destination
+
+		expectedStatusCode int
+		expectedBody       string
+		expectedFiles      map[string]string
+	}{
+		{
+			name:               "invalid body",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"message":"Failed to parse request body"}`,
+		},
+		{
+			name:               "duplicate file ids",
+			body:               `{"file_ids":["abc100","abc100"],"files_location":"http://storage.local","max_file_size":100,"destination":"trusted","required_approvals":1}`,
+			approvals:          approvals,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"message":"File abc100 is in file_ids more than once"}`,
+		},
+		{
+			name:               "one file not approved",
+			body:               `{"file_ids":["abc100","abc200"],"files_location":"http://storage.local","max_file_size":100,"destination":"trusted","required_approvals":1}`,
+			approvals:          map[types.FileId]types.Destination{"abc100": "trusted", "abc200": "world"},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"message":"Required 1 approvals for destination trusted but file abc200 only had 0"}`,
+		},
+		{
+			name:               "one file above max file size",
+			body:               `{"file_ids":["abc100","abc200"],"files_location":"http://storage.local","max_file_size":11,"destination":"trusted","required_approvals":1}`,
+			approvals:          approvals,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"message":"File abc100 size 13 is greater than max_file_size 11"}`,
+		},
+		{
+			name:               "one file not found",
+			body:               `{"file_ids":["abc100","abc300"],"files_location":"http://storage.local","max_file_size":100,"destination":"trusted","required_approvals":0}`,
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `{"message":"Failed to get file abc300 from storage"}`,
+		},
+		{
+			name:               "ok",
+			body:               `{"file_ids":["abc100","abc200"],"files_location":"http://storage.local","max_file_size":100,"destination":"trusted","required_approvals":1}`,
+			approvals:          approvals,
+			expectedStatusCode: http.StatusOK,
+			expectedFiles: map[string]string{
+				"results/file1.csv": "id,value\n1,2\n",
+				"results/file2.txt": "hello world",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := &Handler{
+				storage: generic.NewWithMock(&generic.MockClient{Files: files}),
+				db:      inmemory.New(),
+			}
+			for fileId, destination := range tc.approvals {
+				assert.NoError(t, handler.db.ApproveFile(types.ProjectId(projectId), fileId, types.Actor{UserId: "user1"}, destination, ""))
+			}
+			writer := httptest.NewRecorder()
+			ctx, router := gin.CreateTestContext(writer)
+			router.GET("/", func(ctx *gin.Context) {
+				handler.GetProjectIdArchive(ctx, projectId)
+			})
+			ctx.Request, _ = http.NewRequest(http.MethodGet, "/", strings.NewReader(tc.body))
+			router.ServeHTTP(writer, ctx.Request)
+
+			assert.Equal(t, tc.expectedStatusCode, writer.Code)
+			if tc.expectedFiles == nil {
+				assert.Equal(t, tc.expectedBody, writer.Body.String())
+				return
+			}
+			assert.Equal(t, "application/zip", writer.Header().Get("Content-Type"))
+			bundleId := writer.Header().Get("X-Bundle-Id")
+			assert.NotEmpty(t, bundleId)
+			assert.Equal(t, fmt.Sprintf(`attachment; filename="%s.zip"`, bundleId), writer.Header().Get("Content-Disposition"))
+
+			body := writer.Body.Bytes()
+			zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+			assert.NoError(t, err)
+			archived := map[string]string{}
+			for _, f := range zr.File {
+				r, err := f.Open()
+				assert.NoError(t, err)
+				content, err := io.ReadAll(r)
+				assert.NoError(t, err)
+				archived[f.Name] = string(content)
+			}
+			assert.Equal(t, tc.expectedFiles, archived)
+
+			events, err := handler.db.FileEvents(types.ProjectId(projectId))
+			assert.NoError(t, err)
+			for _, fileId := range []types.FileId{"abc100", "abc200"} {
+				download := events[fileId][len(events[fileId])-1]
+				assert.Equal(t, types.EventActionDownload, download.Action)
+				assert.Equal(t, types.BundleId(bundleId), download.BundleId)
+			}
+		})
+	}
+}
+
+type mockScanner struct {
+	calls int
+}
+
+func (s *mockScanner) Name() string {
+	return "mock"
+}
+
+func (s *mockScanner) Scan(ctx context.Context, content io.Reader) (types.ScanResult, error) {
+	s.calls++
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return types.ScanResult{}, err
+	}
+	if strings.Contains(string(data), "EICAR") {
+		return types.ScanResult{Verdict: types.ScanVerdictInfected, Scanner: s.Name(), Signature: "Eicar-Test-Signature"}, nil
+	}
+	return types.ScanResult{Verdict: types.ScanVerdictClean, Scanner: s.Name()}, nil
+}
+
+func TestScanGeneric(t *testing.T) {
+	scanner := &mockScanner{}
+	handler := &Handler{
+		storage: generic.NewWithMock(&generic.MockClient{
+			Files: []generic.MockFile{
+				{Key: "file1", ETag: `"abc100"`, Content: "hello world"},
+				{Key: "file2", ETag: `"abc200"`, Content: "X5O!P%@AP EICAR"},
+				{Key: "file3", ETag: `"abc300"`, Content: "listed"},
+			},
+		}),
+		db:        inmemory.New(),
+		scanner:   scan.NewPipeline(10, scanner),
+		listScans: make(chan struct{}, 1),
+	}
+	router := newTestRouter()
+	router.GET("/", func(ctx *gin.Context) {
+		ctx.Set("sub", "user2")
+		handler.GetProjectIdFiles(ctx, projectId)
+	})
+	router.GET("/:fileId", downloadRoute(handler))
+	download := func(fileId string) *httptest.ResponseRecorder {
+		return router.get("/"+fileId, downloadBody("trusted"))
+	}
+	events := func(fileId types.FileId) types.FileEvents {
+		events, err := handler.db.FileEvents(types.ProjectId(projectId))
+		assert.NoError(t, err)
+		return events[fileId]
+	}
+
+	// A clean file is served, with its result cached but recorded for each
+	// download
+	for range 2 {
+		writer := download("abc100")
+		assert.Equal(t, http.StatusOK, writer.Code)
+		assert.Equal(t, "hello world", writer.Body.String())
+	}
+	assert.Equal(t, 1, scanner.calls)
+	fileEvents := events("abc100")
+	assert.Len(t, fileEvents, 4)
+	assert.Equal(t, types.EventActionScan, fileEvents[0].Action)
+	assert.Equal(t, "clean (mock)", fileEvents[0].Comment)
+	assert.Equal(t, types.UserId("user1"), fileEvents[0].UserId)
+	assert.Equal(t, types.EventActionScan, fileEvents[2].Action)
+	assert.Equal(t, "clean (mock), cached", fileEvents[2].Comment)
+
+	// An infected file is not
+	writer := download("abc200")
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, `{"message":"Failed to scan file for malware"}`, writer.Body.String())
+	fileEvents = events("abc200")
+	assert.Len(t, fileEvents, 1)
+	assert.Equal(t, "infected: Eicar-Test-Signature (mock)", fileEvents[0].Comment)
+
+	// Listed files not yet scanned are scanned in the background, as of the
+	// user listing them
+	assert.Equal(t, http.StatusOK, router.get("/", `{"files_location":"http://storage.local"}`).Code)
+	assert.Eventually(t, func() bool { return len(handler.listScans) == 0 }, time.Second, time.Millisecond)
+	fileEvents = events("abc300")
+	assert.Len(t, fileEvents, 1)
+	assert.Equal(t, "clean (mock)", fileEvents[0].Comment)
+	assert.Equal(t, types.UserId("user2"), fileEvents[0].UserId)
+}
+
+func TestFileReportGeneric(t *testing.T) {
+	handler := &Handler{
+		storage: generic.NewWithMock(&generic.MockClient{
+			Files: []generic.MockFile{
+				{Key: "results.csv", ETag: `"abc100"`, Content: "group,count\na,20\nb,4\n"},
+				{Key: "notes.txt", ETag: `"abc200"`, Content: "Contact jane@example.com"},
+				{Key: "figure.txt", ETag: `"abc300"`, Content: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"},
+				{Key: "large.csv", ETag: `"abc500"`, Content: "group,count\n" + strings.Repeat("a,20\n", 20)},
+			},
+		}),
+		db: inmemory.New(),
+		checks: checks.NewPipeline(10, 100,
+			checks.Check{Checker: sdc.New(10, 0, nil), BlockDestinations: []types.Destination{"public"}},
+			checks.Check{Checker: pii.New(nil)},
+		),
+		fileTypes: filetype.NewDetector(10),
+	}
+	router := newTestRouter()
+	router.GET("/:fileId", downloadRoute(handler))
+	router.GET("/:fileId/report", func(ctx *gin.Context) {
+		handler.GetProjectIdFilesFileIdReport(ctx, projectId, ctx.Param("fileId"))
+	})
+
+	writer := router.get("/abc100/report", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{
+		"file_id": "abc100",
+		"checks": ["sdc", "pii"],
+		"findings": [{"check":"sdc","type":"small_count","line":3,"column":2,"count":1,"message":"Counts in column 2 \"count\" are below 10"}]
+	}`, writer.Body.String())
+
+	writer = router.get("/abc200/report", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{
+		"file_id": "abc200",
+		"checks": ["pii"],
+		"findings": [{"check":"pii","type":"email","line":1,"count":1,"message":"Email addresses found"}]
+	}`, writer.Body.String())
+
+	// Checks apply by the detected media type, not the extension
+	writer = router.get("/abc300/report", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{"file_id":"abc300","checks":[],"findings":[]}`, writer.Body.String())
+
+	writer = router.get("/abc400/report", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+
+	// Files larger than the max size are not read
+	writer = router.get("/abc500/report", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, `{"message":"Failed to check file"}`, writer.Body.String())
+
+	// Findings block only the configured destinations
+	writer = router.get("/abc100", downloadBody("public"))
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, `{"message":"File has 1 findings of output checks blocking destination public"}`, writer.Body.String())
+	writer = router.get("/abc100", downloadBody("trusted"))
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestFileTypesGeneric(t *testing.T) {
+	handler := &Handler{
+		storage: generic.NewWithMock(&generic.MockClient{
+			Files: []generic.MockFile{
+				{Key: "results.csv", ETag: `"abc100"`, Content: "a,b\n1,2\n"},
+				{Key: "figure.csv", ETag: `"abc200"`, Content: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"},
+			},
+		}),
+		db:                 inmemory.New(),
+		fileTypes:          filetype.NewDetector(10),
+		fileTypeAllowlists: map[types.Destination][]string{"public": {"text/csv"}},
+	}
+	router := newTestRouter()
+	router.GET("/", func(ctx *gin.Context) {
+		handler.GetProjectIdFiles(ctx, projectId)
+	})
+	router.GET("/:fileId", downloadRoute(handler))
+
+	writer := router.get("/", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `[
+		{"file_name":"results.csv","id":"abc100","size":8,"media_type":"text/csv","approvals":[]},
+		{"file_name":"figure.csv","id":"abc200","size":16,"media_type":"image/png","approvals":[]}
+	]`, writer.Body.String())
+
+	// The type is detected from the content, whatever the extension
+	writer = router.get("/abc200", downloadBody("public"))
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, `{"message":"File media type image/png is not allowed for destination public"}`, writer.Body.String())
+	assert.Equal(t, http.StatusOK, router.get("/abc100", downloadBody("public")).Code)
+	assert.Equal(t, http.StatusOK, router.get("/abc200", downloadBody("trusted")).Code)
+
+	// Without allowlists listed files are not read to detect their types
+	handler.fileTypeAllowlists = nil
+	writer = router.get("/", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `[
+		{"file_name":"results.csv","id":"abc100","size":8,"approvals":[]},
+		{"file_name":"figure.csv","id":"abc200","size":16,"approvals":[]}
+	]`, writer.Body.String())
+}
+
+func TestTransformGeneric(t *testing.T) {
+	pdf := "%PDF-1.7\n1 0 obj\n<< /Author (Jane Doe) >>\nendobj\ntrailer\n<< /Info 1 0 R >>\n%%EOF\n"
+	handler := &Handler{
+		storage: generic.NewWithMock(&generic.MockClient{
+			Files: []generic.MockFile{
+				{Key: "report.pdf", ETag: `"abc100"`, Content: pdf},
+				{Key: "results.csv", ETag: `"abc200"`, Content: "a,b\n1,2\n"},
+			},
+		}),
+		db:                        inmemory.New(),
+		fileTypes:                 filetype.NewDetector(10),
+		stripMetadataDestinations: []types.Destination{"public"},
+	}
+	router := newTestRouter()
+	router.GET("/:fileId", downloadRoute(handler))
+	router.GET("/archive", func(ctx *gin.Context) {
+		handler.GetProjectIdArchive(ctx, projectId)
+	})
+
+	// Ranges of a transformed file cannot be requested, but an If-Range
+	// cannot match it so the whole file is transformed
+	writer := router.serve(http.MethodGet, "/abc100", downloadBody("public"), "bytes=0-9")
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, writer.Code)
+	events, err := handler.db.FileEvents(projectId)
+	assert.NoError(t, err)
+	assert.Empty(t, events["abc100"])
+
+	writer = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/abc100", strings.NewReader(downloadBody("public")))
+	req.Header.Set("Range", "bytes=0-9")
+	req.Header.Set("If-Range", `"abc100"`)
+	router.ServeHTTP(writer, req)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, strings.Replace(pdf, "Jane Doe", "        ", 1), writer.Body.String())
+	assert.Equal(t, fmt.Sprint(len(pdf)), writer.Header().Get("Content-Length"))
+	assert.Equal(t, "none", writer.Header().Get("Accept-Ranges"))
+	assert.Empty(t, writer.Header().Get("ETag"))
+
+	events, err = handler.db.FileEvents(projectId)
+	assert.NoError(t, err)
+	assert.Len(t, events["abc100"], 1)
+	assert.Equal(t, types.TransformHashes{
+		Original:    fmt.Sprintf("%x", sha256.Sum256([]byte(pdf))),
+		Transformed: fmt.Sprintf("%x", sha256.Sum256(writer.Body.Bytes())),
+	}, events["abc100"][0].Hashes)
+
+	// Files are not transformed for other destinations, or if their type
+	// has no metadata stripped
+	writer = router.serve(http.MethodGet, "/abc100", downloadBody("trusted"), "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, pdf, writer.Body.String())
+	writer = router.serve(http.MethodGet, "/abc200", downloadBody("public"), "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, `"abc200"`, writer.Header().Get("ETag"))
+
+	archiveBody := `{"file_ids":["abc100","abc200"],"files_location":"http://storage.local","max_file_size":100,"destination":"%s","required_approvals":0}`
+	writer = router.serve(http.MethodGet, "/archive", fmt.Sprintf(archiveBody, "public"), "")
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, `{"message":"File abc100 has metadata stripped for destination public so must be downloaded on its own"}`, writer.Body.String())
+	assert.Equal(t, http.StatusOK, router.serve(http.MethodGet, "/archive", fmt.Sprintf(archiveBody, "trusted"), "").Code)
+}
+
+func TestArchiveMembersGeneric(t *testing.T) {
+	makeZip := func(name string, content string) string {
+		buf := &bytes.Buffer{}
+		w := archive.NewWriter(archive.FormatZip, buf)
+		assert.NoError(t, w.Add(name, int64(len(content)), time.Now(), strings.NewReader(content)))
+		assert.NoError(t, w.Close())
+		return buf.String()
+	}
+	handler := &Handler{
+		storage: generic.NewWithMock(&generic.MockClient{
+			Files: []generic.MockFile{
+				{Key: "clean.zip", ETag: `"abc100"`, Content: makeZip("table.csv", "a,b\n1,2\n")},
+				{Key: "infected.zip", ETag: `"abc200"`, Content: makeZip("eicar.txt", "X5O!P%@AP EICAR"+strings.Repeat(" ", 100))},
+				{Key: "table.csv", ETag: `"abc300"`, Content: "a,b\n1,2\n"},
+			},
+		}),
+		db:                 inmemory.New(),
+		scanner:            scan.NewPipeline(10, &mockScanner{}),
+		fileTypes:          filetype.NewDetector(10),
+		fileTypeAllowlists: map[types.Destination][]string{"public": {"application/zip"}},
+		archives:           archive.NewInspector(10, archive.Limits{MaxRatio: 100, MaxDepth: 3, MaxMembers: 100}),
+	}
+	router := newTestRouter()
+	router.GET("/:fileId/members", func(ctx *gin.Context) {
+		handler.GetProjectIdFilesFileIdMembers(ctx, projectId, ctx.Param("fileId"))
+	})
+	router.GET("/:fileId", downloadRoute(handler))
+	membersBody := `{"files_location":"http://storage.local"}`
+
+	writer := router.get("/abc100/members", membersBody)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{"file_id":"abc100","format":"zip","members":[
+		{"path":"table.csv","size":8,"compressed_size":15,"depth":1,"media_type":"text/csv","scan":"clean (mock)","infected":false}
+	]}`, writer.Body.String())
+	writer = router.get("/abc300/members", membersBody)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, `{"message":"File is not a zip, tar or gzip archive"}`, writer.Body.String())
+
+	// The archive is clean, as its member is compressed, but the member is not
+	writer = router.get("/abc200", downloadBody("trusted"))
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, `{"message":"File has member eicar.txt infected with Eicar-Test-Signature"}`, writer.Body.String())
+	events, err := handler.db.FileEvents(projectId)
+	assert.NoError(t, err)
+	assert.Equal(t, "eicar.txt: infected: Eicar-Test-Signature (mock)", events["abc200"][len(events["abc200"])-1].Comment)
+
+	// Members are checked against the allowlist of the destination
+	writer = router.get("/abc100", downloadBody("public"))
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, `{"message":"File has member table.csv of media type text/csv not allowed for destination public"}`, writer.Body.String())
+	assert.Equal(t, http.StatusOK, router.get("/abc100", downloadBody("trusted")).Code)
+}
+
+func TestPreviewGeneric(t *testing.T) {
+	handler := &Handler{
+		storage: generic.NewWithMock(&generic.MockClient{
+			Files: []generic.MockFile{
+				{Key: "notes.txt", ETag: `"abc100"`, Content: "line 1\nline 2\nline 3\n"},
+				{Key: "results.csv", ETag: `"abc200"`, Content: "a,b\n1,2\n"},
+				{Key: "data.bin", ETag: `"abc300"`, Content: "\x00\x01\x02binary"},
+				{Key: "figure.png", ETag: `"abc400"`, Content: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDRcorrupt"},
+			},
+		}),
+		db:        inmemory.New(),
+		fileTypes: filetype.NewDetector(10),
+		preview:   config.PreviewConfigBundle{MaxLines: 2, HexBytes: 4, ThumbnailSize: 16, MaxImageSize: 1024},
+	}
+	router := newTestRouter()
+	router.GET("/:fileId", func(ctx *gin.Context) {
+		handler.GetProjectIdFilesFileIdPreview(ctx, projectId, ctx.Param("fileId"))
+	})
+
+	// At most the configured number of lines are previewed
+	writer := router.get("/abc100", `{"files_location":"http://storage.local","lines":10,"user_id":"user1"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{"file_id":"abc100","media_type":"text/plain","kind":"text","lines":["line 1","line 2"],"truncated":true}`, writer.Body.String())
+
+	writer = router.get("/abc200", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{"file_id":"abc200","media_type":"text/csv","kind":"table","rows":[["a","b"],["1","2"]],"truncated":false}`, writer.Body.String())
+
+	writer = router.get("/abc300", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{"file_id":"abc300","media_type":"application/octet-stream","kind":"hex","lines":["00000000  00 01 02 62                                      |...b|"],"truncated":true}`, writer.Body.String())
+
+	// An image that fails to be decoded is previewed as hex
+	writer = router.get("/abc400", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Contains(t, writer.Body.String(), `"kind":"hex"`)
+
+	events, err := handler.db.FileEvents(projectId)
+	assert.NoError(t, err)
+	assert.Len(t, events["abc100"], 1)
+	assert.Equal(t, types.EventActionPreview, events["abc100"][0].Action)
+	assert.Equal(t, types.UserId("user1"), events["abc100"][0].UserId)
+	assert.False(t, events["abc100"].HasDownload("user1", ""))
+}
+
+func TestDiffGeneric(t *testing.T) {
+	client := &generic.MockClient{
+		Files: []generic.MockFile{{Key: "results.csv", ETag: `"abc100"`, Content: "id,count\n1,10\n2,20\n"}},
+	}
+	handler := &Handler{
+		storage: generic.NewWithMock(client),
+		db:      inmemory.New(),
+		diff: config.DiffConfigBundle{MaxSize: 1024, MaxChanges: 10, Snapshots: config.DiffSnapshotsConfig{
+			Enabled:   true,
+			Retention: time.Hour,
+		}},
+	}
+	router := newTestRouter()
+	router.GET("/files", func(ctx *gin.Context) {
+		handler.GetProjectIdFiles(ctx, projectId)
+	})
+	router.GET("/:fileId", func(ctx *gin.Context) {
+		handler.GetProjectIdFilesFileIdDiff(ctx, projectId, ctx.Param("fileId"))
+	})
+	router.PUT("/:fileId/reject", func(ctx *gin.Context) {
+		handler.PutProjectIdFilesFileIdReject(ctx, projectId, ctx.Param("fileId"))
+	})
+
+	writer := router.get("/files", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.NotContains(t, writer.Body.String(), "previous_file_id")
+	writer = router.get("/abc100", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+	assert.JSONEq(t, `{"message":"File has no previous version"}`, writer.Body.String())
+
+	// The file is rejected, then overwritten by a resubmission with the
+	// same name, so the base is read from the snapshot taken on rejection
+	rejectBody := `{"user_id":"user1","destination":"trusted","files_location":"http://storage.local"}`
+	assert.Equal(t, http.StatusNoContent, router.serve(http.MethodPut, "/abc100/reject", rejectBody, "").Code)
+	client.Files = []generic.MockFile{
+		{Key: "results.csv", ETag: `"abc200"`, Content: "id,count\n1,10\n2,25\n3,30\n"},
+		{Key: "notes.txt", ETag: `"abc300"`, Content: "first\nsecond\n"},
+		{Key: "data.bin", ETag: `"abc400"`, Content: "\x00\x01\x02"},
+	}
+	writer = router.get("/files", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Contains(t, writer.Body.String(), `"previous_file_id":"abc100"`)
+
+	writer = router.get("/abc200", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.JSONEq(t, `{"base_file_id":"abc100","file_id":"abc200","kind":"table","rows":[
+		{"op":"modify","base_row":3,"row":3,"changes":[{"column":2,"header":"count","base":"20","value":"25"}]},
+		{"op":"insert","row":4,"cells":["3","30"]}
+	]}`, writer.Body.String())
+
+	// Any other version can be the base
+	writer = router.get("/abc300", `{"files_location":"http://storage.local","base_file_id":"abc200"}`)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Contains(t, writer.Body.String(), `"kind":"text"`)
+
+	writer = router.get("/abc400", `{"files_location":"http://storage.local","base_file_id":"abc300"}`)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.JSONEq(t, `{"message":"File is not text, CSV or TSV so cannot be diffed"}`, writer.Body.String())
+
+	// Versions are of names in the location
+	writer = router.get("/abc200", `{"files_location":"http://other.storage.local"}`)
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+	assert.JSONEq(t, `{"message":"File has no previous version"}`, writer.Body.String())
+
+	// A version that was overwritten without being rejected cannot be the base
+	client.Files[1] = generic.MockFile{Key: "notes.txt", ETag: `"abc500"`, Content: "first\n"}
+	writer = router.get("/abc500", `{"files_location":"http://storage.local","base_file_id":"abc300"}`)
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+	assert.JSONEq(t, `{"message":"Base version of file is not in storage and has no snapshot"}`, writer.Body.String())
+
+	handler.diff.MaxChanges = 1
+	writer = router.get("/abc200", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.JSONEq(t, `{"message":"Versions differ by more than 1 lines or rows"}`, writer.Body.String())
+
+	// Snapshots are deleted once they expire
+	handler.diff.Snapshots.Retention = time.Nanosecond
+	writer = router.get("/abc200", `{"files_location":"http://storage.local"}`)
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+	assert.JSONEq(t, `{"message":"Base version of file is not in storage and has no snapshot"}`, writer.Body.String())
+
+	// A file that fails to be snapshotted is still rejected
+	assert.Equal(t, http.StatusNoContent, router.serve(http.MethodPut, "/abc100/reject", rejectBody, "").Code)
+	events, err := handler.db.FileEvents(projectId)
+	assert.NoError(t, err)
+	assert.Equal(t, types.EventActionRejection, events["abc100"][len(events["abc100"])-1].Action)
+	assert.Len(t, events["abc100"], 2)
+}
@@ -9,16 +9,33 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/archive"
+	"github.com/ucl-arc-tre/egress/internal/checks"
 	"github.com/ucl-arc-tre/egress/internal/config"
 	"github.com/ucl-arc-tre/egress/internal/db"
+	"github.com/ucl-arc-tre/egress/internal/filetype"
 	"github.com/ucl-arc-tre/egress/internal/openapi"
+	"github.com/ucl-arc-tre/egress/internal/scan"
 	"github.com/ucl-arc-tre/egress/internal/storage"
 	"github.com/ucl-arc-tre/egress/internal/types"
 )
 
 type Handler struct {
-	db      db.Interface
-	storage storage.Interface
+	db             db.Interface
+	storage        storage.Interface
+	storageActions []config.StorageActionConfig
+	scanner        *scan.Pipeline   // nil if no scanner is configured
+	listScans      chan struct{}    // Slots of background scans of listed files; nil if they are not scanned
+	checks         *checks.Pipeline // nil if no output checks are enabled
+
+	fileTypes          *filetype.Detector // Detects media types as they are needed; of listed files only with allowlists
+	fileTypeAllowlists map[types.Destination][]string
+
+	stripMetadataDestinations []types.Destination
+
+	archives *archive.Inspector // nil if archives are not inspected
+	preview  config.PreviewConfigBundle
+	diff     config.DiffConfigBundle
 }
 
 func New() *Handler {
@@ -29,11 +46,38 @@ func New() *Handler {
 	if err := db.Migrate(); err != nil {
 		panic(err)
 	}
-	storage, err := storage.Provider(config.StorageConfig())
+	backends, err := storage.Providers(config.StorageConfigs())
+	if err != nil {
+		panic(err)
+	}
+	fileTypesConfig := config.FileTypesConfig()
+	scanConfig := config.ScanConfig()
+	scanner, err := scan.Provider(scanConfig)
 	if err != nil {
 		panic(err)
 	}
-	return &Handler{db: db, storage: storage}
+	return &Handler{
+		db:             db,
+		storage:        storage.NewResilient(backends, config.StorageRetryConfig()),
+		storageActions: config.StorageActions(),
+		scanner:        scanner,
+		listScans:      listScans(scanConfig),
+		checks:         checks.Provider(config.ChecksConfig()),
+
+		fileTypes:          filetype.NewDetector(fileTypesConfig.CacheSize),
+		fileTypeAllowlists: fileTypeAllowlists(fileTypesConfig.Allowlists),
+
+		stripMetadataDestinations: stripMetadataDestinations(config.TransformsConfig().StripMetadata),
+
+		archives: archive.Provider(config.ArchivesConfig()),
+		preview:  config.PreviewConfig(),
+		diff:     config.DiffConfig(),
+	}
+}
+
+// Database of the handler, e.g. to authenticate API keys
+func (h *Handler) DB() db.Interface {
+	return h.db
 }
 
 func (h *Handler) GetProjectIdEvents(ctx *gin.Context, projectId openapi.ProjectIdParam) {
@@ -47,12 +91,17 @@ func (h *Handler) GetProjectIdEvents(ctx *gin.Context, projectId openapi.Project
 	for fileId, events := range projectEvents {
 		for _, e := range events {
 			response = append(response, openapi.Event{
-				FileId:      string(fileId),
-				Datetime:    e.Time,
-				UserId:      string(e.UserId),
-				Action:      (*openapi.EventAction)(&e.Action),
-				Destination: (*string)(&e.Destination),
-				Comment:     &e.Comment,
+				FileId:          string(fileId),
+				Datetime:        e.Time,
+				UserId:          string(e.UserId),
+				ApiKeyId:        optionalPtr(string(e.ApiKeyId)),
+				DelegatorId:     optionalPtr(string(e.DelegatorId)),
+				Action:          (*openapi.EventAction)(&e.Action),
+				Destination:     (*string)(&e.Destination),
+				Comment:         &e.Comment,
+				BundleId:        optionalPtr(string(e.BundleId)),
+				OriginalHash:    optionalPtr(e.Hashes.Original),
+				TransformedHash: optionalPtr(e.Hashes.Transformed),
 			})
 		}
 	}
@@ -88,17 +137,29 @@ func (h *Handler) GetProjectIdFiles(ctx *gin.Context, projectId openapi.ProjectI
 		return
 	}
 
+	h.detectMediaTypes(ctx, *location, filesMetadata)
+	versions := h.recordVersions(types.ProjectId(projectId), *location, filesMetadata)
+
 	response := openapi.FileListResponse{}
 	for _, fileMetadata := range filesMetadata {
 		approvals := projectApprovals.FileApprovals(fileMetadata.Id)
+		previous, _ := versions.Previous(fileMetadata.Name, fileMetadata.Id)
 		fileMetadata := openapi.MakeFileMetadata(fileMetadata, approvals)
+		fileMetadata.PreviousFileId = optionalPtr(string(previous))
 		response = append(response, fileMetadata)
 	}
+	h.scanListedFiles(ctx.Request.Context(), types.ProjectId(projectId), *location,
+		actorOf(ctx, ctx.GetString("sub")), filesMetadata)
 
 	ctx.JSON(http.StatusOK, response)
 }
 
-func (h *Handler) GetProjectIdFilesFileId(ctx *gin.Context, projectId openapi.ProjectIdParam, fileId openapi.FileIdParam) {
+func (h *Handler) GetProjectIdFilesFileId(
+	ctx *gin.Context,
+	projectId openapi.ProjectIdParam,
+	fileId openapi.FileIdParam,
+	params openapi.GetProjectIdFilesFileIdParams,
+) {
 	data := openapi.DownloadFileRequest{}
 	if err := ctx.BindJSON(&data); err != nil {
 		setBadRequest(ctx, projectId, err, "Failed to parse request body")
@@ -109,6 +170,7 @@ func (h *Handler) GetProjectIdFilesFileId(ctx *gin.Context, projectId openapi.Pr
 		setError(ctx, projectId, err, "The user_id field does not match token subject")
 		return
 	}
+	actor := actorOf(ctx, userId)
 
 	projectApprovals, err := h.db.FileApprovals(types.ProjectId(projectId))
 	if err != nil {
@@ -134,44 +196,148 @@ func (h *Handler) GetProjectIdFilesFileId(ctx *gin.Context, projectId openapi.Pr
 		return
 	}
 
-	file, err := h.storage.Get(ctx, *location, types.FileId(fileId))
+	byteRange, err := requestedRange(params, types.FileId(fileId))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to parse range")
+		return
+	}
+
+	// Checked before getting the file so that no content is read
+	// from storage for a file that is too large
+	metadata, err := h.storage.Stat(ctx, *location, types.FileId(fileId))
 	if err != nil {
 		setError(ctx, projectId, err, "Failed to get file from storage")
 		return
 	}
-	defer func() {
-		if err := file.Content.Close(); err != nil {
-			log.Err(err).Msg("Failed to close stream")
-		}
-	}()
-	if file.Size > int64(data.MaxFileSize) {
+	if metadata.Size > int64(data.MaxFileSize) {
 		setBadRequest(ctx, projectId, nil,
 			fmt.Sprintf("File size %d is greater than max_file_size %d",
-				file.Size, data.MaxFileSize))
+				metadata.Size, data.MaxFileSize))
+		return
+	}
+	mediaType, allowed, err := h.allowedMediaType(ctx, *location, metadata, types.Destination(data.Destination))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to detect media type of file")
+		return
+	}
+	if !allowed {
+		setBadRequest(ctx, projectId, nil,
+			fmt.Sprintf("File media type %s is not allowed for destination %s",
+				mediaType, data.Destination))
 		return
 	}
 
-	comment := optional(data.Comment)
-	err = h.db.DownloadFile(
-		types.ProjectId(projectId),
-		types.FileId(fileId),
-		types.UserId(userId),
-		types.Destination(data.Destination),
-		comment,
-	)
+	err = h.scanFile(ctx, types.ProjectId(projectId), *location, types.FileId(fileId),
+		actor, types.Destination(data.Destination))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to scan file for malware")
+		return
+	}
+	reason, err := h.archiveBlocked(ctx, types.ProjectId(projectId), *location, metadata,
+		actor, types.Destination(data.Destination))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to inspect archive")
+		return
+	}
+	if reason != "" {
+		setBadRequest(ctx, projectId, nil, "File "+reason)
+		return
+	}
+	blocking, err := h.blockingFindings(ctx, *location, metadata, types.Destination(data.Destination))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to check file")
+		return
+	}
+	if len(blocking) > 0 {
+		setBadRequest(ctx, projectId, nil,
+			fmt.Sprintf("File has %d findings of output checks blocking destination %s",
+				len(blocking), data.Destination))
+		return
+	}
+
+	transformFunc, err := h.transformFor(ctx, *location, metadata, types.Destination(data.Destination))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to detect media type of file")
+		return
+	}
+	if transformFunc != nil {
+		// An If-Range cannot match the transformed file, which has no ETag,
+		// so it is downloaded whole rather than appended to a partial download
+		if params.Range != nil && params.IfRange == nil {
+			setError(ctx, projectId, types.NewErrRangeNotSatisfiableF(
+				"ranges of files transformed for destination %s cannot be requested", data.Destination),
+				"Failed to get range of transformed file")
+			return
+		}
+		h.downloadTransformed(ctx, projectId, *location, types.FileId(fileId), actor, data, transformFunc)
+		return
+	}
+
+	file, err := h.storage.Get(ctx, *location, types.FileId(fileId), byteRange)
 	if err != nil {
-		setError(ctx, projectId, err, "Failed to write download file event")
+		setError(ctx, projectId, err, "Failed to get file from storage")
 		return
 	}
+	defer func() {
+		if err := file.Content.Close(); err != nil {
+			log.Err(err).Msg("Failed to close stream")
+		}
+	}()
+
+	// A range resumes a download, so it is recorded only if the user has not
+	// already downloaded the file to the destination
+	isResumed := false
+	if file.Range != nil {
+		projectEvents, err := h.db.FileEvents(types.ProjectId(projectId))
+		if err != nil {
+			setError(ctx, projectId, err, "Failed to get file events")
+			return
+		}
+		isResumed = projectEvents[types.FileId(fileId)].HasDownload(
+			types.UserId(userId),
+			types.Destination(data.Destination),
+		)
+	}
+	if !isResumed {
+		comment := optional(data.Comment)
+		err = h.db.DownloadFile(
+			types.ProjectId(projectId),
+			types.FileId(fileId),
+			actor,
+			types.Destination(data.Destination),
+			comment,
+		)
+		if err != nil {
+			setError(ctx, projectId, err, "Failed to write download file event")
+			return
+		}
+	}
 
 	ctx.Header("Content-Type", "application/octet-stream")
-	ctx.Status(http.StatusOK)
+	ctx.Header("ETag", fmt.Sprintf(`"%s"`, fileId))
+	if file.Range != nil {
+		ctx.Header("Content-Range", file.Range.Header(file.Size))
+		ctx.Status(http.StatusPartialContent)
+	} else {
+		ctx.Header("Accept-Ranges", "bytes")
+		ctx.Status(http.StatusOK)
+	}
 	numBytes, err := io.Copy(ctx.Writer, file.Content)
 	if err != nil {
 		log.Err(err).
 			Any("projectId", projectId).
 			Int64("numBytes", numBytes).
 			Msg("Failed to copy stream")
+		return
+	}
+
+	// Actions are taken once the whole file has been served, either by this
+	// request or by resuming a download to its end. Other ranges, e.g. the
+	// tail of a file not yet downloaded, do not complete a download
+	isWhole := file.Range == nil || (file.Range.First == 0 && file.Range.Last == file.Size-1)
+	if isWhole || (isResumed && file.Range.Last == file.Size-1) {
+		h.takeDownloadActions(ctx, types.ProjectId(projectId), *location,
+			types.FileId(fileId), actor, types.Destination(data.Destination))
 	}
 }
 
@@ -189,7 +355,7 @@ func (h *Handler) PutProjectIdFilesFileIdApprove(ctx *gin.Context, projectId ope
 	err := h.db.ApproveFile(
 		types.ProjectId(projectId),
 		types.FileId(fileId),
-		types.UserId(data.UserId),
+		actorOf(ctx, data.UserId),
 		types.Destination(data.Destination),
 		comment,
 	)
@@ -210,11 +376,32 @@ func (h *Handler) PutProjectIdFilesFileIdReject(ctx *gin.Context, projectId open
 		setError(ctx, projectId, err, "The user_id field does not match token subject")
 		return
 	}
+	// Actions are taken before the rejection is recorded, so that retrying
+	// after a failed action does not record the rejection again. The file
+	// is first snapshotted, as the actions may delete it, though a file
+	// that fails to be snapshotted is still rejected
+	if data.FilesLocation != nil {
+		location, err := storage.ParseLocation(*data.FilesLocation)
+		if err != nil {
+			setError(ctx, projectId, err, "Failed to parse file location")
+			return
+		}
+		if err := h.snapshotFile(ctx, types.ProjectId(projectId), *location, types.FileId(fileId)); err != nil {
+			log.Err(err).Any("projectId", projectId).Any("fileId", fileId).Msg("Failed to snapshot rejected file")
+		}
+		err = h.takeStorageActions(ctx, actionOnRejection,
+			types.ProjectId(projectId), *location, types.FileId(fileId),
+			actorOf(ctx, data.UserId), types.Destination(data.Destination))
+		if err != nil {
+			setError(ctx, projectId, err, "Failed to take storage actions after rejection")
+			return
+		}
+	}
 	comment := optional(data.Comment)
 	err := h.db.RejectFile(
 		types.ProjectId(projectId),
 		types.FileId(fileId),
-		types.UserId(data.UserId),
+		actorOf(ctx, data.UserId),
 		types.Destination(data.Destination),
 		comment,
 	)
@@ -225,12 +412,19 @@ func (h *Handler) PutProjectIdFilesFileIdReject(ctx *gin.Context, projectId open
 	ctx.Status(http.StatusNoContent)
 }
 
+// Ready reports whether the DB is ready, with the number of storage
+// circuit breakers in each state. An open breaker does not make the
+// service unready, as other hosts may still be served
 func (h *Handler) Ready(ctx *gin.Context) {
+	body := gin.H{}
+	if reporter, ok := h.storage.(storage.BreakerReporter); ok {
+		body["storage_breakers"] = reporter.BreakerCounts()
+	}
 	if !h.db.IsReady() {
-		ctx.Status(http.StatusServiceUnavailable)
+		ctx.JSON(http.StatusServiceUnavailable, body)
 		return
 	}
-	ctx.Status(http.StatusOK)
+	ctx.JSON(http.StatusOK, body)
 }
 
 func (h *Handler) Ping(ctx *gin.Context) {
@@ -238,12 +432,15 @@ func (h *Handler) Ping(ctx *gin.Context) {
 }
 
 // Checks that the user_id matches the `sub` claim from the Bearer
-// token (stored as "sub" in the Gin context) when Bearer auth is used.
+// token (stored as "sub" in the Gin context) when Bearer auth is used,
+// the user ID mapped from the client certificate, or the username of a
+// Basic auth user of the htpasswd file.
 // If user_id is "" (i.e. optional), then 'sub' is assigned to user_id.
-// The check is skipped for Basic auth, i.e. when "sub" is not present
+// The check is skipped for the Basic auth username and password and API
+// keys, i.e. when "sub" is not present
 func matchUserIdWithBearerSub(ctx *gin.Context, userId *string) error {
 	sub, exists := ctx.Get("sub")
-	if !exists { // Exists only for Bearer auth
+	if !exists { // Not set for the Basic auth username and password or API keys
 		return nil
 	}
 	subStr, ok := sub.(string)
@@ -260,9 +457,40 @@ func matchUserIdWithBearerSub(ctx *gin.Context, userId *string) error {
 	return types.NewErrInvalidObjectF("user_id %s differs from token sub %s", *userId, subStr)
 }
 
+// Who is making the request: the user and, if the request was
+// authenticated by an API key, the key (stored as "api_key_id" in the
+// Gin context), or if it was made on behalf of the user, the trusted
+// service (stored as "delegator")
+func actorOf(ctx *gin.Context, userId string) types.Actor {
+	return types.Actor{
+		UserId:      types.UserId(userId),
+		ApiKeyId:    types.ApiKeyId(ctx.GetString("api_key_id")),
+		DelegatorId: types.UserId(ctx.GetString("delegator")),
+	}
+}
+
+// Byte range requested by the Range header, or nil for the whole file. An
+// If-Range other than the file's ETag (e.g. a date) also requests the whole file
+func requestedRange(params openapi.GetProjectIdFilesFileIdParams, fileId types.FileId) (*types.ByteRange, error) {
+	if params.Range == nil {
+		return nil, nil
+	}
+	if params.IfRange != nil && *params.IfRange != fmt.Sprintf(`"%s"`, fileId) {
+		return nil, nil
+	}
+	return types.ParseByteRange(*params.Range)
+}
+
 func optional(param *string) string {
 	if param != nil {
 		return *param
 	}
 	return ""
 }
+
+func optionalPtr(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
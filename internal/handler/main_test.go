@@ -10,9 +10,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/ucl-arc-tre/egress/internal/db/inmemory"
+	"github.com/ucl-arc-tre/egress/internal/openapi"
 	"github.com/ucl-arc-tre/egress/internal/storage/s3"
 	"github.com/ucl-arc-tre/egress/internal/types"
 )
@@ -98,7 +100,7 @@ func TestGetFiles(t *testing.T) {
 				err := handler.db.ApproveFile(
 					types.ProjectId(projectId),
 					fileId,
-					approval.UserId,
+					types.Actor{UserId: approval.UserId},
 					approval.Destination,
 					approval.Comment)
 				assert.NoError(t, err)
@@ -227,7 +229,7 @@ func TestGetFileId(t *testing.T) {
 				db:      inmemory.New(),
 			}
 			for fileId, approval := range tc.approvals {
-				err := handler.db.ApproveFile(types.ProjectId(projectId), fileId, approval.UserId, approval.Destination, "")
+				err := handler.db.ApproveFile(types.ProjectId(projectId), fileId, types.Actor{UserId: approval.UserId}, approval.Destination, "")
 				assert.NoError(t, err)
 			}
 			writer := httptest.NewRecorder()
@@ -236,7 +238,7 @@ func TestGetFileId(t *testing.T) {
 				if tc.authUserId != "" {
 					ctx.Set("sub", tc.authUserId)
 				}
-				handler.GetProjectIdFilesFileId(ctx, projectId, tc.fileId)
+				handler.GetProjectIdFilesFileId(ctx, projectId, tc.fileId, openapi.GetProjectIdFilesFileIdParams{})
 			})
 			ctx.Request, _ = http.NewRequest(http.MethodGet, "/", strings.NewReader(tc.body))
 			router.ServeHTTP(writer, ctx.Request)
@@ -249,6 +251,63 @@ func TestGetFileId(t *testing.T) {
 	}
 }
 
+func TestGetFileIdRange(t *testing.T) {
+	fileId1 := "etag1"
+	handler := &Handler{
+		storage: s3.NewMock(s3.MockClient{
+			Buckets: map[s3.MockBucketName]s3.MockBucket{
+				"bucket1": {Objects: []s3.MockObject{
+					{Key: "object1", Etag: fmt.Sprintf(`"%s"`, fileId1), Content: "hello world"},
+				}},
+			},
+		}),
+		db: inmemory.New(),
+	}
+	body := `{"files_location":"s3://bucket1","max_file_size":100,"destination":"trusted","required_approvals":0,"user_id":"user1"}`
+	download := func(params openapi.GetProjectIdFilesFileIdParams) *httptest.ResponseRecorder {
+		writer := httptest.NewRecorder()
+		ctx, router := gin.CreateTestContext(writer)
+		router.GET("/", func(ctx *gin.Context) {
+			handler.GetProjectIdFilesFileId(ctx, projectId, fileId1, params)
+		})
+		ctx.Request, _ = http.NewRequest(http.MethodGet, "/", strings.NewReader(body))
+		router.ServeHTTP(writer, ctx.Request)
+		return writer
+	}
+	numDownloads := func() int {
+		events, err := handler.db.FileEvents(types.ProjectId(projectId))
+		assert.NoError(t, err)
+		return len(events[types.FileId(fileId1)])
+	}
+
+	writer := download(openapi.GetProjectIdFilesFileIdParams{Range: aws.String("bytes=0-4")})
+	assert.Equal(t, http.StatusPartialContent, writer.Code)
+	assert.Equal(t, "hello", writer.Body.String())
+	assert.Equal(t, "bytes 0-4/11", writer.Header().Get("Content-Range"))
+	assert.Equal(t, `"etag1"`, writer.Header().Get("ETag"))
+	assert.Equal(t, 1, numDownloads())
+
+	// Resuming is tied to the existing download event
+	writer = download(openapi.GetProjectIdFilesFileIdParams{Range: aws.String("bytes=5-"), IfRange: aws.String(`"etag1"`)})
+	assert.Equal(t, http.StatusPartialContent, writer.Code)
+	assert.Equal(t, " world", writer.Body.String())
+	assert.Equal(t, 1, numDownloads())
+
+	// Mismatched If-Range downloads the whole file
+	writer = download(openapi.GetProjectIdFilesFileIdParams{Range: aws.String("bytes=5-"), IfRange: aws.String(`"other"`)})
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "hello world", writer.Body.String())
+	assert.Equal(t, "bytes", writer.Header().Get("Accept-Ranges"))
+	assert.Equal(t, 2, numDownloads())
+
+	writer = download(openapi.GetProjectIdFilesFileIdParams{Range: aws.String("bytes=100-")})
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, writer.Code)
+
+	writer = download(openapi.GetProjectIdFilesFileIdParams{Range: aws.String("bytes=0-1,3-4")})
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, 2, numDownloads())
+}
+
 func TestApproveFileId(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -399,7 +458,7 @@ func TestGetEvents(t *testing.T) {
 		userId      types.UserId
 		destination types.Destination
 		comment     string
-		runner      func(types.ProjectId, types.FileId, types.UserId, types.Destination, string) error
+		runner      func(types.ProjectId, types.FileId, types.Actor, types.Destination, string) error
 	}{
 		{
 			action:      "Approval",
@@ -429,7 +488,7 @@ func TestGetEvents(t *testing.T) {
 
 	// Log the events
 	for _, e := range sourceEvents {
-		err := e.runner(types.ProjectId(projectId), e.fileId, e.userId, e.destination, e.comment)
+		err := e.runner(types.ProjectId(projectId), e.fileId, types.Actor{UserId: e.userId}, e.destination, e.comment)
 		time.Sleep(10 * time.Millisecond)
 		assert.NoError(t, err)
 	}
@@ -474,3 +533,72 @@ func TestGetEvents(t *testing.T) {
 		assert.True(t, currentEventAt.After(previousEventAt), fmt.Sprintf("%v was before %v", currentEventAt, previousEventAt))
 	}
 }
+
+func TestApiKeys(t *testing.T) {
+	handler := &Handler{
+		db: inmemory.New(),
+	}
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	router.GET("/api-keys", handler.GetApiKeys)
+	router.POST("/api-keys", handler.PostApiKeys)
+	router.PUT("/api-keys/:api-key-id/revoke", func(ctx *gin.Context) {
+		handler.PutApiKeysApiKeyIdRevoke(ctx, ctx.Param("api-key-id"))
+	})
+	router.PUT("/api-keys/:api-key-id/expire", func(ctx *gin.Context) {
+		handler.PutApiKeysApiKeyIdExpire(ctx, ctx.Param("api-key-id"))
+	})
+	serve := func(method string, path string, body string) *httptest.ResponseRecorder {
+		writer := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		router.ServeHTTP(writer, req)
+		return writer
+	}
+
+	for _, body := range []string{
+		`{"label": "", "projects": ["p1"], "scopes": ["read"]}`,
+		`{"label": "ci", "projects": [], "scopes": ["read"]}`,
+		`{"label": "ci", "projects": [""], "scopes": ["read"]}`,
+		`{"label": "ci", "projects": ["p1"], "scopes": ["write"]}`,
+		`{"label": "ci", "projects": ["p1"], "scopes": ["read"], "expires_at": "2020-01-01T00:00:00Z"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/api-keys", body).Code, body)
+	}
+
+	writer := serve(http.MethodPost, "/api-keys", `{"label": "ci", "projects": ["p1"], "scopes": ["read", "download"]}`)
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	created := openapi.CreateApiKeyResponse{}
+	assert.NoError(t, json.Unmarshal(writer.Body.Bytes(), &created))
+	assert.Equal(t, "ci", created.ApiKey.Label)
+	assert.Nil(t, created.ApiKey.ExpiresAt)
+
+	// Only the hash of the secret is stored
+	id, secret, ok := types.ParseApiKey(created.Key)
+	assert.True(t, ok)
+	assert.Equal(t, created.ApiKey.Id, string(id))
+	key, err := handler.db.ApiKey(id)
+	assert.NoError(t, err)
+	assert.True(t, key.HasSecret(secret))
+	assert.NotContains(t, key.Hash, secret)
+
+	writer = serve(http.MethodGet, "/api-keys", "")
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.NotContains(t, writer.Body.String(), secret)
+	keys := openapi.ApiKeyListResponse{}
+	assert.NoError(t, json.Unmarshal(writer.Body.Bytes(), &keys))
+	assert.Len(t, keys, 1)
+	assert.Equal(t, []openapi.ApiKeyScope{"read", "download"}, keys[0].Scopes)
+
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	writer = serve(http.MethodPut, "/api-keys/"+string(id)+"/expire", `{"expires_at": "`+expires.Format(time.RFC3339)+`"}`)
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+	key, err = handler.db.ApiKey(id)
+	assert.NoError(t, err)
+	assert.True(t, expires.Equal(key.Expires))
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodPut, "/api-keys/"+string(id)+"/revoke", "").Code)
+	key, err = handler.db.ApiKey(id)
+	assert.NoError(t, err)
+	assert.False(t, key.IsActive(time.Now()))
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPut, "/api-keys/unknown/revoke", "").Code)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ucl-arc-tre/egress/internal/openapi"
+	"github.com/ucl-arc-tre/egress/internal/preview"
+	"github.com/ucl-arc-tre/egress/internal/storage"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+func (h *Handler) GetProjectIdFilesFileIdPreview(ctx *gin.Context, projectId openapi.ProjectIdParam, fileId openapi.FileIdParam) {
+	data := openapi.PreviewFileRequest{}
+	if err := ctx.BindJSON(&data); err != nil {
+		setBadRequest(ctx, projectId, err, "Failed to parse request body")
+		return
+	}
+	userId := optional(data.UserId)
+	if err := matchUserIdWithBearerSub(ctx, &userId); err != nil {
+		setError(ctx, projectId, err, "The user_id field does not match token subject")
+		return
+	}
+	location, err := storage.ParseLocation(data.FilesLocation)
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to parse file location")
+		return
+	}
+	metadata, err := h.storage.Stat(ctx, *location, types.FileId(fileId))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to get file from storage")
+		return
+	}
+	lines := h.preview.MaxLines
+	if data.Lines != nil {
+		lines = min(*data.Lines, lines)
+	}
+	filePreview, err := h.previewFile(ctx, *location, metadata, lines)
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to preview file")
+		return
+	}
+	err = h.db.PreviewFile(types.ProjectId(projectId), types.FileId(fileId), actorOf(ctx, userId), optional(data.Comment))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to write preview file event")
+		return
+	}
+	ctx.JSON(http.StatusOK, openapi.MakeFilePreview(filePreview))
+}
+
+// Preview of the file, reading only as much of it as is previewed. An
+// image that fails to be decoded is previewed as hex
+func (h *Handler) previewFile(
+	ctx context.Context,
+	location types.LocationURI,
+	metadata *types.FileMetadata,
+	lines int,
+) (types.FilePreview, error) {
+	result := types.FilePreview{FileId: metadata.Id, MediaType: "application/octet-stream"}
+	if h.fileTypes != nil {
+		mediaType, err := h.mediaType(ctx, location, metadata)
+		if err != nil {
+			return types.FilePreview{}, err
+		}
+		result.MediaType = mediaType
+	}
+	result.Kind = preview.KindOf(result.MediaType, metadata.Size, h.preview.MaxImageSize)
+
+	if result.Kind == types.PreviewKindImage {
+		content, err := h.openPreview(ctx, location, metadata, 0)
+		if err != nil {
+			return types.FilePreview{}, err
+		}
+		defer content.Close()
+		result.Thumbnail, result.Width, result.Height, err = preview.Thumbnail(content, h.preview.ThumbnailSize)
+		if err == nil {
+			return result, nil
+		} else if !errors.Is(err, types.ErrInvalidObject) {
+			return types.FilePreview{}, err
+		}
+		result.Kind = types.PreviewKindHex
+	}
+
+	length := int64(h.preview.HexBytes) + 1 // To tell whether there are more bytes
+	if result.Kind != types.PreviewKindHex {
+		length = int64(lines) * (preview.MaxLineLength + 2) // Including a CRLF
+	}
+	content, err := h.openPreview(ctx, location, metadata, length)
+	if err != nil {
+		return types.FilePreview{}, err
+	}
+	defer content.Close()
+	switch result.Kind {
+	case types.PreviewKindTable:
+		result.Rows, result.Truncated, err = preview.Table(content, metadata.Name, lines)
+	case types.PreviewKindText:
+		result.Lines, result.Truncated, err = preview.Text(content, lines)
+	default:
+		result.Lines, result.Truncated, err = preview.Hex(content, h.preview.HexBytes)
+	}
+	if err != nil {
+		return types.FilePreview{}, err
+	}
+	// The last line or row read may have been cut short by the end of the range
+	if isPartial := metadata.Size > length; isPartial && result.Kind != types.PreviewKindHex {
+		result.Truncated = true
+		if len(result.Rows) > 0 && len(result.Rows) < lines {
+			result.Rows = result.Rows[:len(result.Rows)-1]
+		}
+		if len(result.Lines) > 0 && len(result.Lines) < lines {
+			result.Lines = result.Lines[:len(result.Lines)-1]
+		}
+	}
+	return result, nil
+}
+
+// Opens the first length bytes of the file, or the whole file if length
+// is 0. A range of an empty file is not satisfiable, so it is not read
+func (h *Handler) openPreview(
+	ctx context.Context,
+	location types.LocationURI,
+	metadata *types.FileMetadata,
+	length int64,
+) (io.ReadCloser, error) {
+	if metadata.Size == 0 {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	var byteRange *types.ByteRange
+	if length > 0 {
+		byteRange = &types.ByteRange{Start: 0, Length: length}
+	}
+	file, err := h.storage.Get(ctx, location, metadata.Id, byteRange)
+	if err != nil {
+		return nil, err
+	}
+	return file.Content, nil
+}
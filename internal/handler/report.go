@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ucl-arc-tre/egress/internal/openapi"
+	"github.com/ucl-arc-tre/egress/internal/storage"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+func (h *Handler) GetProjectIdFilesFileIdReport(ctx *gin.Context, projectId openapi.ProjectIdParam, fileId openapi.FileIdParam) {
+	data := openapi.FileReportRequest{}
+	if err := ctx.BindJSON(&data); err != nil {
+		setBadRequest(ctx, projectId, err, "Failed to parse request body")
+		return
+	}
+	location, err := storage.ParseLocation(data.FilesLocation)
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to parse file location")
+		return
+	}
+	metadata, err := h.storage.Stat(ctx, *location, types.FileId(fileId))
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to get file from storage")
+		return
+	}
+	report, err := h.fileReport(ctx, *location, metadata)
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to check file")
+		return
+	}
+	ctx.JSON(http.StatusOK, openapi.MakeFileReport(report))
+}
+
+// Report of the output checks of the file, which is empty if no checks
+// are configured. The media type of the file is detected, if it is not
+// known, as checks may apply only to some types
+func (h *Handler) fileReport(
+	ctx context.Context,
+	location types.LocationURI,
+	metadata *types.FileMetadata,
+) (types.FileReport, error) {
+	if h.checks == nil {
+		return types.FileReport{FileId: metadata.Id}, nil
+	}
+	if h.fileTypes != nil && metadata.MediaType == "" {
+		mediaType, err := h.mediaType(ctx, location, metadata)
+		if err != nil {
+			return types.FileReport{}, err
+		}
+		metadata.MediaType = mediaType
+	}
+	return h.checks.Report(ctx, location, metadata, func(ctx context.Context) (io.ReadCloser, error) {
+		file, err := h.storage.Get(ctx, location, metadata.Id, nil)
+		if err != nil {
+			return nil, err
+		}
+		return file.Content, nil
+	})
+}
+
+// Findings of the output checks of the file that block a download to the
+// destination, if any
+func (h *Handler) blockingFindings(
+	ctx context.Context,
+	location types.LocationURI,
+	metadata *types.FileMetadata,
+	destination types.Destination,
+) ([]types.Finding, error) {
+	if h.checks == nil {
+		return nil, nil
+	}
+	report, err := h.fileReport(ctx, location, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return h.checks.Blocking(report, destination), nil
+}
@@ -28,6 +28,10 @@ func setError(ctx *gin.Context, projectId string, err error, message string) {
 		statusCode = http.StatusBadRequest
 	} else if errors.Is(err, types.ErrNotFound) {
 		statusCode = http.StatusNotFound
+	} else if errors.Is(err, types.ErrRangeNotSatisfiable) {
+		statusCode = http.StatusRequestedRangeNotSatisfiable
+	} else if errors.Is(err, types.ErrUnavailable) {
+		statusCode = http.StatusServiceUnavailable
 	} else {
 		statusCode = 520
 		err = fmt.Errorf("unknown error: %v", err)
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Max number of list requests whose files are scanned in the background at
+// once. The files of other list requests are scanned when downloaded
+const maxListScans = 4
+
+func listScans(cfg config.ScanConfigBundle) chan struct{} {
+	if !cfg.OnList {
+		return nil
+	}
+	return make(chan struct{}, maxListScans)
+}
+
+// Scan the whole file for malware before any of it is served, recording an
+// event for each result, including a cached one, so that every download
+// has its scan. An infected file is an ErrInvalidObject, and a file that
+// failed to be scanned is an error so that it is not served
+func (h *Handler) scanFile(
+	ctx context.Context,
+	projectId types.ProjectId,
+	location types.LocationURI,
+	fileId types.FileId,
+	actor types.Actor,
+	destination types.Destination,
+) error {
+	if h.scanner == nil {
+		return nil
+	}
+	result, cached, err := h.scanner.Scan(ctx, location, fileId, func(ctx context.Context) (io.ReadCloser, error) {
+		file, err := h.storage.Get(ctx, location, fileId, nil)
+		if err != nil {
+			return nil, err
+		}
+		return file.Content, nil
+	})
+	if err != nil {
+		return err
+	}
+	comment := result.String()
+	if cached {
+		comment += ", cached"
+	}
+	err = h.db.RecordScan(projectId, fileId, actor, destination, comment)
+	if err != nil {
+		return err
+	}
+	if result.Verdict == types.ScanVerdictInfected {
+		return types.NewErrInvalidObjectF("file %s is infected with %s", fileId, result.Signature)
+	}
+	return nil
+}
+
+// Scan the listed files that have not been scanned in the background, so
+// that they are not scanned when downloaded. Results are recorded as of the
+// actor listing the files, with no destination. If the max list requests
+// are already being scanned, the files are not. The context must outlive
+// the request so must not be a gin.Context, which is reused
+func (h *Handler) scanListedFiles(
+	ctx context.Context,
+	projectId types.ProjectId,
+	location types.LocationURI,
+	actor types.Actor,
+	files []types.FileMetadata,
+) {
+	if h.scanner == nil || h.listScans == nil {
+		return
+	}
+	select {
+	case h.listScans <- struct{}{}:
+	default:
+		log.Debug().Any("projectId", projectId).Msg("Not scanning listed files as too many are being scanned")
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer func() { <-h.listScans }()
+		for _, file := range files {
+			if _, cached := h.scanner.Cached(location, file.Id); cached {
+				continue
+			}
+			// An infected file is recorded by its event so is not logged
+			err := h.scanFile(ctx, projectId, location, file.Id, actor, "")
+			if err != nil && !errors.Is(err, types.ErrInvalidObject) {
+				log.Err(err).
+					Any("projectId", projectId).
+					Any("fileId", file.Id).
+					Msg("Failed to scan listed file")
+			}
+		}
+	}()
+}
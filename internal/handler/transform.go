@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/openapi"
+	"github.com/ucl-arc-tre/egress/internal/transform"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// File transformed for download, spooled to a temporary file so that its
+// size is known before it is served
+type transformedFile struct {
+	*os.File
+	Size   int64
+	Hashes types.TransformHashes
+}
+
+// Remove the temporary file
+func (f *transformedFile) Close() error {
+	err := f.File.Close()
+	if removeErr := os.Remove(f.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// Download the whole file transformed for the destination. The transformed
+// content differs from the file in storage, so it has no ETag and ranges of
+// it cannot be requested, which is advertised with Accept-Ranges: none
+func (h *Handler) downloadTransformed(
+	ctx *gin.Context,
+	projectId openapi.ProjectIdParam,
+	location types.LocationURI,
+	fileId types.FileId,
+	actor types.Actor,
+	data openapi.DownloadFileRequest,
+	transformFunc transform.Func,
+) {
+	file, err := h.transformFile(ctx, location, fileId, transformFunc)
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to transform file")
+		return
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Err(err).Msg("Failed to remove transformed file")
+		}
+	}()
+
+	err = h.db.DownloadTransformedFile(
+		types.ProjectId(projectId),
+		fileId,
+		actor,
+		types.Destination(data.Destination),
+		optional(data.Comment),
+		file.Hashes,
+	)
+	if err != nil {
+		setError(ctx, projectId, err, "Failed to write download file event")
+		return
+	}
+
+	ctx.Header("Content-Type", "application/octet-stream")
+	ctx.Header("Content-Length", strconv.FormatInt(file.Size, 10))
+	ctx.Header("Accept-Ranges", "none")
+	ctx.Status(http.StatusOK)
+	numBytes, err := io.Copy(ctx.Writer, file)
+	if err != nil {
+		log.Err(err).
+			Any("projectId", projectId).
+			Int64("numBytes", numBytes).
+			Msg("Failed to copy stream")
+		return
+	}
+	h.takeDownloadActions(ctx, types.ProjectId(projectId), location, fileId, actor, types.Destination(data.Destination))
+}
+
+// Transform of the file for the destination, or nil if it is downloaded
+// as it is
+func (h *Handler) transformFor(
+	ctx context.Context,
+	location types.LocationURI,
+	metadata *types.FileMetadata,
+	destination types.Destination,
+) (transform.Func, error) {
+	if h.fileTypes == nil || !slices.Contains(h.stripMetadataDestinations, destination) {
+		return nil, nil
+	}
+	mediaType, err := h.mediaType(ctx, location, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return transform.StripMetadata(mediaType), nil
+}
+
+// Transform the whole file into a temporary file, hashing its content
+// before and after
+func (h *Handler) transformFile(
+	ctx context.Context,
+	location types.LocationURI,
+	fileId types.FileId,
+	transformFunc transform.Func,
+) (*transformedFile, error) {
+	file, err := h.storage.Get(ctx, location, fileId, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Content.Close()
+
+	spool, err := os.CreateTemp("", "egress-transform-*")
+	if err != nil {
+		return nil, types.NewErrServerF("failed to create temporary file: %v", err)
+	}
+	transformed := &transformedFile{File: spool}
+	originalHash := sha256.New()
+	transformedHash := sha256.New()
+	err = transformFunc(io.TeeReader(file.Content, originalHash), io.MultiWriter(spool, transformedHash))
+	if err == nil {
+		// Any trailing content not read by the transform is still hashed
+		_, err = io.Copy(originalHash, file.Content)
+	}
+	if err == nil {
+		transformed.Size, err = spool.Seek(0, io.SeekCurrent)
+	}
+	if err == nil {
+		_, err = spool.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = transformed.Close()
+		return nil, err
+	}
+	transformed.Hashes = types.TransformHashes{
+		Original:    hex.EncodeToString(originalHash.Sum(nil)),
+		Transformed: hex.EncodeToString(transformedHash.Sum(nil)),
+	}
+	return transformed, nil
+}
+
+func stripMetadataDestinations(cfg config.StripMetadataTransformConfig) []types.Destination {
+	destinations := []types.Destination{}
+	for _, destination := range cfg.Destinations {
+		destinations = append(destinations, types.Destination(destination))
+	}
+	return destinations
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/openapi"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Store of the API keys that authenticate requests
+type ApiKeyStore interface {
+	// The key with the id, or an ErrNotFound
+	ApiKey(id types.ApiKeyId) (types.ApiKey, error)
+}
+
+// Closure for authenticating an API key. The key must allow the project
+// of the request with the scopes of its operation
+func apiKeyAuthenticator(keys ApiKeyStore) authFunction {
+	if keys == nil {
+		log.Info().Msg("API key auth not configured")
+		return nil
+	}
+
+	return func(ctx *gin.Context) {
+		id, secret, ok := types.ParseApiKey(apiKeyOf(ctx.Request))
+		if !ok {
+			fail(ctx, []string{"ApiKey"}, "could not validate API key")
+			return
+		}
+		key, err := keys.ApiKey(id)
+		if errors.Is(err, types.ErrNotFound) {
+			fail(ctx, []string{"ApiKey"}, "could not validate API key")
+			log.Error().Str("apiKeyId", string(id)).Msg("API key does not exist")
+			return
+		} else if err != nil {
+			ctx.JSON(http.StatusInternalServerError, openapi.InternalServerError{
+				Message: "Failed to validate API key",
+			})
+			ctx.Abort()
+			log.Err(err).Str("apiKeyId", string(id)).Msg("failed to get API key")
+			return
+		}
+		if !key.HasSecret(secret) || !key.IsActive(time.Now()) {
+			fail(ctx, []string{"ApiKey"}, "could not validate API key")
+			log.Error().Str("apiKeyId", string(id)).Msg("API key is invalid, revoked or expired")
+			return
+		}
+		// Scopes are set only for operations that API keys may be used for
+		scopes, allowed := ctx.Get(string(openapi.ApiKeyAuthScopes))
+		if !allowed {
+			forbid(ctx, "API keys cannot be used for this operation")
+			return
+		}
+		required := []types.ApiKeyScope{}
+		for _, scope := range scopes.([]string) {
+			required = append(required, types.ApiKeyScope(scope))
+		}
+		if !key.Allows(types.ProjectId(ctx.Param("project-id")), required) {
+			forbid(ctx, "API key does not allow this operation on the project")
+			return
+		}
+		// Save the key ID so it is recorded on the events the request causes
+		ctx.Set("api_key_id", string(key.Id))
+	}
+}
+
+// API key given by an X-API-Key header or an "Authorization: ApiKey"
+// header, or "" if there is none
+func apiKeyOf(req *http.Request) string {
+	if key := req.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return strings.TrimPrefix(req.Header.Get("Authorization"), "ApiKey ")
+}
+
+func hasApiKey(req *http.Request) bool {
+	return req.Header.Get("X-API-Key") != "" || strings.HasPrefix(req.Header.Get("Authorization"), "ApiKey ")
+}
@@ -1,23 +1,194 @@
 package middleware
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 	"github.com/ucl-arc-tre/egress/internal/config"
+	"golang.org/x/crypto/bcrypt"
 )
 
-// Closure for authenticating HTTP Basic
+// Users of Basic auth: those of the htpasswd file (if any), which is
+// reloaded when it changes, and the configured username and password
+type basicUsers struct {
+	cfg   config.BasicAuthConfigBundle
+	dummy bcryptHash // Checked for unknown users so they take as long as known users
+
+	mu       sync.Mutex
+	hashes   map[string]passwordHash // Of the users in the htpasswd file
+	loaded   os.FileInfo             // Of the htpasswd file when it was last loaded
+	failures map[string]basicFailures
+}
+
+// Consecutive failed attempts of a user, who is locked out until the time
+// once there are too many
+type basicFailures struct {
+	count  int
+	locked time.Time
+}
+
+// Closure for authenticating HTTP Basic. Users of the htpasswd file are
+// authenticated as their username, i.e. sub, whereas the configured
+// username and password set no identity
 func basicAuthenticator() authFunction {
 	cfg := config.BasicAuthConfig()
-	if cfg.Username == "" || cfg.Password == "" {
+	if (cfg.Username == "" || cfg.Password == "") && cfg.HtpasswdFile == "" {
 		log.Info().Msg("Basic auth not configured")
 		return nil
 	}
+	users, err := newBasicUsers(cfg)
+	if err != nil {
+		log.Error().Err(err).Str("path", cfg.HtpasswdFile).Msg("Failed to load htpasswd file")
+		return nil
+	}
 
 	return func(ctx *gin.Context) {
 		username, password, ok := ctx.Request.BasicAuth()
-		if !ok || username != cfg.Username || password != cfg.Password { /* pragma: allowlist secret */
+		if !ok {
+			fail(ctx, []string{"Basic"}, "authentication failed")
+			return
+		}
+		users.reload()
+		if users.isLocked(username, time.Now()) {
+			fail(ctx, []string{"Basic"}, "too many failed attempts")
+			log.Error().Str("username", username).Msg("basic auth user is locked out")
+			return
+		}
+		isHtpasswdUser, valid := users.authenticate(username, password)
+		users.record(username, valid, time.Now())
+		if !valid {
 			fail(ctx, []string{"Basic"}, "authentication failed")
+			return
 		}
+		if isHtpasswdUser {
+			setIdentity(ctx, username, nil)
+		} else {
+			// Not an identity for user_id checks, as the single username
+			// and password are those of services acting for users, but
+			// that of a service that may be a trusted delegator
+			ctx.Set("basic_username", username)
+		}
+		setAuthMethod(ctx, authMethodBasic, "")
+	}
+}
+
+func newBasicUsers(cfg config.BasicAuthConfigBundle) (*basicUsers, error) {
+	dummy, err := bcrypt.GenerateFromPassword([]byte("dummy"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	users := &basicUsers{
+		cfg:      cfg,
+		dummy:    bcryptHash(dummy),
+		hashes:   map[string]passwordHash{},
+		failures: map[string]basicFailures{},
+	}
+	if cfg.HtpasswdFile != "" {
+		// Fail-fast; the file must be valid initially but may then change
+		if err := users.load(); err != nil {
+			return nil, err
+		}
+	}
+	return users, nil
+}
+
+// Reload the htpasswd file if it has changed e.g. as a mounted Secret is
+// updated. The users are kept if the file cannot be loaded
+func (u *basicUsers) reload() {
+	if u.cfg.HtpasswdFile == "" {
+		return
+	}
+	info, err := os.Stat(u.cfg.HtpasswdFile)
+	u.mu.Lock()
+	unchanged := err == nil && info.ModTime().Equal(u.loaded.ModTime()) && info.Size() == u.loaded.Size()
+	u.mu.Unlock()
+	if unchanged {
+		return
+	}
+	if err := u.load(); err != nil {
+		log.Error().Err(err).Str("path", u.cfg.HtpasswdFile).Msg("Failed to reload htpasswd file")
+		return
+	}
+	log.Info().Str("path", u.cfg.HtpasswdFile).Msg("Reloaded htpasswd file")
+}
+
+func (u *basicUsers) load() error {
+	file, err := os.Open(u.cfg.HtpasswdFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	hashes, err := parseHtpasswd(file)
+	if err != nil {
+		return fmt.Errorf("failed to parse htpasswd file: %w", err)
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.hashes = hashes
+	u.loaded = info
+	return nil
+}
+
+// Whether the credentials are valid, and whether the user is one of the
+// htpasswd file. Passwords are compared in constant time
+func (u *basicUsers) authenticate(username string, password string) (bool, bool) {
+	u.mu.Lock()
+	hash, exists := u.hashes[username]
+	u.mu.Unlock()
+	if exists {
+		return true, hash.matches(password)
+	}
+	if u.cfg.Username != "" && u.cfg.Password != "" {
+		// Compare hashes so that the time does not depend on the lengths
+		usernameHash, expectedUsernameHash := sha256.Sum256([]byte(username)), sha256.Sum256([]byte(u.cfg.Username))
+		passwordHash, expectedPasswordHash := sha256.Sum256([]byte(password)), sha256.Sum256([]byte(u.cfg.Password))
+		usernameMatches := subtle.ConstantTimeCompare(usernameHash[:], expectedUsernameHash[:])
+		passwordMatches := subtle.ConstantTimeCompare(passwordHash[:], expectedPasswordHash[:])
+		if usernameMatches&passwordMatches == 1 {
+			return false, true
+		}
+	}
+	u.dummy.matches(password)
+	return false, false
+}
+
+func (u *basicUsers) isLocked(username string, now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return now.Before(u.failures[username].locked)
+}
+
+// Record an attempt of the user, locking them out once they have failed
+// the max consecutive attempts. Only attempts of known users are recorded
+// so that the failures are bounded
+func (u *basicUsers) record(username string, valid bool, now time.Time) {
+	if u.cfg.Lockout.MaxFailures == 0 {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if valid {
+		delete(u.failures, username)
+		return
+	}
+	if _, exists := u.hashes[username]; !exists && username != u.cfg.Username {
+		return
+	}
+	failures := u.failures[username]
+	failures.count++
+	if failures.count >= u.cfg.Lockout.MaxFailures {
+		failures = basicFailures{locked: now.Add(u.cfg.Lockout.Duration)}
+		log.Warn().Str("username", username).Time("until", failures.locked).Msg("Locked out basic auth user")
 	}
+	u.failures[username] = failures
 }
@@ -1,6 +1,11 @@
 package middleware
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/url"
 	"strings"
 	"time"
@@ -14,41 +19,223 @@ import (
 
 const tokenCacheDuration = 5 * time.Minute
 
+// Trusted issuer of bearer tokens, with a validator for each of its
+// allowed signing algorithms
+type bearerIssuer struct {
+	validators map[string]*jwtv.Validator
+	claims     config.BearerClaimsConfig
+}
+
+// Validates bearer tokens by the trusted issuer of their iss claim or, if
+// they are not JWTs of a trusted issuer, by introspection (if configured)
+type bearerValidator struct {
+	issuers      map[string]*bearerIssuer
+	introspector *introspector // nil if introspection is not configured
+}
+
 // Closure for authenticating HTTP Bearer
 func bearerAuthenticator() authFunction {
+	return newBearerValidator().authenticator()
+}
+
+// Nil if neither bearer issuers nor introspection are configured
+func newBearerValidator() *bearerValidator {
 	cfg := config.BearerAuthConfig()
-	issuer, _ := url.Parse(cfg.IssuerURL) // Issuer url has already been validated
-
-	provider := jwks.NewCachingProvider(issuer, tokenCacheDuration)
-	validator, err := jwtv.New(
-		provider.KeyFunc,
-		jwtv.RS256,
-		issuer.String(),
-		[]string{cfg.Audience},
-	)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to create token validator")
+	introspector := newIntrospector(config.IntrospectionAuthConfig())
+	if len(cfg.Issuers) == 0 && introspector == nil {
+		log.Info().Msg("Bearer auth not configured")
+		return nil
+	}
+	issuers := map[string]*bearerIssuer{}
+	for _, issuerCfg := range cfg.Issuers {
+		issuer, err := newBearerIssuer(issuerCfg)
+		if err != nil {
+			log.Error().Err(err).Str("issuer", issuerCfg.IssuerURL).Msg("Failed to create token validator")
+			return nil
+		}
+		issuers[issuerCfg.IssuerURL] = issuer
+	}
+	return &bearerValidator{issuers: issuers, introspector: introspector}
+}
+
+func (v *bearerValidator) authenticator() authFunction {
+	if v == nil {
 		return nil
 	}
 
 	return func(ctx *gin.Context) {
 		header := ctx.GetHeader("Authorization")
-		token := strings.TrimPrefix(header, "Bearer")
+		token := strings.TrimSpace(strings.TrimPrefix(header, "Bearer"))
 
-		claims, err := validator.ValidateToken(ctx, token)
+		userId, roles, err := v.identity(ctx.Request.Context(), token)
 		if err != nil {
 			fail(ctx, []string{"Bearer"}, "could not validate token")
 			log.Error().Err(err).Msg("failed to validate bearer token")
 			return
 		}
-		validated, ok := claims.(*jwtv.ValidatedClaims)
+		setIdentity(ctx, userId, roles)
+		setAuthMethod(ctx, authMethodBearer, v.issuerOf(token))
+	}
+}
+
+// Issuer of a valid token: its iss if it is a JWT of a trusted issuer,
+// otherwise the introspection endpoint that validated it
+func (v *bearerValidator) issuerOf(token string) string {
+	unverified, _ := readUnverified(token)
+	if _, exists := v.issuers[unverified.Issuer]; exists || v.introspector == nil {
+		return unverified.Issuer
+	}
+	return v.introspector.cfg.URL
+}
+
+// User ID and roles, if mapped, of a valid token
+func (v *bearerValidator) identity(ctx context.Context, token string) (string, []string, error) {
+	unverified, err := readUnverified(token)
+	issuer, exists := v.issuers[unverified.Issuer]
+	if !exists && v.introspector != nil {
+		return v.introspector.identity(ctx, token)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read token: %w", err)
+	}
+	if !exists {
+		return "", nil, fmt.Errorf("token issuer %s is not trusted", unverified.Issuer)
+	}
+	validator, exists := issuer.validators[unverified.Algorithm]
+	if !exists {
+		return "", nil, fmt.Errorf("token signing algorithm %s is not allowed", unverified.Algorithm)
+	}
+	claims, err := validator.ValidateToken(ctx, token)
+	if err != nil {
+		return "", nil, err
+	}
+	validated, ok := claims.(*jwtv.ValidatedClaims)
+	if !ok {
+		return "", nil, errors.New("failed to assert validated claims type")
+	}
+	mapped, ok := validated.CustomClaims.(*mappedClaims)
+	if !ok {
+		return "", nil, errors.New("failed to assert mapped claims type")
+	}
+	return mapped.identity(issuer.claims)
+}
+
+// Save authenticated user ID (i.e. sub) to cross-check against the
+// user-id argument (if any) of the API request, and roles if mapped
+func setIdentity(ctx *gin.Context, userId string, roles []string) {
+	ctx.Set("sub", userId)
+	if roles != nil {
+		ctx.Set("roles", roles)
+	}
+}
+
+func newBearerIssuer(cfg config.BearerIssuerConfig) (*bearerIssuer, error) {
+	issuerURL, err := url.Parse(cfg.IssuerURL) // Issuer url has already been validated
+	if err != nil {
+		return nil, err
+	}
+	provider := jwks.NewCachingProvider(issuerURL, tokenCacheDuration)
+	issuer := &bearerIssuer{validators: map[string]*jwtv.Validator{}, claims: cfg.Claims}
+	for _, algorithm := range cfg.Algorithms {
+		validator, err := jwtv.New(
+			provider.KeyFunc,
+			jwtv.SignatureAlgorithm(algorithm),
+			cfg.IssuerURL,
+			[]string{cfg.Audience},
+			jwtv.WithAllowedClockSkew(cfg.Leeway),
+			jwtv.WithCustomClaims(func() jwtv.CustomClaims { return &mappedClaims{} }),
+		)
+		if err != nil {
+			return nil, err
+		}
+		issuer.validators[algorithm] = validator
+	}
+	return issuer, nil
+}
+
+// All the claims of a token, so that any of them can be mapped
+type mappedClaims map[string]any
+
+func (c *mappedClaims) Validate(context.Context) error {
+	return nil
+}
+
+// User ID and roles, if mapped, from the claims of a validated token
+func (c mappedClaims) identity(cfg config.BearerClaimsConfig) (string, []string, error) {
+	userId, ok := c.at(cfg.UserId).(string)
+	if !ok || userId == "" {
+		return "", nil, fmt.Errorf("claim %s is not a string", cfg.UserId)
+	}
+	if cfg.Roles == "" {
+		return userId, nil, nil
+	}
+	switch value := c.at(cfg.Roles).(type) {
+	case nil:
+		return userId, []string{}, nil
+	case string:
+		return userId, strings.Fields(value), nil
+	case []any:
+		roles := []string{}
+		for _, role := range value {
+			role, ok := role.(string)
+			if !ok {
+				return "", nil, fmt.Errorf("claim %s is not an array of strings", cfg.Roles)
+			}
+			roles = append(roles, role)
+		}
+		return userId, roles, nil
+	}
+	return "", nil, fmt.Errorf("claim %s is not a string or an array of strings", cfg.Roles)
+}
+
+// Claim with the name or, if there is none, at the path of names
+// separated by dots
+func (c mappedClaims) at(path string) any {
+	if value, exists := c[path]; exists {
+		return value
+	}
+	var value any = map[string]any(c)
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
 		if !ok {
-			fail(ctx, []string{"Bearer"}, "could not read token claims")
-			log.Error().Msg("failed to assert validated claims type")
-			return
+			return nil
 		}
-		// Save authenticated user ID (i.e. sub) to cross-check against
-		// the user-id argument (if any) of the API request
-		ctx.Set("sub", validated.RegisteredClaims.Subject)
+		value = object[name]
+	}
+	return value
+}
+
+// Signing algorithm and issuer of a token, read without verifying it so
+// that it can be dispatched to the validator of its issuer
+type unverifiedToken struct {
+	Algorithm string
+	Issuer    string
+}
+
+func readUnverified(token string) (unverifiedToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return unverifiedToken{}, errors.New("token is not a compact JWS")
+	}
+	header := struct {
+		Algorithm string `json:"alg"`
+	}{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return unverifiedToken{}, fmt.Errorf("failed to decode token header: %w", err)
+	}
+	payload := struct {
+		Issuer string `json:"iss"`
+	}{}
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return unverifiedToken{}, fmt.Errorf("failed to decode token payload: %w", err)
+	}
+	return unverifiedToken{Algorithm: header.Algorithm, Issuer: payload.Issuer}, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	content, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
 	}
+	return json.Unmarshal(content, v)
 }
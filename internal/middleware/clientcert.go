@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"crypto/x509"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/config"
+)
+
+// Closure for authenticating a client certificate verified in the TLS
+// handshake, by mapping its subject or a SAN to a configured identity
+func clientCertAuthenticator() authFunction {
+	cfg := config.ClientCertAuthConfig()
+	if len(cfg.Identities) == 0 {
+		log.Info().Msg("Client certificate auth not configured")
+		return nil
+	}
+
+	return func(ctx *gin.Context) {
+		if !hasVerifiedClientCert(ctx.Request) {
+			fail(ctx, []string{}, "no verified client certificate")
+			return
+		}
+		cert := ctx.Request.TLS.VerifiedChains[0][0]
+		identity, ok := clientCertIdentity(cfg.Identities, cert)
+		if !ok {
+			fail(ctx, []string{}, "client certificate not recognised")
+			log.Error().Str("subject", cert.Subject.String()).Msg("failed to map client certificate to an identity")
+			return
+		}
+		// Save authenticated user ID as the sub, as for Bearer auth, to
+		// cross-check against the user-id argument (if any) of the API request
+		ctx.Set("sub", identity.UserId)
+		ctx.Set("roles", identity.Roles)
+		setAuthMethod(ctx, authMethodClientCert, "")
+	}
+}
+
+func hasVerifiedClientCert(req *http.Request) bool {
+	return req.TLS != nil && len(req.TLS.VerifiedChains) > 0 && len(req.TLS.VerifiedChains[0]) > 0
+}
+
+// First identity with the subject of the certificate, or one of its SANs
+func clientCertIdentity(identities []config.ClientCertIdentityConfig, cert *x509.Certificate) (config.ClientCertIdentityConfig, bool) {
+	sans := append([]string{}, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	for _, identity := range identities {
+		if identity.Subject != "" && identity.Subject == cert.Subject.String() {
+			return identity, true
+		}
+		if identity.SAN != "" && slices.Contains(sans, identity.SAN) {
+			return identity, true
+		}
+	}
+	return config.ClientCertIdentityConfig{}, false
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/config"
+)
+
+const (
+	onBehalfOfHeader      = "X-On-Behalf-Of"       // User ID asserted by a trusted delegator
+	onBehalfOfTokenHeader = "X-On-Behalf-Of-Token" // Bearer token of the user
+)
+
+// Requests of trusted services on behalf of users, who are then the
+// identity of the request with the service recorded as its delegator
+type delegation struct {
+	delegators []config.DelegatorConfig
+	bearer     *bearerValidator // nil if user tokens cannot be validated
+}
+
+// Nil if no delegators are configured, in which case no identity may
+// act on behalf of users
+func newDelegation(cfg config.DelegationConfigBundle, bearer *bearerValidator) *delegation {
+	if len(cfg.Delegators) == 0 {
+		log.Info().Msg("Delegation not configured")
+		return nil
+	}
+	return &delegation{delegators: cfg.Delegators, bearer: bearer}
+}
+
+// Make the user given by the X-On-Behalf-Of or X-On-Behalf-Of-Token header
+// the identity of an authenticated request, if it was made by a trusted
+// delegator. Without a header the identity is unchanged, except that the
+// Basic auth username becomes the identity so that, as for other
+// identities, it can act only for itself
+func (d *delegation) apply(ctx *gin.Context) {
+	userId := ctx.GetHeader(onBehalfOfHeader)
+	token := ctx.GetHeader(onBehalfOfTokenHeader)
+	identity := identityOf(ctx)
+	if userId == "" && token == "" {
+		if _, exists := ctx.Get("sub"); d != nil && !exists && identity != "" {
+			setIdentity(ctx, identity, nil)
+		}
+		return
+	}
+	if d == nil || identity == "" || !d.isDelegator(ctx, identity) {
+		forbid(ctx, "not a trusted delegator")
+		log.Error().Str("identity", identity).Str("method", ctx.GetString("auth_method")).
+			Msg("untrusted identity attempted to act on behalf of a user")
+		return
+	}
+
+	roles := []string{} // Roles of the delegator are not those of the user
+	if token != "" {
+		if d.bearer == nil {
+			forbid(ctx, "user tokens cannot be validated")
+			return
+		}
+		tokenUserId, tokenRoles, err := d.bearer.identity(ctx.Request.Context(), token)
+		if err != nil {
+			fail(ctx, []string{"Bearer"}, "could not validate user token")
+			log.Error().Err(err).Str("delegator", identity).Msg("failed to validate on behalf of token")
+			return
+		}
+		if userId != "" && userId != tokenUserId {
+			forbid(ctx, onBehalfOfHeader+" differs from the user token")
+			return
+		}
+		userId = tokenUserId
+		if tokenRoles != nil {
+			roles = tokenRoles
+		}
+	}
+	ctx.Set("delegator", identity)
+	setIdentity(ctx, userId, roles)
+}
+
+// Whether the identity, with the method and issuer by which it was
+// authenticated, is that of a delegator
+func (d *delegation) isDelegator(ctx *gin.Context, identity string) bool {
+	return slices.Contains(d.delegators, config.DelegatorConfig{
+		Method:   ctx.GetString("auth_method"),
+		Issuer:   ctx.GetString("auth_issuer"),
+		Identity: identity,
+	})
+}
+
+// How the request was authenticated, so that a delegator is matched only
+// when authenticated by its own method and issuer
+func setAuthMethod(ctx *gin.Context, method string, issuer string) {
+	ctx.Set("auth_method", method)
+	ctx.Set("auth_issuer", issuer)
+}
+
+// Identity of an authenticated request: the user ID (i.e. sub), or the
+// Basic auth username, or "" for an API key
+func identityOf(ctx *gin.Context) string {
+	if sub := ctx.GetString("sub"); sub != "" {
+		return sub
+	}
+	return ctx.GetString("basic_username")
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Hash of a password in an htpasswd file
+type passwordHash interface {
+	matches(password string) bool
+}
+
+// Hashes of the users in an htpasswd file, i.e. lines of "user:hash".
+// Hashes must be bcrypt ($2a$, $2b$ or $2y$) or argon2 in the PHC format
+// ($argon2id$ or $argon2i$). Blank lines and comments (#) are skipped
+func parseHtpasswd(r io.Reader) (map[string]passwordHash, error) {
+	users := map[string]passwordHash{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		username, encoded, found := strings.Cut(text, ":")
+		if !found || username == "" {
+			return nil, fmt.Errorf("line %d is not of the form user:hash", line)
+		}
+		if _, exists := users[username]; exists {
+			return nil, fmt.Errorf("user on line %d is duplicated", line)
+		}
+		hash, err := parsePasswordHash(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("hash on line %d is invalid: %w", line, err)
+		}
+		users[username] = hash
+	}
+	return users, scanner.Err()
+}
+
+func parsePasswordHash(encoded string) (passwordHash, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		if _, err := bcrypt.Cost([]byte(encoded)); err != nil {
+			return nil, err
+		}
+		return bcryptHash(encoded), nil
+	case strings.HasPrefix(encoded, "$argon2id$"), strings.HasPrefix(encoded, "$argon2i$"):
+		return parseArgon2Hash(encoded)
+	}
+	return nil, errors.New("only bcrypt and argon2 hashes are supported")
+}
+
+type bcryptHash string
+
+func (h bcryptHash) matches(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(h), []byte(password)) == nil
+}
+
+type argon2Hash struct {
+	variant string // argon2id or argon2i
+	memory  uint32 // KiB
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+// Parse e.g. "$argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>", where the salt
+// and key are base64 encoded without padding
+func parseArgon2Hash(encoded string) (argon2Hash, error) {
+	h := argon2Hash{}
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return h, errors.New("argon2 hash must have a variant, version, parameters, salt and key")
+	}
+	h.variant = parts[1]
+	version := 0
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return h, fmt.Errorf("argon2 version must be %d", argon2.Version)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.memory, &h.time, &h.threads); err != nil {
+		return h, fmt.Errorf("failed to parse argon2 parameters: %w", err)
+	}
+	if h.time == 0 || h.threads == 0 {
+		return h, errors.New("argon2 time and parallelism must be positive")
+	}
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return h, fmt.Errorf("failed to decode argon2 salt: %w", err)
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(h.key) == 0 {
+		return h, errors.New("failed to decode argon2 key")
+	}
+	return h, nil
+}
+
+func (h argon2Hash) matches(password string) bool {
+	var key []byte
+	if h.variant == "argon2id" {
+		key = argon2.IDKey([]byte(password), h.salt, h.time, h.memory, h.threads, uint32(len(h.key)))
+	} else {
+		key = argon2.Key([]byte(password), h.salt, h.time, h.memory, h.threads, uint32(len(h.key)))
+	}
+	return subtle.ConstantTimeCompare(key, h.key) == 1
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/cache"
+	"github.com/ucl-arc-tre/egress/internal/config"
+)
+
+const (
+	introspectionTimeout = 10 * time.Second
+	maxIntrospectionSize = 1 << 20 // 1MiB
+	maxIntrospections    = 10000   // Cached at once
+)
+
+// Validates bearer tokens by RFC 7662 introspection. The response for an
+// active token is cached for the cache duration, or until the token
+// expires if sooner. Inactive and unknown tokens are not cached, so that
+// they cannot fill the cache
+type introspector struct {
+	cfg    config.IntrospectionAuthConfigBundle
+	client *http.Client
+	cache  *cache.FIFO[[sha256.Size]byte, introspection] // Keyed by the hash of the token so tokens are not kept
+}
+
+// Cached response of the introspection endpoint for an active token
+type introspection struct {
+	claims  mappedClaims
+	expires time.Time
+}
+
+func newIntrospector(cfg config.IntrospectionAuthConfigBundle) *introspector {
+	if cfg.URL == "" {
+		log.Info().Msg("Token introspection not configured")
+		return nil
+	}
+	return &introspector{
+		cfg:    cfg,
+		client: &http.Client{Timeout: introspectionTimeout},
+		cache:  cache.NewFIFO[[sha256.Size]byte, introspection](maxIntrospections),
+	}
+}
+
+// User ID and roles, if mapped, of an active token
+func (i *introspector) identity(ctx context.Context, token string) (string, []string, error) {
+	claims, err := i.introspect(ctx, token)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to introspect token: %w", err)
+	}
+	if claims == nil {
+		return "", nil, errors.New("token is not active")
+	}
+	return claims.identity(i.cfg.Claims)
+}
+
+// Claims of the token if it is active, otherwise nil
+func (i *introspector) introspect(ctx context.Context, token string) (mappedClaims, error) {
+	key := sha256.Sum256([]byte(token))
+	now := time.Now()
+	cached, exists := i.cache.Get(key)
+	if exists && now.Before(cached.expires) {
+		return cached.claims, nil
+	}
+
+	claims, err := i.request(ctx, token)
+	if err != nil {
+		return nil, err // Not cached so that the endpoint is retried
+	}
+	expires := now.Add(i.cfg.CacheDuration)
+	if claims != nil {
+		if exp, ok := claims["exp"].(float64); ok {
+			tokenExpires := time.Unix(int64(exp), 0)
+			if !now.Before(tokenExpires) {
+				claims = nil
+			} else if tokenExpires.Before(expires) {
+				expires = tokenExpires
+			}
+		}
+		if claims != nil && !i.hasAudience(claims) {
+			log.Error().Str("audience", i.cfg.Audience).Msg("introspected token is not for the audience")
+			claims = nil
+		}
+	}
+	if claims != nil && i.cfg.CacheDuration > 0 {
+		i.cache.Put(key, introspection{claims: claims, expires: expires})
+	}
+	return claims, nil
+}
+
+// POST the token to the introspection endpoint, authenticated with the
+// client credentials (if any)
+func (i *introspector) request(ctx context.Context, token string) (mappedClaims, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.cfg.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.cfg.ClientId != "" {
+		// Client credentials are form encoded, as in RFC 6749 section 2.3.1
+		req.SetBasicAuth(url.QueryEscape(i.cfg.ClientId), url.QueryEscape(i.cfg.ClientSecret))
+	}
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call introspection endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint responded with %s", resp.Status)
+	}
+	claims := mappedClaims{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxIntrospectionSize)).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+	if active, _ := claims["active"].(bool); !active {
+		return nil, nil
+	}
+	return claims, nil
+}
+
+// Whether the aud of the claims, a string or an array of strings,
+// includes the audience (if configured)
+func (i *introspector) hasAudience(claims mappedClaims) bool {
+	if i.cfg.Audience == "" {
+		return true
+	}
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == i.cfg.Audience
+	case []any:
+		return slices.Contains(aud, any(i.cfg.Audience))
+	}
+	return false
+}
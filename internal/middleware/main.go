@@ -2,34 +2,54 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ucl-arc-tre/egress/internal/config"
 	"github.com/ucl-arc-tre/egress/internal/openapi"
 )
 
 type authFunction func(*gin.Context)
 
-func All() []openapi.MiddlewareFunc {
+// Methods by which a delegator may authenticate
+const (
+	authMethodBasic      = "basic"
+	authMethodBearer     = "bearer"
+	authMethodClientCert = "client_cert"
+)
+
+func All(apiKeys ApiKeyStore) []openapi.MiddlewareFunc {
 	return []openapi.MiddlewareFunc{
-		authMiddleware(),
+		authMiddleware(apiKeys),
 		swaggerMiddleware(),
 	}
 }
 
-func authMiddleware() openapi.MiddlewareFunc {
+func authMiddleware(apiKeys ApiKeyStore) openapi.MiddlewareFunc {
+	apiKeyAuth := apiKeyAuthenticator(apiKeys)
 	basicAuth := basicAuthenticator()
-	bearerAuth := bearerAuthenticator()
+	bearer := newBearerValidator()
+	bearerAuth := bearer.authenticator()
+	clientCertAuth := clientCertAuthenticator()
+	delegation := newDelegation(config.DelegationConfig(), bearer)
+	adminRole := config.AdminRole()
 
 	return func(ctx *gin.Context) {
 		var auth authFunction
 		authHeader := ctx.GetHeader("Authorization")
-		if strings.HasPrefix(authHeader, "Basic ") {
+		if hasApiKey(ctx.Request) {
+			auth = apiKeyAuth
+		} else if strings.HasPrefix(authHeader, "Basic ") {
 			auth = basicAuth
 		} else if strings.HasPrefix(authHeader, "Bearer ") {
 			auth = bearerAuth
+		} else if clientCertAuth != nil && hasVerifiedClientCert(ctx.Request) {
+			// A client certificate authenticates only requests without
+			// an Authorization header
+			auth = clientCertAuth
 		} else {
-			fail(ctx, []string{"Basic", "Bearer"}, "authentication required")
+			fail(ctx, []string{"Basic", "Bearer", "ApiKey"}, "authentication required")
 			return
 		}
 		if auth == nil {
@@ -37,9 +57,26 @@ func authMiddleware() openapi.MiddlewareFunc {
 			return
 		}
 		auth(ctx)
+		if !ctx.IsAborted() {
+			delegation.apply(ctx)
+		}
+		if !ctx.IsAborted() && requiresAdmin(ctx) && !hasRole(ctx, adminRole) {
+			forbid(ctx, "admin role required")
+		}
 	}
 }
 
+// Whether the operation requires the admin scope of bearer auth
+func requiresAdmin(ctx *gin.Context) bool {
+	scopes, exists := ctx.Get(string(openapi.BearerAuthScopes))
+	return exists && slices.Contains(scopes.([]string), "admin")
+}
+
+func hasRole(ctx *gin.Context, role string) bool {
+	roles, exists := ctx.Get("roles")
+	return exists && slices.Contains(roles.([]string), role)
+}
+
 func fail(ctx *gin.Context, schemes []string, message string) {
 	for _, s := range schemes {
 		ctx.Writer.Header().Add("WWW-Authenticate", s+` realm="egress"`)
@@ -49,3 +86,10 @@ func fail(ctx *gin.Context, schemes []string, message string) {
 	})
 	ctx.Abort()
 }
+
+func forbid(ctx *gin.Context, message string) {
+	ctx.JSON(http.StatusForbidden, openapi.Forbidden{
+		Message: "Forbidden; " + message,
+	})
+	ctx.Abort()
+}
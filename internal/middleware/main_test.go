@@ -1,13 +1,23 @@
 package middleware
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,6 +28,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/openapi"
+	"github.com/ucl-arc-tre/egress/internal/types"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -81,6 +95,117 @@ auth:
 	assert.Equal(t, http.StatusUnauthorized, rec.Code)
 }
 
+func TestBasicAuthHtpasswd(t *testing.T) {
+	path := writeHtpasswd(t, "", "# Users\nalice:"+bcryptOf(t, "alice-password")+"\nbob:"+argon2idOf("bob-password")+"\n")
+	initConfig(t, `
+auth:
+  basic:
+    username: "`+username+`"
+    password: "`+password+`"
+    htpasswd_file: "`+path+`"
+`)
+	basic := basicAuthenticator()
+	require.NotNil(t, basic)
+	authenticate := func(username string, password string) (int, string, bool) {
+		ctx, rec, _ := contextAndRecorder(t)
+		ctx.Request.SetBasicAuth(username, password)
+		basic(ctx)
+		sub, exists := ctx.Get("sub")
+		subStr, _ := sub.(string)
+		return rec.Code, subStr, exists
+	}
+
+	code, sub, _ := authenticate("alice", "alice-password")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "alice", sub)
+
+	code, sub, _ = authenticate("bob", "bob-password")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "bob", sub)
+
+	code, _, _ = authenticate("bob", "alice-password")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _, _ = authenticate("carol", "alice-password")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// The configured username and password set no identity
+	code, _, exists := authenticate(username, password)
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, exists)
+
+	// Reloaded when changed, keeping the users if the file is invalid
+	writeHtpasswd(t, path, "carol:"+bcryptOf(t, "carol-password")+"\n")
+	code, sub, _ = authenticate("carol", "carol-password")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "carol", sub)
+	code, _, _ = authenticate("alice", "alice-password")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	writeHtpasswd(t, path, "dave:plaintext\n")
+	code, _, _ = authenticate("carol", "carol-password")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestBasicAuthHtpasswdInvalid(t *testing.T) {
+	for _, content := range []string{
+		"alice",
+		"alice:plaintext",
+		"alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=",
+		"alice:$apr1$salt$hash",
+		"alice:$2y$05$invalid",
+		"alice:$argon2id$v=19$m=65536,t=3,p=4$salt",
+		"alice:$argon2id$v=16$m=65536,t=3,p=4$c2FsdA$a2V5",
+		"alice:" + argon2idOf("a") + "\nalice:" + argon2idOf("b"),
+	} {
+		_, err := parseHtpasswd(strings.NewReader(content))
+		assert.Error(t, err, content)
+	}
+
+	initConfig(t, `
+auth:
+  basic:
+    htpasswd_file: "`+filepath.Join(t.TempDir(), "missing")+`"
+`)
+	assert.Nil(t, basicAuthenticator())
+}
+
+func TestBasicAuthLockout(t *testing.T) {
+	path := writeHtpasswd(t, "", "alice:"+bcryptOf(t, "alice-password")+"\n")
+	initConfig(t, `
+auth:
+  basic:
+    username: "`+username+`"
+    password: "`+password+`"
+    htpasswd_file: "`+path+`"
+    lockout:
+      max_failures: 2
+      duration: 200ms
+`)
+	basic := basicAuthenticator()
+	require.NotNil(t, basic)
+	authenticate := func(username string, password string) int {
+		ctx, rec, _ := contextAndRecorder(t)
+		ctx.Request.SetBasicAuth(username, password)
+		basic(ctx)
+		return rec.Code
+	}
+
+	// A success resets the failures
+	assert.Equal(t, http.StatusUnauthorized, authenticate("alice", "wrong"))
+	assert.Equal(t, http.StatusOK, authenticate("alice", "alice-password"))
+	assert.Equal(t, http.StatusUnauthorized, authenticate("alice", "wrong"))
+	assert.Equal(t, http.StatusOK, authenticate("alice", "alice-password"))
+
+	assert.Equal(t, http.StatusUnauthorized, authenticate("alice", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, authenticate("alice", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, authenticate("alice", "alice-password"))
+	assert.Equal(t, http.StatusOK, authenticate(username, password)) // Other users are not locked out
+
+	time.Sleep(250 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, authenticate("alice", "alice-password"))
+}
+
 func TestBearerAuthValidToken(t *testing.T) {
 	as, key := newAuthServer(t)
 	issuer := as.URL
@@ -153,7 +278,7 @@ auth:
     username: "`+username+`"
     password: "`+password+`"
 `)
-	auth := authMiddleware()
+	auth := authMiddleware(nil)
 
 	var authedUserId string
 	ctx, rec, router := contextAndRecorder(t)
@@ -176,7 +301,7 @@ auth:
     username: "`+username+`"
     password: "`+password+`"
 `)
-	auth := authMiddleware()
+	auth := authMiddleware(nil)
 
 	ctx, rec, router := contextAndRecorder(t)
 	router.Use(gin.HandlerFunc(auth))
@@ -200,7 +325,7 @@ auth:
     username: ""
     password: ""
 `)
-	auth := authMiddleware()
+	auth := authMiddleware(nil)
 
 	ctx, rec, router := contextAndRecorder(t)
 	router.Use(gin.HandlerFunc(auth))
@@ -223,7 +348,7 @@ auth:
     issuer_url: "`+issuer+`"
     audience: "`+audience+`"
 `)
-	auth := authMiddleware()
+	auth := authMiddleware(nil)
 
 	var authedUserId string
 	ctx, rec, router := contextAndRecorder(t)
@@ -250,7 +375,7 @@ auth:
     issuer_url: "`+issuer+`"
     audience: "`+audience+`"
 `)
-	auth := authMiddleware()
+	auth := authMiddleware(nil)
 
 	var authedUserId string
 	ctx, rec, router := contextAndRecorder(t)
@@ -280,7 +405,7 @@ auth:
     issuer_url: "`+issuer+`"
     audience: "not-egress"
 `)
-	auth := authMiddleware()
+	auth := authMiddleware(nil)
 
 	ctx, rec, router := contextAndRecorder(t)
 	router.Use(gin.HandlerFunc(auth))
@@ -301,7 +426,7 @@ auth:
     username: "`+username+`"
     password: "`+password+`"
 `)
-	auth := authMiddleware()
+	auth := authMiddleware(nil)
 
 	ctx, rec, router := contextAndRecorder(t)
 	router.Use(gin.HandlerFunc(auth))
@@ -312,10 +437,528 @@ auth:
 
 	assert.Equal(t, http.StatusUnauthorized, rec.Code)
 
-	// When no auth header, both Basic and Bearer schemes must be advertised
+	// When no auth header, all schemes must be advertised
 	wwwAuth := rec.Result().Header.Values("WWW-Authenticate")
 	assert.Contains(t, wwwAuth, `Basic realm="egress"`)
 	assert.Contains(t, wwwAuth, `Bearer realm="egress"`)
+	assert.Contains(t, wwwAuth, `ApiKey realm="egress"`)
+}
+
+func TestBearerAuthMultipleIssuers(t *testing.T) {
+	rsaServer, rsaKey := newAuthServer(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecServer, ecJWK := newAuthServerWithKey(t, ecKey, ecKey.Public(), jwa.ES256())
+
+	initConfig(t, `
+auth:
+  bearer:
+    issuers:
+      - issuer_url: "`+rsaServer.URL+`"
+        audience: "`+audience+`"
+      - issuer_url: "`+ecServer.URL+`"
+        audience: "egress-api"
+        algorithms: [ES256]
+        leeway: 30s
+        claims:
+          user_id: preferred_username
+          roles: realm_access.roles
+`)
+	bearer := bearerAuthenticator()
+	require.NotNil(t, bearer)
+	authenticate := func(token string) (*gin.Context, int) {
+		ctx, rec, _ := contextAndRecorder(t)
+		ctx.Request.Header.Set("Authorization", "Bearer "+token)
+		bearer(ctx)
+		return ctx, rec.Code
+	}
+
+	ctx, code := authenticate(signToken(t, rsaKey, rsaServer.URL, audience, username))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, username, ctx.GetString("sub"))
+	_, exists := ctx.Get("roles")
+	assert.False(t, exists) // Roles are not mapped
+
+	ctx, code = authenticate(signTokenWithClaims(t, ecJWK, jwa.ES256(), ecServer.URL, "egress-api", map[string]any{
+		"sub":                "a1b2c3",
+		"preferred_username": "checker1",
+		"realm_access":       map[string]any{"roles": []string{"checker"}},
+	}))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "checker1", ctx.GetString("sub"))
+	assert.Equal(t, []string{"checker"}, ctx.GetStringSlice("roles"))
+
+	// Each issuer has its own audience
+	_, code = authenticate(signTokenWithClaims(t, ecJWK, jwa.ES256(), ecServer.URL, audience, map[string]any{"preferred_username": "checker1"}))
+	assert.Equal(t, http.StatusUnauthorized, code)
+	// A token of an issuer that is not trusted
+	_, code = authenticate(signToken(t, rsaKey, "http://untrusted.local", audience, username))
+	assert.Equal(t, http.StatusUnauthorized, code)
+	// An algorithm that is not allowed for the issuer
+	_, code = authenticate(signToken(t, rsaKey, ecServer.URL, "egress-api", username))
+	assert.Equal(t, http.StatusUnauthorized, code)
+	// Without the claim mapped to the user ID
+	_, code = authenticate(signTokenWithClaims(t, ecJWK, jwa.ES256(), ecServer.URL, "egress-api", map[string]any{"sub": "a1b2c3"}))
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestMappedClaims(t *testing.T) {
+	claims := mappedClaims{
+		"https://example.com/roles": []any{"a"},
+		"realm_access":              map[string]any{"roles": []any{"b"}},
+	}
+	assert.Equal(t, []any{"a"}, claims.at("https://example.com/roles"))
+	assert.Equal(t, []any{"b"}, claims.at("realm_access.roles"))
+	assert.Nil(t, claims.at("realm_access.roles.x"))
+	assert.Nil(t, claims.at("missing"))
+
+	cfg := config.BearerClaimsConfig{UserId: "sub", Roles: "scope"}
+	userId, roles, err := mappedClaims{"sub": "u1", "scope": "read write"}.identity(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", userId)
+	assert.Equal(t, []string{"read", "write"}, roles)
+	_, _, err = mappedClaims{"sub": "u1", "scope": 1.0}.identity(cfg)
+	assert.Error(t, err)
+}
+
+func TestBearerAuthIntrospection(t *testing.T) {
+	is, calls := newIntrospectionServer(t, map[string]map[string]any{
+		"opaque1":  {"active": true, "sub": "u1", "aud": []any{"egress"}, "scope": "checker"},
+		"opaque2":  {"active": true, "sub": "u2", "aud": "other"},
+		"expired":  {"active": true, "sub": "u3", "exp": time.Now().Add(-time.Minute).Unix()},
+		"inactive": {"active": false},
+	})
+	initConfig(t, `
+auth:
+  introspection:
+    url: "`+is.URL+`"
+    client_id: egress
+    client_secret: secret
+    audience: egress
+    claims:
+      roles: scope
+`)
+	bearer := bearerAuthenticator()
+	require.NotNil(t, bearer)
+
+	authenticate := func(token string) (*gin.Context, int) {
+		ctx, rec, _ := contextAndRecorder(t)
+		ctx.Request.Header.Set("Authorization", "Bearer "+token)
+		bearer(ctx)
+		return ctx, rec.Code
+	}
+
+	ctx, code := authenticate("opaque1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "u1", ctx.GetString("sub"))
+	assert.Equal(t, []string{"checker"}, ctx.GetStringSlice("roles"))
+	_, code = authenticate("opaque1") // Cached
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int32(1), calls.Load())
+
+	for _, token := range []string{"opaque2", "expired", "inactive", "unknown"} {
+		ctx, code = authenticate(token)
+		assert.Equal(t, http.StatusUnauthorized, code, token)
+		assert.Equal(t, "", ctx.GetString("sub"))
+	}
+	// Tokens that are not active are not cached
+	_, code = authenticate("inactive")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+func TestBearerAuthIntrospectionUnavailable(t *testing.T) {
+	is, _ := newIntrospectionServer(t, map[string]map[string]any{})
+	initConfig(t, `
+auth:
+  introspection:
+    url: "`+is.URL+`"
+    client_id: not-egress
+`)
+	ctx, rec, _ := contextAndRecorder(t)
+	ctx.Request.Header.Set("Authorization", "Bearer opaque1")
+
+	bearer := bearerAuthenticator()
+	require.NotNil(t, bearer)
+	bearer(ctx)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestMiddlewareBearerJWKSAndIntrospection(t *testing.T) {
+	as, key := newAuthServer(t)
+	is, calls := newIntrospectionServer(t, map[string]map[string]any{
+		"opaque1": {"active": true, "sub": "u1"},
+	})
+	initConfig(t, `
+auth:
+  bearer:
+    issuer_url: "`+as.URL+`"
+    audience: "`+audience+`"
+  introspection:
+    url: "`+is.URL+`"
+    client_id: egress
+    client_secret: secret
+`)
+	router := gin.New()
+	router.Use(gin.HandlerFunc(authMiddleware(nil)))
+	router.GET("/", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.GetString("sub"))
+	})
+	get := func(token string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get(signToken(t, key, as.URL, audience, username))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, username, rec.Body.String())
+	assert.Equal(t, int32(0), calls.Load())
+
+	rec = get("opaque1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "u1", rec.Body.String())
+	assert.Equal(t, int32(1), calls.Load())
+
+	// A JWT of a trusted issuer is not introspected if it is invalid
+	rec = get(signToken(t, key, as.URL, "wrong-audience", username))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClientCertAuth(t *testing.T) {
+	initConfig(t, `
+auth:
+  client_cert:
+    identities:
+      - subject: "CN=portal,O=UCL"
+        user_id: portal
+        roles: [checker]
+      - san: "spiffe://tre/airlock"
+        user_id: airlock
+`)
+	clientCert := clientCertAuthenticator()
+	require.NotNil(t, clientCert)
+
+	ctx, rec, _ := contextAndRecorder(t)
+	ctx.Request.TLS = verifiedTLS(&x509.Certificate{Subject: pkix.Name{CommonName: "portal", Organization: []string{"UCL"}}})
+	clientCert(ctx)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "portal", ctx.GetString("sub"))
+	assert.Equal(t, []string{"checker"}, ctx.GetStringSlice("roles"))
+
+	ctx, rec, _ = contextAndRecorder(t)
+	airlock, _ := url.Parse("spiffe://tre/airlock")
+	ctx.Request.TLS = verifiedTLS(&x509.Certificate{Subject: pkix.Name{CommonName: "x"}, URIs: []*url.URL{airlock}})
+	clientCert(ctx)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "airlock", ctx.GetString("sub"))
+
+	ctx, rec, _ = contextAndRecorder(t)
+	ctx.Request.TLS = verifiedTLS(&x509.Certificate{Subject: pkix.Name{CommonName: "unknown"}})
+	clientCert(ctx)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "", ctx.GetString("sub"))
+}
+
+func TestMiddlewareClientCertAuth(t *testing.T) {
+	initConfig(t, `
+auth:
+  basic:
+    username: "`+username+`"
+    password: "`+password+`"
+  client_cert:
+    identities:
+      - subject: "CN=portal"
+        user_id: portal
+`)
+	auth := authMiddleware(nil)
+
+	var authedUserId string
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	router.Use(gin.HandlerFunc(auth))
+	router.GET("/", func(c *gin.Context) {
+		authedUserId = c.GetString("sub")
+		c.String(http.StatusOK, "Ok")
+	})
+	serve := func(req *http.Request) int {
+		authedUserId = ""
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = verifiedTLS(&x509.Certificate{Subject: pkix.Name{CommonName: "portal"}})
+	assert.Equal(t, http.StatusOK, serve(req))
+	assert.Equal(t, "portal", authedUserId)
+
+	// An Authorization header takes precedence over the certificate
+	req.SetBasicAuth(username, password)
+	assert.Equal(t, http.StatusOK, serve(req))
+	assert.Equal(t, "", authedUserId)
+
+	req, _ = http.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{} // Without a verified certificate
+	assert.Equal(t, http.StatusUnauthorized, serve(req))
+}
+
+func TestApiKeyAuth(t *testing.T) {
+	keys := apiKeyStore{}
+	key, value := keys.add(types.NewApiKey("ci", []types.ProjectId{"p1"}, []types.ApiKeyScope{types.ApiKeyScopeRead}, time.Time{}))
+	revoked, revokedValue := keys.add(types.NewApiKey("old", []types.ProjectId{"p1"}, []types.ApiKeyScope{types.ApiKeyScopeRead}, time.Time{}))
+	revoked.Revoked = time.Now()
+	keys[revoked.Id] = revoked
+
+	auth := apiKeyAuthenticator(keys)
+	require.NotNil(t, auth)
+	authenticate := func(projectId string, scopes []string, header string, value string) (int, string) {
+		ctx, rec, _ := contextAndRecorder(t)
+		ctx.Params = gin.Params{{Key: "project-id", Value: projectId}}
+		if scopes != nil {
+			ctx.Set(string(openapi.ApiKeyAuthScopes), scopes)
+		}
+		ctx.Request.Header.Set(header, value)
+		auth(ctx)
+		return rec.Code, ctx.GetString("api_key_id")
+	}
+
+	code, apiKeyId := authenticate("p1", []string{"read"}, "X-API-Key", value)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, string(key.Id), apiKeyId)
+
+	code, apiKeyId = authenticate("p1", []string{"read"}, "Authorization", "ApiKey "+value)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, string(key.Id), apiKeyId)
+
+	code, _ = authenticate("p1", []string{"read"}, "X-API-Key", string(key.Id)+".wrong")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = authenticate("p1", []string{"read"}, "X-API-Key", "unknown.secret")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = authenticate("p1", []string{"read"}, "X-API-Key", revokedValue)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = authenticate("p2", []string{"read"}, "X-API-Key", value)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = authenticate("p1", []string{"download"}, "X-API-Key", value)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, apiKeyId = authenticate("p1", nil, "X-API-Key", value) // Not an API key operation
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "", apiKeyId)
+}
+
+func TestMiddlewareAdminRole(t *testing.T) {
+	initConfig(t, `
+auth:
+  basic:
+    username: "`+username+`"
+    password: "`+password+`"
+  client_cert:
+    identities:
+      - subject: "CN=portal"
+        user_id: portal
+        roles: [admin]
+      - subject: "CN=reviewer"
+        user_id: reviewer
+`)
+	keys := apiKeyStore{}
+	_, value := keys.add(types.NewApiKey("ci", []types.ProjectId{types.ApiKeyAllProjects}, types.ApiKeyScopes, time.Time{}))
+	auth := authMiddleware(keys)
+
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	router.GET("/api-keys", func(c *gin.Context) {
+		c.Set(string(openapi.BearerAuthScopes), []string{"admin"})
+	}, gin.HandlerFunc(auth), func(c *gin.Context) {
+		c.String(http.StatusOK, "Ok")
+	})
+	serve := func(req *http.Request) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/api-keys", nil)
+	req.TLS = verifiedTLS(&x509.Certificate{Subject: pkix.Name{CommonName: "portal"}})
+	assert.Equal(t, http.StatusOK, serve(req))
+
+	req.TLS = verifiedTLS(&x509.Certificate{Subject: pkix.Name{CommonName: "reviewer"}})
+	assert.Equal(t, http.StatusForbidden, serve(req))
+
+	req, _ = http.NewRequest(http.MethodGet, "/api-keys", nil)
+	req.SetBasicAuth(username, password)
+	assert.Equal(t, http.StatusForbidden, serve(req))
+
+	// An API key cannot manage API keys, whatever its scopes
+	req, _ = http.NewRequest(http.MethodGet, "/api-keys", nil)
+	req.Header.Set("X-API-Key", value)
+	assert.Equal(t, http.StatusForbidden, serve(req))
+}
+
+func TestMiddlewareDelegation(t *testing.T) {
+	as, key := newAuthServer(t)
+	initConfig(t, `
+auth:
+  basic:
+    username: "`+username+`"
+    password: "`+password+`"
+  bearer:
+    issuer_url: "`+as.URL+`"
+    audience: "`+audience+`"
+  client_cert:
+    identities:
+      - subject: "CN=portal"
+        user_id: portal
+        roles: [admin]
+      - subject: "CN=reviewer"
+        user_id: reviewer
+  delegation:
+    delegators:
+      - method: client_cert
+        identity: portal
+      - method: basic
+        identity: "`+username+`"
+      - method: bearer
+        issuer: "`+as.URL+`"
+        identity: service
+`)
+	auth := authMiddleware(nil)
+
+	var sub, delegator string
+	var roles []string
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	router.Use(gin.HandlerFunc(auth))
+	router.GET("/", func(c *gin.Context) {
+		sub, delegator, roles = c.GetString("sub"), c.GetString("delegator"), c.GetStringSlice("roles")
+		c.String(http.StatusOK, "Ok")
+	})
+	serve := func(subject string, headers map[string]string) int {
+		sub, delegator, roles = "", "", nil
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		if subject != "" {
+			req.TLS = verifiedTLS(&x509.Certificate{Subject: pkix.Name{CommonName: subject}})
+		} else {
+			req.SetBasicAuth(username, password)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("portal", map[string]string{"X-On-Behalf-Of": "alice"}))
+	assert.Equal(t, "alice", sub)
+	assert.Equal(t, "portal", delegator)
+	assert.Empty(t, roles) // Not those of the delegator
+
+	assert.Equal(t, http.StatusOK, serve("", map[string]string{"X-On-Behalf-Of": "alice"}))
+	assert.Equal(t, "alice", sub)
+	assert.Equal(t, username, delegator)
+
+	token := signToken(t, key, as.URL, audience, "bob")
+	assert.Equal(t, http.StatusOK, serve("portal", map[string]string{"X-On-Behalf-Of-Token": token}))
+	assert.Equal(t, "bob", sub)
+	assert.Equal(t, "portal", delegator)
+	assert.Equal(t, http.StatusForbidden, serve("portal", map[string]string{"X-On-Behalf-Of-Token": token, "X-On-Behalf-Of": "alice"}))
+	assert.Equal(t, http.StatusUnauthorized, serve("portal", map[string]string{"X-On-Behalf-Of-Token": "bad-token"}))
+
+	serviceToken := signToken(t, key, as.URL, audience, "service")
+	assert.Equal(t, http.StatusOK, serve("", map[string]string{"Authorization": "Bearer " + serviceToken, "X-On-Behalf-Of": "alice"}))
+	assert.Equal(t, "alice", sub)
+	assert.Equal(t, "service", delegator)
+
+	// Untrusted identities cannot act on behalf of users
+	assert.Equal(t, http.StatusForbidden, serve("reviewer", map[string]string{"X-On-Behalf-Of": "alice"}))
+	assert.Equal(t, http.StatusForbidden, serve("reviewer", map[string]string{"X-On-Behalf-Of-Token": token}))
+
+	// Nor can a delegator's identity when authenticated by another method
+	portalToken := signToken(t, key, as.URL, audience, "portal")
+	assert.Equal(t, http.StatusForbidden, serve("", map[string]string{"Authorization": "Bearer " + portalToken, "X-On-Behalf-Of": "alice"}))
+
+	// Without a header, the identity is that authenticated
+	assert.Equal(t, http.StatusOK, serve("portal", nil))
+	assert.Equal(t, "portal", sub)
+	assert.Equal(t, "", delegator)
+	assert.Equal(t, []string{"admin"}, roles)
+	assert.Equal(t, http.StatusOK, serve("", nil))
+	assert.Equal(t, username, sub)
+}
+
+func TestMiddlewareDelegationNotConfigured(t *testing.T) {
+	initConfig(t, `
+auth:
+  basic:
+    username: "`+username+`"
+    password: "`+password+`"
+`)
+	auth := authMiddleware(nil)
+
+	ctx, rec, router := contextAndRecorder(t)
+	router.Use(gin.HandlerFunc(auth))
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "Ok")
+	})
+	ctx.Request.SetBasicAuth(username, password)
+	ctx.Request.Header.Set("X-On-Behalf-Of", "alice")
+	router.ServeHTTP(rec, ctx.Request)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+// In memory store of API keys
+type apiKeyStore map[types.ApiKeyId]types.ApiKey
+
+func (s apiKeyStore) add(key types.ApiKey, value string) (types.ApiKey, string) {
+	s[key.Id] = key
+	return key, value
+}
+
+func (s apiKeyStore) ApiKey(id types.ApiKeyId) (types.ApiKey, error) {
+	key, exists := s[id]
+	if !exists {
+		return key, types.NewErrNotFoundF("API key [%v] not found", id)
+	}
+	return key, nil
+}
+
+// Write the htpasswd file at the path, or a new path if "", with a later
+// modification time so that it is reloaded
+func writeHtpasswd(t *testing.T, path string, content string) string {
+	t.Helper()
+	modified := time.Now()
+	if path == "" {
+		path = filepath.Join(t.TempDir(), "htpasswd")
+	} else if info, err := os.Stat(path); err == nil {
+		modified = info.ModTime().Add(time.Second)
+	}
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	require.NoError(t, os.Chtimes(path, modified, modified))
+	return path
+}
+
+func bcryptOf(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	return string(hash)
+}
+
+func argon2idOf(password string) string {
+	salt := []byte("0123456789abcdef")
+	key := argon2.IDKey([]byte(password), salt, 1, 8*1024, 1, 32)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=1,p=1$%s$%s", argon2.Version, 8*1024,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func verifiedTLS(cert *x509.Certificate) *tls.ConnectionState {
+	return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
 }
 
 func initConfig(t *testing.T, yaml string) {
@@ -337,16 +980,20 @@ func newAuthServer(t *testing.T) (*httptest.Server, jwk.Key) {
 	t.Helper()
 	rawKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	require.NoError(t, err)
+	return newAuthServerWithKey(t, rawKey, rawKey.Public(), jwa.RS256())
+}
 
+func newAuthServerWithKey(t *testing.T, rawKey any, rawPublicKey any, alg jwa.SignatureAlgorithm) (*httptest.Server, jwk.Key) {
+	t.Helper()
 	privateKey, err := jwk.Import(rawKey)
 	require.NoError(t, err)
 	require.NoError(t, privateKey.Set(jwk.KeyIDKey, "test-key"))
-	require.NoError(t, privateKey.Set(jwk.AlgorithmKey, jwa.RS256()))
+	require.NoError(t, privateKey.Set(jwk.AlgorithmKey, alg))
 
-	publicKey, err := jwk.Import(rawKey.Public())
+	publicKey, err := jwk.Import(rawPublicKey)
 	require.NoError(t, err)
 	require.NoError(t, publicKey.Set(jwk.KeyIDKey, "test-key"))
-	require.NoError(t, publicKey.Set(jwk.AlgorithmKey, jwa.RS256()))
+	require.NoError(t, publicKey.Set(jwk.AlgorithmKey, alg))
 
 	pubSet := jwk.NewSet()
 	require.NoError(t, pubSet.AddKey(publicKey))
@@ -372,18 +1019,48 @@ func newAuthServer(t *testing.T) (*httptest.Server, jwk.Key) {
 	return server, privateKey
 }
 
+// Introspection endpoint for the client egress:secret, responding with the
+// claims of known tokens
+func newIntrospectionServer(t *testing.T, tokens map[string]map[string]any) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	calls := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		clientId, clientSecret, ok := r.BasicAuth()
+		if !ok || clientId != "egress" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		claims, exists := tokens[r.PostFormValue("token")]
+		if !exists {
+			claims = map[string]any{"active": false}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(claims)
+	}))
+	t.Cleanup(server.Close)
+	return server, calls
+}
+
 func signToken(t *testing.T, key jwk.Key, iss, aud, sub string) string {
 	t.Helper()
-	token, err := jwt.NewBuilder().
+	return signTokenWithClaims(t, key, jwa.RS256(), iss, aud, map[string]any{"sub": sub})
+}
+
+func signTokenWithClaims(t *testing.T, key jwk.Key, alg jwa.SignatureAlgorithm, iss, aud string, claims map[string]any) string {
+	t.Helper()
+	builder := jwt.NewBuilder().
 		Issuer(iss).
 		Audience([]string{aud}).
-		Subject(sub).
 		IssuedAt(time.Now()).
-		Expiration(time.Now().Add(5 * time.Minute)).
-		Build()
+		Expiration(time.Now().Add(5 * time.Minute))
+	for name, value := range claims {
+		builder = builder.Claim(name, value)
+	}
+	token, err := builder.Build()
 	require.NoError(t, err)
 
-	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256(), key))
+	signed, err := jwt.Sign(token, jwt.WithKey(alg, key))
 	require.NoError(t, err)
 	return string(signed)
 }
@@ -20,15 +20,102 @@ import (
 )
 
 const (
+	ApiKeyAuthScopes apiKeyAuthContextKey = "apiKeyAuth.Scopes"
 	BasicAuthScopes  basicAuthContextKey  = "basicAuth.Scopes"
 	BearerAuthScopes bearerAuthContextKey = "bearerAuth.Scopes"
 )
 
+// Defines values for ApiKeyScope.
+const (
+	ApiKeyScopeDownload ApiKeyScope = "download"
+	ApiKeyScopeRead     ApiKeyScope = "read"
+	ApiKeyScopeReview   ApiKeyScope = "review"
+)
+
+// Valid indicates whether the value is a known member of the ApiKeyScope enum.
+func (e ApiKeyScope) Valid() bool {
+	switch e {
+	case ApiKeyScopeDownload:
+		return true
+	case ApiKeyScopeRead:
+		return true
+	case ApiKeyScopeReview:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for ArchiveInspectionExceeded.
+const (
+	Depth   ArchiveInspectionExceeded = "depth"
+	Members ArchiveInspectionExceeded = "members"
+	Ratio   ArchiveInspectionExceeded = "ratio"
+)
+
+// Valid indicates whether the value is a known member of the ArchiveInspectionExceeded enum.
+func (e ArchiveInspectionExceeded) Valid() bool {
+	switch e {
+	case Depth:
+		return true
+	case Members:
+		return true
+	case Ratio:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for ArchiveInspectionFormat.
+const (
+	ArchiveInspectionFormatGzip ArchiveInspectionFormat = "gzip"
+	ArchiveInspectionFormatTar  ArchiveInspectionFormat = "tar"
+	ArchiveInspectionFormatZip  ArchiveInspectionFormat = "zip"
+)
+
+// Valid indicates whether the value is a known member of the ArchiveInspectionFormat enum.
+func (e ArchiveInspectionFormat) Valid() bool {
+	switch e {
+	case ArchiveInspectionFormatGzip:
+		return true
+	case ArchiveInspectionFormatTar:
+		return true
+	case ArchiveInspectionFormatZip:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for DownloadArchiveRequestFormat.
+const (
+	DownloadArchiveRequestFormatTar DownloadArchiveRequestFormat = "tar"
+	DownloadArchiveRequestFormatZip DownloadArchiveRequestFormat = "zip"
+)
+
+// Valid indicates whether the value is a known member of the DownloadArchiveRequestFormat enum.
+func (e DownloadArchiveRequestFormat) Valid() bool {
+	switch e {
+	case DownloadArchiveRequestFormatTar:
+		return true
+	case DownloadArchiveRequestFormatZip:
+		return true
+	default:
+		return false
+	}
+}
+
 // Defines values for EventAction.
 const (
 	EventActionApproval  EventAction = "Approval"
+	EventActionCopy      EventAction = "Copy"
+	EventActionDelete    EventAction = "Delete"
 	EventActionDownload  EventAction = "Download"
+	EventActionPreview   EventAction = "Preview"
 	EventActionRejection EventAction = "Rejection"
+	EventActionScan      EventAction = "Scan"
+	EventActionTag       EventAction = "Tag"
 )
 
 // Valid indicates whether the value is a known member of the EventAction enum.
@@ -36,15 +123,137 @@ func (e EventAction) Valid() bool {
 	switch e {
 	case EventActionApproval:
 		return true
+	case EventActionCopy:
+		return true
+	case EventActionDelete:
+		return true
 	case EventActionDownload:
 		return true
+	case EventActionPreview:
+		return true
 	case EventActionRejection:
 		return true
+	case EventActionScan:
+		return true
+	case EventActionTag:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for FileDiffKind.
+const (
+	FileDiffKindTable FileDiffKind = "table"
+	FileDiffKindText  FileDiffKind = "text"
+)
+
+// Valid indicates whether the value is a known member of the FileDiffKind enum.
+func (e FileDiffKind) Valid() bool {
+	switch e {
+	case FileDiffKindTable:
+		return true
+	case FileDiffKindText:
+		return true
 	default:
 		return false
 	}
 }
 
+// Defines values for FilePreviewKind.
+const (
+	FilePreviewKindHex   FilePreviewKind = "hex"
+	FilePreviewKindImage FilePreviewKind = "image"
+	FilePreviewKindTable FilePreviewKind = "table"
+	FilePreviewKindText  FilePreviewKind = "text"
+)
+
+// Valid indicates whether the value is a known member of the FilePreviewKind enum.
+func (e FilePreviewKind) Valid() bool {
+	switch e {
+	case FilePreviewKindHex:
+		return true
+	case FilePreviewKindImage:
+		return true
+	case FilePreviewKindTable:
+		return true
+	case FilePreviewKindText:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for LineChangeOp.
+const (
+	LineChangeOpDelete LineChangeOp = "delete"
+	LineChangeOpInsert LineChangeOp = "insert"
+)
+
+// Valid indicates whether the value is a known member of the LineChangeOp enum.
+func (e LineChangeOp) Valid() bool {
+	switch e {
+	case LineChangeOpDelete:
+		return true
+	case LineChangeOpInsert:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for RowChangeOp.
+const (
+	RowChangeOpDelete RowChangeOp = "delete"
+	RowChangeOpInsert RowChangeOp = "insert"
+	RowChangeOpModify RowChangeOp = "modify"
+)
+
+// Valid indicates whether the value is a known member of the RowChangeOp enum.
+func (e RowChangeOp) Valid() bool {
+	switch e {
+	case RowChangeOpDelete:
+		return true
+	case RowChangeOpInsert:
+		return true
+	case RowChangeOpModify:
+		return true
+	default:
+		return false
+	}
+}
+
+// ApiKey defines model for ApiKey.
+type ApiKey struct {
+	// Active Whether the key has neither been revoked nor expired
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// ExpiresAt When the key expires, if it does
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Id Unique API key identifier, which is not secret
+	Id string `json:"id"`
+
+	// Label Label of the key e.g. the service consumer
+	Label string `json:"label"`
+
+	// Projects Projects the key may be used for, or "*" for all
+	Projects []string `json:"projects"`
+
+	// RevokedAt When the key was revoked, if it was
+	RevokedAt *time.Time    `json:"revoked_at,omitempty"`
+	Scopes    []ApiKeyScope `json:"scopes"`
+}
+
+// ApiKeyListResponse defines model for ApiKeyListResponse.
+type ApiKeyListResponse = []ApiKey
+
+// ApiKeyScope Operations an API key may be used for; read lists files and events
+// and reads reports, previews, diffs and archive members, review
+// approves and rejects files, and download downloads files and archives
+type ApiKeyScope string
+
 // Approval defines model for Approval.
 type Approval struct {
 	// Comment Comment associated with approval (optional)
@@ -69,6 +278,132 @@ type ApproveFileRequest struct {
 	UserId string `json:"user_id"`
 }
 
+// ArchiveInspection defines model for ArchiveInspection.
+type ArchiveInspection struct {
+	// Exceeded Limit exceeded by the archive, whose members are listed only up to the limit
+	Exceeded *ArchiveInspectionExceeded `json:"exceeded,omitempty"`
+
+	// FileId Unique file identifier
+	FileId string `json:"file_id"`
+
+	// Format Format of the archive
+	Format ArchiveInspectionFormat `json:"format"`
+
+	// Members Members of the archive, each archive within it followed by its own members
+	Members []ArchiveMember `json:"members"`
+}
+
+// ArchiveInspectionExceeded Limit exceeded by the archive, whose members are listed only up to the limit
+type ArchiveInspectionExceeded string
+
+// ArchiveInspectionFormat Format of the archive
+type ArchiveInspectionFormat string
+
+// ArchiveMember defines model for ArchiveMember.
+type ArchiveMember struct {
+	// CompressedSize Compressed size in bytes, unknown for members of a compressed tar
+	CompressedSize *int64 `json:"compressed_size,omitempty"`
+
+	// Depth 1 for members of the archive, 2 for members of an archive within it etc.
+	Depth int `json:"depth"`
+
+	// Infected Whether malware was found in the member, if it was scanned
+	Infected *bool `json:"infected,omitempty"`
+
+	// MediaType Media type detected from the leading bytes of the member
+	MediaType string `json:"media_type"`
+
+	// Path Path in the archive, under the path of the archive within it if any e.g. data.zip/table.csv
+	Path string `json:"path"`
+
+	// Scan Result of scanning the member for malware, if it was scanned e.g. "clean (clamd)"
+	Scan *string `json:"scan,omitempty"`
+
+	// Size Uncompressed size in bytes
+	Size int64 `json:"size"`
+}
+
+// ArchiveMembersRequest defines model for ArchiveMembersRequest.
+type ArchiveMembersRequest struct {
+	// FilesLocation Location (i.e. path) of the archive file
+	FilesLocation string `json:"files_location"`
+}
+
+// CellChange defines model for CellChange.
+type CellChange struct {
+	// Base Value of the cell in the base version, or empty if the row had no such cell
+	Base string `json:"base"`
+
+	// Column Number of the column, starting at 1
+	Column int `json:"column"`
+
+	// Header Header of the column, if both versions have the same header row
+	Header *string `json:"header,omitempty"`
+
+	// Value Value of the cell in this version, or empty if the row has no such cell
+	Value string `json:"value"`
+}
+
+// CreateApiKeyRequest defines model for CreateApiKeyRequest.
+type CreateApiKeyRequest struct {
+	// ExpiresAt When the key expires (optional)
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Label Label of the key e.g. the service consumer
+	Label string `json:"label"`
+
+	// Projects Projects the key may be used for, or "*" for all
+	Projects []string      `json:"projects"`
+	Scopes   []ApiKeyScope `json:"scopes"`
+}
+
+// CreateApiKeyResponse defines model for CreateApiKeyResponse.
+type CreateApiKeyResponse struct {
+	ApiKey ApiKey `json:"api_key"`
+
+	// Key The key, to be given in an X-API-Key header. It cannot be retrieved again
+	Key string `json:"key"`
+}
+
+// DiffFileRequest defines model for DiffFileRequest.
+type DiffFileRequest struct {
+	// BaseFileId Id of the version to diff with (optional); defaults to the previous version of the file
+	BaseFileId *string `json:"base_file_id,omitempty"`
+
+	// FilesLocation Location (i.e. path) of both versions of the file
+	FilesLocation string `json:"files_location"`
+}
+
+// DownloadArchiveRequest defines model for DownloadArchiveRequest.
+type DownloadArchiveRequest struct {
+	// Comment Comment accompanying download request (optional)
+	Comment *string `json:"comment,omitempty"`
+
+	// Destination Destination to which the files are egressed
+	Destination string `json:"destination"`
+
+	// FileIds Unique identifiers of the files to egress
+	FileIds []string `json:"file_ids"`
+
+	// FilesLocation Location (i.e. path) of the files to egress
+	FilesLocation string `json:"files_location"`
+
+	// Format Format of the archive
+	Format *DownloadArchiveRequestFormat `json:"format,omitempty"`
+
+	// MaxFileSize Maximum allowed size of each file in bytes
+	MaxFileSize int `json:"max_file_size"`
+
+	// RequiredApprovals Min number of approvals of each file required to egress
+	RequiredApprovals int `json:"required_approvals"`
+
+	// UserId User id of downloader (optional)
+	UserId *string `json:"user_id,omitempty"`
+}
+
+// DownloadArchiveRequestFormat Format of the archive
+type DownloadArchiveRequestFormat string
+
 // DownloadFileRequest defines model for DownloadFileRequest.
 type DownloadFileRequest struct {
 	// Comment Comment accompanying download request (optional)
@@ -101,18 +436,33 @@ type Event struct {
 	// Action Action associated with event
 	Action *EventAction `json:"action,omitempty"`
 
-	// Comment Comment associated with approval, rejection or download
+	// ApiKeyId Identifier of the API key that authenticated the request causing the event
+	ApiKeyId *string `json:"api_key_id,omitempty"`
+
+	// BundleId Bundle id of the archive, for a download of several files in an archive
+	BundleId *string `json:"bundle_id,omitempty"`
+
+	// Comment Comment associated with approval, rejection or download, or the result of a scan
 	Comment *string `json:"comment,omitempty"`
 
 	// Datetime Date and time of event; ISO 8601, millisecond resolution
 	Datetime time.Time `json:"datetime"`
 
+	// DelegatorId Identifier of the trusted service that caused the event on behalf of the user
+	DelegatorId *string `json:"delegator_id,omitempty"`
+
 	// Destination Egress/download destination
 	Destination *string `json:"destination,omitempty"`
 
 	// FileId Unique file identifier
 	FileId string `json:"file_id"`
 
+	// OriginalHash SHA-256 hash, hex encoded, of the file before it was transformed, for a download with its metadata stripped
+	OriginalHash *string `json:"original_hash,omitempty"`
+
+	// TransformedHash SHA-256 hash, hex encoded, of the file as downloaded, for a download with its metadata stripped
+	TransformedHash *string `json:"transformed_hash,omitempty"`
+
 	// UserId User identifier
 	UserId string `json:"user_id"`
 }
@@ -123,6 +473,33 @@ type EventAction string
 // EventListResponse defines model for EventListResponse.
 type EventListResponse = []Event
 
+// ExpireApiKeyRequest defines model for ExpireApiKeyRequest.
+type ExpireApiKeyRequest struct {
+	// ExpiresAt When the key expires; now if not given
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// FileDiff defines model for FileDiff.
+type FileDiff struct {
+	// BaseFileId Id of the version the changes are from
+	BaseFileId string `json:"base_file_id"`
+
+	// FileId Id of the version the changes are to
+	FileId string `json:"file_id"`
+
+	// Kind Kind of diff
+	Kind FileDiffKind `json:"kind"`
+
+	// Lines Lines of text inserted and deleted, in order
+	Lines *[]LineChange `json:"lines,omitempty"`
+
+	// Rows Rows of a table inserted, deleted and modified, in order
+	Rows *[]RowChange `json:"rows,omitempty"`
+}
+
+// FileDiffKind Kind of diff
+type FileDiffKind string
+
 // FileListResponse defines model for FileListResponse.
 type FileListResponse = []FileMetadata
 
@@ -137,16 +514,125 @@ type FileMetadata struct {
 	// Id Unique file identifier
 	Id string `json:"id"`
 
+	// MediaType Media type detected from the leading bytes of the file, whatever
+	// its extension e.g. image/png. Only detected if a destination has a
+	// media type allowlist, and null otherwise or if it failed to be
+	// detected
+	MediaType *string `json:"media_type,omitempty"`
+
+	// PreviousFileId Id of the version of the file listed with the same name before
+	// this one. Null if this is the first version
+	PreviousFileId *string `json:"previous_file_id,omitempty"`
+
 	// Size Size of file in bytes
 	Size int `json:"size"`
 }
 
+// FilePreview defines model for FilePreview.
+type FilePreview struct {
+	// FileId Unique file identifier
+	FileId string `json:"file_id"`
+
+	// Height Height in pixels of the image, not the thumbnail
+	Height *int `json:"height,omitempty"`
+
+	// Kind Kind of preview
+	Kind FilePreviewKind `json:"kind"`
+
+	// Lines First lines of text, each truncated to 1024 bytes, or of a hex dump
+	Lines *[]string `json:"lines,omitempty"`
+
+	// MediaType Media type detected from the leading bytes of the file
+	MediaType string `json:"media_type"`
+
+	// Rows First rows of a table
+	Rows *[][]string `json:"rows,omitempty"`
+
+	// Thumbnail Base64 encoded PNG thumbnail of an image
+	Thumbnail *[]byte `json:"thumbnail,omitempty"`
+
+	// Truncated Whether the file has more content than is previewed
+	Truncated bool `json:"truncated"`
+
+	// Width Width in pixels of the image, not the thumbnail
+	Width *int `json:"width,omitempty"`
+}
+
+// FilePreviewKind Kind of preview
+type FilePreviewKind string
+
+// FileReport defines model for FileReport.
+type FileReport struct {
+	// Checks Names of the checks run on the file e.g. sdc
+	Checks []string `json:"checks"`
+
+	// FileId Unique file identifier
+	FileId   string    `json:"file_id"`
+	Findings []Finding `json:"findings"`
+}
+
+// FileReportRequest defines model for FileReportRequest.
+type FileReportRequest struct {
+	// FilesLocation Location (i.e. path) of the file to check
+	FilesLocation string `json:"files_location"`
+}
+
+// Finding defines model for Finding.
+type Finding struct {
+	// Check Name of the check with the finding e.g. sdc
+	Check string `json:"check"`
+
+	// Column Column of a table with the finding, from 1
+	Column *int `json:"column,omitempty"`
+
+	// Count Number of occurrences in the file e.g. of cells in the column
+	Count int `json:"count"`
+
+	// Line First line of the file with the finding, from 1
+	Line int `json:"line"`
+
+	// Message Description of the finding
+	Message string `json:"message"`
+
+	// Type Kind of finding e.g. small_count, unrounded or email
+	Type string `json:"type"`
+}
+
+// LineChange defines model for LineChange.
+type LineChange struct {
+	// BaseLine Number, starting at 1, of a deleted line in the base version
+	BaseLine *int `json:"base_line,omitempty"`
+
+	// Line Number, starting at 1, of an inserted line in this version
+	Line *int         `json:"line,omitempty"`
+	Op   LineChangeOp `json:"op"`
+	Text string       `json:"text"`
+}
+
+// LineChangeOp defines model for LineChange.Op.
+type LineChangeOp string
+
 // ListFilesRequest defines model for ListFilesRequest.
 type ListFilesRequest struct {
 	// FilesLocation Location (i.e. path) of files to list
 	FilesLocation string `json:"files_location"`
 }
 
+// PreviewFileRequest defines model for PreviewFileRequest.
+type PreviewFileRequest struct {
+	// Comment Comment accompanying preview request (optional)
+	Comment *string `json:"comment,omitempty"`
+
+	// FilesLocation Location (i.e. path) of the file to preview
+	FilesLocation string `json:"files_location"`
+
+	// Lines Number of lines of text or rows of a table (optional); defaults to, and is at most, the configured maximum
+	Lines *int `json:"lines,omitempty"`
+
+	// UserId User id of previewer (optional)
+	UserId *string `json:"user_id,omitempty"`
+}
+
 // RejectFileRequest defines model for RejectFileRequest.
 type RejectFileRequest struct {
 	// Comment Comment accompanying rejection (optional)
@@ -155,37 +641,104 @@ type RejectFileRequest struct {
 	// Destination Destination to which egressing is denied
 	Destination string `json:"destination"`
 
+	// FilesLocation Location of the file in storage (optional). If given, the storage
+	// actions configured for rejections to the destination are taken
+	FilesLocation *string `json:"files_location,omitempty"`
+
 	// UserId User id of rejecter
 	UserId string `json:"user_id"`
 }
 
+// RowChange defines model for RowChange.
+type RowChange struct {
+	// BaseRow Number, starting at 1, of a deleted or modified row in the base version
+	BaseRow *int `json:"base_row,omitempty"`
+
+	// Cells Cells of an inserted or deleted row
+	Cells *[]string `json:"cells,omitempty"`
+
+	// Changes Cells of a modified row that changed
+	Changes *[]CellChange `json:"changes,omitempty"`
+	Op      RowChangeOp   `json:"op"`
+
+	// Row Number, starting at 1, of an inserted or modified row in this version
+	Row *int `json:"row,omitempty"`
+}
+
+// RowChangeOp defines model for RowChange.Op.
+type RowChangeOp string
+
+// ApiKeyIdParam defines model for ApiKeyIdParam.
+type ApiKeyIdParam = string
+
 // FileIdParam defines model for FileIdParam.
 type FileIdParam = string
 
+// IfRangeParam defines model for IfRangeParam.
+type IfRangeParam = string
+
 // ProjectIdParam defines model for ProjectIdParam.
 type ProjectIdParam = string
 
+// RangeParam defines model for RangeParam.
+type RangeParam = string
+
 // BadRequest defines model for BadRequest.
 type BadRequest = ErrorResponse
 
+// Forbidden defines model for Forbidden.
+type Forbidden = ErrorResponse
+
 // InternalServerError defines model for InternalServerError.
 type InternalServerError = ErrorResponse
 
 // NotFound defines model for NotFound.
 type NotFound = ErrorResponse
 
+// RangeNotSatisfiable defines model for RangeNotSatisfiable.
+type RangeNotSatisfiable = ErrorResponse
+
+// StorageUnavailable defines model for StorageUnavailable.
+type StorageUnavailable = ErrorResponse
+
 // Unauthorized defines model for Unauthorized.
 type Unauthorized = ErrorResponse
 
 // UnknownError defines model for UnknownError.
 type UnknownError = ErrorResponse
 
+// apiKeyAuthContextKey is the context key for apiKeyAuth security scheme
+type apiKeyAuthContextKey string
+
 // basicAuthContextKey is the context key for basicAuth security scheme
 type basicAuthContextKey string
 
 // bearerAuthContextKey is the context key for bearerAuth security scheme
 type bearerAuthContextKey string
 
+// GetProjectIdFilesFileIdParams defines parameters for GetProjectIdFilesFileId.
+type GetProjectIdFilesFileIdParams struct {
+	// Range Single byte range of the file to download as per RFC9110 e.g.
+	// "bytes=1024-". Multiple ranges are not supported. Ranges of a file
+	// transformed for the destination cannot be requested, which is a 416
+	// unless If-Range is also given
+	Range *RangeParam `json:"Range,omitempty"`
+
+	// IfRange Only return the requested range if the file has this ETag;
+	// otherwise return the whole file. A transformed file has no ETag,
+	// so it is always returned whole
+	IfRange *IfRangeParam `json:"If-Range,omitempty"`
+}
+
+// PostApiKeysJSONRequestBody defines body for PostApiKeys for application/json ContentType.
+type PostApiKeysJSONRequestBody = CreateApiKeyRequest
+
+// PutApiKeysApiKeyIdExpireJSONRequestBody defines body for PutApiKeysApiKeyIdExpire for application/json ContentType.
+type PutApiKeysApiKeyIdExpireJSONRequestBody = ExpireApiKeyRequest
+
+// GetProjectIdArchiveJSONRequestBody defines body for GetProjectIdArchive for application/json ContentType.
+type GetProjectIdArchiveJSONRequestBody = DownloadArchiveRequest
+
 // GetProjectIdFilesJSONRequestBody defines body for GetProjectIdFiles for application/json ContentType.
 type GetProjectIdFilesJSONRequestBody = ListFilesRequest
 
@@ -195,11 +748,38 @@ type GetProjectIdFilesFileIdJSONRequestBody = DownloadFileRequest
 // PutProjectIdFilesFileIdApproveJSONRequestBody defines body for PutProjectIdFilesFileIdApprove for application/json ContentType.
 type PutProjectIdFilesFileIdApproveJSONRequestBody = ApproveFileRequest
 
+// GetProjectIdFilesFileIdDiffJSONRequestBody defines body for GetProjectIdFilesFileIdDiff for application/json ContentType.
+type GetProjectIdFilesFileIdDiffJSONRequestBody = DiffFileRequest
+
+// GetProjectIdFilesFileIdMembersJSONRequestBody defines body for GetProjectIdFilesFileIdMembers for application/json ContentType.
+type GetProjectIdFilesFileIdMembersJSONRequestBody = ArchiveMembersRequest
+
+// GetProjectIdFilesFileIdPreviewJSONRequestBody defines body for GetProjectIdFilesFileIdPreview for application/json ContentType.
+type GetProjectIdFilesFileIdPreviewJSONRequestBody = PreviewFileRequest
+
 // PutProjectIdFilesFileIdRejectJSONRequestBody defines body for PutProjectIdFilesFileIdReject for application/json ContentType.
 type PutProjectIdFilesFileIdRejectJSONRequestBody = RejectFileRequest
 
+// GetProjectIdFilesFileIdReportJSONRequestBody defines body for GetProjectIdFilesFileIdReport for application/json ContentType.
+type GetProjectIdFilesFileIdReportJSONRequestBody = FileReportRequest
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// List API keys
+	// (GET /api-keys)
+	GetApiKeys(c *gin.Context)
+	// Create API key
+	// (POST /api-keys)
+	PostApiKeys(c *gin.Context)
+	// Expire API key
+	// (PUT /api-keys/{api-key-id}/expire)
+	PutApiKeysApiKeyIdExpire(c *gin.Context, apiKeyId ApiKeyIdParam)
+	// Revoke API key
+	// (PUT /api-keys/{api-key-id}/revoke)
+	PutApiKeysApiKeyIdRevoke(c *gin.Context, apiKeyId ApiKeyIdParam)
+	// Download approved files as an archive
+	// (GET /{project-id}/archive)
+	GetProjectIdArchive(c *gin.Context, projectId ProjectIdParam)
 	// List events
 	// (GET /{project-id}/events)
 	GetProjectIdEvents(c *gin.Context, projectId ProjectIdParam)
@@ -208,13 +788,25 @@ type ServerInterface interface {
 	GetProjectIdFiles(c *gin.Context, projectId ProjectIdParam)
 	// Download approved file
 	// (GET /{project-id}/files/{file-id})
-	GetProjectIdFilesFileId(c *gin.Context, projectId ProjectIdParam, fileId FileIdParam)
+	GetProjectIdFilesFileId(c *gin.Context, projectId ProjectIdParam, fileId FileIdParam, params GetProjectIdFilesFileIdParams)
 	// Approve file
 	// (PUT /{project-id}/files/{file-id}/approve)
 	PutProjectIdFilesFileIdApprove(c *gin.Context, projectId ProjectIdParam, fileId FileIdParam)
+	// Diff file with a previous version
+	// (GET /{project-id}/files/{file-id}/diff)
+	GetProjectIdFilesFileIdDiff(c *gin.Context, projectId ProjectIdParam, fileId FileIdParam)
+	// List members of archive file
+	// (GET /{project-id}/files/{file-id}/members)
+	GetProjectIdFilesFileIdMembers(c *gin.Context, projectId ProjectIdParam, fileId FileIdParam)
+	// Preview file
+	// (GET /{project-id}/files/{file-id}/preview)
+	GetProjectIdFilesFileIdPreview(c *gin.Context, projectId ProjectIdParam, fileId FileIdParam)
 	// Reject file
 	// (PUT /{project-id}/files/{file-id}/reject)
 	PutProjectIdFilesFileIdReject(c *gin.Context, projectId ProjectIdParam, fileId FileIdParam)
+	// Get output checking report of file
+	// (GET /{project-id}/files/{file-id}/report)
+	GetProjectIdFilesFileIdReport(c *gin.Context, projectId ProjectIdParam, fileId FileIdParam)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -226,6 +818,121 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(c *gin.Context)
 
+// GetApiKeys operation middleware
+func (siw *ServerInterfaceWrapper) GetApiKeys(c *gin.Context) {
+
+	c.Set(string(BearerAuthScopes), []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetApiKeys(c)
+}
+
+// PostApiKeys operation middleware
+func (siw *ServerInterfaceWrapper) PostApiKeys(c *gin.Context) {
+
+	c.Set(string(BearerAuthScopes), []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostApiKeys(c)
+}
+
+// PutApiKeysApiKeyIdExpire operation middleware
+func (siw *ServerInterfaceWrapper) PutApiKeysApiKeyIdExpire(c *gin.Context) {
+
+	var err error
+	_ = err
+
+	// ------------- Path parameter "api-key-id" -------------
+	var apiKeyId ApiKeyIdParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "api-key-id", c.Param("api-key-id"), &apiKeyId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter api-key-id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(string(BearerAuthScopes), []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutApiKeysApiKeyIdExpire(c, apiKeyId)
+}
+
+// PutApiKeysApiKeyIdRevoke operation middleware
+func (siw *ServerInterfaceWrapper) PutApiKeysApiKeyIdRevoke(c *gin.Context) {
+
+	var err error
+	_ = err
+
+	// ------------- Path parameter "api-key-id" -------------
+	var apiKeyId ApiKeyIdParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "api-key-id", c.Param("api-key-id"), &apiKeyId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter api-key-id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(string(BearerAuthScopes), []string{"admin"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutApiKeysApiKeyIdRevoke(c, apiKeyId)
+}
+
+// GetProjectIdArchive operation middleware
+func (siw *ServerInterfaceWrapper) GetProjectIdArchive(c *gin.Context) {
+
+	var err error
+	_ = err
+
+	// ------------- Path parameter "project-id" -------------
+	var projectId ProjectIdParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "project-id", c.Param("project-id"), &projectId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter project-id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(string(BasicAuthScopes), []string{})
+
+	c.Set(string(BearerAuthScopes), []string{})
+
+	c.Set(string(ApiKeyAuthScopes), []string{"download"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetProjectIdArchive(c, projectId)
+}
+
 // GetProjectIdEvents operation middleware
 func (siw *ServerInterfaceWrapper) GetProjectIdEvents(c *gin.Context) {
 
@@ -245,6 +952,8 @@ func (siw *ServerInterfaceWrapper) GetProjectIdEvents(c *gin.Context) {
 
 	c.Set(string(BearerAuthScopes), []string{})
 
+	c.Set(string(ApiKeyAuthScopes), []string{"read"})
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -274,6 +983,8 @@ func (siw *ServerInterfaceWrapper) GetProjectIdFiles(c *gin.Context) {
 
 	c.Set(string(BearerAuthScopes), []string{})
 
+	c.Set(string(ApiKeyAuthScopes), []string{"read"})
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -312,6 +1023,51 @@ func (siw *ServerInterfaceWrapper) GetProjectIdFilesFileId(c *gin.Context) {
 
 	c.Set(string(BearerAuthScopes), []string{})
 
+	c.Set(string(ApiKeyAuthScopes), []string{"download"})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetProjectIdFilesFileIdParams
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "Range" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Range")]; found {
+		var Range RangeParam
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for Range, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Range", valueList[0], &Range, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false, Type: "string", Format: ""})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter Range: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.Range = &Range
+
+	}
+
+	// ------------- Optional header parameter "If-Range" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Range")]; found {
+		var IfRange IfRangeParam
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for If-Range, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Range", valueList[0], &IfRange, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false, Type: "string", Format: ""})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter If-Range: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.IfRange = &IfRange
+
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -319,7 +1075,7 @@ func (siw *ServerInterfaceWrapper) GetProjectIdFilesFileId(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetProjectIdFilesFileId(c, projectId, fileId)
+	siw.Handler.GetProjectIdFilesFileId(c, projectId, fileId, params)
 }
 
 // PutProjectIdFilesFileIdApprove operation middleware
@@ -350,6 +1106,8 @@ func (siw *ServerInterfaceWrapper) PutProjectIdFilesFileIdApprove(c *gin.Context
 
 	c.Set(string(BearerAuthScopes), []string{})
 
+	c.Set(string(ApiKeyAuthScopes), []string{"review"})
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -360,6 +1118,126 @@ func (siw *ServerInterfaceWrapper) PutProjectIdFilesFileIdApprove(c *gin.Context
 	siw.Handler.PutProjectIdFilesFileIdApprove(c, projectId, fileId)
 }
 
+// GetProjectIdFilesFileIdDiff operation middleware
+func (siw *ServerInterfaceWrapper) GetProjectIdFilesFileIdDiff(c *gin.Context) {
+
+	var err error
+	_ = err
+
+	// ------------- Path parameter "project-id" -------------
+	var projectId ProjectIdParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "project-id", c.Param("project-id"), &projectId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter project-id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "file-id" -------------
+	var fileId FileIdParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "file-id", c.Param("file-id"), &fileId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter file-id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(string(BasicAuthScopes), []string{})
+
+	c.Set(string(BearerAuthScopes), []string{})
+
+	c.Set(string(ApiKeyAuthScopes), []string{"read"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetProjectIdFilesFileIdDiff(c, projectId, fileId)
+}
+
+// GetProjectIdFilesFileIdMembers operation middleware
+func (siw *ServerInterfaceWrapper) GetProjectIdFilesFileIdMembers(c *gin.Context) {
+
+	var err error
+	_ = err
+
+	// ------------- Path parameter "project-id" -------------
+	var projectId ProjectIdParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "project-id", c.Param("project-id"), &projectId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter project-id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "file-id" -------------
+	var fileId FileIdParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "file-id", c.Param("file-id"), &fileId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter file-id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(string(BasicAuthScopes), []string{})
+
+	c.Set(string(BearerAuthScopes), []string{})
+
+	c.Set(string(ApiKeyAuthScopes), []string{"read"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetProjectIdFilesFileIdMembers(c, projectId, fileId)
+}
+
+// GetProjectIdFilesFileIdPreview operation middleware
+func (siw *ServerInterfaceWrapper) GetProjectIdFilesFileIdPreview(c *gin.Context) {
+
+	var err error
+	_ = err
+
+	// ------------- Path parameter "project-id" -------------
+	var projectId ProjectIdParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "project-id", c.Param("project-id"), &projectId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter project-id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "file-id" -------------
+	var fileId FileIdParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "file-id", c.Param("file-id"), &fileId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter file-id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(string(BasicAuthScopes), []string{})
+
+	c.Set(string(BearerAuthScopes), []string{})
+
+	c.Set(string(ApiKeyAuthScopes), []string{"read"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetProjectIdFilesFileIdPreview(c, projectId, fileId)
+}
+
 // PutProjectIdFilesFileIdReject operation middleware
 func (siw *ServerInterfaceWrapper) PutProjectIdFilesFileIdReject(c *gin.Context) {
 
@@ -388,6 +1266,8 @@ func (siw *ServerInterfaceWrapper) PutProjectIdFilesFileIdReject(c *gin.Context)
 
 	c.Set(string(BearerAuthScopes), []string{})
 
+	c.Set(string(ApiKeyAuthScopes), []string{"review"})
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -398,6 +1278,46 @@ func (siw *ServerInterfaceWrapper) PutProjectIdFilesFileIdReject(c *gin.Context)
 	siw.Handler.PutProjectIdFilesFileIdReject(c, projectId, fileId)
 }
 
+// GetProjectIdFilesFileIdReport operation middleware
+func (siw *ServerInterfaceWrapper) GetProjectIdFilesFileIdReport(c *gin.Context) {
+
+	var err error
+	_ = err
+
+	// ------------- Path parameter "project-id" -------------
+	var projectId ProjectIdParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "project-id", c.Param("project-id"), &projectId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter project-id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "file-id" -------------
+	var fileId FileIdParam
+
+	err = runtime.BindStyledParameterWithOptions("simple", "file-id", c.Param("file-id"), &fileId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter file-id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(string(BasicAuthScopes), []string{})
+
+	c.Set(string(BearerAuthScopes), []string{})
+
+	c.Set(string(ApiKeyAuthScopes), []string{"read"})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetProjectIdFilesFileIdReport(c, projectId, fileId)
+}
+
 // GinServerOptions provides options for the Gin server.
 type GinServerOptions struct {
 	BaseURL      string
@@ -425,11 +1345,20 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 		ErrorHandler:       errorHandler,
 	}
 
+	router.GET(options.BaseURL+"/api-keys", wrapper.GetApiKeys)
+	router.POST(options.BaseURL+"/api-keys", wrapper.PostApiKeys)
+	router.PUT(options.BaseURL+"/api-keys/:api-key-id/expire", wrapper.PutApiKeysApiKeyIdExpire)
+	router.PUT(options.BaseURL+"/api-keys/:api-key-id/revoke", wrapper.PutApiKeysApiKeyIdRevoke)
+	router.GET(options.BaseURL+"/:project-id/archive", wrapper.GetProjectIdArchive)
 	router.GET(options.BaseURL+"/:project-id/events", wrapper.GetProjectIdEvents)
 	router.GET(options.BaseURL+"/:project-id/files", wrapper.GetProjectIdFiles)
 	router.GET(options.BaseURL+"/:project-id/files/:file-id", wrapper.GetProjectIdFilesFileId)
 	router.PUT(options.BaseURL+"/:project-id/files/:file-id/approve", wrapper.PutProjectIdFilesFileIdApprove)
+	router.GET(options.BaseURL+"/:project-id/files/:file-id/diff", wrapper.GetProjectIdFilesFileIdDiff)
+	router.GET(options.BaseURL+"/:project-id/files/:file-id/members", wrapper.GetProjectIdFilesFileIdMembers)
+	router.GET(options.BaseURL+"/:project-id/files/:file-id/preview", wrapper.GetProjectIdFilesFileIdPreview)
 	router.PUT(options.BaseURL+"/:project-id/files/:file-id/reject", wrapper.PutProjectIdFilesFileIdReject)
+	router.GET(options.BaseURL+"/:project-id/files/:file-id/report", wrapper.GetProjectIdFilesFileIdReport)
 }
 
 // Base64 encoded, compressed with deflate, json marshaled OpenAPI spec.
@@ -437,34 +1366,117 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 // const string: with thousands of chunks the chained `+` fold is several
 // times slower for the Go compiler than parsing a slice literal.
 var swaggerSpec = []string{
-	"7Flrb9u8Dv4rgs75sAFOnF42DNmn3nZOd3Yp2g4FTlEUis0m2mzJk+S0WeH//oKSbOeiNOma9X0x7Ftd",
-	"0+Qj8iEpMvc0kXkhBQijaf+eFkyxHAwo+/SOZ3CcnuD/8DEFnSheGC4F7dMvgn8vgdzwDAhPQRh+w0HR",
-	"iHJ8WzAzohEVLAfapyjU4SmNqILvJVeQ0r5RJURUJyPIGWo3kwJFtVFcDGlVRfREya+QmFUICie2EoSX",
-	"eyyOCoV1IYUG65R9lp7C9xK0wadECgPC/smKIuMJQ3DxV40I76fU/lvBDe3Tf8Wtw2P3VsdHSkl16o04",
-	"k7Mn3WcpUc7oW8LFmGU8JVOxqiJ6LAwowbIzUGNQVuPz4fsi4K6AxEBKuMdBtAVCwCKpIvpJmneyFOnz",
-	"oUL6EiENubF2q4h+Eaw0I6n4D0if0zut1bcE/0aiOlukIWJER8BSn3kXFxedvVYQZtEsUNQe7ZuQt+LZ",
-	"A2+ttlH34a7qjLKn2SsKJccssxVGyQKU4S6ZEpnnHuWs4gP3gjCtZcIZMuuWmxFhXhV5Ia0oy17SaN4h",
-	"FqXhgjll87odHEgJDBVoTaaFA7pKDeqap4H6o0ERnhJ542HZyrNYx9pac9kom4V41XwmB1ij0KxHiSSe",
-	"qjePdV+CUWViwsVwI747bF8SI8ntiCcjYka+DyRMkAF4v1pO/1O8eShvRSZZukF3pl5lXZl/nVu5ftCl",
-	"KKOvM5ksUf7BvyEveBe6BLviS3Sz1W2k1x3SnLO7a5S61vwHLCr+yO54XuaEZZm8hdQpRFHCBRlMDKDS",
-	"nAsUov1eYwCrxRBsV6ijeV2TUwfMcEFEmQ9AteRgmW5K58wZGnNbIXPrsK+OK6gHIzpHxdkiEjjWQpzm",
-	"3Rui7WwBXiBsDlqzIQT55J7G4EoyqUUjCncsLzK0c+xvEv561t4oVp621hbEPPY5NIuVJUuqsf3/QqEH",
-	"qyWiIDCYl20PiegpoCnnwjqvEYkos4wN8GTuTrdA55/uNhFRtU0iVcMQuobNlBkwPA/FiBkgTKQEXyPx",
-	"7JHfkuOzz+TN695WRHKeZVxDIgUWGS2z0p+6DeF2b/t1p7fb2d4933rd333T773pvtra/j/STaqcGdq3",
-	"EDoWw2PL0pHNqripdLMkX3l2y+1gti2bHR7dLpZ/O5+fdRxaWK32pTT+wLWZTj9uINcrb02WulWjkynF",
-	"JviMzeenNOKHH8GwlBm2THHzfjHzlpdWRGOp525C09VqLWBNVgZAWTe72Wve7CfmGI8ioag/jTPhZnWG",
-	"fanuemv2pzkOtSeyEL2laMrBISKhjzFAeum94ykNXGP3y7g2KzNgzkoIqqusG7wjtWVz05cjx1i0wfEG",
-	"L/jPXzcdyA1eN5GDkJSKm8kZJorz3IBpnuBQtwjmv+fnJ2Qf38+Nh9TPUajdft9iHBlT4AkHwBSoWq97",
-	"eleX/vcX53R+ZkNRUlrXfT4+PCDvL86Jkd9AaDIGhVmVEjZkXGhDGHl/8b+zGRTWwDwMPDIXNzLg54MP",
-	"ZO/0gJyfHhHXTsjeyTGNaMYT8DXQL2j2zw47O52DjJUas6pUmdev+3EsCxBaliqBrlTD2H8dD3Ta2ekk",
-	"7hssQtzYvlgmWYeppGMUdJq74RiUdqi2ujvdHsqjWlZw2qc73V63RyO7NLLxiu/bjVEV2+Zs/z8Ey35M",
-	"Chuj45T26X/ANOuqIycazWzTLsOFtBWJ59Zd1dXc4mm719vcYL/Q3ALD/SmYUgltqwvmSTJSUshMDnnC",
-	"smxCpEoBr9/eNVVEd3tbyyw3R4lnljBVRF+5cz38UWi9ZfOszHOmJnUrq6FU0Vz4bPFbK3q2VG8meLaE",
-	"7st0srG4LfSSarZG4UWs+oW8WbjBPEAbf35IGwK5IFierBHyqUXr81Mroq+2e+sYnNq6BfjY+sCfPUzM",
-	"+N6PYNX6FHXL+ScTNVr5xfSvAL+K16H1zJOpLRMDpqONAvfrQQulGY0GXGC0otDWP7BM9oaIshSHlOgy",
-	"SUDrmzLLJs9J7N3e7uqPmo3735wJdXDrvZ5LhtW5EHt5ewktAzlxUgZzwm9Of5PUCOyB18qM3cW7mOXw",
-	"NGWbiPzh7hLueu+vy1g3SjyWsG7s+k34ujhDbo6uflL7Q9dldHXOr9k6NYdaPk1NoJdXSJfp2fHyChnh",
-	"frV19HMDWDzeotVV9VcAAAD//w==",
+	"7H17c9s4kvhXQfH3q7qZK1qWM97crlP3RyaPWc8zZWc2WzdMpWCyJWFDAVwAtKOk/N2vugGQoAS9HMUz",
+	"k8t/tkgCjUa/u9H4kJVq3igJ0prs7EPWcM3nYEHTf48b8QMszqsX+Cv+UIEptWisUDI7y36V4t8tsMcv",
+	"ztlbWDBRgbRiIkBneSbwhYbbWZZnks8hO8t4I47ewuJIVFmeafh3KzRU2ZnVLeSZKWcw5ziHXTT4trFa",
+	"yGl2e5tnz0UN24CYiBq2QoAv7T/9+eSCyymsmf8XWS+YBttqyewMGI4MxkLFNH7FxIR+Jvhm3DA7E4Y9",
+	"e8mnjwqp7Az0jTAQD3AzU7V7f8QeM6u5NBOl51D1Y0hFI+SFNIoJy4RhvL7hC+PHgcqNUsiAhxnwCnSP",
+	"ifPJES0q27z0F1r9C0q7DfmNe20r/v17+2/Bpg24FHJaA7taWPAoVxHKrWKVupG14hXjhjWg2cXzJ387",
+	"ORkzGE1HhSwy/NL898n4welRkY3YT21tRVP7wQzjGphUlpm2aZS2UI3YhXuiJozTLIUcbJPSNH8FxgrJ",
+	"EUxWcoljXEX0kbObmShntHns9ORhIVtZgzEs7I3bVqPYVFyDXL+X2zfyFrFtGiUNEF9/y6sLBwb+Vypp",
+	"QdKfvGlqURLIx/8yiN4P0bD/X8MkO8v+33EvM47dU3P8TGulL/wkbsrhNn3Lq7D2R0zIa16LikXiBvlc",
+	"6StRVSDvD6puyke0Zby1M5AWJ4PKk7Nd4Ebg7vG6VjdQ0ZuqAU0QIeDn0oKWvL4EfQ2aJr2/Jfwq4V0D",
+	"JQHs4WCGAGFAkNzm2c/KPletrO4Rsch8iLQJzRt4+GdlL7kVZiL4VQ33B87FklSuFLg9Vdega950AgMh",
+	"vbRK8yn8Kvk1F/X9AurnZrVy4yPttT0gjE8saKahASLRCRd1q4HY51eJ5Ku0eA/VfZJfP+ujmIEQ+E7G",
+	"515qkfh59erV0eP+RRhCsyK8aGlvpbqR985ZNGvPVp6fboOsjYwk/KvRKBWscEKWl1Zcw6q+ejUDVPtE",
+	"cWg2kUIHQb9dASDSrtVbqJhUmsG7xuPPo+VKqRo4SZ1SEw284YQHVD74V1ZxC0dWzCHLl3GZZ248479Z",
+	"AUx2UPkXc7RfhCV+yfL0JLKtPZM4Rb4yqaj2sB0jtUhaF0oNNrWUml9BvTrwj/hzMAFoJaPpiP5BoShK",
+	"YKWSpp2DTg3qLRSzOq43hUw37pwvUJ+3xqn8nCnNiuw/iwz/Q12R5ZmwMDcJou5m5lrzRUb6mTZ9+8bc",
+	"cBMoJGzODb/73phSNY5eO1g38Ywj9kv8aHUVt7FR91tGVp7bpQix3ZQDAs4Du7zuBlVX+AHO4ib9URjb",
+	"cet+8KYQHq9k1agP2t0wLjsiXdrxR0wDr1gtjDWkO/DlisE1QlBI/BtfwO1Cw9HkrNFwLeDG5KwSk4l7",
+	"netyJq6BzWF+BdrkzL1TSN40Wl37QTU44qNpcvqpM2vDHzEQflRDViPIdo4bgtCQ4Y0TZHkWPsxeJwjj",
+	"MU3P61XBVqr53AvfIdaeuAeMG6NKQfrpRtgZ434o9pWiV3n9dYr3Int5dWwHDlQMphqMiY3r1FitAf0m",
+	"KXcMaCYqst3dkAk5sETIYbAhiGlKpSHR+IkM7H3RVyIhc7kQcnoQ3D3tHzKrvHztPKSSS6Rqh1eoUqP/",
+	"Xth0RHwuDdq3fmlDZMK7EqCCBGg/irmwLDxnVwtn4LshUcko0zEd+XjIx1Axhf582yCi8IMah4l5CMEl",
+	"2BvybP0I2esdZC2i+80GZbgaw1gdwgv5D6t+zJzboPb8KiOw34sGh+M46BT/SbF8WMvK6D+5B0vD5wx4",
+	"OQv/EasLyQSa/N5RulowYQ1DGyqMne8ot92gbuKtmiYgtsPPYF/W0ZUfPMWgjeOFN0a8hySj+hcYvsCE",
+	"pOiDyVnrLUa0AOY90jjrx2RuEzptLaR9eLpeUwtpYepQ4EhuBZqT5dkGW/RgBRaZ2DGw5ShLzSnkhFzL",
+	"9WbsHCNPGsguIV8P0YEguDkjG4UZjIJAtX6xkXE7h0rwN+73VXKsBGf4jFVgCT420WruGBZ4hYKTdiSg",
+	"w8GSNPh4CqcvuJ2FdXSobGXlzXb8aAnTETIFItnbnRW3fPReNMcWlzsqzXWWNMJ4QmxfgGlr4mlCHC6q",
+	"X4vbVof7BI7d7EVWIj7ZV2XN59XXRbaTRZik+V9luYbqU8S8TEhL/OqjgjRTLEq7Ld/KtWateiUb6E1w",
+	"nxN6wT9hX4kRjGgrv17eSxxjqx5bmigF8hOo6yczCs+twHnFTQLN/+B12wUwS6jrQIX4OrsGbYSS5GrA",
+	"vMHIlHtTqxs24+gyMtOWM/oyRWilqtt5Aik/t0RTYV56K2fGcm3JCLHsJCkefChyZby/0+/L44kJu1J2",
+	"FpZh2IxfA71h+ByYGwzXsguZXiOmdkagMNuQZ7Ygb2n3PSZzt48BnCQRkJfj3I21VLuvXz40B3fz/f/c",
+	"DvNcyHP38GTVmfs4L3bD0Eu7vt6b3b7zvee6FCdqxJu3sNgNZhzWvzzE9kuH5JxZhUimlAGSPpfsn0eP",
+	"X5wf/QALz2Ejdm4HCQmrBaBrxadcyK2UH+B1gKTW/VRMJhtdIOSZN2ut4fMqUKNnWVwTOszOm+wp/xGr",
+	"YMLb2ppgrZODrdqO2eNUULbGIr+LshiKsY2T7K81nnq33Cu8AzmSXbjAJ2E+mUPpvKlN7qTfebPWEep9",
+	"oAFyaZvdwJtFBX8X+Hk8Hm/i7zxraUb/GFXNR1BFGs6NPhyRb3bm/bO7+nRJZ46/cyyWtud+4u/EvJ13",
+	"OS18C6cil845or19NxcSX87OxikjIJD3mxCrSHmQQjLZGRndi8MZw0AD9HVzn6Tm3iUyESgf9EaaT/uU",
+	"ZilOkVzuCskso38Tmx8wWHRvPM6E2crhd+GgkLJfzzx7kjUNuOyufDpy/kNR8Kch22HWbIVg52AMn0KS",
+	"ntx/1+DyaCy8ilkpPm9qqkvxhQG+UqcvENi62jBaEuZrz0OrGbpkrJl+XwljU2A/kr9dhDzPLig871AY",
+	"+DrLs5d8muXZE9WgpnkKNVhc7SV6+1hb4+Lwu0QOvc21xlwKyjKoipCvsDNul0oaogolVvLWhKBCWNtW",
+	"SK5aWa2x276lR55kB5ETsu976YQRDbgGzWuvLp2d2mu4rVDcOQWR+0wK2Ya6A4l8EYebEHPhFErZBZiK",
+	"WyCfa5XmuQVKx+BjHJTQ/IidX/7C/vpwfJKzuahrYaBUlOMxqm49FfUs8WD84OHR+PTowenLk4dnp389",
+	"G/919JeTB/+zs99XQQ1TbpXekXysbikSHtw/IiMkFk8/tAimJLuCGa8n4TMUZDuha5PWeUZC87jPbQ1k",
+	"2H1E2JUWUyF5/WbGTSIyePn3x0cP/vIQAwaznM3gHQNZqgqqPOCB5riCidIQgnJRddgKMxB5CmvYHCzH",
+	"cCFDWJpmU5y0hzYa+eMA5qYD6eBAblNx67djWaMFTsujkH8Yfa3gv1PimL5M5Y2fUQDmwOGcR0yqGyYm",
+	"VPJAvvuO3H2bWDQalOiCH8DvxhjarC+DxCB7tg/bbR/SqtSAb4VMjPaDkDQexgIiPWzhHRWIEA2mXKFa",
+	"SDCpDKH0GQJ4Z5mQBrSFyuXWSVdXOROoJly15U5kg2P6iO/tWs7oaEmrmwRcF+rGZ41oSR1keQCLQJyr",
+	"CpnmDjBeqJtdQVzivwH9xDxI+/V6DS3eif/ww5+8sEmx4eB5Iqa21nRHaEgVuzqC2BreMXboPkgBRQhx",
+	"VbkrEXbuLIB1kaiPU1qHzZbhhJgh5xauQRcSBT+8syCJgyk8LOZ8CseNnI4YFcB3I2Pya1D5jJF1Xsh5",
+	"DwX5Zphtd3UsSIGsr4RX2mezsLTReVJXUMgwQbGTHRDigPsIu1gb+loAUntdfgI31iv2QlJKQUkYsZ8R",
+	"fkolCMOE8YNoY8PIu4Gc9mcvfVhmn4hMKpBBRJm7kiyfduspfx3jBg8lmWP7SDNrBmI6s6nMEf6OS23E",
+	"O6g7miSCy0k/4r921s6vJBf1Tpnzzeqk6Qqikholz2huKmF9t49+eU5EUMdaxpdMWN1K748phgcPQv2A",
+	"cvEEMtOqdt5sDHNu0y6HFwqpjUzrMLd2PdRk8Wp2r4zctsyeFFY9Um7g4WkweNmLn7/rCcfXQoS97Ywt",
+	"XPRulrffw80Vvt2ZnbnSwHzdMrpUVNntSW9Nge+NqFLFCa/w54NyyNpymoiEPBfFC18nNy6o3jERzJxB",
+	"+dakdWO3DPcS061k3lwkFJLWMVW5V1HtIQquhEReMHsYLrLy3+5Ys+TREs21GbGfpPQhRF4JmENksQIe",
+	"0lSw3kDqaKDXvh4vMQ3sXNnwhH6PzenlYXMn+E520iSlaqXdVD+hyrLVGmTpwlpD+lUTKi7onnRFBKsT",
+	"odrYpFIG+7ZhSasjb4/QxsaQ28R8Ha+t06nDLZvzun5DqMMqKo1VYlC5OgwnoLbUWsQ06VETtiLfGPiN",
+	"3LG0M5xGs9vOpQKY3BFR8MFoExLFOTuR0d7Tyt4/7ScWZq9JVYNTBhvHjZe58KBNO85kB5192LI9qsn8",
+	"q+ktMBZl1+ErtrpUK1rrhxBZ3t49YELO6/cd83GHkN+9MburmdrLroGpiuy5ZL+tK71wfpwwSK9zhX6d",
+	"E25yIqathorNXVbuILkwv8D9k7lbNt+lcA64932i4dBZWBe6wDmEYRVI8ZFJ2JiEhGTGH0HswR6x84kL",
+	"S7qt9W8U0mXQTLzXGDnult4V5kSLdGE//tYfab7TYQM3wUEPG/RRsbSmwLLEOykKpbtYHTLUnXUGWQ4J",
+	"2sOfl3WE0t30rpzy7l6kD9Zumni4Ppcuoq+qXYNqUaHsDiBtVmR5RuAskhpt310c4nR1H/dSwat6c5UQ",
+	"MRIEZauFXVwicroawR9ggWdmE9nq1s6Yy+T2Z9XCKUqsuRTSWOBVXxZ4tWBcYueDx/7kLnHFGXNJDVa0",
+	"4/E35VtY0B9QZF3dIBYZunpHx8R0hsanMgo5b42lkt71rQq6UsSecXlX13jFjSjTa/z7y5cv2Lf4fOmE",
+	"MbbIQB4PMmxmG27MTRWqVAo5zH9TAw4Qmj5CoPKossULC0fTQWXjqgo557aceQS4fhORyAtjkRqk+ZWu",
+	"CENc0gkkWsYZBg1hEWGO5glZVhSlckriE8czOTNqGSqB5wo9YCackCXrGKFw3SN8ttcfHe+hpE0hfqMo",
+	"A+Ky34SZtQ1tAXANOuyB++95iIx8/+rlSo1aRHy/nD99wr5/9ZJZ9RYk8YVjFiorNZZx9v2rHy4HUNAE",
+	"y2DcunMnKqEBnvzIHl88YS8vnjGXKUZ6J5egBJ9d8JT27eXTo2+OntSYts7yrNW1H9+cHR+rBqRRrS5h",
+	"pPT02H99fGWqo2+OSvcNmr/CIjNnbVkfcV0eWQ1HXVVPYPuz7GT0zWjs5BJI3ojsLPtmNB6NM3e6hBj4",
+	"2De/oX+mYNfkJaLqDePKJIYV2CZnQpZ1WzkDw50TR6rz58QLiV+O2IUTM4Zx5nDsNgVlWFkLkJaVoK2Y",
+	"EFf07iOv5kIyHTrHdD0uzqvsLPsOrBMQJltqJ/JgPD7YofzEWd9kOwfbamnI8kc8BZzlTNWo6F0AHvfk",
+	"dHyybspuDceDzgn00TfbP+q7ldzm2V/G4+1fpNqExAI/O/vtw4AFf8toR7LXt6/zzLTzOdeLQCphyTh7",
+	"o0zKKtXg6k/Cu11nmlC+TqTjBLqTbfiS6LsIFZJOQwZNF1aSoxilJ5zKCpxowg/RMIQqpr9CHowAXygz",
+	"oEASg9+qanEw4ksd1bgdqm1fIrxE/yefCIT1HBC21LRlCcZM2rpeMH+g3pHwDgQZ9QH6TFnF84DHFg3R",
+	"ieLjD31HsttjJ0HJ+G8TzHQJPqwu5gOW6vpkdAUckqpjydL6VIzQBj4IDdpcUQopnL5/229pJPevHA/7",
+	"u92+doR+aK5KVczsxFWnCWM3RvsS9Ruwf2zKPx2fbv+ia9V036zitmkXVnGGx1pWuaDHMZOQJcstE5ZJ",
+	"xWolp6AHhakmmNtmVMhPYbyssoyD8iAssyvRDqjVW29fyM+Tn6eaAfl96BsG3h6H8uB1BnSou+5qi3lo",
+	"GdIXGQfn7b1okJws16HqmOyfQval/GQahUMx16AXvk5SQ+dx+SLP+OQwepzGauBzNIMesw4oVzRLRlSp",
+	"dOUDZd3ZlxyrYjpaLuTVmlrqEXvWwxL6A4Yz39GRcAS/kAHQ4IHhQKq1TWtDmhVXmSrN8dMWMgqb0QHe",
+	"3qHM0+tn/fIp7F/I0GrDV47eqLZG3HUlpMmGibgGDRMqOka0FJKz0/GYvGIKNFxBVLDKlATGLeOknylc",
+	"6TozUhWRKaQ7FUn9epQshzBjdp5CP1B54CmFtrytICsPi2otFcNazUVNgaPQhdP3NUx7T10zzcddoft+",
+	"smepG+en0tdrzh/upLI3OYLvjizXQzD6qgshUQykwrnxEHjkbc8BVi1nv6eDA3td39RYRg+71j1xqzp6",
+	"KkyjjFhzdMRaXs4oGRAlZWtfPDbk5dCjwcXbHMcfiYr+BWwaQf0aNrVF/eeRO3NxdL7XcYxOBPkCi6GQ",
+	"Mhsnvb1XC+veFBZ+u4M+TXSGxE8fjHdZXNTCcFVB9rHP317f5ksak36JY8C/Rb27hmq028sl7cdNfMRm",
+	"Vbv6re+V63rx9SxQyQGk1yeKIq3W/e8QRCpnWklVq6koOVpoyCMaOq64KxUf0Iral0g0rBIIYqVb0goZ",
+	"ELFsj1FGFlXIL+adwBOaDc0oS11rKsELma44FDpUxm3RnGRR/FH15kqlw0drzP3mX6m130DzfZfyQP1u",
+	"5+9Ruv8fEtZr+bDfBo/+NEMef/DnYW+3ez9ceu7zg1JRcef4DLukC8usojOPFNQqpEB067ZBiIKG2dWN",
+	"8WsppMt4+RzXhGbvZvRWechrudMBNeJnEZnzZK93dfeRW0CWfXBxuq5YgzxX3jfRL2Rwk4KH5D0WLhd9",
+	"zDqYfsEuwhE5w0O6brW5a+VJqeCJl1t+2NhBYeSfILL6krxQ0MnUBAcZ+F4uInJVK3TDlrwf2jPnNnWy",
+	"MhagPvMn5PKH/2Hi0xXBP1wFs5Dnk94jEybyxVAkO/He70eiiiPHiOb3L559l2NZdc5ePH2OQP8ymYgS",
+	"WKXKdu4pJW6Nz41Hej/2qJAvw4at3nYg4isNvOvFpbv9gImpVJq2Sy4KSTTmS5GsWSZZt7cO05izGBa2",
+	"d8c6aKcnFvQuisjdS/HR6ijf+kV8AcYOr0e3Jezw9uB6i0/tU8b1VR+tHlVpwR45l/2j3cLnou7r83dw",
+	"Bx+XKOXcTQ1m/RmAXuAOCc43w+oUADqCUkkI3bC87Np2r8RX7jRWdDK3kIGXv45vmHDXg0Qs3V8Q0qMt",
+	"lNSEU0YIUKKMBk/BYmuDRHnyoJ6effXvVlkCxJc1MCG76y1G7Gfq7y3tPitegjjlnj4YP7wvoumvFVhz",
+	"/cgecYWLUHy25Mb3A3eDBaM6xCeHVeb+RhM6WHT0YHz6X8en47893BpMOMCW/nkjB6cnD7d/kLrB4kvU",
+	"YX3UYbs5e+zfj3JJK3mblM71fbbvW/V+IuWY6Bp+1/wosesgzxR2JPtsA3e/i0PnWvcMucDv4660X/ke",
+	"DUl/7qK/ECy0SyDTnCd7HHJ/uvTJ5T+Y0uzl5T/CvVSKhZPKeZeGpdKdm6hMl0mAyrCQJHFHEoMrU0h0",
+	"EK/mwq45Ek0+HJNww0SVd7op7o6I/mEh6WXvs3TX21A1pOYuTWWiBoLuCLYrS8Ja6UKG9foz1uFf92Kf",
+	"jAqr9fWQ3N2yRikcX8LuTytQNqg79Gokb8xMWVeazm5mIEP7Fl9tTvdtFDK86MAEifLYqWRhfR9ZG+rx",
+	"8m4cYaLUt5BopZFQH7GXeNhi4lJlXUNcdxQj3ZMC/6HzGFEDXTypUcjw/rpOEd3muVNocaX27v7OU9d9",
+	"43MQvMuNSn+HgB1hc0OgbkUALJ8dGJzP/pKc+cPG+3Cjo9DQqhzfQWNEFzRsjs8P7h54L5qcSh2UZnj3",
+	"g3caLdej6fuvB03PcxT2Zavd4SLUHv6p6fvbO5EcFQ14Ae8nJUHbRa24JKm5NWYXD+BKhUxBDYqFNVAP",
+	"bi3ow3kj9lN0f4dP7se3dxSyn8Pd5NE54STn/D9CM6ssr50/FUKPvW9VyC516rzRxrX/X8ZN93/QgV2L",
+	"SOx/4q/3Cc/q4f0kUaBrxB73q3UvUJCrkPSNF+NxPNIjTmn/KPwQKi+6reqwujFyGDWHjuJ0rL+HBY9V",
+	"aGAlL2fulo9encsQuHPLSMQD+6305cV+R9wJGMMQ9IpQ4qjtYiV6iQTVDSE6qEJYNKhkpQf9SvFRPPeu",
+	"+u6n7uaSz8LXSN6icM+Kb/Viny0aMH27yRd19wdPb8VqKNIyO2i6pm89tMk94uzKNxPAt6MEU1Rx6u+3",
+	"8nKi9xSELaQwnX98FjVuWurZ0z/oD0T3npaLW+aMJ/rLuNwIpknw5e/On4cuLUpHXX5IQfSTdG13KE1F",
+	"zou7DfplkGbd7aUS+g5ZYSHot/mT7KvpOtcfhnfZkd3F4IvuYPnnIAYTR/1/B+M/4HSL9PPM8MXS/3OI",
+	"vsB8O4o6F13YNwLq2hV8Jty42nvhcPHPELz5Ev/85PFPt427033oEpbW8K1cbiMyLF133c2bpl6gBuS+",
+	"2MVd6m8st8JYLORjlTBlrUzr269pRaoZ9Tc6KyFUalAlN6CNkrwuZHwDiZCuIUp096ruorNCd1Ue6Krg",
+	"ksgdLeRm7yiKxS0vpw+8LheRMO7eTjpoiSIN48wK983A4yajAWtPdjcA3OI+E4mz2k3td1D/HqNbtL/j",
+	"ky/K/8+h/L8DO5BT7qB+2EEvGvedGWcgTDmOc80Mjq9PstvXt/87AA==",
 }
 
 // decodeSpec returns the embedded OpenAPI spec as raw JSON bytes,
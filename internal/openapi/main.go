@@ -1,6 +1,10 @@
 package openapi
 
-import "github.com/ucl-arc-tre/egress/internal/types"
+import (
+	"time"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
 
 //go:generate go tool oapi-codegen -generate types,spec,gin -package openapi -o main.gen.go ../../api/api.yaml
 
@@ -11,6 +15,9 @@ func MakeFileMetadata(metadata types.FileMetadata, approvals types.FileApprovals
 		Size:      int(metadata.Size),
 		Approvals: []Approval{},
 	}
+	if metadata.MediaType != "" {
+		fileMetadata.MediaType = &metadata.MediaType
+	}
 	for _, approval := range approvals {
 		fileMetadata.Approvals = append(fileMetadata.Approvals, Approval{
 			UserId:      string(approval.UserId),
@@ -20,3 +27,157 @@ func MakeFileMetadata(metadata types.FileMetadata, approvals types.FileApprovals
 	}
 	return fileMetadata
 }
+
+func MakeFileReport(report types.FileReport) FileReport {
+	fileReport := FileReport{
+		FileId:   string(report.FileId),
+		Checks:   append([]string{}, report.Checks...),
+		Findings: []Finding{},
+	}
+	for _, finding := range report.Findings {
+		var column *int
+		if finding.Column > 0 {
+			column = &finding.Column
+		}
+		fileReport.Findings = append(fileReport.Findings, Finding{
+			Check:   finding.Check,
+			Type:    finding.Type,
+			Line:    finding.Line,
+			Column:  column,
+			Count:   finding.Count,
+			Message: finding.Message,
+		})
+	}
+	return fileReport
+}
+
+func MakeArchiveInspection(inspection types.ArchiveInspection) ArchiveInspection {
+	archiveInspection := ArchiveInspection{
+		FileId:  string(inspection.FileId),
+		Format:  ArchiveInspectionFormat(inspection.Format),
+		Members: []ArchiveMember{},
+	}
+	if inspection.Exceeded != "" {
+		exceeded := ArchiveInspectionExceeded(inspection.Exceeded)
+		archiveInspection.Exceeded = &exceeded
+	}
+	for _, member := range inspection.Members {
+		archiveMember := ArchiveMember{
+			Path:      member.Path,
+			Size:      member.Size,
+			Depth:     member.Depth,
+			MediaType: member.MediaType,
+		}
+		if member.CompressedSize >= 0 {
+			archiveMember.CompressedSize = &member.CompressedSize
+		}
+		if member.Scan != nil {
+			scan := member.Scan.String()
+			infected := member.Scan.Verdict == types.ScanVerdictInfected
+			archiveMember.Scan = &scan
+			archiveMember.Infected = &infected
+		}
+		archiveInspection.Members = append(archiveInspection.Members, archiveMember)
+	}
+	return archiveInspection
+}
+
+func MakeFilePreview(preview types.FilePreview) FilePreview {
+	filePreview := FilePreview{
+		FileId:    string(preview.FileId),
+		MediaType: preview.MediaType,
+		Kind:      FilePreviewKind(preview.Kind),
+		Truncated: preview.Truncated,
+	}
+	switch preview.Kind {
+	case types.PreviewKindTable:
+		filePreview.Rows = &preview.Rows
+	case types.PreviewKindImage:
+		filePreview.Thumbnail = &preview.Thumbnail
+		filePreview.Width = &preview.Width
+		filePreview.Height = &preview.Height
+	default:
+		filePreview.Lines = &preview.Lines
+	}
+	return filePreview
+}
+
+func MakeFileDiff(diff types.FileDiff) FileDiff {
+	fileDiff := FileDiff{
+		BaseFileId: string(diff.BaseFileId),
+		FileId:     string(diff.FileId),
+		Kind:       FileDiffKind(diff.Kind),
+	}
+	if diff.Kind == types.DiffKindTable {
+		rows := []RowChange{}
+		for _, change := range diff.Rows {
+			row := RowChange{
+				Op:      RowChangeOp(change.Op),
+				BaseRow: positive(change.BaseRow),
+				Row:     positive(change.Row),
+			}
+			if change.Op == types.DiffOpModify {
+				cells := []CellChange{}
+				for _, cell := range change.Changes {
+					cellChange := CellChange{Column: cell.Column, Base: cell.Base, Value: cell.Value}
+					if cell.Header != "" {
+						cellChange.Header = &cell.Header
+					}
+					cells = append(cells, cellChange)
+				}
+				row.Changes = &cells
+			} else {
+				row.Cells = &change.Cells
+			}
+			rows = append(rows, row)
+		}
+		fileDiff.Rows = &rows
+		return fileDiff
+	}
+	lines := []LineChange{}
+	for _, change := range diff.Lines {
+		lines = append(lines, LineChange{
+			Op:       LineChangeOp(change.Op),
+			BaseLine: positive(change.BaseLine),
+			Line:     positive(change.Line),
+			Text:     change.Text,
+		})
+	}
+	fileDiff.Lines = &lines
+	return fileDiff
+}
+
+func MakeApiKey(key types.ApiKey) ApiKey {
+	apiKey := ApiKey{
+		Id:        string(key.Id),
+		Label:     key.Label,
+		Projects:  []string{},
+		Scopes:    []ApiKeyScope{},
+		CreatedAt: key.Created,
+		ExpiresAt: optionalTime(key.Expires),
+		RevokedAt: optionalTime(key.Revoked),
+		Active:    key.IsActive(time.Now()),
+	}
+	for _, projectId := range key.Projects {
+		apiKey.Projects = append(apiKey.Projects, string(projectId))
+	}
+	for _, scope := range key.Scopes {
+		apiKey.Scopes = append(apiKey.Scopes, ApiKeyScope(scope))
+	}
+	return apiKey
+}
+
+// Line and row numbers start at 1, so 0 is not set
+func positive(value int) *int {
+	if value < 1 {
+		return nil
+	}
+	return &value
+}
+
+func optionalTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,199 @@
+// Package preview builds bounded views of the content of files, so that
+// they can be inspected without downloading them
+package preview
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Registers the decoders of the previewed image types
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+const (
+	// Longer lines are truncated, so that a file without line breaks is not
+	// read in full
+	MaxLineLength = 1024
+	// Images with more pixels are not decoded
+	maxImagePixels = 64 * 1024 * 1024
+	hexLineLength  = 16
+)
+
+// Kind of preview of a file with the media type. Images larger than the
+// maximum size are previewed as hex, since they would be read in full
+func KindOf(mediaType string, size int64, maxImageSize int64) types.PreviewKind {
+	switch {
+	case mediaType == "text/csv" || mediaType == "text/tab-separated-values":
+		return types.PreviewKindTable
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/json":
+		return types.PreviewKindText
+	case (mediaType == "image/png" || mediaType == "image/jpeg" || mediaType == "image/gif") && size <= maxImageSize:
+		return types.PreviewKindImage
+	}
+	return types.PreviewKindHex
+}
+
+// First lines of the text, each truncated to MaxLineLength bytes, and
+// whether there is more text
+func Text(r io.Reader, lines int) ([]string, bool, error) {
+	br := bufio.NewReaderSize(r, MaxLineLength)
+	result := []string{}
+	truncated := false
+	for len(result) < lines {
+		line, isPrefix, err := br.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return result, truncated, nil
+		} else if err != nil {
+			return nil, false, err
+		}
+		result = append(result, validUTF8(line))
+		for isPrefix { // The rest of a long line is skipped
+			truncated = true
+			if _, isPrefix, err = br.ReadLine(); err != nil {
+				return result, truncated, nil
+			}
+		}
+	}
+	_, err := br.Peek(1)
+	return result, truncated || err == nil, nil
+}
+
+// First rows of the CSV or TSV file with the name, and whether there are
+// more rows. A row that fails to be parsed ends the preview
+func Table(r io.Reader, name string, rows int) ([][]string, bool, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = Delimiter(name)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	result := [][]string{}
+	for len(result) < rows {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return result, false, nil
+		} else if err != nil {
+			return result, true, nil
+		}
+		for i, cell := range record {
+			record[i] = validUTF8([]byte(cell))
+		}
+		result = append(result, record)
+	}
+	_, err := cr.Read()
+	return result, !errors.Is(err, io.EOF), nil
+}
+
+// Delimiter of the cells of the CSV or TSV file with the name
+func Delimiter(name string) rune {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".tsv", ".tab":
+		return '\t'
+	}
+	return ','
+}
+
+// Hex dump of the first n bytes, in the format of hexdump -C, and whether
+// there are more bytes
+func Hex(r io.Reader, n int) ([]string, bool, error) {
+	content := make([]byte, n+1)
+	read, err := io.ReadFull(r, content)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, false, err
+	}
+	truncated := read > n
+	content = content[:min(read, n)]
+	lines := []string{}
+	for offset := 0; offset < len(content); offset += hexLineLength {
+		chunk := content[offset:min(offset+hexLineLength, len(content))]
+		printable := bytes.Map(func(r rune) rune {
+			if r < 0x20 || r > 0x7e {
+				return '.'
+			}
+			return r
+		}, chunk)
+		encoded := hex.EncodeToString(chunk)
+		pairs := []string{}
+		for i := 0; i < len(encoded); i += 2 {
+			pairs = append(pairs, encoded[i:i+2])
+		}
+		lines = append(lines, fmt.Sprintf("%08x  %-47s  |%s|", offset, strings.Join(pairs, " "), printable))
+	}
+	return lines, truncated, nil
+}
+
+// PNG thumbnail of the image, no larger than size in either dimension, and
+// the size of the image
+func Thumbnail(r io.Reader, size int) ([]byte, int, int, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(64 * 1024)
+	config, _, err := image.DecodeConfig(bytes.NewReader(head))
+	if err != nil {
+		return nil, 0, 0, types.NewErrInvalidObjectF("failed to decode image: %v", err)
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, 0, 0, types.NewErrInvalidObjectF("image of %dx%d pixels is too large to preview", config.Width, config.Height)
+	}
+	img, _, err := image.Decode(br)
+	if err != nil {
+		return nil, 0, 0, types.NewErrInvalidObjectF("failed to decode image: %v", err)
+	}
+	buffer := bytes.Buffer{}
+	if err := png.Encode(&buffer, scale(img, size)); err != nil {
+		return nil, 0, 0, types.NewErrServerF("failed to encode thumbnail: %v", err)
+	}
+	return buffer.Bytes(), config.Width, config.Height, nil
+}
+
+// Image scaled down to fit in a square of the size, averaging the pixels
+// that each pixel of the result covers
+func scale(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+	factor := float64(max(width, height)) / float64(size)
+	result := image.NewNRGBA(image.Rect(0, 0, max(1, int(float64(width)/factor)), max(1, int(float64(height)/factor))))
+	for y := range result.Bounds().Dy() {
+		y0, y1 := int(float64(y)*factor), min(height, int(float64(y+1)*factor))
+		for x := range result.Bounds().Dx() {
+			x0, x1 := int(float64(x)*factor), min(width, int(float64(x+1)*factor))
+			var r, g, b, a, n uint64
+			for sy := y0; sy < max(y1, y0+1); sy++ {
+				for sx := x0; sx < max(x1, x0+1); sx++ {
+					pr, pg, pb, pa := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r, g, b, a, n = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa), n+1
+				}
+			}
+			i := result.PixOffset(x, y)
+			if a == 0 {
+				continue
+			}
+			// Colours are alpha-premultiplied, so are divided by the alpha
+			result.Pix[i] = uint8(r * 0xff / a)
+			result.Pix[i+1] = uint8(g * 0xff / a)
+			result.Pix[i+2] = uint8(b * 0xff / a)
+			result.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return result
+}
+
+// Text with any invalid UTF-8, e.g. of a line truncated within a character,
+// replaced
+func validUTF8(text []byte) string {
+	if utf8.Valid(text) {
+		return string(text)
+	}
+	return strings.ToValidUTF8(string(text), "�")
+}
@@ -0,0 +1,91 @@
+package preview
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+func TestKindOf(t *testing.T) {
+	assert.Equal(t, types.PreviewKindTable, KindOf("text/csv", 10, 100))
+	assert.Equal(t, types.PreviewKindText, KindOf("text/plain", 10, 100))
+	assert.Equal(t, types.PreviewKindImage, KindOf("image/png", 10, 100))
+	assert.Equal(t, types.PreviewKindHex, KindOf("image/png", 1000, 100))
+	assert.Equal(t, types.PreviewKindHex, KindOf("application/zip", 10, 100))
+}
+
+func TestText(t *testing.T) {
+	lines, truncated, err := Text(strings.NewReader("a\nb\nc\n"), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, lines)
+	assert.True(t, truncated)
+
+	lines, truncated, err = Text(strings.NewReader("a\nb"), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, lines)
+	assert.False(t, truncated)
+
+	long := strings.Repeat("x", MaxLineLength+10)
+	lines, truncated, err = Text(strings.NewReader(long+"\nnext\n"), 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{long[:MaxLineLength], "next"}, lines)
+	assert.True(t, truncated)
+}
+
+func TestTable(t *testing.T) {
+	rows, truncated, err := Table(strings.NewReader("a,b\n1,\"2,3\"\n4,5\n"), "t.csv", 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"1", "2,3"}}, rows)
+	assert.True(t, truncated)
+
+	rows, truncated, err = Table(strings.NewReader("a\tb\n1\t2\n"), "t.tsv", 5)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"1", "2"}}, rows)
+	assert.False(t, truncated)
+}
+
+func TestHex(t *testing.T) {
+	lines, truncated, err := Hex(strings.NewReader("\x00\x01hello world, this is binary"), 20)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"00000000  00 01 68 65 6c 6c 6f 20 77 6f 72 6c 64 2c 20 74  |..hello world, t|",
+		"00000010  68 69 73 20                                      |his |",
+	}, lines)
+	assert.True(t, truncated)
+
+	_, truncated, err = Hex(strings.NewReader("ab"), 20)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+}
+
+func TestThumbnail(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := range 400 {
+		for y := range 200 {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	buffer := bytes.Buffer{}
+	require.NoError(t, png.Encode(&buffer, img))
+
+	thumbnail, width, height, err := Thumbnail(&buffer, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 400, width)
+	assert.Equal(t, 200, height)
+	decoded, err := png.Decode(bytes.NewReader(thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), decoded.Bounds())
+	r, g, _, a := decoded.At(50, 25).RGBA()
+	assert.Equal(t, []uint32{0xffff, 0, 0xffff}, []uint32{r, g, a})
+
+	_, _, _, err = Thumbnail(strings.NewReader("not an image"), 100)
+	assert.ErrorIs(t, err, types.ErrInvalidObject)
+}
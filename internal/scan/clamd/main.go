@@ -0,0 +1,130 @@
+package clamd
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+const (
+	Name = "clamd"
+
+	chunkSize = 64 * 1024 // bytes sent per INSTREAM chunk
+)
+
+// Scanner sends files to a clamd daemon with the INSTREAM command
+type Scanner struct {
+	network string // tcp or unix
+	address string
+	timeout time.Duration
+}
+
+// New scanner for the clamd daemon at the address
+// e.g. tcp://clamav:3310 or unix:///run/clamav/clamd.sock
+func New(address string, timeout time.Duration) (*Scanner, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	s := &Scanner{network: u.Scheme, timeout: timeout}
+	switch u.Scheme {
+	case "tcp":
+		s.address = u.Host
+	case "unix":
+		s.address = u.Path
+	default:
+		return nil, types.NewErrInvalidObjectF("[clamd] unsupported address scheme [%s]", u.Scheme)
+	}
+	return s, nil
+}
+
+func (s *Scanner) Name() string {
+	return Name
+}
+
+// Scan streams the content to clamd and parses its reply, which is one of
+// "stream: OK", "stream: <signature> FOUND" or "<message> ERROR"
+func (s *Scanner) Scan(ctx context.Context, content io.Reader) (types.ScanResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return types.ScanResult{}, types.NewErrServerF("[clamd] failed to connect: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return types.ScanResult{}, types.NewErrServerF("[clamd] failed to set deadline: %w", err)
+		}
+	}
+
+	writeErr := writeStream(conn, content)
+	// clamd replies and closes the connection early if e.g. the stream is
+	// too large, so its reply is read even if writing failed. If instead the
+	// content failed to be read, clamd would wait for the rest of the stream
+	if writeErr != nil {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	}
+	reply, readErr := bufio.NewReader(conn).ReadString(0)
+	reply = strings.TrimRight(reply, "\x00\n")
+	if writeErr != nil || (readErr != nil && reply == "") {
+		if reply != "" {
+			return types.ScanResult{}, types.NewErrServerF("[clamd] scan failed: %s", reply)
+		}
+		return types.ScanResult{}, types.NewErrServerF("[clamd] failed to scan: %w", errors.Join(writeErr, readErr))
+	}
+	return parseReply(reply)
+}
+
+func writeStream(w io.Writer, content io.Reader) error {
+	if _, err := w.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+	buf := make([]byte, chunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := content.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n)) // #nosec G115 -- n is at most chunkSize
+			if _, err := w.Write(size); err != nil {
+				return err
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	_, err := w.Write([]byte{0, 0, 0, 0}) // Zero length chunk ends the stream
+	return err
+}
+
+func parseReply(reply string) (types.ScanResult, error) {
+	message := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case message == "OK":
+		return types.ScanResult{Verdict: types.ScanVerdictClean, Scanner: Name}, nil
+	case strings.HasSuffix(message, " FOUND"):
+		return types.ScanResult{
+			Verdict:   types.ScanVerdictInfected,
+			Scanner:   Name,
+			Signature: strings.TrimSuffix(message, " FOUND"),
+		}, nil
+	default:
+		return types.ScanResult{}, types.NewErrServerF("[clamd] scan failed: %s", reply)
+	}
+}
@@ -0,0 +1,103 @@
+package clamd
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Serves a fake clamd that reads an INSTREAM command and replies with the
+// result of reply for the streamed content
+func serveClamd(t *testing.T, reply func(content string) string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			r := bufio.NewReader(conn)
+			command, err := r.ReadString(0)
+			if err != nil || command != "zINSTREAM\x00" {
+				_ = conn.Close()
+				continue
+			}
+			content := strings.Builder{}
+			for {
+				size := make([]byte, 4)
+				if _, err := io.ReadFull(r, size); err != nil {
+					break
+				}
+				n := binary.BigEndian.Uint32(size)
+				if n == 0 {
+					break
+				}
+				_, _ = io.CopyN(&content, r, int64(n))
+			}
+			_, _ = conn.Write([]byte(reply(content.String()) + "\x00"))
+			_ = conn.Close()
+		}
+	}()
+	return "tcp://" + listener.Addr().String()
+}
+
+func TestScan(t *testing.T) {
+	address := serveClamd(t, func(content string) string {
+		switch {
+		case strings.Contains(content, "EICAR"):
+			return "stream: Eicar-Test-Signature FOUND"
+		case content == "too large":
+			return "INSTREAM size limit exceeded. ERROR"
+		default:
+			return "stream: OK"
+		}
+	})
+	scanner, err := New(address, time.Second)
+	require.NoError(t, err)
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader(strings.Repeat("a", 3*chunkSize)))
+	require.NoError(t, err)
+	assert.Equal(t, types.ScanResult{Verdict: types.ScanVerdictClean, Scanner: Name}, result)
+
+	result, err = scanner.Scan(context.Background(), strings.NewReader("X5O!P%@AP EICAR"))
+	require.NoError(t, err)
+	assert.Equal(t, types.ScanVerdictInfected, result.Verdict)
+	assert.Equal(t, "Eicar-Test-Signature", result.Signature)
+
+	_, err = scanner.Scan(context.Background(), strings.NewReader("too large"))
+	assert.ErrorIs(t, err, types.ErrServer)
+}
+
+func TestScanUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	scanner, err := New("tcp://"+address, time.Second)
+	require.NoError(t, err)
+	_, err = scanner.Scan(context.Background(), strings.NewReader("hello"))
+	assert.ErrorIs(t, err, types.ErrServer)
+}
+
+func TestNew(t *testing.T) {
+	scanner, err := New("unix:///run/clamav/clamd.sock", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "unix", scanner.network)
+	assert.Equal(t, "/run/clamav/clamd.sock", scanner.address)
+
+	_, err = New("http://clamav:3310", time.Second)
+	assert.ErrorIs(t, err, types.ErrInvalidObject)
+}
@@ -0,0 +1,160 @@
+package scan
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/ucl-arc-tre/egress/internal/cache"
+	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/scan/clamd"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Scanner checks the content of a file for malware
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, content io.Reader) (types.ScanResult, error)
+}
+
+// Opens the content of the file to be scanned
+type OpenFunc func(ctx context.Context) (io.ReadCloser, error)
+
+// Pipeline scans files with each scanner in turn, stopping at the first
+// infected verdict. Results are cached by the location and id of the file
+type Pipeline struct {
+	scanners []Scanner
+
+	results *cache.FIFO[cache.FileKey, types.ScanResult]
+
+	mu       sync.Mutex
+	inflight map[cache.FileKey]chan struct{} // Closed once the scan finishes
+}
+
+// Pipeline of the configured scanners, or nil if none are configured
+func Provider(cfg config.ScanConfigBundle) (*Pipeline, error) {
+	scanners := []Scanner{}
+	if cfg.Clamd.Address != "" {
+		scanner, err := clamd.New(cfg.Clamd.Address, cfg.Clamd.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		scanners = append(scanners, scanner)
+	}
+	if len(scanners) == 0 {
+		return nil, nil
+	}
+	return NewPipeline(cfg.CacheSize, scanners...), nil
+}
+
+func NewPipeline(cacheSize int, scanners ...Scanner) *Pipeline {
+	return &Pipeline{
+		scanners: scanners,
+		results:  cache.NewFIFO[cache.FileKey, types.ScanResult](cacheSize),
+		inflight: map[cache.FileKey]chan struct{}{},
+	}
+}
+
+// Cached result of the file, if it has been scanned
+func (p *Pipeline) Cached(location types.LocationURI, fileId types.FileId) (types.ScanResult, bool) {
+	return p.results.Get(cache.KeyOf(location, fileId))
+}
+
+// Scan the file with each scanner, opening its content for each, and cache
+// the result. A cached result is not rescanned, and a file already being
+// scanned waits for that scan rather than scanning it again
+func (p *Pipeline) Scan(
+	ctx context.Context,
+	location types.LocationURI,
+	fileId types.FileId,
+	open OpenFunc,
+) (result types.ScanResult, cached bool, err error) {
+	key := cache.KeyOf(location, fileId)
+	var done chan struct{}
+	for {
+		p.mu.Lock()
+		if result, exists := p.results.Get(key); exists {
+			p.mu.Unlock()
+			return result, true, nil
+		}
+		var scanning bool
+		if done, scanning = p.inflight[key]; !scanning {
+			done = make(chan struct{})
+			p.inflight[key] = done
+			p.mu.Unlock()
+			break
+		}
+		p.mu.Unlock()
+		select {
+		case <-done: // Cached, unless the scan failed and is retried here
+		case <-ctx.Done():
+			return types.ScanResult{}, false, ctx.Err()
+		}
+	}
+	defer func() {
+		p.mu.Lock()
+		delete(p.inflight, key)
+		p.mu.Unlock()
+		close(done)
+	}()
+
+	for _, scanner := range p.scanners {
+		result, err = scanWith(ctx, scanner, open)
+		if err != nil {
+			return types.ScanResult{}, false, err
+		}
+		if result.Verdict == types.ScanVerdictInfected {
+			break
+		}
+	}
+	p.results.Put(key, result)
+	return result, false, nil
+}
+
+// Scan content that cannot be reopened e.g. a member of an archive, with
+// every scanner at once. The result is that of the first scanner giving an
+// infected verdict, as for Scan, and is not cached
+func (p *Pipeline) ScanContent(ctx context.Context, content io.Reader) (types.ScanResult, error) {
+	results := make([]types.ScanResult, len(p.scanners))
+	errs := make([]error, len(p.scanners))
+	writers := []io.Writer{}
+	pipes := []*io.PipeWriter{}
+	wg := sync.WaitGroup{}
+	for i, scanner := range p.scanners {
+		r, w := io.Pipe()
+		writers = append(writers, w)
+		pipes = append(pipes, w)
+		wg.Go(func() {
+			results[i], errs[i] = scanner.Scan(ctx, r)
+			// Drained so that the other scanners are not blocked if this one
+			// stopped reading early
+			_, _ = io.Copy(io.Discard, r)
+		})
+	}
+	_, err := io.Copy(io.MultiWriter(writers...), content)
+	for _, w := range pipes {
+		_ = w.CloseWithError(err)
+	}
+	wg.Wait()
+
+	result := types.ScanResult{}
+	for i := range p.scanners {
+		if errs[i] != nil {
+			return types.ScanResult{}, errs[i]
+		}
+		result = results[i]
+		if result.Verdict == types.ScanVerdictInfected {
+			break
+		}
+	}
+	return result, nil
+}
+
+func scanWith(ctx context.Context, scanner Scanner, open OpenFunc) (types.ScanResult, error) {
+	content, err := open(ctx)
+	if err != nil {
+		return types.ScanResult{}, err
+	}
+	defer content.Close()
+	return scanner.Scan(ctx, content)
+}
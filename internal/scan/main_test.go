@@ -0,0 +1,131 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+type mockScanner struct {
+	name    string
+	verdict types.ScanVerdict
+	err     error
+	calls   int
+}
+
+func (s *mockScanner) Name() string {
+	return s.name
+}
+
+func (s *mockScanner) Scan(ctx context.Context, content io.Reader) (types.ScanResult, error) {
+	s.calls++
+	if _, err := io.ReadAll(content); err != nil {
+		return types.ScanResult{}, err
+	}
+	return types.ScanResult{Verdict: s.verdict, Scanner: s.name}, s.err
+}
+
+var location = types.LocationURI{Scheme: "s3", Host: "bucket"}
+
+func open(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("hello")), nil
+}
+
+func TestPipelineScan(t *testing.T) {
+	first := &mockScanner{name: "first", verdict: types.ScanVerdictClean}
+	second := &mockScanner{name: "second", verdict: types.ScanVerdictInfected}
+	third := &mockScanner{name: "third", verdict: types.ScanVerdictClean}
+	pipeline := NewPipeline(10, first, second, third)
+
+	// Scanning stops at the first infected verdict
+	result, cached, err := pipeline.Scan(context.Background(), location, "id1", open)
+	require.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, types.ScanResult{Verdict: types.ScanVerdictInfected, Scanner: "second"}, result)
+	assert.Equal(t, 0, third.calls)
+
+	result, cached, err = pipeline.Scan(context.Background(), location, "id1", open)
+	require.NoError(t, err)
+	assert.True(t, cached)
+	assert.Equal(t, "second", result.Scanner)
+	assert.Equal(t, 1, first.calls)
+
+	// A file with the same id in another location is scanned
+	other := types.LocationURI{Scheme: "s3", Host: "other-bucket"}
+	_, cached, err = pipeline.Scan(context.Background(), other, "id1", open)
+	require.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, 2, first.calls)
+}
+
+func TestPipelineScanContent(t *testing.T) {
+	clean := &mockScanner{name: "clean", verdict: types.ScanVerdictClean}
+	infected := &mockScanner{name: "infected", verdict: types.ScanVerdictInfected}
+
+	result, err := NewPipeline(10, clean, infected).ScanContent(context.Background(), strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "infected", result.Scanner)
+	assert.Equal(t, 1, clean.calls)
+
+	result, err = NewPipeline(10, clean).ScanContent(context.Background(), strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, types.ScanResult{Verdict: types.ScanVerdictClean, Scanner: "clean"}, result)
+
+	failing := &mockScanner{name: "failing", err: types.NewErrServerF("unavailable")}
+	_, err = NewPipeline(10, failing, clean).ScanContent(context.Background(), strings.NewReader("hello"))
+	assert.ErrorIs(t, err, types.ErrServer)
+}
+
+func TestPipelineScanError(t *testing.T) {
+	scanner := &mockScanner{name: "failing", err: types.NewErrServerF("unavailable")}
+	pipeline := NewPipeline(10, scanner)
+
+	// Failures are not cached
+	for range 2 {
+		_, _, err := pipeline.Scan(context.Background(), location, "id1", open)
+		assert.ErrorIs(t, err, types.ErrServer)
+	}
+	assert.Equal(t, 2, scanner.calls)
+
+	_, _, err := pipeline.Scan(context.Background(), location, "id2", func(ctx context.Context) (io.ReadCloser, error) {
+		return nil, errors.New("not found")
+	})
+	assert.Error(t, err)
+	_, cached := pipeline.Cached(location, "id2")
+	assert.False(t, cached)
+}
+
+func TestPipelineCacheEviction(t *testing.T) {
+	scanner := &mockScanner{name: "clean", verdict: types.ScanVerdictClean}
+	pipeline := NewPipeline(2, scanner)
+	for _, fileId := range []types.FileId{"id1", "id2", "id3"} {
+		_, _, err := pipeline.Scan(context.Background(), location, fileId, open)
+		require.NoError(t, err)
+	}
+	_, cached := pipeline.Cached(location, "id1")
+	assert.False(t, cached)
+	_, cached = pipeline.Cached(location, "id3")
+	assert.True(t, cached)
+}
+
+func TestProvider(t *testing.T) {
+	pipeline, err := Provider(config.ScanConfigBundle{CacheSize: 10})
+	require.NoError(t, err)
+	assert.Nil(t, pipeline)
+
+	pipeline, err = Provider(config.ScanConfigBundle{
+		CacheSize: 10,
+		Clamd:     config.ClamdScanConfig{Address: "tcp://clamav:3310"},
+	})
+	require.NoError(t, err)
+	require.Len(t, pipeline.scanners, 1)
+	assert.Equal(t, "clamd", pipeline.scanners[0].Name())
+}
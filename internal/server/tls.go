@@ -0,0 +1,99 @@
+// Package server terminates TLS for the egress API, verifying client
+// certificates so that they can authenticate requests
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ucl-arc-tre/egress/internal/config"
+)
+
+// Builds a tls.Config for the server using the CA cert, server cert and
+// server key found in the cert dir
+// Expected files:
+//
+//	ca.crt  – CA certificate of client certificates
+//	tls.crt – server certificate for TLS handshake
+//	tls.key – private key for server certificate
+func TLSConfig(cfg config.ServerTLSConfigBundle) (*tls.Config, error) {
+	caPath := filepath.Clean(filepath.Join(cfg.CertDir, "ca.crt"))
+	certPath := filepath.Join(cfg.CertDir, "tls.crt")
+	keyPath := filepath.Join(cfg.CertDir, "tls.key")
+	clientAuth := tls.VerifyClientCertIfGiven
+	if cfg.RequireClientCert {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	// Fail-fast; check if the files are accessible initially
+	// but are loaded dynamically on every TLS handshake
+	if _, err := loadCertPool(caPath); err != nil {
+		return nil, err
+	}
+	if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+		return nil, fmt.Errorf("failed to access server cert/key: %w", err)
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Load cert/key and CA files upon every TLS handshake so that cert
+		// rotations are picked up without needing a service restart
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load server cert/key: %w", err)
+			}
+			clientCAs, err := loadCertPool(caPath)
+			if err != nil {
+				return nil, err
+			}
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{cert},
+				ClientCAs:    clientCAs,
+				ClientAuth:   clientAuth,
+			}, nil
+		},
+	}, nil
+}
+
+// Serve TLS until the process is interrupted or terminated, then shut the
+// server down gracefully within the duration
+func ServeTLS(server *http.Server, shutdownDuration time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// Server TLSConfig already has cert/key, so pass empty strings here
+		if err := server.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal().Err(err).Msg("Server exited with error")
+		}
+	}()
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownDuration)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Err(err).Msg("Failed to shut down server")
+	}
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	caCert, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA cert: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse CA cert")
+	}
+	return pool, nil
+}
@@ -0,0 +1,36 @@
+package azblob
+
+import (
+	"fmt"
+	"strings"
+
+	azBlob "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+
+	"github.com/ucl-arc-tre/egress/internal/config"
+)
+
+// newClient generates a new API client for Azure Blob Storage using either
+// a shared key or a SAS token. The service URL defaults to the public Azure
+// endpoint of the account, but may be overridden e.g. to point at Azurite
+func newClient(azConfig config.AzBlobStorageConfig) (*azBlob.Client, error) {
+	if azConfig.AccountName == "" {
+		return nil, fmt.Errorf("account name is required")
+	}
+	serviceURL := azConfig.ServiceURL
+	if serviceURL == "" {
+		serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net/", azConfig.AccountName)
+	}
+	switch {
+	case azConfig.AccountKey != "":
+		cred, err := azBlob.NewSharedKeyCredential(azConfig.AccountName, azConfig.AccountKey)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring shared key credential: %w", err)
+		}
+		return azBlob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+
+	case azConfig.SASToken != "":
+		sasURL := strings.TrimRight(serviceURL, "?") + "?" + strings.TrimPrefix(azConfig.SASToken, "?")
+		return azBlob.NewClientWithNoCredential(sasURL, nil)
+	}
+	return nil, fmt.Errorf("either an account key or a SAS token is required")
+}
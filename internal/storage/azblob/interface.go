@@ -0,0 +1,21 @@
+package azblob
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	azBlob "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+)
+
+type ClientInterface interface {
+	NewListBlobsFlatPager(
+		containerName string,
+		options *azBlob.ListBlobsFlatOptions,
+	) *runtime.Pager[azBlob.ListBlobsFlatResponse]
+	DownloadStream(
+		ctx context.Context,
+		containerName string,
+		blobName string,
+		options *azBlob.DownloadStreamOptions,
+	) (azBlob.DownloadStreamResponse, error)
+}
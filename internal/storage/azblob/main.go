@@ -0,0 +1,244 @@
+package azblob
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	azBlob "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Property of a blob used as its FileId
+type fileIdSource string
+
+const (
+	fileIdSourceETag       = fileIdSource("etag")
+	fileIdSourceContentMD5 = fileIdSource("content_md5")
+)
+
+var errActionsNotSupported = types.NewErrInvalidObjectF("storage actions are not supported by azblob storage")
+
+type Storage struct {
+	client       ClientInterface
+	accountName  string
+	fileIdSource fileIdSource
+}
+
+// This New constructor is called when the handler is created, which panics
+// if New returns an error. Therefore, errors are not wrapped in ErrServer
+func New(azConfig config.AzBlobStorageConfig) (*Storage, error) {
+	source, err := parseFileIdSource(azConfig.FileId)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newClient(azConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Blob client: %w", err)
+	}
+	return &Storage{
+		client:       client,
+		accountName:  azConfig.AccountName,
+		fileIdSource: source,
+	}, nil
+}
+
+func (s *Storage) List(ctx context.Context, location types.LocationURI) ([]types.FileMetadata, error) {
+	filesMetadata := []types.FileMetadata{}
+	containerName, err := s.containerName(location)
+	if err != nil {
+		return filesMetadata, err
+	}
+	pager := s.client.NewListBlobsFlatPager(containerName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return filesMetadata, types.NewErrServerF("failed to list blobs [%w]", err)
+		}
+		for _, item := range blobItems(page) {
+			metadata, ok := s.fileMetadata(item)
+			if !ok {
+				log.Error().Any("blob", item).Msg("Blob missing a required field")
+				continue
+			}
+			filesMetadata = append(filesMetadata, metadata)
+		}
+	}
+	log.Debug().Any("location", location).Str("containerName", containerName).Msg("Found blobs")
+	return filesMetadata, nil
+}
+
+func (s *Storage) Get(ctx context.Context, location types.LocationURI, fileId types.FileId, byteRange *types.ByteRange) (*types.File, error) {
+	containerName, err := s.containerName(location)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.blobWithFileId(ctx, containerName, fileId)
+	if err != nil {
+		return nil, err
+	}
+	// Conditional on the ETag of the listed blob so that the content cannot
+	// change between identifying the blob and downloading it
+	options := &azBlob.DownloadStreamOptions{
+		AccessConditions: &azBlob.AccessConditions{
+			ModifiedAccessConditions: &container.ModifiedAccessConditions{
+				IfMatch: item.Properties.ETag,
+			},
+		},
+	}
+	// Resolved against the listed size as suffix ranges are not supported
+	var contentRange *types.ContentRange
+	if byteRange != nil {
+		if item.Properties.ContentLength == nil {
+			return nil, types.NewErrServerF("blob missing content length")
+		}
+		resolved, err := byteRange.Resolve(*item.Properties.ContentLength)
+		if err != nil {
+			return nil, err
+		}
+		contentRange = &resolved
+		options.Range = blob.HTTPRange{Offset: resolved.First, Count: resolved.Length()}
+	}
+	output, err := s.client.DownloadStream(ctx, containerName, *item.Name, options)
+	if bloberror.HasCode(err, bloberror.ConditionNotMet, bloberror.BlobNotFound) {
+		return nil, types.NewErrNotFoundF("no blob with fileId [%v]", fileId)
+	} else if bloberror.HasCode(err, bloberror.InvalidRange) {
+		return nil, types.NewErrRangeNotSatisfiableF("range [%v] not satisfiable [%w]", byteRange, err)
+	} else if err != nil {
+		return nil, types.NewErrServerF("failed to download blob [%w]", err)
+	}
+	if output.ContentLength == nil {
+		if err := output.Body.Close(); err != nil {
+			return nil, types.NewErrServerF("failed to close [%w]", err)
+		}
+		return nil, types.NewErrServerF("blob missing content length")
+	}
+	if contentRange != nil {
+		return &types.File{Content: output.Body, Size: *item.Properties.ContentLength, Range: contentRange}, nil
+	}
+	return &types.File{Content: output.Body, Size: *output.ContentLength}, nil
+}
+
+// Stat gets the metadata of a file from the properties of its listed blob,
+// without downloading it
+func (s *Storage) Stat(ctx context.Context, location types.LocationURI, fileId types.FileId) (*types.FileMetadata, error) {
+	containerName, err := s.containerName(location)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.blobWithFileId(ctx, containerName, fileId)
+	if err != nil {
+		return nil, err
+	}
+	metadata, ok := s.fileMetadata(item)
+	if !ok {
+		return nil, types.NewErrServerF("blob with fileId [%v] missing a required field", fileId)
+	}
+	return &metadata, nil
+}
+
+// Actions after a download or rejection are not supported for Azure Blob
+// Storage
+func (s *Storage) Tag(ctx context.Context, location types.LocationURI, fileId types.FileId, tags map[string]string) error {
+	return errActionsNotSupported
+}
+
+func (s *Storage) Copy(ctx context.Context, location types.LocationURI, fileId types.FileId, prefix string) error {
+	return errActionsNotSupported
+}
+
+func (s *Storage) Delete(ctx context.Context, location types.LocationURI, fileId types.FileId) error {
+	return errActionsNotSupported
+}
+
+func (s *Storage) blobWithFileId(ctx context.Context, containerName string, fileId types.FileId) (*container.BlobItem, error) {
+	pager := s.client.NewListBlobsFlatPager(containerName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, types.NewErrServerF("failed to list blobs [%v]", err)
+		}
+		for _, item := range blobItems(page) {
+			if item.Name == nil || item.Properties.ETag == nil {
+				log.Error().Any("blob", item).Msg("Blob missing a required field")
+				continue
+			}
+			if id, ok := s.fileId(item); ok && id == fileId {
+				return item, nil
+			}
+		}
+	}
+	return nil, types.NewErrNotFoundF("no blob with fileId [%v]", fileId)
+}
+
+// Container name of the location, which must be in the configured account
+func (s *Storage) containerName(location types.LocationURI) (string, error) {
+	accountName, containerName, err := location.AccountAndContainer()
+	if err != nil {
+		return "", err
+	}
+	if accountName != s.accountName {
+		return "", types.NewErrInvalidObjectF("storage account [%s] is not configured", accountName)
+	}
+	return containerName, nil
+}
+
+func (s *Storage) fileMetadata(item *container.BlobItem) (types.FileMetadata, bool) {
+	fileId, ok := s.fileId(item)
+	if !ok || item.Name == nil || item.Properties.ContentLength == nil || item.Properties.LastModified == nil {
+		return types.FileMetadata{}, false
+	}
+	return types.FileMetadata{
+		Name:           *item.Name,
+		Id:             fileId,
+		Size:           *item.Properties.ContentLength,
+		LastModifiedAt: *item.Properties.LastModified,
+	}, true
+}
+
+func (s *Storage) fileId(item *container.BlobItem) (types.FileId, bool) {
+	if item.Properties == nil {
+		return "", false
+	}
+	switch s.fileIdSource {
+	case fileIdSourceContentMD5:
+		if len(item.Properties.ContentMD5) == 0 {
+			return "", false
+		}
+		return types.FileId(hex.EncodeToString(item.Properties.ContentMD5)), true
+	default:
+		if item.Properties.ETag == nil {
+			return "", false
+		}
+		return eTagToFileId(*item.Properties.ETag), true
+	}
+}
+
+func parseFileIdSource(value string) (fileIdSource, error) {
+	switch source := fileIdSource(value); source {
+	case "":
+		return fileIdSourceETag, nil
+	case fileIdSourceETag, fileIdSourceContentMD5:
+		return source, nil
+	}
+	return "", fmt.Errorf("unsupported file_id [%s]; must be etag or content_md5", value)
+}
+
+func blobItems(page azBlob.ListBlobsFlatResponse) []*container.BlobItem {
+	if page.Segment == nil {
+		return nil
+	}
+	return page.Segment.BlobItems
+}
+
+func eTagToFileId(eTag azcore.ETag) types.FileId {
+	return types.FileId(strings.ReplaceAll(string(eTag), `"`, ""))
+}
@@ -0,0 +1,125 @@
+package azblob
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+var (
+	blob1 = MockBlob{
+		Name:           "project-1/data.csv",
+		ETag:           `"0x8DC1"`,
+		ContentMD5:     []byte{0xab, 0xcd},
+		LastModifiedAt: time.Date(2026, 3, 4, 16, 4, 0, 0, time.UTC),
+		Content:        "id,result\n1,4.16\n",
+	}
+	blob2 = MockBlob{
+		Name:    "project-1/report.txt",
+		ETag:    `"0x8DC2"`,
+		Content: "Hello, World!",
+	}
+)
+
+func mustLocation(t *testing.T, raw string) types.LocationURI {
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return types.LocationURI(*u)
+}
+
+func TestListReturnsAllBlobs(t *testing.T) {
+	s := NewMock(MockClient{
+		Containers: map[MockContainerName]MockContainer{
+			"container1": {Blobs: []MockBlob{blob1, blob2}},
+		},
+	})
+	files, err := s.List(context.Background(), mustLocation(t, "azblob://devstoreaccount1/container1"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	assert.Equal(t, blob1.Name, files[0].Name)
+	assert.Equal(t, types.FileId("0x8DC1"), files[0].Id)
+	assert.Equal(t, int64(len(blob1.Content)), files[0].Size)
+	assert.Equal(t, blob1.LastModifiedAt, files[0].LastModifiedAt)
+	assert.Equal(t, types.FileId("0x8DC2"), files[1].Id)
+}
+
+func TestListContentMD5FileId(t *testing.T) {
+	s := NewMock(MockClient{
+		Containers: map[MockContainerName]MockContainer{
+			"container1": {Blobs: []MockBlob{blob1, blob2}},
+		},
+	})
+	s.fileIdSource = fileIdSourceContentMD5
+
+	files, err := s.List(context.Background(), mustLocation(t, "azblob://devstoreaccount1/container1"))
+	require.NoError(t, err)
+	require.Len(t, files, 1) // blob2 has no Content-MD5 so is skipped
+	assert.Equal(t, types.FileId("abcd"), files[0].Id)
+}
+
+func TestListOtherAccount(t *testing.T) {
+	s := NewMock(MockClient{})
+	_, err := s.List(context.Background(), mustLocation(t, "azblob://other/container1"))
+	assert.ErrorIs(t, err, types.ErrInvalidObject)
+}
+
+func TestGet(t *testing.T) {
+	s := NewMock(MockClient{
+		Containers: map[MockContainerName]MockContainer{
+			"container1": {Blobs: []MockBlob{blob1, blob2}},
+		},
+	})
+	location := mustLocation(t, "azblob://devstoreaccount1/container1")
+
+	file, err := s.Get(context.Background(), location, "0x8DC2", nil)
+	require.NoError(t, err)
+	content, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	assert.Equal(t, blob2.Content, string(content))
+	assert.Equal(t, int64(len(blob2.Content)), file.Size)
+
+	_, err = s.Get(context.Background(), location, "0x8DC3", nil)
+	assert.ErrorIs(t, err, types.ErrNotFound)
+}
+
+func TestStat(t *testing.T) {
+	s := NewMock(MockClient{
+		Containers: map[MockContainerName]MockContainer{
+			"container1": {Blobs: []MockBlob{blob1, blob2}},
+		},
+	})
+	location := mustLocation(t, "azblob://devstoreaccount1/container1")
+
+	metadata, err := s.Stat(context.Background(), location, "0x8DC1")
+	require.NoError(t, err)
+	assert.Equal(t, &types.FileMetadata{
+		Name:           blob1.Name,
+		Id:             "0x8DC1",
+		Size:           int64(len(blob1.Content)),
+		LastModifiedAt: blob1.LastModifiedAt,
+	}, metadata)
+
+	_, err = s.Stat(context.Background(), location, "0x8DC3")
+	assert.ErrorIs(t, err, types.ErrNotFound)
+}
+
+func TestParseFileIdSource(t *testing.T) {
+	source, err := parseFileIdSource("")
+	assert.NoError(t, err)
+	assert.Equal(t, fileIdSourceETag, source)
+
+	source, err = parseFileIdSource("content_md5")
+	assert.NoError(t, err)
+	assert.Equal(t, fileIdSourceContentMD5, source)
+
+	_, err = parseFileIdSource("sha1")
+	assert.Error(t, err)
+}
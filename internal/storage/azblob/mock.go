@@ -0,0 +1,122 @@
+package azblob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	azBlob "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+const MockAccountName = "devstoreaccount1"
+
+func NewMock(client MockClient) *Storage {
+	return &Storage{
+		client:       &client,
+		accountName:  MockAccountName,
+		fileIdSource: fileIdSourceETag,
+	}
+}
+
+type MockBlob struct {
+	Name           string
+	ETag           string
+	ContentMD5     []byte
+	LastModifiedAt time.Time
+	Content        string
+}
+
+type MockContainer struct {
+	Blobs []MockBlob
+}
+
+type MockContainerName = string
+
+type MockClient struct {
+	Containers map[MockContainerName]MockContainer
+}
+
+func (c *MockClient) NewListBlobsFlatPager(
+	containerName string,
+	_ *azBlob.ListBlobsFlatOptions,
+) *runtime.Pager[azBlob.ListBlobsFlatResponse] {
+	return runtime.NewPager(runtime.PagingHandler[azBlob.ListBlobsFlatResponse]{
+		More: func(azBlob.ListBlobsFlatResponse) bool { return false },
+		Fetcher: func(context.Context, *azBlob.ListBlobsFlatResponse) (azBlob.ListBlobsFlatResponse, error) {
+			mockContainer, exists := c.Containers[containerName]
+			if !exists {
+				return azBlob.ListBlobsFlatResponse{}, errors.New("container did not exist")
+			}
+			segment := &container.BlobFlatListSegment{}
+			for _, b := range mockContainer.Blobs {
+				segment.BlobItems = append(segment.BlobItems, &container.BlobItem{
+					Name: to.Ptr(b.Name),
+					Properties: &container.BlobProperties{
+						ETag:          to.Ptr(azcore.ETag(b.ETag)),
+						ContentMD5:    b.ContentMD5,
+						ContentLength: to.Ptr(int64(len(b.Content))),
+						LastModified:  to.Ptr(b.LastModifiedAt),
+					},
+				})
+			}
+			page := azBlob.ListBlobsFlatResponse{}
+			page.Segment = segment
+			return page, nil
+		},
+	})
+}
+
+func (c *MockClient) DownloadStream(
+	_ context.Context,
+	containerName string,
+	blobName string,
+	options *azBlob.DownloadStreamOptions,
+) (azBlob.DownloadStreamResponse, error) {
+	mockContainer, exists := c.Containers[containerName]
+	if !exists {
+		return azBlob.DownloadStreamResponse{}, errors.New("container did not exist")
+	}
+	idx := slices.IndexFunc(mockContainer.Blobs, func(b MockBlob) bool {
+		return b.Name == blobName
+	})
+	if idx < 0 {
+		return azBlob.DownloadStreamResponse{}, mockResponseError(http.StatusNotFound, bloberror.BlobNotFound)
+	}
+	b := mockContainer.Blobs[idx]
+	// Enforce the If-Match precondition
+	if options != nil && options.AccessConditions != nil && options.AccessConditions.ModifiedAccessConditions != nil {
+		ifMatch := options.AccessConditions.ModifiedAccessConditions.IfMatch
+		if ifMatch != nil && string(*ifMatch) != b.ETag {
+			return azBlob.DownloadStreamResponse{}, mockResponseError(http.StatusPreconditionFailed, bloberror.ConditionNotMet)
+		}
+	}
+	content := b.Content
+	if options != nil && options.Range.Count > 0 {
+		offset := options.Range.Offset
+		if offset >= int64(len(content)) {
+			return azBlob.DownloadStreamResponse{}, mockResponseError(http.StatusRequestedRangeNotSatisfiable, bloberror.InvalidRange)
+		}
+		content = content[offset:min(offset+options.Range.Count, int64(len(content)))]
+	}
+	output := azBlob.DownloadStreamResponse{}
+	output.DownloadResponse = blob.DownloadResponse{
+		Body:          io.NopCloser(strings.NewReader(content)),
+		ContentLength: to.Ptr(int64(len(content))),
+		ETag:          to.Ptr(azcore.ETag(b.ETag)),
+	}
+	return output, nil
+}
+
+func mockResponseError(statusCode int, code bloberror.Code) error {
+	return &azcore.ResponseError{StatusCode: statusCode, ErrorCode: string(code)}
+}
@@ -0,0 +1,96 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Error responses common to the file action endpoints
+type actionErrorResponses struct {
+	json400, json403, json404, json412, json500 *ErrorResponse
+}
+
+// Tag adds the tags to the file, keeping its other existing tags
+func (s *Storage) Tag(ctx context.Context, location types.LocationURI, fileId types.FileId, tags map[string]string) error {
+	client, err := s.getter.Get(location)
+	if err != nil {
+		return err
+	}
+	key, err := s.keyForFileId(ctx, client, fileId)
+	if err != nil {
+		return err
+	}
+	params := &PutFileTagsParams{Key: key, IfMatch: fmt.Sprintf(`"%s"`, fileId)}
+	resp, err := client.PutFileTagsWithResponse(ctx, params, PutFileTagsJSONRequestBody{Tags: tags})
+	if err != nil {
+		return types.NewErrServerF("[generic] failed to tag file: %w", err)
+	}
+	return actionError("tag", fileId, resp.StatusCode(), actionErrorResponses{
+		resp.JSON400, resp.JSON403, resp.JSON404, resp.JSON412, resp.JSON500,
+	})
+}
+
+// Copy copies the file to the key with the prefix prepended
+func (s *Storage) Copy(ctx context.Context, location types.LocationURI, fileId types.FileId, prefix string) error {
+	client, err := s.getter.Get(location)
+	if err != nil {
+		return err
+	}
+	key, err := s.keyForFileId(ctx, client, fileId)
+	if err != nil {
+		return err
+	}
+	params := &PostFileCopyParams{Key: key, IfMatch: fmt.Sprintf(`"%s"`, fileId)}
+	resp, err := client.PostFileCopyWithResponse(ctx, params, PostFileCopyJSONRequestBody{DestinationKey: prefix + key})
+	if err != nil {
+		return types.NewErrServerF("[generic] failed to copy file: %w", err)
+	}
+	return actionError("copy", fileId, resp.StatusCode(), actionErrorResponses{
+		resp.JSON400, resp.JSON403, resp.JSON404, resp.JSON412, resp.JSON500,
+	})
+}
+
+// Delete deletes the file
+func (s *Storage) Delete(ctx context.Context, location types.LocationURI, fileId types.FileId) error {
+	client, err := s.getter.Get(location)
+	if err != nil {
+		return err
+	}
+	key, err := s.keyForFileId(ctx, client, fileId)
+	if err != nil {
+		return err
+	}
+	params := &DeleteFileParams{Key: key, IfMatch: fmt.Sprintf(`"%s"`, fileId)}
+	resp, err := client.DeleteFileWithResponse(ctx, params)
+	if err != nil {
+		return types.NewErrServerF("[generic] failed to delete file: %w", err)
+	}
+	return actionError("delete", fileId, resp.StatusCode(), actionErrorResponses{
+		resp.JSON400, resp.JSON403, resp.JSON404, resp.JSON412, resp.JSON500,
+	})
+}
+
+func actionError(action string, fileId types.FileId, statusCode int, resps actionErrorResponses) error {
+	errmsg := fmt.Sprintf("[generic] failed to %s file", action)
+	switch statusCode {
+	case http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		m := extractResponseMessageOrDefault(resps.json404, "file not found")
+		return types.NewErrNotFoundF("%s: %s", errmsg, m)
+	case http.StatusPreconditionFailed:
+		return types.NewErrNotFoundF("%s: ETag mismatch for fileId [%v]", errmsg, fileId)
+	case http.StatusBadRequest:
+		m := extractResponseMessageOrDefault(resps.json400, "bad request")
+		return types.NewErrInvalidObjectF("%s: %s", errmsg, m)
+	case http.StatusForbidden:
+		m := extractResponseMessageOrDefault(resps.json403, "forbidden")
+		return types.NewErrServerF("%s: %s", errmsg, m)
+	default:
+		m := extractResponseMessageOrDefault(resps.json500, "unexpected error")
+		return types.NewErrServerF("%s: %s (status %d)", errmsg, m, statusCode)
+	}
+}
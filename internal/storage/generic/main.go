@@ -73,7 +73,7 @@ func (s *Storage) List(ctx context.Context, location types.LocationURI) ([]types
 	return result, nil
 }
 
-func (s *Storage) Get(ctx context.Context, location types.LocationURI, fileId types.FileId) (*types.File, error) {
+func (s *Storage) Get(ctx context.Context, location types.LocationURI, fileId types.FileId, byteRange *types.ByteRange) (*types.File, error) {
 	client, err := s.getter.Get(location)
 	if err != nil {
 		return nil, err
@@ -84,15 +84,31 @@ func (s *Storage) Get(ctx context.Context, location types.LocationURI, fileId ty
 	}
 
 	errmsg := "[generic] failed to get file"
-	resp, err := client.GetFileWithResponse(ctx, &GetFileParams{
+	params := &GetFileParams{
 		Key:     key,
 		IfMatch: fmt.Sprintf(`"%s"`, fileId),
-	})
+	}
+	if byteRange != nil {
+		params.Range = ptr(byteRange.String())
+	}
+	resp, err := client.GetFileWithResponse(ctx, params)
 	if err != nil {
 		return nil, types.NewErrServerF("%s: %w", errmsg, err)
 	}
 	switch resp.StatusCode() {
 	case http.StatusOK: // Handled after switch
+	case http.StatusPartialContent:
+		contentRange, size, err := types.ParseContentRange(resp.HTTPResponse.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, types.NewErrServerF("%s: %w", errmsg, err)
+		}
+		return &types.File{
+			Content: io.NopCloser(bytes.NewReader(resp.Body)),
+			Size:    size,
+			Range:   &contentRange,
+		}, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		return nil, types.NewErrRangeNotSatisfiableF("%s: range [%v] not satisfiable", errmsg, byteRange)
 	case http.StatusNotFound:
 		m := extractResponseMessageOrDefault(resp.JSON404, "file not found")
 		return nil, types.NewErrNotFoundF("%s: %s", errmsg, m)
@@ -112,6 +128,53 @@ func (s *Storage) Get(ctx context.Context, location types.LocationURI, fileId ty
 	}, nil
 }
 
+// Stat gets the metadata of a file from the headers of a HEAD request,
+// without getting its content
+func (s *Storage) Stat(ctx context.Context, location types.LocationURI, fileId types.FileId) (*types.FileMetadata, error) {
+	client, err := s.getter.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.keyForFileId(ctx, client, fileId)
+	if err != nil {
+		return nil, err
+	}
+
+	errmsg := "[generic] failed to stat file"
+	resp, err := client.HeadFileWithResponse(ctx, &HeadFileParams{
+		Key:     key,
+		IfMatch: fmt.Sprintf(`"%s"`, fileId),
+	})
+	if err != nil {
+		return nil, types.NewErrServerF("%s: %w", errmsg, err)
+	}
+	switch resp.StatusCode() {
+	case http.StatusOK: // Handled after switch
+	case http.StatusNotFound:
+		return nil, types.NewErrNotFoundF("%s: file not found", errmsg)
+	case http.StatusBadRequest:
+		return nil, types.NewErrInvalidObjectF("%s: bad request", errmsg)
+	case http.StatusPreconditionFailed:
+		return nil, types.NewErrNotFoundF("%s: ETag mismatch for fileId [%v]", errmsg, fileId)
+	default:
+		return nil, types.NewErrServerF("%s: unexpected status %d", errmsg, resp.StatusCode())
+	}
+
+	if resp.HTTPResponse.ContentLength < 0 {
+		return nil, types.NewErrServerF("%s: missing Content-Length", errmsg)
+	}
+	lastModifiedAt, err := http.ParseTime(resp.HTTPResponse.Header.Get("Last-Modified"))
+	if err != nil {
+		return nil, types.NewErrServerF("%s: invalid Last-Modified [%w]", errmsg, err)
+	}
+	return &types.FileMetadata{
+		Name:           key,
+		Id:             fileId,
+		Size:           resp.HTTPResponse.ContentLength,
+		LastModifiedAt: lastModifiedAt,
+	}, nil
+}
+
 func (s *Storage) keyForFileId(ctx context.Context, client ClientWithResponsesInterface, fileId types.FileId) (string, error) {
 	resp, err := client.GetFilesWithResponse(ctx, &GetFilesParams{})
 	if err != nil {
@@ -135,6 +198,10 @@ func extractResponseMessageOrDefault(body *ErrorResponse, fallback string) strin
 	return fallback
 }
 
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func stripQuotes(s string) string {
 	return strings.ReplaceAll(s, `"`, "")
 }
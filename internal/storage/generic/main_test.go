@@ -91,7 +91,7 @@ func TestGetFileContentByFileId(t *testing.T) {
 	ms := NewWithMock(&MockClient{
 		Files: []MockFile{file1, file2},
 	})
-	f, err := ms.Get(context.Background(), location, types.FileId("abc123"))
+	f, err := ms.Get(context.Background(), location, types.FileId("abc123"), nil)
 
 	require.NoError(t, err)
 	require.NotNil(t, f)
@@ -103,11 +103,48 @@ func TestGetFileContentByFileId(t *testing.T) {
 	assert.Equal(t, int64(len(file1.Content)), f.Size)
 }
 
+func TestGetFileRange(t *testing.T) {
+	ms := NewWithMock(&MockClient{
+		Files: []MockFile{file1},
+	})
+	f, err := ms.Get(context.Background(), location, types.FileId("abc123"), &types.ByteRange{Start: 1, Length: 2})
+
+	require.NoError(t, err)
+	defer f.Content.Close() // nolint:errcheck
+
+	data, err := io.ReadAll(f.Content)
+	require.NoError(t, err)
+	assert.Equal(t, file1.Content[1:3], string(data))
+	assert.Equal(t, int64(len(file1.Content)), f.Size)
+	assert.Equal(t, &types.ContentRange{First: 1, Last: 2}, f.Range)
+
+	_, err = ms.Get(context.Background(), location, types.FileId("abc123"), &types.ByteRange{Start: 1000})
+	assert.ErrorIs(t, err, types.ErrRangeNotSatisfiable)
+}
+
+func TestStatFile(t *testing.T) {
+	ms := NewWithMock(&MockClient{
+		Files: []MockFile{file1, file2},
+	})
+	metadata, err := ms.Stat(context.Background(), location, types.FileId("def456"))
+
+	require.NoError(t, err)
+	assert.Equal(t, &types.FileMetadata{
+		Name:           file2.Key,
+		Id:             "def456",
+		Size:           int64(len(file2.Content)),
+		LastModifiedAt: file2.LastModifiedAt,
+	}, metadata)
+
+	_, err = ms.Stat(context.Background(), location, types.FileId("nonexistent"))
+	assert.ErrorIs(t, err, types.ErrNotFound)
+}
+
 func TestGetFileIdNotFound(t *testing.T) {
 	ms := NewWithMock(&MockClient{
 		Files: []MockFile{file1},
 	})
-	_, err := ms.Get(context.Background(), location, types.FileId("nonexistent"))
+	_, err := ms.Get(context.Background(), location, types.FileId("nonexistent"), nil)
 
 	require.Error(t, err)
 	assert.ErrorIs(t, err, types.ErrNotFound)
@@ -117,7 +154,7 @@ func TestGetPropagatesClientListError(t *testing.T) {
 	ms := NewWithMock(&MockClient{
 		ForceListErr: errors.New("network error"),
 	})
-	_, err := ms.Get(context.Background(), location, types.FileId("abc123"))
+	_, err := ms.Get(context.Background(), location, types.FileId("abc123"), nil)
 
 	require.Error(t, err)
 	assert.ErrorIs(t, err, types.ErrServer)
@@ -128,7 +165,7 @@ func TestGetPropagatesClientError(t *testing.T) {
 		Files:       []MockFile{file1},
 		ForceGetErr: errors.New("network error"),
 	})
-	_, err := ms.Get(context.Background(), location, types.FileId("abc123"))
+	_, err := ms.Get(context.Background(), location, types.FileId("abc123"), nil)
 
 	require.Error(t, err)
 	assert.ErrorIs(t, err, types.ErrServer)
@@ -140,3 +177,23 @@ func TestStripQuotes(t *testing.T) {
 	assert.Equal(t, "", stripQuotes(`""`))
 	assert.Equal(t, "", stripQuotes(``))
 }
+
+func TestActions(t *testing.T) {
+	client := &MockClient{Files: []MockFile{file1, file2}}
+	ms := NewWithMock(client)
+	ctx := context.Background()
+
+	require.NoError(t, ms.Tag(ctx, location, "abc123", map[string]string{"egress-status": "downloaded"}))
+	assert.Equal(t, map[string]string{"egress-status": "downloaded"}, client.Files[0].Tags)
+
+	require.NoError(t, ms.Copy(ctx, location, "abc123", "archive/"))
+	require.Len(t, client.Files, 3)
+	assert.Equal(t, "archive/project-1/data.csv", client.Files[2].Key)
+	assert.Equal(t, file1.Content, client.Files[2].Content)
+
+	require.NoError(t, ms.Delete(ctx, location, "def456"))
+	assert.Len(t, client.Files, 2)
+
+	assert.ErrorIs(t, ms.Delete(ctx, location, "def456"), types.ErrNotFound)
+	assert.ErrorIs(t, ms.Tag(ctx, location, "nonexistent", map[string]string{"a": "b"}), types.ErrNotFound)
+}
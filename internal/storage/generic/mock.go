@@ -3,9 +3,13 @@ package generic
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -34,6 +38,7 @@ type MockFile struct {
 	ETag           string
 	LastModifiedAt time.Time
 	Content        string
+	Tags           map[string]string
 }
 
 type MockClient struct {
@@ -95,6 +100,9 @@ func (c *MockClient) GetFileWithResponse(
 			}, nil
 		}
 		content := []byte(f.Content)
+		if params.Range != nil {
+			return mockRangeResponse(content, *params.Range)
+		}
 		return &GetFileResponse{
 			Body: content,
 			HTTPResponse: &http.Response{
@@ -109,3 +117,188 @@ func (c *MockClient) GetFileWithResponse(
 		JSON404:      &NotFound{Message: fmt.Sprintf("file not found: %s", params.Key)},
 	}, nil
 }
+
+func (c *MockClient) HeadFileWithResponse(
+	_ context.Context,
+	params *HeadFileParams,
+	_ ...RequestEditorFn,
+) (*HeadFileResponse, error) {
+	for _, f := range c.Files {
+		if f.Key != params.Key {
+			continue
+		}
+		if f.ETag != params.IfMatch {
+			return &HeadFileResponse{
+				HTTPResponse: &http.Response{StatusCode: http.StatusPreconditionFailed},
+			}, nil
+		}
+		return &HeadFileResponse{
+			HTTPResponse: &http.Response{
+				StatusCode:    http.StatusOK,
+				ContentLength: int64(len(f.Content)),
+				Header: http.Header{
+					"Etag":          {f.ETag},
+					"Last-Modified": {f.LastModifiedAt.UTC().Format(http.TimeFormat)},
+				},
+			},
+		}, nil
+	}
+	return &HeadFileResponse{
+		HTTPResponse: &http.Response{StatusCode: http.StatusNotFound},
+	}, nil
+}
+
+func mockRangeResponse(content []byte, value string) (*GetFileResponse, error) {
+	byteRange, err := types.ParseByteRange(value)
+	if err != nil {
+		return &GetFileResponse{
+			HTTPResponse: &http.Response{StatusCode: http.StatusBadRequest},
+			JSON400:      &BadRequest{Message: err.Error()},
+		}, nil
+	}
+	contentRange, err := byteRange.Resolve(int64(len(content)))
+	if err != nil {
+		return &GetFileResponse{
+			HTTPResponse: &http.Response{StatusCode: http.StatusRequestedRangeNotSatisfiable},
+			JSON416:      &RangeNotSatisfiable{Message: err.Error()},
+		}, nil
+	}
+	part := content[contentRange.First : contentRange.Last+1]
+	return &GetFileResponse{
+		Body: part,
+		HTTPResponse: &http.Response{
+			StatusCode:    http.StatusPartialContent,
+			ContentLength: int64(len(part)),
+			Header:        http.Header{"Content-Range": {contentRange.Header(int64(len(content)))}},
+			Body:          io.NopCloser(bytes.NewReader(part)),
+		},
+	}, nil
+}
+
+func (c *MockClient) PutFileWithBodyWithResponse(
+	_ context.Context,
+	params *PutFileParams,
+	_ string,
+	body io.Reader,
+	_ ...RequestEditorFn,
+) (*PutFileResponse, error) {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	file := MockFile{
+		Key:            params.Key,
+		ETag:           fmt.Sprintf(`"%x"`, sha256.Sum256(content)),
+		LastModifiedAt: time.Now(),
+		Content:        string(content),
+	}
+	metadata := FileMetadata{Key: file.Key, Etag: file.ETag, Size: int64(len(content)), LastModified: file.LastModifiedAt}
+	idx := slices.IndexFunc(c.Files, func(f MockFile) bool { return f.Key == params.Key })
+	if idx < 0 {
+		c.Files = append(c.Files, file)
+		return &PutFileResponse{
+			HTTPResponse: &http.Response{StatusCode: http.StatusCreated},
+			JSON201:      &metadata,
+		}, nil
+	}
+	if params.IfNoneMatch != nil {
+		return &PutFileResponse{
+			HTTPResponse: &http.Response{StatusCode: http.StatusPreconditionFailed},
+			JSON412:      &PreconditionFailed{Message: "file already exists"},
+		}, nil
+	}
+	c.Files[idx] = file
+	return &PutFileResponse{
+		HTTPResponse: &http.Response{StatusCode: http.StatusOK},
+		JSON200:      &metadata,
+	}, nil
+}
+
+func (c *MockClient) PutFileTagsWithResponse(
+	_ context.Context,
+	params *PutFileTagsParams,
+	body PutFileTagsJSONRequestBody,
+	_ ...RequestEditorFn,
+) (*PutFileTagsResponse, error) {
+	idx, status := c.matchingFile(params.Key, params.IfMatch)
+	if idx < 0 {
+		return &PutFileTagsResponse{HTTPResponse: &http.Response{StatusCode: status}}, nil
+	}
+	if c.Files[idx].Tags == nil {
+		c.Files[idx].Tags = map[string]string{}
+	}
+	maps.Copy(c.Files[idx].Tags, body.Tags)
+	return &PutFileTagsResponse{HTTPResponse: &http.Response{StatusCode: http.StatusNoContent}}, nil
+}
+
+func (c *MockClient) PutFileTagsWithBodyWithResponse(
+	ctx context.Context,
+	params *PutFileTagsParams,
+	_ string,
+	body io.Reader,
+	reqEditors ...RequestEditorFn,
+) (*PutFileTagsResponse, error) {
+	var request PutFileTagsJSONRequestBody
+	if err := json.NewDecoder(body).Decode(&request); err != nil {
+		return nil, err
+	}
+	return c.PutFileTagsWithResponse(ctx, params, request, reqEditors...)
+}
+
+func (c *MockClient) PostFileCopyWithResponse(
+	_ context.Context,
+	params *PostFileCopyParams,
+	body PostFileCopyJSONRequestBody,
+	_ ...RequestEditorFn,
+) (*PostFileCopyResponse, error) {
+	idx, status := c.matchingFile(params.Key, params.IfMatch)
+	if idx < 0 {
+		return &PostFileCopyResponse{HTTPResponse: &http.Response{StatusCode: status}}, nil
+	}
+	copied := c.Files[idx]
+	copied.Key = body.DestinationKey
+	copied.Tags = maps.Clone(copied.Tags)
+	c.Files = slices.DeleteFunc(c.Files, func(f MockFile) bool { return f.Key == body.DestinationKey })
+	c.Files = append(c.Files, copied)
+	return &PostFileCopyResponse{HTTPResponse: &http.Response{StatusCode: http.StatusNoContent}}, nil
+}
+
+func (c *MockClient) PostFileCopyWithBodyWithResponse(
+	ctx context.Context,
+	params *PostFileCopyParams,
+	_ string,
+	body io.Reader,
+	reqEditors ...RequestEditorFn,
+) (*PostFileCopyResponse, error) {
+	var request PostFileCopyJSONRequestBody
+	if err := json.NewDecoder(body).Decode(&request); err != nil {
+		return nil, err
+	}
+	return c.PostFileCopyWithResponse(ctx, params, request, reqEditors...)
+}
+
+func (c *MockClient) DeleteFileWithResponse(
+	_ context.Context,
+	params *DeleteFileParams,
+	_ ...RequestEditorFn,
+) (*DeleteFileResponse, error) {
+	idx, status := c.matchingFile(params.Key, params.IfMatch)
+	if idx < 0 {
+		return &DeleteFileResponse{HTTPResponse: &http.Response{StatusCode: status}}, nil
+	}
+	c.Files = slices.Delete(c.Files, idx, idx+1)
+	return &DeleteFileResponse{HTTPResponse: &http.Response{StatusCode: http.StatusNoContent}}, nil
+}
+
+// Index of the file with the key and ETag, otherwise -1 and the status
+// code of the error response
+func (c *MockClient) matchingFile(key string, ifMatch string) (int, int) {
+	idx := slices.IndexFunc(c.Files, func(f MockFile) bool { return f.Key == key })
+	if idx < 0 {
+		return -1, http.StatusNotFound
+	}
+	if c.Files[idx].ETag != ifMatch {
+		return -1, http.StatusPreconditionFailed
+	}
+	return idx, http.StatusOK
+}
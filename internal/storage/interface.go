@@ -8,5 +8,15 @@ import (
 
 type Interface interface {
 	List(ctx context.Context, location types.LocationURI) ([]types.FileMetadata, error)
-	Get(ctx context.Context, location types.LocationURI, fileId types.FileId) (*types.File, error)
+	// Get the file, or only the given byte range of it if not nil
+	Get(ctx context.Context, location types.LocationURI, fileId types.FileId, byteRange *types.ByteRange) (*types.File, error)
+	// Get the metadata of the file without its content
+	Stat(ctx context.Context, location types.LocationURI, fileId types.FileId) (*types.FileMetadata, error)
+
+	// Actions taken on the file after it is downloaded or rejected
+	// Add the tags to the file, keeping its other tags
+	Tag(ctx context.Context, location types.LocationURI, fileId types.FileId, tags map[string]string) error
+	// Copy the file to its key with the prefix prepended
+	Copy(ctx context.Context, location types.LocationURI, fileId types.FileId, prefix string) error
+	Delete(ctx context.Context, location types.LocationURI, fileId types.FileId) error
 }
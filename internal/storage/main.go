@@ -18,5 +18,10 @@ func ParseLocation(raw string) (*types.LocationURI, error) {
 	if uri.Path != "" && uri.StorageProvider() == types.StorageProviderS3 {
 		return nil, types.NewErrInvalidObjectF("s3 location [%s] cannot have path", raw)
 	}
+	if uri.StorageProvider() == types.StorageProviderAzBlob {
+		if _, _, err := uri.AccountAndContainer(); err != nil {
+			return nil, err
+		}
+	}
 	return &uri, nil
 }
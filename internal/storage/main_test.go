@@ -28,3 +28,15 @@ func TestParseLocationEmptyLocation(t *testing.T) {
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, types.ErrInvalidObject)
 }
+
+func TestParseLocationAzBlob(t *testing.T) {
+	_, err := ParseLocation("azblob://account1")
+	assert.ErrorIs(t, err, types.ErrInvalidObject)
+
+	_, err = ParseLocation("azblob://account1/container1/with/path")
+	assert.ErrorIs(t, err, types.ErrInvalidObject)
+
+	location, err := ParseLocation("azblob://account1/container1")
+	assert.NoError(t, err)
+	assert.Equal(t, types.StorageProviderAzBlob, location.StorageProvider())
+}
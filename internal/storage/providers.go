@@ -4,11 +4,34 @@ import (
 	"fmt"
 
 	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/storage/azblob"
 	"github.com/ucl-arc-tre/egress/internal/storage/generic"
 	"github.com/ucl-arc-tre/egress/internal/storage/s3"
 	"github.com/ucl-arc-tre/egress/internal/types"
 )
 
+// Storage for the given backends. A single backend is used directly,
+// otherwise requests are routed to the backend serving their location
+func Providers(cfgs []config.StorageConfigBundle) (Interface, error) {
+	if len(cfgs) == 1 {
+		return Provider(cfgs[0])
+	}
+	if len(cfgs) == 0 {
+		return nil, fmt.Errorf("no storage backends configured")
+	}
+	router := &Router{}
+	for _, cfg := range cfgs {
+		storage, err := Provider(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialise backend %s: %w", cfg.Name, err)
+		}
+		if err := router.add(cfg.Name, types.StorageProvider(cfg.Provider), cfg.Hosts, storage); err != nil {
+			return nil, err
+		}
+	}
+	return router, nil
+}
+
 func Provider(cfg config.StorageConfigBundle) (Interface, error) {
 	switch types.StorageProvider(cfg.Provider) {
 	case types.StorageProviderS3:
@@ -24,6 +47,13 @@ func Provider(cfg config.StorageConfigBundle) (Interface, error) {
 			return nil, fmt.Errorf("failed to initialise generic provider: %w", err)
 		}
 		return storage, nil
+
+	case types.StorageProviderAzBlob:
+		storage, err := azblob.New(cfg.AzBlob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialise azblob provider: %w", err)
+		}
+		return storage, nil
 	}
 	// An unsupported backend should have been failed by Helm
 	// So, this is fallback
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/storage/azblob"
 	"github.com/ucl-arc-tre/egress/internal/storage/generic"
 	"github.com/ucl-arc-tre/egress/internal/storage/s3"
 	"github.com/ucl-arc-tre/egress/internal/types"
@@ -66,6 +67,23 @@ storage:
 	assert.IsType(t, &generic.Storage{}, storage)
 }
 
+func TestAzBlobStorageProvider(t *testing.T) {
+	cfg := config.StorageConfigBundle{
+		Provider: string(types.StorageProviderAzBlob),
+		AzBlob: config.AzBlobStorageConfig{
+			AccountName: "devstoreaccount1",
+			SASToken:    "sv=2024-01-01&sig=abc",
+		},
+	}
+	storage, err := Provider(cfg)
+	assert.NoError(t, err)
+	assert.IsType(t, &azblob.Storage{}, storage)
+
+	cfg.AzBlob.SASToken = ""
+	_, err = Provider(cfg)
+	assert.Error(t, err)
+}
+
 func TestUnsupportedProvider(t *testing.T) {
 	cfg := config.StorageConfigBundle{
 		Provider: "blah",
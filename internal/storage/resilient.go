@@ -0,0 +1,297 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"math/rand/v2"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ucl-arc-tre/egress/internal/config"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+type BreakerState string
+
+const (
+	BreakerClosed   = BreakerState("closed")    // Requests are made
+	BreakerOpen     = BreakerState("open")      // Requests fail fast until the cooldown has passed
+	BreakerHalfOpen = BreakerState("half-open") // Requests are made; the next result closes or opens the breaker
+)
+
+// Reports how many circuit breakers are in each state
+type BreakerReporter interface {
+	BreakerCounts() map[BreakerState]int
+}
+
+// Max number of breakers kept, since locations are given by requests
+const maxBreakers = 1000
+
+// Resilient wraps storage to retry requests that fail with a server error,
+// with jittered exponential backoff. Only the idempotent List, Get and Stat
+// are retried. Get is retried only until the file stream is opened.
+// A circuit breaker per host fails requests fast once the host has failed
+// repeatedly, so a failing backend is not retried indefinitely
+type Resilient struct {
+	storage Interface
+	cfg     config.StorageRetryConfigBundle
+
+	mu       sync.Mutex
+	breakers map[string]*breaker // Keyed by host
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+type breaker struct {
+	state    BreakerState
+	failures int // Consecutive failures
+	openedAt time.Time
+	trial    bool // Whether the single request allowed while half-open is in flight
+}
+
+func NewResilient(storage Interface, cfg config.StorageRetryConfigBundle) *Resilient {
+	return &Resilient{
+		storage:  storage,
+		cfg:      cfg,
+		breakers: map[string]*breaker{},
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+}
+
+func (r *Resilient) List(ctx context.Context, location types.LocationURI) ([]types.FileMetadata, error) {
+	return retry(ctx, r, location, func() ([]types.FileMetadata, error) {
+		return r.storage.List(ctx, location)
+	})
+}
+
+func (r *Resilient) Get(ctx context.Context, location types.LocationURI, fileId types.FileId, byteRange *types.ByteRange) (*types.File, error) {
+	return retry(ctx, r, location, func() (*types.File, error) {
+		return r.storage.Get(ctx, location, fileId, byteRange)
+	})
+}
+
+func (r *Resilient) Stat(ctx context.Context, location types.LocationURI, fileId types.FileId) (*types.FileMetadata, error) {
+	return retry(ctx, r, location, func() (*types.FileMetadata, error) {
+		return r.storage.Stat(ctx, location, fileId)
+	})
+}
+
+// Actions modify storage so are not retried, but fail fast if the
+// host's breaker is open
+func (r *Resilient) Tag(ctx context.Context, location types.LocationURI, fileId types.FileId, tags map[string]string) error {
+	return r.act(ctx, location, func() error {
+		return r.storage.Tag(ctx, location, fileId, tags)
+	})
+}
+
+func (r *Resilient) Copy(ctx context.Context, location types.LocationURI, fileId types.FileId, prefix string) error {
+	return r.act(ctx, location, func() error {
+		return r.storage.Copy(ctx, location, fileId, prefix)
+	})
+}
+
+func (r *Resilient) Delete(ctx context.Context, location types.LocationURI, fileId types.FileId) error {
+	return r.act(ctx, location, func() error {
+		return r.storage.Delete(ctx, location, fileId)
+	})
+}
+
+// Number of breakers in each state. Hosts are not reported since they
+// are given by requests
+func (r *Resilient) BreakerCounts() map[BreakerState]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := map[BreakerState]int{BreakerClosed: 0, BreakerOpen: 0, BreakerHalfOpen: 0}
+	for _, b := range r.breakers {
+		counts[b.state]++
+	}
+	return counts
+}
+
+func (r *Resilient) act(ctx context.Context, location types.LocationURI, call func() error) error {
+	if err := r.checkBreaker(location); err != nil {
+		return err
+	}
+	err := call()
+	r.record(ctx, locationKey(location), err)
+	return err
+}
+
+func retry[T any](ctx context.Context, r *Resilient, location types.LocationURI, call func() (T, error)) (T, error) {
+	key := locationKey(location)
+	var result T
+	var err error
+	for attempt := range r.cfg.MaxAttempts {
+		if attempt > 0 {
+			if sleepErr := r.sleep(ctx, r.backoff(attempt)); sleepErr != nil {
+				return result, err // Cancelled, so return the last failure
+			}
+		}
+		if err := r.checkBreaker(location); err != nil {
+			return result, err
+		}
+		result, err = call()
+		r.record(ctx, key, err)
+		if ctx.Err() != nil || !isRetryable(err) {
+			return result, err
+		}
+		log.Warn().Err(err).
+			Str("location", key).
+			Int("attempt", attempt+1).
+			Int("maxAttempts", r.cfg.MaxAttempts).
+			Msg("Storage request failed")
+	}
+	return result, err
+}
+
+func (r *Resilient) checkBreaker(location types.LocationURI) error {
+	if key := locationKey(location); !r.allow(key) {
+		return types.NewErrUnavailableF("storage host [%s] is unavailable after repeated failures", key)
+	}
+	return nil
+}
+
+// Record the result of a request to a host. Failures from cancellation
+// are not of the host, so only end a half-open trial
+func (r *Resilient) record(ctx context.Context, key string, err error) {
+	switch {
+	case ctx.Err() != nil:
+		r.release(key)
+	case isRetryable(err):
+		r.recordFailure(key)
+	default:
+		r.recordSuccess(key)
+	}
+}
+
+// Only server errors may be transient; other errors would fail again, as
+// would server errors from a client error response e.g. S3 AccessDenied
+func isRetryable(err error) bool {
+	if !errors.Is(err, types.ErrServer) {
+		return false
+	}
+	var response interface{ HTTPStatusCode() int }
+	if errors.As(err, &response) {
+		status := response.HTTPStatusCode()
+		return status < 400 || status >= 500
+	}
+	return true
+}
+
+// Random wait before a retry, up to a maximum that doubles for each retry
+func (r *Resilient) backoff(attempt int) time.Duration {
+	ceiling := r.cfg.InitialBackoff
+	for range attempt - 1 {
+		if ceiling >= r.cfg.MaxBackoff {
+			break
+		}
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, r.cfg.MaxBackoff)
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1) // #nosec G404 -- jitter does not need a secure random number
+}
+
+// Whether a request to the host is allowed by its breaker. An open
+// breaker becomes half-open once the cooldown has passed, when a single
+// trial request is allowed until its result is recorded
+func (r *Resilient) allow(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := r.breaker(key)
+	if b.state == BreakerOpen {
+		if r.now().Sub(b.openedAt) < r.cfg.BreakerCooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		log.Info().Str("location", key).Msg("Storage circuit breaker half-open")
+	}
+	if b.state == BreakerHalfOpen {
+		if b.trial {
+			return false
+		}
+		b.trial = true
+	}
+	return true
+}
+
+// End a half-open trial without a result, so another may be made
+func (r *Resilient) release(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breaker(key).trial = false
+}
+
+func (r *Resilient) recordSuccess(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := r.breaker(key)
+	if b.state != BreakerClosed {
+		log.Info().Str("location", key).Msg("Storage circuit breaker closed")
+	}
+	b.state = BreakerClosed
+	b.failures = 0
+	b.trial = false
+}
+
+func (r *Resilient) recordFailure(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := r.breaker(key)
+	b.failures++
+	b.trial = false
+	if b.state == BreakerHalfOpen || b.failures >= r.cfg.FailureThreshold {
+		if b.state != BreakerOpen {
+			log.Warn().Str("location", key).Int("failures", b.failures).Msg("Storage circuit breaker opened")
+		}
+		b.state = BreakerOpen
+		b.openedAt = r.now()
+	}
+}
+
+// Breaker of a host, which must be called with the lock held. Once there
+// are max breakers the closed ones are dropped, as they hold no more than
+// failures below the threshold. If all are open or half-open, the breaker
+// of a new host is not kept
+func (r *Resilient) breaker(key string) *breaker {
+	b, exists := r.breakers[key]
+	if exists {
+		return b
+	}
+	b = &breaker{state: BreakerClosed}
+	if len(r.breakers) >= maxBreakers {
+		maps.DeleteFunc(r.breakers, func(_ string, b *breaker) bool {
+			return b.state == BreakerClosed
+		})
+	}
+	if len(r.breakers) < maxBreakers {
+		r.breakers[key] = b
+	}
+	return b
+}
+
+// Key of the host of a location e.g. "s3://bucket", so that the breakers
+// are bounded by the backends rather than their paths
+func locationKey(location types.LocationURI) string {
+	u := url.URL(location)
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
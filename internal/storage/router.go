@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Router dispatches each request to the storage backend serving the
+// location. A backend serves locations with the same storage provider
+// (i.e. scheme) and, if it lists any hosts, a host matching one of them
+type Router struct {
+	backends []routedBackend
+}
+
+type routedBackend struct {
+	name     string
+	provider types.StorageProvider
+	hosts    []string // Host globs e.g. "*.storage.local"
+	storage  Interface
+}
+
+func (r *Router) List(ctx context.Context, location types.LocationURI) ([]types.FileMetadata, error) {
+	backend, err := r.backend(location)
+	if err != nil {
+		return nil, err
+	}
+	return backend.List(ctx, location)
+}
+
+func (r *Router) Get(ctx context.Context, location types.LocationURI, fileId types.FileId, byteRange *types.ByteRange) (*types.File, error) {
+	backend, err := r.backend(location)
+	if err != nil {
+		return nil, err
+	}
+	return backend.Get(ctx, location, fileId, byteRange)
+}
+
+func (r *Router) Stat(ctx context.Context, location types.LocationURI, fileId types.FileId) (*types.FileMetadata, error) {
+	backend, err := r.backend(location)
+	if err != nil {
+		return nil, err
+	}
+	return backend.Stat(ctx, location, fileId)
+}
+
+func (r *Router) Tag(ctx context.Context, location types.LocationURI, fileId types.FileId, tags map[string]string) error {
+	backend, err := r.backend(location)
+	if err != nil {
+		return err
+	}
+	return backend.Tag(ctx, location, fileId, tags)
+}
+
+func (r *Router) Copy(ctx context.Context, location types.LocationURI, fileId types.FileId, prefix string) error {
+	backend, err := r.backend(location)
+	if err != nil {
+		return err
+	}
+	return backend.Copy(ctx, location, fileId, prefix)
+}
+
+func (r *Router) Delete(ctx context.Context, location types.LocationURI, fileId types.FileId) error {
+	backend, err := r.backend(location)
+	if err != nil {
+		return err
+	}
+	return backend.Delete(ctx, location, fileId)
+}
+
+// Backend for a location. Backends with a matching host take precedence
+// over a backend of the same provider that serves any host
+func (r *Router) backend(location types.LocationURI) (Interface, error) {
+	var fallback *routedBackend
+	for i, b := range r.backends {
+		if b.provider != location.StorageProvider() {
+			continue
+		}
+		if len(b.hosts) == 0 {
+			fallback = &r.backends[i]
+			continue
+		}
+		// Hosts are matched without the port
+		if matchesAnyHost(b.hosts, (*url.URL)(&location).Hostname()) {
+			log.Debug().Str("backend", b.name).Str("host", location.Host).Msg("Routing to storage backend")
+			return b.storage, nil
+		}
+	}
+	if fallback != nil {
+		log.Debug().Str("backend", fallback.name).Str("host", location.Host).Msg("Routing to storage backend")
+		return fallback.storage, nil
+	}
+	return nil, types.NewErrInvalidObjectF("no storage backend configured for [%s://%s]", location.Scheme, location.Host)
+}
+
+func (r *Router) add(name string, provider types.StorageProvider, hosts []string, storage Interface) error {
+	for _, host := range hosts {
+		if _, err := path.Match(host, ""); err != nil {
+			return fmt.Errorf("backend %s has invalid host pattern %q: %w", name, host, err)
+		}
+	}
+	for _, b := range r.backends {
+		if b.name == name {
+			return fmt.Errorf("duplicate storage backend name %s", name)
+		}
+		if b.provider == provider && len(b.hosts) == 0 && len(hosts) == 0 {
+			return fmt.Errorf("backends %s and %s both serve any %s host", b.name, name, provider)
+		}
+	}
+	r.backends = append(r.backends, routedBackend{
+		name:     name,
+		provider: provider,
+		hosts:    hosts,
+		storage:  storage,
+	})
+	return nil
+}
+
+func matchesAnyHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, host); matched {
+			return true
+		}
+	}
+	return false
+}
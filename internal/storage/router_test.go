@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ucl-arc-tre/egress/internal/storage/generic"
+	"github.com/ucl-arc-tre/egress/internal/storage/s3"
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+func TestRouterDispatch(t *testing.T) {
+	s3Storage := s3.NewMock(s3.MockClient{
+		Buckets: map[s3.MockBucketName]s3.MockBucket{
+			"bucket1": {Objects: []s3.MockObject{{Key: "s3-file", Etag: `"s3etag"`, Content: "a"}}},
+		},
+	})
+	internalStorage := generic.NewWithMock(&generic.MockClient{
+		Files: []generic.MockFile{{Key: "internal-file", ETag: `"etag1"`, Content: "b"}},
+	})
+	anyStorage := generic.NewWithMock(&generic.MockClient{
+		Files: []generic.MockFile{{Key: "any-file", ETag: `"etag2"`, Content: "c"}},
+	})
+	router := &Router{}
+	require.NoError(t, router.add("s3", types.StorageProviderS3, nil, s3Storage))
+	require.NoError(t, router.add("any", types.StorageProviderGeneric, nil, anyStorage))
+	require.NoError(t, router.add("internal", types.StorageProviderGeneric, []string{"*.internal"}, internalStorage))
+
+	testCases := []struct {
+		location     string
+		expectedName string
+	}{
+		{location: "s3://bucket1", expectedName: "s3-file"},
+		{location: "https://storage.internal", expectedName: "internal-file"},
+		{location: "https://storage.internal:8443", expectedName: "internal-file"},
+		{location: "https://storage.example.com", expectedName: "any-file"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.location, func(t *testing.T) {
+			location, err := ParseLocation(tc.location)
+			require.NoError(t, err)
+			files, err := router.List(context.Background(), *location)
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+			assert.Equal(t, tc.expectedName, files[0].Name)
+		})
+	}
+}
+
+func TestRouterNoBackend(t *testing.T) {
+	router := &Router{}
+	require.NoError(t, router.add("internal", types.StorageProviderGeneric, []string{"*.internal"}, generic.NewWithMock(&generic.MockClient{})))
+
+	location, err := ParseLocation("https://storage.example.com")
+	require.NoError(t, err)
+	_, err = router.List(context.Background(), *location)
+	assert.ErrorIs(t, err, types.ErrInvalidObject)
+
+	location, err = ParseLocation("s3://bucket1")
+	require.NoError(t, err)
+	_, err = router.Get(context.Background(), *location, "abc", nil)
+	assert.ErrorIs(t, err, types.ErrInvalidObject)
+}
+
+func TestRouterAddValidation(t *testing.T) {
+	router := &Router{}
+	storage := generic.NewWithMock(&generic.MockClient{})
+	require.NoError(t, router.add("a", types.StorageProviderGeneric, nil, storage))
+
+	assert.Error(t, router.add("a", types.StorageProviderS3, nil, storage), "duplicate name")
+	assert.Error(t, router.add("b", types.StorageProviderGeneric, nil, storage), "two backends serving any host")
+	assert.Error(t, router.add("c", types.StorageProviderGeneric, []string{"[bad"}, storage), "invalid host pattern")
+	assert.NoError(t, router.add("d", types.StorageProviderGeneric, []string{"*.local"}, storage))
+}
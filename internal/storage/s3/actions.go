@@ -0,0 +1,114 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/url"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	awsS3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Tag adds the tags to the object, replacing the values of existing tags
+// with the same keys. Other existing tags are kept
+func (s *Storage) Tag(ctx context.Context, location types.LocationURI, fileId types.FileId, tags map[string]string) error {
+	bucketName, err := location.BucketName()
+	if err != nil {
+		return err
+	}
+	object, err := s.objectWithFileId(ctx, bucketName, fileId)
+	if err != nil {
+		return err
+	}
+	client := s.clientFor(bucketName)
+	existing, err := client.GetObjectTagging(ctx, &awsS3.GetObjectTaggingInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(object.key),
+		VersionId: object.versionId,
+	})
+	if err != nil {
+		return actionError("tag", fileId, err)
+	}
+	merged := map[string]string{}
+	for _, tag := range existing.TagSet {
+		merged[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	maps.Copy(merged, tags)
+	tagSet := []awsS3types.Tag{}
+	for _, key := range slices.Sorted(maps.Keys(merged)) {
+		tagSet = append(tagSet, awsS3types.Tag{Key: aws.String(key), Value: aws.String(merged[key])})
+	}
+	_, err = client.PutObjectTagging(ctx, &awsS3.PutObjectTaggingInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(object.key),
+		VersionId: object.versionId,
+		Tagging:   &awsS3types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		return actionError("tag", fileId, err)
+	}
+	return nil
+}
+
+// Copy copies the object to the key with the prefix prepended, in the same
+// bucket. Objects larger than 5 GiB cannot be copied in a single request
+func (s *Storage) Copy(ctx context.Context, location types.LocationURI, fileId types.FileId, prefix string) error {
+	bucketName, err := location.BucketName()
+	if err != nil {
+		return err
+	}
+	object, err := s.objectWithFileId(ctx, bucketName, fileId)
+	if err != nil {
+		return err
+	}
+	source := bucketName + "/" + url.PathEscape(object.key)
+	if object.versionId != nil {
+		source += "?versionId=" + url.QueryEscape(*object.versionId)
+	}
+	_, err = s.clientFor(bucketName).CopyObject(ctx, &awsS3.CopyObjectInput{
+		Bucket:            aws.String(bucketName),
+		Key:               aws.String(prefix + object.key),
+		CopySource:        aws.String(source),
+		CopySourceIfMatch: aws.String(object.eTag),
+	})
+	if err != nil {
+		return actionError("copy", fileId, err)
+	}
+	return nil
+}
+
+// Delete deletes the object. In a versioned bucket a delete marker is added,
+// so the object can be recovered
+func (s *Storage) Delete(ctx context.Context, location types.LocationURI, fileId types.FileId) error {
+	bucketName, err := location.BucketName()
+	if err != nil {
+		return err
+	}
+	object, err := s.objectWithFileId(ctx, bucketName, fileId)
+	if err != nil {
+		return err
+	}
+	_, err = s.clientFor(bucketName).DeleteObject(ctx, &awsS3.DeleteObjectInput{
+		Bucket:  aws.String(bucketName),
+		Key:     aws.String(object.key),
+		IfMatch: aws.String(object.eTag),
+	})
+	if err != nil {
+		return actionError("delete", fileId, err)
+	}
+	return nil
+}
+
+func actionError(action string, fileId types.FileId, err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && slices.Contains([]string{"PreconditionFailed", "NoSuchKey", "NoSuchVersion"}, apiErr.ErrorCode()) {
+		return types.NewErrNotFoundF("failed to %s object; no object with fileId [%v]", action, fileId)
+	}
+	return types.NewErrServerF("failed to %s object [%w]", action, err)
+}
@@ -1,38 +1,81 @@
 package s3
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	awsCredentials "github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"github.com/ucl-arc-tre/egress/internal/config"
 )
 
 // newClient generates a new API client for AWS S3, optionally configuring the AWS AccessKeyId and SecretAccessKey if provided.
 func newClient(s3Config config.S3StorageConfig) (*awsS3.Client, error) {
+	opts := loadOptions(s3Config.Region, s3Config.AccessKeyId, s3Config.SecretAccessKey)
+	cfg, err := awsConfig.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring S3 client: %w", err)
+	}
+	if config.IsDevS3() {
+		return newDevClientWithBucket(cfg), nil
+	}
+	return awsS3.NewFromConfig(cfg), nil
+}
+
+// newBucketClient generates a new API client for the buckets matching bucketConfig, which may be
+// served by an S3 compatible endpoint other than AWS. Credentials of s3Config are used if
+// bucketConfig has neither its own nor a role to assume.
+func newBucketClient(s3Config config.S3StorageConfig, bucketConfig config.S3BucketConfig) (*awsS3.Client, error) {
+	accessKeyId, secretAccessKey := bucketConfig.AccessKeyId, bucketConfig.SecretAccessKey
+	if accessKeyId == "" && secretAccessKey == "" && bucketConfig.RoleARN == "" {
+		accessKeyId, secretAccessKey = s3Config.AccessKeyId, s3Config.SecretAccessKey
+	}
+	opts := loadOptions(cmp.Or(bucketConfig.Region, s3Config.Region), accessKeyId, secretAccessKey)
+	if bucketConfig.CABundle != "" {
+		caBundle, err := os.ReadFile(bucketConfig.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA bundle for buckets %s: %w", bucketConfig.Bucket, err)
+		}
+		opts = append(opts, awsConfig.WithCustomCABundle(bytes.NewReader(caBundle)))
+	}
+	cfg, err := awsConfig.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring S3 client for buckets %s: %w", bucketConfig.Bucket, err)
+	}
+	if bucketConfig.RoleARN != "" {
+		cfg.Credentials = aws.NewCredentialsCache(
+			stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), bucketConfig.RoleARN),
+		)
+	}
+	return awsS3.NewFromConfig(cfg, func(o *awsS3.Options) {
+		if bucketConfig.Endpoint != "" {
+			o.BaseEndpoint = aws.String(bucketConfig.Endpoint)
+		}
+		o.UsePathStyle = bucketConfig.UsePathStyle
+	}), nil
+}
+
+func loadOptions(region string, accessKeyId string, secretAccessKey string) []func(*awsConfig.LoadOptions) error {
 	opts := []func(*awsConfig.LoadOptions) error{
-		awsConfig.WithRegion(s3Config.Region),
+		awsConfig.WithRegion(region),
 	}
-	if s3Config.AccessKeyId != "" && s3Config.SecretAccessKey != "" {
+	if accessKeyId != "" && secretAccessKey != "" {
 		opts = append(opts, awsConfig.WithCredentialsProvider(
 			awsCredentials.StaticCredentialsProvider{
 				Value: aws.Credentials{
-					AccessKeyID:     s3Config.AccessKeyId,
-					SecretAccessKey: s3Config.SecretAccessKey,
+					AccessKeyID:     accessKeyId,
+					SecretAccessKey: secretAccessKey,
 				},
 			},
 		))
 	}
-	cfg, err := awsConfig.LoadDefaultConfig(context.Background(), opts...)
-	if err != nil {
-		return nil, fmt.Errorf("error configuring S3 client: %w", err)
-	}
-	if config.IsDevS3() {
-		return newDevClientWithBucket(cfg), nil
-	}
-	return awsS3.NewFromConfig(cfg), nil
+	return opts
 }
@@ -5,6 +5,7 @@ import (
 	"path"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	awsCredentials "github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/stretchr/testify/assert"
 	"github.com/ucl-arc-tre/egress/internal/config"
@@ -24,3 +25,40 @@ func TestEmptyCredsLoadsDefaultConfig(t *testing.T) {
 	_, isStatic := client.Options().Credentials.(*awsCredentials.StaticCredentialsProvider)
 	assert.False(t, isStatic, "expected default credentials chain, not StaticCredentialsProvider")
 }
+
+func TestBucketClientOptions(t *testing.T) {
+	tmpConfigPath := path.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(tmpConfigPath, []byte(""), 0o775)
+	config.InitWithPath(tmpConfigPath)
+
+	s3Config := config.S3StorageConfig{
+		Region:          "eu-west-2",
+		AccessKeyId:     "aws-key",
+		SecretAccessKey: "aws-secret",
+	}
+	client, err := newBucketClient(s3Config, config.S3BucketConfig{
+		Bucket:       "ceph-*",
+		Endpoint:     "https://rgw.example.com",
+		UsePathStyle: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-2", client.Options().Region)
+	assert.Equal(t, "https://rgw.example.com", *client.Options().BaseEndpoint)
+	assert.True(t, client.Options().UsePathStyle)
+	creds, err := client.Options().Credentials.Retrieve(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "aws-key", creds.AccessKeyID, "expected credentials to be inherited")
+
+	client, err = newBucketClient(s3Config, config.S3BucketConfig{
+		Bucket:  "assumed",
+		RoleARN: "arn:aws:iam::123456789012:role/egress",
+	})
+	assert.NoError(t, err)
+	assert.IsType(t, &aws.CredentialsCache{}, client.Options().Credentials)
+
+	_, err = newBucketClient(s3Config, config.S3BucketConfig{
+		Bucket:   "ceph-*",
+		CABundle: path.Join(t.TempDir(), "missing.pem"),
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,199 @@
+package s3
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	awsS3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Property of an object used as its FileId
+type fileIdSource string
+
+const (
+	fileIdSourceETag           = fileIdSource("etag")
+	fileIdSourceChecksumSHA256 = fileIdSource("checksum_sha256")
+	fileIdSourceVersionId      = fileIdSource("version_id")
+
+	checksumCacheSize = 10000 // Objects whose checksums are cached
+)
+
+func parseFileIdSource(value string) (fileIdSource, error) {
+	switch source := fileIdSource(value); source {
+	case "":
+		return fileIdSourceETag, nil
+	case fileIdSourceETag, fileIdSourceChecksumSHA256, fileIdSourceVersionId:
+		return source, nil
+	default:
+		return "", fmt.Errorf("unsupported file_id [%s]; must be one of: etag, checksum_sha256, version_id", value)
+	}
+}
+
+// Listed object, with what is needed to get the same content by its FileId
+type listedObject struct {
+	metadata  types.FileMetadata
+	key       string
+	eTag      string  // Quoted ETag when listed
+	versionId *string // Set only when identified by version
+}
+
+// List the current objects of a bucket identified by the configured source
+func (s *Storage) listObjects(ctx context.Context, bucketName string) ([]listedObject, error) {
+	if s.fileIdSource == fileIdSourceVersionId {
+		return s.listObjectVersions(ctx, bucketName)
+	}
+	objects := []listedObject{}
+	objectPaginator := s.newListObjectsPaginator(bucketName)
+	for objectPaginator.HasMorePages() {
+		output, err := objectPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, types.NewErrServerF("failed to list objects [%w]", err)
+		}
+		for _, o := range output.Contents {
+			if o.Key == nil || o.ETag == nil || o.Size == nil || o.LastModified == nil {
+				log.Error().Any("object", o).Msg("Object missing a required field")
+				continue
+			}
+			object := listedObject{
+				metadata: types.FileMetadata{
+					Name:           *o.Key,
+					Id:             types.FileId(stripQuotes(*o.ETag)),
+					Size:           *o.Size,
+					LastModifiedAt: *o.LastModified,
+				},
+				key:  *o.Key,
+				eTag: *o.ETag,
+			}
+			if s.fileIdSource == fileIdSourceChecksumSHA256 {
+				fileId, err := s.checksumFileIdOf(ctx, bucketName, object)
+				if err != nil {
+					return nil, err
+				} else if fileId == "" {
+					continue
+				}
+				object.metadata.Id = fileId
+			}
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+// Current versions of the objects of a bucket, identified by version ID.
+// Objects without one (i.e. written before versioning was enabled) are skipped
+func (s *Storage) listObjectVersions(ctx context.Context, bucketName string) ([]listedObject, error) {
+	objects := []listedObject{}
+	versionPaginator := awsS3.NewListObjectVersionsPaginator(s.clientFor(bucketName), &awsS3.ListObjectVersionsInput{
+		Bucket: aws.String(bucketName),
+	})
+	for versionPaginator.HasMorePages() {
+		output, err := versionPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, types.NewErrServerF("failed to list object versions [%w]", err)
+		}
+		for _, v := range output.Versions {
+			if v.IsLatest == nil || !*v.IsLatest {
+				continue
+			}
+			if v.Key == nil || v.ETag == nil || v.Size == nil || v.LastModified == nil || v.VersionId == nil {
+				log.Error().Any("version", v).Msg("Object version missing a required field")
+				continue
+			}
+			if *v.VersionId == "null" {
+				log.Debug().Str("key", *v.Key).Msg("Object has no version ID")
+				continue
+			}
+			objects = append(objects, listedObject{
+				metadata: types.FileMetadata{
+					Name:           *v.Key,
+					Id:             types.FileId(*v.VersionId),
+					Size:           *v.Size,
+					LastModifiedAt: *v.LastModified,
+				},
+				key:       *v.Key,
+				eTag:      *v.ETag,
+				versionId: v.VersionId,
+			})
+		}
+	}
+	return objects, nil
+}
+
+// FileId from the full object SHA-256 checksum of a listed object, or ""
+// if it has none or it changed since it was listed. Checksums are cached
+// by bucket, key and ETag so that an object is only headed once
+func (s *Storage) checksumFileIdOf(ctx context.Context, bucketName string, object listedObject) (types.FileId, error) {
+	key := checksumKey{bucket: bucketName, key: object.key, eTag: object.eTag}
+	if fileId, exists := s.checksums.Get(key); exists {
+		return fileId, nil
+	}
+	output, err := s.clientFor(bucketName).HeadObject(ctx, &awsS3.HeadObjectInput{
+		Bucket:       aws.String(bucketName),
+		Key:          aws.String(object.key),
+		IfMatch:      aws.String(object.eTag),
+		ChecksumMode: awsS3types.ChecksumModeEnabled,
+	})
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && slices.Contains([]string{"PreconditionFailed", "NotFound"}, apiErr.ErrorCode()) {
+		log.Debug().Str("key", object.key).Msg("Object changed since it was listed")
+		return "", nil
+	} else if err != nil {
+		return "", types.NewErrServerF("failed to head object [%w]", err)
+	}
+	fileId := types.FileId("")
+	if output.ChecksumSHA256 == nil {
+		log.Debug().Str("key", object.key).Msg("Object has no SHA-256 checksum")
+	} else if output.ChecksumType == awsS3types.ChecksumTypeComposite {
+		// A composite checksum depends on the part size, as does the ETag
+		log.Debug().Str("key", object.key).Msg("Object has a composite SHA-256 checksum")
+	} else if fileId, err = checksumFileId(*output.ChecksumSHA256); err != nil {
+		return "", err
+	}
+	s.checksums.Put(key, fileId)
+	return fileId, nil
+}
+
+// Object whose checksum is cached; the ETag changes with its content
+type checksumKey struct {
+	bucket string
+	key    string
+	eTag   string
+}
+
+// Whether the object got matches the FileId, where its source is returned
+func (s *Storage) matchesFileId(output *awsS3.GetObjectOutput, fileId types.FileId) bool {
+	switch s.fileIdSource {
+	case fileIdSourceChecksumSHA256:
+		if output.ChecksumSHA256 == nil { // Not returned for ranges
+			return true
+		}
+		if output.ChecksumType == awsS3types.ChecksumTypeComposite {
+			return false
+		}
+		id, err := checksumFileId(*output.ChecksumSHA256)
+		return err == nil && id == fileId
+	case fileIdSourceVersionId:
+		return output.VersionId == nil || types.FileId(*output.VersionId) == fileId
+	default:
+		return output.ETag == nil || eTagEqualsFileId(output.ETag, fileId)
+	}
+}
+
+// Hex encoding of a base64 encoded full object S3 checksum
+func checksumFileId(checksum string) (types.FileId, error) {
+	decoded, err := base64.StdEncoding.DecodeString(checksum)
+	if err != nil {
+		return "", types.NewErrServerF("invalid checksum [%s]: %w", checksum, err)
+	}
+	return types.FileId(hex.EncodeToString(decoded)), nil
+}
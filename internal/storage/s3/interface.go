@@ -12,9 +12,39 @@ type ClientInterface interface {
 		input *awsS3.ListObjectsV2Input,
 		optFns ...func(*awsS3.Options),
 	) (*awsS3.ListObjectsV2Output, error)
+	ListObjectVersions(
+		ctx context.Context,
+		input *awsS3.ListObjectVersionsInput,
+		optFns ...func(*awsS3.Options),
+	) (*awsS3.ListObjectVersionsOutput, error)
+	HeadObject(
+		ctx context.Context,
+		input *awsS3.HeadObjectInput,
+		optFns ...func(*awsS3.Options),
+	) (*awsS3.HeadObjectOutput, error)
 	GetObject(
 		ctx context.Context,
 		input *awsS3.GetObjectInput,
 		optFns ...func(*awsS3.Options),
 	) (*awsS3.GetObjectOutput, error)
+	GetObjectTagging(
+		ctx context.Context,
+		input *awsS3.GetObjectTaggingInput,
+		optFns ...func(*awsS3.Options),
+	) (*awsS3.GetObjectTaggingOutput, error)
+	PutObjectTagging(
+		ctx context.Context,
+		input *awsS3.PutObjectTaggingInput,
+		optFns ...func(*awsS3.Options),
+	) (*awsS3.PutObjectTaggingOutput, error)
+	CopyObject(
+		ctx context.Context,
+		input *awsS3.CopyObjectInput,
+		optFns ...func(*awsS3.Options),
+	) (*awsS3.CopyObjectOutput, error)
+	DeleteObject(
+		ctx context.Context,
+		input *awsS3.DeleteObjectInput,
+		optFns ...func(*awsS3.Options),
+	) (*awsS3.DeleteObjectOutput, error)
 }
@@ -2,29 +2,69 @@ package s3
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
+	"slices"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	awsS3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"github.com/rs/zerolog/log"
 
+	"github.com/ucl-arc-tre/egress/internal/cache"
 	"github.com/ucl-arc-tre/egress/internal/config"
 	"github.com/ucl-arc-tre/egress/internal/types"
 )
 
 type Storage struct {
-	client ClientInterface
+	client        ClientInterface
+	bucketClients []bucketClient // Used instead of client for matching buckets
+	fileIdSource  fileIdSource
+	checksums     *cache.FIFO[checksumKey, types.FileId] // Used by the checksum_sha256 source
+}
+
+type bucketClient struct {
+	pattern string // Bucket name or glob
+	client  ClientInterface
 }
 
 // This New constructor is called when the handler is created, which panics
 // if New returns an error. Therefore, errors are not wrapped in ErrServer
 func New(s3Config config.S3StorageConfig) (*Storage, error) {
+	source, err := parseFileIdSource(s3Config.FileId)
+	if err != nil {
+		return nil, err
+	}
 	s3, err := newClient(s3Config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create S3 client: %w", err)
 	}
-	return &Storage{client: s3}, nil
+	storage := newStorage(s3, source)
+	for _, bucketConfig := range s3Config.Buckets {
+		if _, err := path.Match(bucketConfig.Bucket, ""); err != nil {
+			return nil, fmt.Errorf("invalid bucket pattern %q: %w", bucketConfig.Bucket, err)
+		}
+		client, err := newBucketClient(s3Config, bucketConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 client: %w", err)
+		}
+		storage.bucketClients = append(storage.bucketClients, bucketClient{
+			pattern: bucketConfig.Bucket,
+			client:  client,
+		})
+	}
+	return storage, nil
+}
+
+func newStorage(client ClientInterface, source fileIdSource) *Storage {
+	return &Storage{
+		client:       client,
+		fileIdSource: source,
+		checksums:    cache.NewFIFO[checksumKey, types.FileId](checksumCacheSize),
+	}
 }
 
 func (s *Storage) List(ctx context.Context, location types.LocationURI) ([]types.FileMetadata, error) {
@@ -33,46 +73,50 @@ func (s *Storage) List(ctx context.Context, location types.LocationURI) ([]types
 	if err != nil {
 		return filesMetadata, err
 	}
-	objectPaginator := s.newListObjectsPaginator(bucketName)
-	for objectPaginator.HasMorePages() {
-		output, err := objectPaginator.NextPage(ctx)
-		if err != nil {
-			return filesMetadata, types.NewErrServerF("failed to list objects [%w]", err)
-		}
-		for _, o := range output.Contents {
-			if o.Key == nil || o.ETag == nil || o.Size == nil || o.LastModified == nil {
-				log.Error().Any("object", o).Msg("Object missing a required field")
-				continue
-			}
-			filesMetadata = append(filesMetadata, types.FileMetadata{
-				Name:           *o.Key,
-				Id:             types.FileId(stripQuotes(*o.ETag)),
-				Size:           *o.Size,
-				LastModifiedAt: *o.LastModified,
-			})
-		}
+	objects, err := s.listObjects(ctx, bucketName)
+	if err != nil {
+		return filesMetadata, err
+	}
+	for _, object := range objects {
+		filesMetadata = append(filesMetadata, object.metadata)
 	}
 	log.Debug().Any("location", location).Str("bucketName", bucketName).Msg("Found objects")
 	return filesMetadata, nil
 }
 
-func (s *Storage) Get(ctx context.Context, location types.LocationURI, fileId types.FileId) (*types.File, error) {
+func (s *Storage) Get(ctx context.Context, location types.LocationURI, fileId types.FileId, byteRange *types.ByteRange) (*types.File, error) {
 	bucketName, err := location.BucketName()
 	if err != nil {
 		return nil, err
 	}
-	objectKey, err := s.objectKeyWithFileId(ctx, bucketName, fileId)
+	object, err := s.objectWithFileId(ctx, bucketName, fileId)
 	if err != nil {
 		return nil, err
 	}
-	output, err := s.client.GetObject(ctx, &awsS3.GetObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    objectKey,
-	})
-	if err != nil {
+	// Conditional on the listed ETag so that the content cannot change
+	// between identifying the object and getting it
+	input := &awsS3.GetObjectInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(object.key),
+		IfMatch:   aws.String(object.eTag),
+		VersionId: object.versionId,
+	}
+	if s.fileIdSource == fileIdSourceChecksumSHA256 {
+		input.ChecksumMode = awsS3types.ChecksumModeEnabled
+	}
+	if byteRange != nil {
+		input.Range = aws.String(byteRange.String())
+	}
+	output, err := s.clientFor(bucketName).GetObject(ctx, input)
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidRange" {
+		return nil, types.NewErrRangeNotSatisfiableF("range [%v] not satisfiable [%w]", byteRange, err)
+	} else if errors.As(err, &apiErr) && apiErr.ErrorCode() == "PreconditionFailed" {
+		return nil, types.NewErrNotFoundF("no object with fileId [%v]", fileId)
+	} else if err != nil {
 		return nil, types.NewErrServerF("failed to get object [%w]", err)
 	}
-	if output.ETag != nil && !eTagEqualsFileId(output.ETag, fileId) {
+	if !s.matchesFileId(output, fileId) {
 		if err := output.Body.Close(); err != nil {
 			return nil, types.NewErrServerF("failed to close [%w]", err)
 		}
@@ -84,24 +128,63 @@ func (s *Storage) Get(ctx context.Context, location types.LocationURI, fileId ty
 		}
 		return nil, types.NewErrServerF("object missing content length")
 	}
-	return &types.File{Content: output.Body, Size: *output.ContentLength}, nil
-}
-
-func (s *Storage) objectKeyWithFileId(ctx context.Context, bucketName string, fileId types.FileId) (*string, error) {
-	objectPaginator := s.newListObjectsPaginator(bucketName)
-	for objectPaginator.HasMorePages() {
-		output, err := objectPaginator.NextPage(ctx)
+	file := &types.File{Content: output.Body, Size: *output.ContentLength}
+	if output.ContentRange != nil {
+		contentRange, size, err := types.ParseContentRange(*output.ContentRange)
 		if err != nil {
-			return nil, types.NewErrServerF("failed to list objects [%v]", err)
-		}
-		for _, o := range output.Contents {
-			if o.Key == nil || o.ETag == nil {
-				log.Error().Any("object", o).Msg("Object missing a required field")
-				continue
-			}
-			if eTagEqualsFileId(o.ETag, fileId) {
-				return o.Key, nil
+			if err := output.Body.Close(); err != nil {
+				return nil, types.NewErrServerF("failed to close [%w]", err)
 			}
+			return nil, types.NewErrServerF("object has invalid content range [%w]", err)
+		}
+		file.Range = &contentRange
+		file.Size = size
+	}
+	return file, nil
+}
+
+// Stat gets the metadata of a file with a HEAD request, without getting
+// its content
+func (s *Storage) Stat(ctx context.Context, location types.LocationURI, fileId types.FileId) (*types.FileMetadata, error) {
+	bucketName, err := location.BucketName()
+	if err != nil {
+		return nil, err
+	}
+	object, err := s.objectWithFileId(ctx, bucketName, fileId)
+	if err != nil {
+		return nil, err
+	}
+	output, err := s.clientFor(bucketName).HeadObject(ctx, &awsS3.HeadObjectInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(object.key),
+		IfMatch:   aws.String(object.eTag),
+		VersionId: object.versionId,
+	})
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && slices.Contains([]string{"PreconditionFailed", "NotFound"}, apiErr.ErrorCode()) {
+		return nil, types.NewErrNotFoundF("no object with fileId [%v]", fileId)
+	} else if err != nil {
+		return nil, types.NewErrServerF("failed to head object [%w]", err)
+	}
+	if output.ContentLength == nil {
+		return nil, types.NewErrServerF("object missing content length")
+	}
+	metadata := object.metadata
+	metadata.Size = *output.ContentLength
+	if output.LastModified != nil {
+		metadata.LastModifiedAt = *output.LastModified
+	}
+	return &metadata, nil
+}
+
+func (s *Storage) objectWithFileId(ctx context.Context, bucketName string, fileId types.FileId) (*listedObject, error) {
+	objects, err := s.listObjects(ctx, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		if object.metadata.Id == fileId {
+			return &object, nil
 		}
 	}
 	return nil, types.NewErrNotFoundF("no object with fileId [%v]", fileId)
@@ -111,7 +194,17 @@ func (s *Storage) newListObjectsPaginator(bucketName string) *awsS3.ListObjectsV
 	input := awsS3.ListObjectsV2Input{
 		Bucket: aws.String(bucketName),
 	}
-	return awsS3.NewListObjectsV2Paginator(s.client, &input)
+	return awsS3.NewListObjectsV2Paginator(s.clientFor(bucketName), &input)
+}
+
+// Client for a bucket; the first matching bucket client, otherwise the default
+func (s *Storage) clientFor(bucketName string) ClientInterface {
+	for _, bc := range s.bucketClients {
+		if matched, _ := path.Match(bc.pattern, bucketName); matched {
+			return bc.client
+		}
+	}
+	return s.client
 }
 
 func eTagEqualsFileId(eTag *string, fileId types.FileId) bool {
@@ -1,10 +1,16 @@
 package s3
 
 import (
+	"context"
+	"io"
+	"net/url"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	awsS3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/ucl-arc-tre/egress/internal/types"
 )
 
@@ -19,3 +25,204 @@ func TestStripQuotes(t *testing.T) {
 	assert.Equal(t, "thing", stripQuotes(`"thing`))
 	assert.Equal(t, "thing", stripQuotes(`"thing"`))
 }
+
+func TestClientForBucket(t *testing.T) {
+	defaultClient := &MockClient{}
+	cephClient := &MockClient{}
+	exactClient := &MockClient{}
+	storage := &Storage{
+		client: defaultClient,
+		bucketClients: []bucketClient{
+			{pattern: "ceph.exact", client: exactClient},
+			{pattern: "ceph*", client: cephClient},
+		},
+	}
+	assert.Same(t, defaultClient, storage.clientFor("aws-bucket"))
+	assert.Same(t, cephClient, storage.clientFor("ceph-bucket"))
+	assert.Same(t, exactClient, storage.clientFor("ceph.exact"))
+}
+
+func TestParseFileIdSource(t *testing.T) {
+	source, err := parseFileIdSource("")
+	assert.NoError(t, err)
+	assert.Equal(t, fileIdSourceETag, source)
+
+	for _, value := range []string{"etag", "checksum_sha256", "version_id"} {
+		source, err := parseFileIdSource(value)
+		assert.NoError(t, err)
+		assert.Equal(t, fileIdSource(value), source)
+	}
+
+	_, err = parseFileIdSource("md5")
+	assert.Error(t, err)
+}
+
+func TestChecksumFileId(t *testing.T) {
+	fileId, err := checksumFileId("uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=")
+	assert.NoError(t, err)
+	assert.Equal(t, types.FileId("b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"), fileId)
+
+	_, err = checksumFileId("not base64!")
+	assert.ErrorIs(t, err, types.ErrServer)
+}
+
+func TestFileIdSources(t *testing.T) {
+	helloWorldSHA256 := "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	client := MockClient{
+		Buckets: map[MockBucketName]MockBucket{
+			"bucket1": {Objects: []MockObject{
+				{
+					Key:            "object1",
+					Etag:           `"etag1"`,
+					ChecksumSHA256: "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=",
+					VersionId:      "v1",
+					Content:        "hello world",
+				},
+				{Key: "object2", Etag: `"etag2"`, Content: "unversioned without checksum"},
+				{
+					Key:            "object3",
+					Etag:           `"etag3-2"`,
+					ChecksumSHA256: "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=-2",
+					ChecksumType:   awsS3types.ChecksumTypeComposite,
+					Content:        "multipart",
+				},
+			}},
+		},
+	}
+	u, err := url.Parse("s3://bucket1")
+	require.NoError(t, err)
+	location := (*types.LocationURI)(u)
+
+	testCases := []struct {
+		source      fileIdSource
+		expectedIds []types.FileId
+	}{
+		{source: fileIdSourceETag, expectedIds: []types.FileId{"etag1", "etag2", "etag3-2"}},
+		{source: fileIdSourceChecksumSHA256, expectedIds: []types.FileId{types.FileId(helloWorldSHA256)}},
+		{source: fileIdSourceVersionId, expectedIds: []types.FileId{"v1"}},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.source), func(t *testing.T) {
+			storage := newStorage(&client, tc.source)
+			files, err := storage.List(context.Background(), *location)
+			require.NoError(t, err)
+			ids := []types.FileId{}
+			for _, f := range files {
+				ids = append(ids, f.Id)
+			}
+			assert.Equal(t, tc.expectedIds, ids)
+
+			file, err := storage.Get(context.Background(), *location, tc.expectedIds[0], nil)
+			require.NoError(t, err)
+			content, err := io.ReadAll(file.Content)
+			require.NoError(t, err)
+			assert.Equal(t, "hello world", string(content))
+
+			metadata, err := storage.Stat(context.Background(), *location, tc.expectedIds[0])
+			require.NoError(t, err)
+			assert.Equal(t, "object1", metadata.Name)
+			assert.Equal(t, int64(len("hello world")), metadata.Size)
+
+			_, err = storage.Get(context.Background(), *location, "unknown", nil)
+			assert.ErrorIs(t, err, types.ErrNotFound)
+			_, err = storage.Stat(context.Background(), *location, "unknown")
+			assert.ErrorIs(t, err, types.ErrNotFound)
+		})
+	}
+}
+
+func TestChecksumsCached(t *testing.T) {
+	client := MockClient{
+		Buckets: map[MockBucketName]MockBucket{
+			"bucket1": {Objects: []MockObject{
+				{Key: "object1", Etag: `"etag1"`, ChecksumSHA256: "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=", Content: "hello world"},
+				{Key: "object2", Etag: `"etag2"`, Content: "without checksum"},
+			}},
+		},
+	}
+	u, err := url.Parse("s3://bucket1")
+	require.NoError(t, err)
+	location := (*types.LocationURI)(u)
+	storage := newStorage(&client, fileIdSourceChecksumSHA256)
+	ctx := context.Background()
+
+	files, err := storage.List(ctx, *location)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, 2, client.HeadObjectCalls)
+
+	_, err = storage.Stat(ctx, *location, files[0].Id)
+	require.NoError(t, err)
+	assert.Equal(t, 3, client.HeadObjectCalls) // Only that of Stat itself
+
+	// A changed object is headed again
+	client.Buckets["bucket1"].Objects[1].Etag = `"etag2b"`
+	client.Buckets["bucket1"].Objects[1].ChecksumSHA256 = "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="
+	files, err = storage.List(ctx, *location)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, 4, client.HeadObjectCalls)
+}
+
+func TestChecksumOfChangedObject(t *testing.T) {
+	client := MockClient{
+		Buckets: map[MockBucketName]MockBucket{
+			"bucket1": {Objects: []MockObject{
+				{Key: "object1", Etag: `"etag1"`, ChecksumSHA256: "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=", Content: "hello world"},
+			}},
+		},
+	}
+	storage := newStorage(&client, fileIdSourceChecksumSHA256)
+	object := listedObject{key: "object1", eTag: `"etag0"`} // Listed before it changed
+
+	fileId, err := storage.checksumFileIdOf(context.Background(), "bucket1", object)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FileId(""), fileId)
+}
+
+func TestGetValidatesFileId(t *testing.T) {
+	storage := &Storage{fileIdSource: fileIdSourceChecksumSHA256}
+	output := &awsS3.GetObjectOutput{ChecksumSHA256: aws.String("uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=")}
+	assert.True(t, storage.matchesFileId(output, "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"))
+	assert.False(t, storage.matchesFileId(output, "other"))
+
+	output.ChecksumType = awsS3types.ChecksumTypeComposite
+	assert.False(t, storage.matchesFileId(output, "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"))
+
+	storage.fileIdSource = fileIdSourceVersionId
+	output = &awsS3.GetObjectOutput{VersionId: aws.String("v2")}
+	assert.True(t, storage.matchesFileId(output, "v2"))
+	assert.False(t, storage.matchesFileId(output, "v1"))
+}
+
+func TestActions(t *testing.T) {
+	client := MockClient{
+		Buckets: map[MockBucketName]MockBucket{
+			"bucket1": {Objects: []MockObject{
+				{Key: "dir/object1", Etag: `"etag1"`, Content: "hello world", Tags: map[string]string{"owner": "user1"}},
+			}},
+		},
+	}
+	u, err := url.Parse("s3://bucket1")
+	require.NoError(t, err)
+	location := (*types.LocationURI)(u)
+	storage := &Storage{client: &client, fileIdSource: fileIdSourceETag}
+	ctx := context.Background()
+
+	require.NoError(t, storage.Tag(ctx, *location, "etag1", map[string]string{"egress-status": "downloaded"}))
+	assert.Equal(t, map[string]string{"owner": "user1", "egress-status": "downloaded"}, client.Buckets["bucket1"].Objects[0].Tags)
+
+	require.NoError(t, storage.Copy(ctx, *location, "etag1", "archive/"))
+	objects := client.Buckets["bucket1"].Objects
+	require.Len(t, objects, 2)
+	assert.Equal(t, "archive/dir/object1", objects[1].Key)
+	assert.Equal(t, "hello world", objects[1].Content)
+	assert.Equal(t, objects[0].Tags, objects[1].Tags)
+
+	require.NoError(t, storage.Delete(ctx, *location, "etag1"))
+	objects = client.Buckets["bucket1"].Objects
+	require.Len(t, objects, 1)
+	assert.Equal(t, "archive/dir/object1", objects[0].Key)
+
+	assert.ErrorIs(t, storage.Delete(ctx, *location, "unknown"), types.ErrNotFound)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"io"
+	"maps"
+	"net/url"
 	"slices"
 	"strings"
 	"time"
@@ -11,17 +13,23 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
 	awsS3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/ucl-arc-tre/egress/internal/types"
 )
 
 func NewMock(client MockClient) *Storage {
-	return &Storage{client: &client}
+	return newStorage(&client, fileIdSourceETag)
 }
 
 type MockObject struct {
 	Key            string
 	Etag           string
+	ChecksumSHA256 string // Base64 encoded
+	ChecksumType   awsS3types.ChecksumType
+	VersionId      string
 	LastModifiedAt time.Time
 	Content        string
+	Tags           map[string]string
 }
 
 func (o MockObject) Size() int {
@@ -35,7 +43,8 @@ type MockBucket struct {
 type MockBucketName = string
 
 type MockClient struct {
-	Buckets map[MockBucketName]MockBucket
+	Buckets         map[MockBucketName]MockBucket
+	HeadObjectCalls int
 }
 
 func (c *MockClient) ListObjectsV2(
@@ -84,9 +93,214 @@ func (c *MockClient) GetObject(
 		return nil, errors.New("no object")
 	}
 	object := bucket.Objects[idx]
+	if input.VersionId != nil && *input.VersionId != object.VersionId {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchVersion"}
+	}
+	if input.IfMatch != nil && *input.IfMatch != object.Etag {
+		return nil, &smithy.GenericAPIError{Code: "PreconditionFailed"}
+	}
+	if input.Range != nil {
+		return object.getRange(*input.Range)
+	}
 	output := awsS3.GetObjectOutput{
+		ContentLength:  aws.Int64(int64(object.Size())),
+		ETag:           aws.String(object.Etag),
+		ChecksumSHA256: optionalString(object.ChecksumSHA256),
+		ChecksumType:   object.ChecksumType,
+		VersionId:      optionalString(object.VersionId),
+		Body:           io.NopCloser(strings.NewReader(object.Content)),
+	}
+	return &output, nil
+}
+
+func (c *MockClient) ListObjectVersions(
+	_ context.Context,
+	input *awsS3.ListObjectVersionsInput,
+	optFns ...func(*awsS3.Options),
+) (*awsS3.ListObjectVersionsOutput, error) {
+	if input.Bucket == nil {
+		return nil, errors.New("no bucket")
+	}
+	bucket, exists := c.Buckets[*input.Bucket]
+	if !exists {
+		return nil, errors.New("bucket did not exist")
+	}
+	output := awsS3.ListObjectVersionsOutput{}
+	for _, object := range bucket.Objects {
+		versionId := object.VersionId
+		if versionId == "" {
+			versionId = "null"
+		}
+		output.Versions = append(output.Versions, awsS3types.ObjectVersion{
+			Key:          aws.String(object.Key),
+			ETag:         aws.String(object.Etag),
+			Size:         aws.Int64(int64(object.Size())),
+			LastModified: aws.Time(object.LastModifiedAt),
+			VersionId:    aws.String(versionId),
+			IsLatest:     aws.Bool(true),
+		})
+	}
+	return &output, nil
+}
+
+func (c *MockClient) HeadObject(
+	_ context.Context,
+	input *awsS3.HeadObjectInput,
+	optFns ...func(*awsS3.Options),
+) (*awsS3.HeadObjectOutput, error) {
+	c.HeadObjectCalls++
+	if input.Key == nil || input.Bucket == nil {
+		return nil, errors.New("no input")
+	}
+	bucket, exists := c.Buckets[*input.Bucket]
+	if !exists {
+		return nil, errors.New("bucket did not exist")
+	}
+	idx := slices.IndexFunc(bucket.Objects, func(o MockObject) bool {
+		return o.Key == *input.Key
+	})
+	if idx < 0 {
+		return nil, &smithy.GenericAPIError{Code: "NotFound"}
+	}
+	object := bucket.Objects[idx]
+	if input.VersionId != nil && *input.VersionId != object.VersionId {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchVersion"}
+	}
+	if input.IfMatch != nil && *input.IfMatch != object.Etag {
+		return nil, &smithy.GenericAPIError{Code: "PreconditionFailed"}
+	}
+	output := awsS3.HeadObjectOutput{
 		ContentLength: aws.Int64(int64(object.Size())),
-		Body:          io.NopCloser(strings.NewReader(object.Content)),
+		ETag:          aws.String(object.Etag),
+		LastModified:  aws.Time(object.LastModifiedAt),
+		VersionId:     optionalString(object.VersionId),
+	}
+	if input.ChecksumMode == awsS3types.ChecksumModeEnabled {
+		output.ChecksumSHA256 = optionalString(object.ChecksumSHA256)
+		output.ChecksumType = object.ChecksumType
 	}
 	return &output, nil
 }
+
+func (c *MockClient) GetObjectTagging(
+	_ context.Context,
+	input *awsS3.GetObjectTaggingInput,
+	optFns ...func(*awsS3.Options),
+) (*awsS3.GetObjectTaggingOutput, error) {
+	object, _, err := c.object(input.Bucket, input.Key)
+	if err != nil {
+		return nil, err
+	}
+	output := awsS3.GetObjectTaggingOutput{TagSet: []awsS3types.Tag{}}
+	for key, value := range object.Tags {
+		output.TagSet = append(output.TagSet, awsS3types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	return &output, nil
+}
+
+func (c *MockClient) PutObjectTagging(
+	_ context.Context,
+	input *awsS3.PutObjectTaggingInput,
+	optFns ...func(*awsS3.Options),
+) (*awsS3.PutObjectTaggingOutput, error) {
+	object, _, err := c.object(input.Bucket, input.Key)
+	if err != nil {
+		return nil, err
+	}
+	object.Tags = map[string]string{}
+	for _, tag := range input.Tagging.TagSet {
+		object.Tags[*tag.Key] = *tag.Value
+	}
+	return &awsS3.PutObjectTaggingOutput{}, nil
+}
+
+func (c *MockClient) CopyObject(
+	_ context.Context,
+	input *awsS3.CopyObjectInput,
+	optFns ...func(*awsS3.Options),
+) (*awsS3.CopyObjectOutput, error) {
+	bucketName, key, found := strings.Cut(*input.CopySource, "/")
+	if !found {
+		return nil, errors.New("invalid copy source")
+	}
+	key, _, _ = strings.Cut(key, "?")
+	key, err := url.PathUnescape(key)
+	if err != nil {
+		return nil, err
+	}
+	source, _, err := c.object(&bucketName, &key)
+	if err != nil {
+		return nil, err
+	}
+	if input.CopySourceIfMatch != nil && *input.CopySourceIfMatch != source.Etag {
+		return nil, &smithy.GenericAPIError{Code: "PreconditionFailed"}
+	}
+	copied := *source
+	copied.Key = *input.Key
+	copied.Tags = maps.Clone(source.Tags)
+	bucket := c.Buckets[*input.Bucket]
+	bucket.Objects = slices.DeleteFunc(bucket.Objects, func(o MockObject) bool { return o.Key == copied.Key })
+	bucket.Objects = append(bucket.Objects, copied)
+	c.Buckets[*input.Bucket] = bucket
+	return &awsS3.CopyObjectOutput{}, nil
+}
+
+func (c *MockClient) DeleteObject(
+	_ context.Context,
+	input *awsS3.DeleteObjectInput,
+	optFns ...func(*awsS3.Options),
+) (*awsS3.DeleteObjectOutput, error) {
+	object, idx, err := c.object(input.Bucket, input.Key)
+	if err != nil {
+		return nil, err
+	}
+	if input.IfMatch != nil && *input.IfMatch != object.Etag {
+		return nil, &smithy.GenericAPIError{Code: "PreconditionFailed"}
+	}
+	bucket := c.Buckets[*input.Bucket]
+	bucket.Objects = slices.Delete(bucket.Objects, idx, idx+1)
+	c.Buckets[*input.Bucket] = bucket
+	return &awsS3.DeleteObjectOutput{}, nil
+}
+
+// Object with the key in the bucket, and its index in the bucket
+func (c *MockClient) object(bucketName *string, key *string) (*MockObject, int, error) {
+	if bucketName == nil || key == nil {
+		return nil, 0, errors.New("no input")
+	}
+	bucket, exists := c.Buckets[*bucketName]
+	if !exists {
+		return nil, 0, errors.New("bucket did not exist")
+	}
+	idx := slices.IndexFunc(bucket.Objects, func(o MockObject) bool {
+		return o.Key == *key
+	})
+	if idx < 0 {
+		return nil, 0, &smithy.GenericAPIError{Code: "NoSuchKey"}
+	}
+	return &bucket.Objects[idx], idx, nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
+
+func (o MockObject) getRange(value string) (*awsS3.GetObjectOutput, error) {
+	byteRange, err := types.ParseByteRange(value)
+	if err != nil {
+		return nil, err
+	}
+	size := int64(len(o.Content))
+	contentRange, err := byteRange.Resolve(size)
+	if err != nil {
+		return nil, &smithy.GenericAPIError{Code: "InvalidRange"}
+	}
+	return &awsS3.GetObjectOutput{
+		ContentLength: aws.Int64(contentRange.Length()),
+		ContentRange:  aws.String(contentRange.Header(size)),
+		Body:          io.NopCloser(strings.NewReader(o.Content[contentRange.First : contentRange.Last+1])),
+	}, nil
+}
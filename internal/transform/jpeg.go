@@ -0,0 +1,113 @@
+package transform
+
+import (
+	"bufio"
+	"io"
+)
+
+const (
+	markerSOS = 0xDA // Start of scan, followed by entropy-coded data
+	markerEOI = 0xD9 // End of image
+	markerCOM = 0xFE // Comment
+)
+
+// Copy a JPEG without its comments and APPn segments other than JFIF
+// (APP0), ICC profiles (APP2) and Adobe colour transforms (APP14). EXIF and
+// XMP are APP1 segments. Anything after the end of the image is dropped
+func stripJPEG(src io.Reader, dst io.Writer) error {
+	r := bufio.NewReader(src)
+	w := bufio.NewWriter(dst)
+	soi := make([]byte, 2)
+	if _, err := io.ReadFull(r, soi); err != nil || soi[0] != 0xFF || soi[1] != 0xD8 {
+		return errInvalid("JPEG")
+	}
+	if _, err := w.Write(soi); err != nil {
+		return err
+	}
+	marker, err := readJPEGMarker(r)
+	for err == nil {
+		switch {
+		case marker == markerEOI:
+			if _, err := w.Write([]byte{0xFF, marker}); err != nil {
+				return err
+			}
+			return w.Flush()
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // Without a length
+			if _, err = w.Write([]byte{0xFF, marker}); err != nil {
+				return err
+			}
+			marker, err = readJPEGMarker(r)
+			continue
+		}
+		length := make([]byte, 2)
+		if _, err := io.ReadFull(r, length); err != nil {
+			return errInvalid("JPEG")
+		}
+		size := int64(length[0])<<8 | int64(length[1])
+		if size < 2 {
+			return errInvalid("JPEG")
+		}
+		if isJPEGMetadata(marker) {
+			_, err = io.CopyN(io.Discard, r, size-2)
+		} else if _, err = w.Write([]byte{0xFF, marker, length[0], length[1]}); err == nil {
+			_, err = io.CopyN(w, r, size-2)
+		}
+		if err != nil {
+			return errInvalid("JPEG")
+		}
+		if marker == markerSOS {
+			marker, err = copyEntropyCoded(r, w)
+		} else {
+			marker, err = readJPEGMarker(r)
+		}
+	}
+	return errInvalid("JPEG")
+}
+
+func isJPEGMetadata(marker byte) bool {
+	return (marker >= 0xE1 && marker <= 0xEF && marker != 0xE2 && marker != 0xEE) || marker == markerCOM
+}
+
+// Marker of the next segment, skipping any fill bytes
+func readJPEGMarker(r *bufio.Reader) (byte, error) {
+	b, err := r.ReadByte()
+	if err != nil || b != 0xFF {
+		return 0, errInvalid("JPEG")
+	}
+	for b == 0xFF {
+		if b, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+	}
+	return b, nil
+}
+
+// Copy entropy-coded data, returning the marker ending it. Within the data
+// 0xFF is followed by 0x00 (stuffing) or a restart marker
+func copyEntropyCoded(r *bufio.Reader, w *bufio.Writer) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != 0xFF {
+			if err := w.WriteByte(b); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		next, err := r.ReadByte()
+		for err == nil && next == 0xFF {
+			next, err = r.ReadByte()
+		}
+		if err != nil {
+			return 0, err
+		}
+		if next != 0x00 && (next < 0xD0 || next > 0xD7) {
+			return next, nil
+		}
+		if _, err := w.Write([]byte{0xFF, next}); err != nil {
+			return 0, err
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Package transform rewrites the content of files as they are downloaded,
+// e.g. to strip their metadata
+package transform
+
+import (
+	"io"
+
+	"github.com/ucl-arc-tre/egress/internal/types"
+)
+
+// Func writes the transformed content of src to dst
+type Func func(src io.Reader, dst io.Writer) error
+
+// Transform stripping the metadata of a file with the media type, or nil
+// if metadata is not stripped from the type. Office documents are zip
+// files, so are identified by their content as they are transformed
+func StripMetadata(mediaType string) Func {
+	switch mediaType {
+	case "image/jpeg":
+		return stripJPEG
+	case "image/png":
+		return stripPNG
+	case "application/pdf":
+		return stripPDF
+	case "application/zip":
+		return stripOOXML
+	}
+	return nil
+}
+
+func errInvalid(format string) error {
+	return types.NewErrInvalidObjectF("failed to strip metadata: not a valid %s file", format)
+}
@@ -0,0 +1,172 @@
+package transform
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripMetadata(t *testing.T) {
+	assert.NotNil(t, StripMetadata("image/jpeg"))
+	assert.NotNil(t, StripMetadata("image/png"))
+	assert.NotNil(t, StripMetadata("application/pdf"))
+	assert.NotNil(t, StripMetadata("application/zip")) // Stripped only if it is an Office document
+	assert.Nil(t, StripMetadata("text/csv"))
+}
+
+func TestStripJPEG(t *testing.T) {
+	original := makeJPEG(t)
+	// Insert EXIF and a comment after the start of the image, and a trailer
+	exif := append([]byte{0xFF, 0xE1, 0x00, 0x0E}, []byte("Exif\x00\x00secret")...)
+	comment := append([]byte{0xFF, 0xFE, 0x00, 0x08}, []byte("secret")...)
+	withMetadata := append([]byte{}, original[:2]...)
+	withMetadata = append(withMetadata, exif...)
+	withMetadata = append(withMetadata, comment...)
+	withMetadata = append(withMetadata, original[2:]...)
+	withMetadata = append(withMetadata, []byte("secret")...)
+
+	stripped := transform(t, stripJPEG, withMetadata)
+	assert.NotContains(t, string(stripped), "secret")
+	assert.Equal(t, original, stripped)
+	_, err := jpeg.Decode(bytes.NewReader(stripped))
+	assert.NoError(t, err)
+
+	assert.Error(t, stripJPEG(strings.NewReader("not a jpeg"), io.Discard))
+}
+
+func TestStripPNG(t *testing.T) {
+	original := makePNG(t)
+	text := []byte("\x00\x00\x00\x0dtEXtAuthor\x00secret\x00\x00\x00\x00")
+	iend := len(original) - 12
+	withMetadata := append([]byte{}, original[:33]...) // Signature and header
+	withMetadata = append(withMetadata, text...)
+	withMetadata = append(withMetadata, original[33:]...)
+	withMetadata = append(withMetadata, []byte("secret")...)
+
+	stripped := transform(t, stripPNG, withMetadata)
+	assert.NotContains(t, string(stripped), "secret")
+	assert.Equal(t, original, stripped)
+	assert.Equal(t, "IEND", string(stripped[iend+4:iend+8]))
+	_, err := png.Decode(bytes.NewReader(stripped))
+	assert.NoError(t, err)
+
+	assert.Error(t, stripPNG(strings.NewReader("not a png"), io.Discard))
+}
+
+func TestStripPDF(t *testing.T) {
+	pdf := "%PDF-1.7\n" +
+		"1 0 obj\n<< /Title (Results) /Author (Jane \\(J\\) Doe) /Producer <4A616E65> /Pages 2 0 R >>\nendobj\n" +
+		"2 0 obj\n<< /Type /Metadata /Length 60 >>\nstream\n" +
+		"<?xpacket begin=\"\" id=\"x\"?><x>secret</x><?xpacket end=\"w\"?>\nendstream\nendobj\n" +
+		"3 0 obj\n<< /Title (Figure 1) /Length 24 >>\nstream\n/Title (Kept) obj endobj\nendstream\nendobj\n" +
+		"trailer\n<< /Size 4 /Info 1 0 R >>\n%%EOF\n"
+	stripped := string(transform(t, stripPDF, []byte(pdf)))
+	assert.Len(t, stripped, len(pdf))
+	assert.NotContains(t, stripped, "Results")
+	assert.NotContains(t, stripped, "Jane")
+	assert.NotContains(t, stripped, "4A61")
+	assert.NotContains(t, stripped, "secret")
+	assert.Contains(t, stripped, "/Author (              ) /Producer <00000000> /Pages 2 0 R")
+	assert.Contains(t, stripped, `<?xpacket begin="" id="x"?>`)
+	assert.Contains(t, stripped, `<?xpacket end="w"?>`)
+
+	// Only the objects referenced by /Info are blanked, and not streams
+	assert.Contains(t, stripped, "/Title (Figure 1)")
+	assert.Contains(t, stripped, "/Title (Kept) obj endobj")
+
+	// Without a trailer referencing it, the object is not blanked
+	withoutInfo := strings.Replace(pdf, "/Info 1 0 R", "/Root 1 0 R", 1)
+	assert.Contains(t, string(transform(t, stripPDF, []byte(withoutInfo))), "/Title (Results)")
+
+	assert.Error(t, stripPDF(strings.NewReader("not a pdf"), io.Discard))
+}
+
+func TestStripOOXML(t *testing.T) {
+	entries := map[string][]byte{
+		"[Content_Types].xml": []byte(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/></Types>`),
+		"_rels/.rels": []byte(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/></Relationships>`),
+		"docProps/core.xml":     []byte("<cp:coreProperties><dc:creator>secret</dc:creator></cp:coreProperties>"),
+		"docProps/app.xml":      []byte("<Properties><Company>secret</Company></Properties>"),
+		"word/document.xml":     []byte("<w:document>content</w:document>"),
+		"word/media/image1.png": append(makePNG(t)[:33], append([]byte("\x00\x00\x00\x06tEXtsecret\x00\x00\x00\x00"), makePNG(t)[33:]...)...),
+	}
+	stripped := transform(t, stripOOXML, makeZip(t, entries))
+	zr, err := zip.NewReader(bytes.NewReader(stripped), int64(len(stripped)))
+	require.NoError(t, err)
+	require.Len(t, zr.File, len(entries))
+	for _, file := range zr.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "secret", file.Name)
+		switch file.Name {
+		case "word/document.xml":
+			assert.Equal(t, entries[file.Name], content)
+		case "word/media/image1.png":
+			assert.Equal(t, makePNG(t), content)
+		}
+	}
+
+	// Other zips, and content that is not a zip, are copied unchanged
+	delete(entries, "_rels/.rels")
+	notDocument := makeZip(t, entries)
+	assert.Equal(t, notDocument, transform(t, stripOOXML, notDocument))
+	assert.Equal(t, []byte("not a zip"), transform(t, stripOOXML, []byte("not a zip")))
+}
+
+func makeZip(t *testing.T, entries map[string][]byte) []byte {
+	t.Helper()
+	buffer := bytes.Buffer{}
+	zw := zip.NewWriter(&buffer)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buffer.Bytes()
+}
+
+func transform(t *testing.T, f Func, content []byte) []byte {
+	t.Helper()
+	result := bytes.Buffer{}
+	require.NoError(t, f(bytes.NewReader(content), &result))
+	return result.Bytes()
+}
+
+func makeImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for x := range 16 {
+		for y := range 16 {
+			img.Set(x, y, color.RGBA{R: uint8(x * 16), G: uint8(y * 16), A: 255})
+		}
+	}
+	return img
+}
+
+func makeJPEG(t *testing.T) []byte {
+	t.Helper()
+	buffer := bytes.Buffer{}
+	require.NoError(t, jpeg.Encode(&buffer, makeImage(), nil))
+	return buffer.Bytes()
+}
+
+func makePNG(t *testing.T) []byte {
+	t.Helper()
+	buffer := bytes.Buffer{}
+	require.NoError(t, png.Encode(&buffer, makeImage()))
+	return buffer.Bytes()
+}
@@ -0,0 +1,100 @@
+package transform
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ucl-arc-tre/egress/internal/filetype"
+)
+
+// Empty document properties, replacing those with the author, company,
+// dates, template etc.
+var ooxmlEmptyProperties = map[string]string{
+	"docProps/core.xml":   xml.Header + `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:dcmitype="http://purl.org/dc/dcmitype/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"/>`,
+	"docProps/app.xml":    xml.Header + `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"/>`,
+	"docProps/custom.xml": xml.Header + `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"/>`,
+}
+
+// Copy an Office Open XML document (e.g. .docx, .xlsx or .pptx) with empty
+// document properties and the metadata stripped from the images it
+// contains. The zip is spooled to a temporary file as its directory is at
+// the end, and is copied unchanged if it is not such a document, whatever
+// its name. Authors of comments and tracked changes are not stripped
+func stripOOXML(src io.Reader, dst io.Writer) error {
+	spool, err := os.CreateTemp("", "egress-ooxml-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	size, err := io.Copy(spool, src)
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(spool, size)
+	format := filetype.OfficeFormat("")
+	if err == nil {
+		format, _ = filetype.OfficeDocument(zr)
+	}
+	if format != filetype.OfficeFormatOOXML {
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		_, err = io.Copy(dst, spool)
+		return err
+	}
+
+	zw := zip.NewWriter(dst)
+	for _, file := range zr.File {
+		if properties, exists := ooxmlEmptyProperties[file.Name]; exists {
+			err = writeZipEntry(zw, file, strings.NewReader(properties), nil)
+		} else if strip := stripImage(file.Name); strip != nil {
+			err = writeZipEntry(zw, file, nil, strip)
+		} else {
+			err = zw.Copy(file)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func stripImage(name string) Func {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg":
+		return stripJPEG
+	case ".png":
+		return stripPNG
+	}
+	return nil
+}
+
+// Write an entry with the name and method of the file, and either the
+// content or the file's content transformed
+func writeZipEntry(zw *zip.Writer, file *zip.File, content io.Reader, transform Func) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     file.Name,
+		Method:   file.Method,
+		Modified: file.Modified,
+	})
+	if err != nil {
+		return err
+	}
+	if transform == nil {
+		_, err = io.Copy(w, content)
+		return err
+	}
+	r, err := file.Open()
+	if err != nil {
+		return errInvalid("Office document")
+	}
+	defer r.Close()
+	return transform(r, w)
+}
@@ -0,0 +1,339 @@
+package transform
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Keys of the document information dictionary whose string values are blanked
+var pdfInfoKeys = map[string]bool{
+	"Title": true, "Author": true, "Subject": true, "Keywords": true,
+	"Creator": true, "Producer": true, "CreationDate": true, "ModDate": true,
+}
+
+var (
+	xmpBegin  = []byte("<?xpacket begin")
+	xmpEnd    = []byte("<?xpacket end")
+	endstream = []byte("endstream")
+)
+
+// Copy a PDF with the document information strings and XMP metadata
+// blanked out. Blanking keeps the length of everything so that the cross
+// reference offsets stay valid. The PDF is spooled so that the objects
+// referenced by /Info of its trailers are known before they are copied.
+// Only the strings of those objects are blanked, and streams are copied as
+// they are other than their XMP packets. Values that are indirect
+// references, keys other than the standard ones and metadata in compressed
+// streams are not blanked
+func stripPDF(src io.Reader, dst io.Writer) error {
+	spool, err := os.CreateTemp("", "egress-pdf-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	if _, err := io.Copy(spool, src); err != nil {
+		return err
+	}
+
+	// Find the document information objects, then blank them
+	info := map[string]bool{}
+	for _, w := range []*bufio.Writer{bufio.NewWriter(io.Discard), bufio.NewWriter(dst)} {
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r := bufio.NewReader(spool)
+		if head, err := r.Peek(5); err != nil || string(head) != "%PDF-" {
+			return errInvalid("PDF")
+		}
+		s := pdfStripper{r: r, w: w, info: info}
+		if err := s.strip(); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type pdfStripper struct {
+	r *bufio.Reader
+	w *bufio.Writer
+
+	info   map[string]bool // Objects referenced by /Info e.g. "12 0"
+	object string          // Object being copied, if any
+	tokens []string        // Last names and keywords copied
+}
+
+func (s *pdfStripper) strip() error {
+	for {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if err := s.w.WriteByte(b); err != nil {
+			return err
+		}
+		switch {
+		case isPDFWhitespace(b):
+		case b == '%':
+			err = s.copyComment()
+		case b == '(':
+			err = s.copyLiteral(false)
+		case b == '<' && s.peekIs([]byte("<")):
+			_, _ = s.r.ReadByte()
+			err = s.w.WriteByte('<')
+		case b == '<':
+			err = s.copyHex(false)
+		case b == '/':
+			var name string
+			if name, err = s.copyToken(); err == nil {
+				s.push("/" + name)
+				if s.info[s.object] && pdfInfoKeys[name] {
+					err = s.blankValue()
+				}
+			}
+		case isPDFDelimiter(b):
+		default:
+			var token string
+			if token, err = s.copyToken(); err == nil {
+				err = s.keyword(string(b) + token)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Track the object being copied and the objects referenced by /Info, and
+// copy streams
+func (s *pdfStripper) keyword(token string) error {
+	s.push(token)
+	n := len(s.tokens)
+	switch {
+	case token == "obj" && n >= 3 && isPDFInteger(s.tokens[n-3]) && isPDFInteger(s.tokens[n-2]):
+		s.object = s.tokens[n-3] + " " + s.tokens[n-2]
+	case token == "endobj":
+		s.object = ""
+	case token == "R" && n >= 4 && s.tokens[n-4] == "/Info" && isPDFInteger(s.tokens[n-3]) && isPDFInteger(s.tokens[n-2]):
+		s.info[s.tokens[n-3]+" "+s.tokens[n-2]] = true
+	case token == "stream":
+		return s.copyStream()
+	}
+	return nil
+}
+
+func (s *pdfStripper) push(token string) {
+	s.tokens = append(s.tokens, token)
+	if len(s.tokens) > 4 {
+		s.tokens = s.tokens[1:]
+	}
+}
+
+// Copy the content of a stream, blanking only XMP packets
+func (s *pdfStripper) copyStream() error {
+	for !s.peekIs(endstream) {
+		if s.peekIs(xmpBegin) {
+			if err := s.copyThrough([]byte("?>")); err != nil {
+				return err
+			}
+			if err := s.blankUntil(xmpEnd); err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if err := s.w.WriteByte(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *pdfStripper) copyComment() error {
+	for {
+		next, err := s.r.Peek(1)
+		if err != nil {
+			return err
+		}
+		if next[0] == '\n' || next[0] == '\r' {
+			return nil
+		}
+		b, _ := s.r.ReadByte()
+		if err := s.w.WriteByte(b); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *pdfStripper) peekIs(value []byte) bool {
+	next, _ := s.r.Peek(len(value))
+	return bytes.Equal(next, value)
+}
+
+// Copy the rest of a name or keyword
+func (s *pdfStripper) copyToken() (string, error) {
+	token := []byte{}
+	for {
+		next, err := s.r.Peek(1)
+		if err != nil {
+			return string(token), err
+		}
+		if isPDFWhitespace(next[0]) || isPDFDelimiter(next[0]) {
+			return string(token), nil
+		}
+		b, _ := s.r.ReadByte()
+		token = append(token, b)
+		if err := s.w.WriteByte(b); err != nil {
+			return "", err
+		}
+	}
+}
+
+// Blank the literal or hex string following a key, if it is one
+func (s *pdfStripper) blankValue() error {
+	for {
+		next, err := s.r.Peek(2)
+		if len(next) == 0 {
+			return err
+		}
+		switch {
+		case isPDFWhitespace(next[0]):
+			b, _ := s.r.ReadByte()
+			if err := s.w.WriteByte(b); err != nil {
+				return err
+			}
+			continue
+		case next[0] == '(':
+			_, _ = s.r.ReadByte()
+			if err := s.w.WriteByte('('); err != nil {
+				return err
+			}
+			return s.copyLiteral(true)
+		case next[0] == '<' && len(next) == 2 && next[1] != '<':
+			_, _ = s.r.ReadByte()
+			if err := s.w.WriteByte('<'); err != nil {
+				return err
+			}
+			return s.copyHex(true)
+		}
+		return nil
+	}
+}
+
+// Copy the rest of a literal string, which may contain balanced or escaped
+// parentheses, replacing its content with spaces if it is blanked
+func (s *pdfStripper) copyLiteral(blank bool) error {
+	for depth := 1; ; {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch b {
+		case '\\':
+			escaped, err := s.r.ReadByte()
+			if err != nil {
+				return err
+			}
+			if blank {
+				b, escaped = ' ', ' '
+			}
+			if _, err := s.w.Write([]byte{b, escaped}); err != nil {
+				return err
+			}
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s.w.WriteByte(')')
+			}
+		}
+		if blank {
+			b = ' '
+		}
+		if err := s.w.WriteByte(b); err != nil {
+			return err
+		}
+	}
+}
+
+// Copy the rest of a hex string, replacing its digits with zeros if it is
+// blanked
+func (s *pdfStripper) copyHex(blank bool) error {
+	for {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == '>' {
+			return s.w.WriteByte(b)
+		}
+		if blank && !isPDFWhitespace(b) {
+			b = '0'
+		}
+		if err := s.w.WriteByte(b); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *pdfStripper) copyThrough(end []byte) error {
+	for !s.peekIs(end) {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if err := s.w.WriteByte(b); err != nil {
+			return err
+		}
+	}
+	_, err := s.r.Discard(len(end))
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(end)
+	return err
+}
+
+// Replace everything up to the marker with spaces, other than line breaks
+func (s *pdfStripper) blankUntil(marker []byte) error {
+	for !s.peekIs(marker) {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b != '\n' && b != '\r' {
+			b = ' '
+		}
+		if err := s.w.WriteByte(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isPDFWhitespace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\r' || b == '\t' || b == '\f' || b == 0
+}
+
+func isPDFDelimiter(b byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), b) >= 0
+}
+
+func isPDFInteger(token string) bool {
+	_, err := strconv.Atoi(token)
+	return err == nil
+}
@@ -0,0 +1,48 @@
+package transform
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// Chunks of text, EXIF (including XMP, which is in an iTXt chunk) and the
+// time of last modification
+var pngMetadataChunks = map[string]bool{"tEXt": true, "zTXt": true, "iTXt": true, "eXIf": true, "tIME": true}
+
+// Copy a PNG without its metadata chunks. Anything after the end of the
+// image is dropped
+func stripPNG(src io.Reader, dst io.Writer) error {
+	r := bufio.NewReader(src)
+	w := bufio.NewWriter(dst)
+	signature := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, signature); err != nil || !bytes.Equal(signature, pngSignature) {
+		return errInvalid("PNG")
+	}
+	if _, err := w.Write(signature); err != nil {
+		return err
+	}
+	header := make([]byte, 8) // Length and type
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return errInvalid("PNG")
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4])) + 4 // Including the CRC
+		chunkType := string(header[4:])
+		var err error
+		if pngMetadataChunks[chunkType] {
+			_, err = io.CopyN(io.Discard, r, length)
+		} else if _, err = w.Write(header); err == nil {
+			_, err = io.CopyN(w, r, length)
+		}
+		if err != nil {
+			return errInvalid("PNG")
+		}
+		if chunkType == "IEND" {
+			return w.Flush()
+		}
+	}
+}
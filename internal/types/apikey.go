@@ -0,0 +1,95 @@
+package types
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Unique identifier of an API key, which is not secret
+type ApiKeyId string
+
+// Operations an API key may be used for
+type ApiKeyScope string
+
+const (
+	ApiKeyScopeRead     ApiKeyScope = "read"     // List files and events, and read reports, previews and diffs
+	ApiKeyScopeReview   ApiKeyScope = "review"   // Approve and reject files
+	ApiKeyScopeDownload ApiKeyScope = "download" // Download files and archives
+)
+
+var ApiKeyScopes = []ApiKeyScope{ApiKeyScopeRead, ApiKeyScopeReview, ApiKeyScopeDownload}
+
+// Allows an API key to be used for any project
+const ApiKeyAllProjects = ProjectId("*")
+
+// An API key of a service consumer. Only the hash of its secret is stored
+type ApiKey struct {
+	Id       ApiKeyId
+	Label    string
+	Projects []ProjectId
+	Scopes   []ApiKeyScope
+	Hash     string // SHA-256 of the secret, hex encoded
+	Created  time.Time
+	Expires  time.Time // Zero if the key does not expire
+	Revoked  time.Time // Zero if the key has not been revoked
+}
+
+// Make an API key with a new id and secret, returning the key as given
+// to its consumer i.e. "<id>.<secret>"
+func NewApiKey(label string, projects []ProjectId, scopes []ApiKeyScope, expires time.Time) (ApiKey, string) {
+	secret := rand.Text() // 128 bits, so a fast hash suffices
+	key := ApiKey{
+		Id:       ApiKeyId(uuid.NewString()),
+		Label:    label,
+		Projects: projects,
+		Scopes:   scopes,
+		Hash:     hashApiKeySecret(secret),
+		Created:  time.Now(),
+		Expires:  expires,
+	}
+	return key, string(key.Id) + "." + secret
+}
+
+// Id and secret of an API key as given by its consumer
+func ParseApiKey(value string) (ApiKeyId, string, bool) {
+	id, secret, found := strings.Cut(value, ".")
+	if !found || id == "" || secret == "" {
+		return "", "", false
+	}
+	return ApiKeyId(id), secret, true
+}
+
+// Whether the secret is that of the key, compared in constant time
+func (k ApiKey) HasSecret(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashApiKeySecret(secret)), []byte(k.Hash)) == 1
+}
+
+// Whether the key has neither been revoked nor expired
+func (k ApiKey) IsActive(now time.Time) bool {
+	return k.Revoked.IsZero() && (k.Expires.IsZero() || now.Before(k.Expires))
+}
+
+// Whether the key may be used for the project with all of the scopes
+func (k ApiKey) Allows(projectId ProjectId, scopes []ApiKeyScope) bool {
+	if !slices.Contains(k.Projects, projectId) && !slices.Contains(k.Projects, ApiKeyAllProjects) {
+		return false
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(k.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+func hashApiKeySecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiKeySecret(t *testing.T) {
+	key, value := NewApiKey("ci", []ProjectId{"p1"}, []ApiKeyScope{ApiKeyScopeRead}, time.Time{})
+
+	id, secret, ok := ParseApiKey(value)
+	assert.True(t, ok)
+	assert.Equal(t, key.Id, id)
+	assert.True(t, key.HasSecret(secret))
+	assert.False(t, key.HasSecret(secret+"x"))
+	assert.NotContains(t, key.Hash, secret)
+
+	for _, value := range []string{"", "id", "id.", ".secret"} {
+		_, _, ok := ParseApiKey(value)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestApiKeyIsActive(t *testing.T) {
+	now := time.Now()
+	assert.True(t, ApiKey{}.IsActive(now))
+	assert.True(t, ApiKey{Expires: now.Add(time.Minute)}.IsActive(now))
+	assert.False(t, ApiKey{Expires: now}.IsActive(now))
+	assert.False(t, ApiKey{Revoked: now.Add(-time.Minute)}.IsActive(now))
+}
+
+func TestApiKeyAllows(t *testing.T) {
+	key := ApiKey{Projects: []ProjectId{"p1"}, Scopes: []ApiKeyScope{ApiKeyScopeRead, ApiKeyScopeDownload}}
+	assert.True(t, key.Allows("p1", []ApiKeyScope{ApiKeyScopeRead}))
+	assert.True(t, key.Allows("p1", []ApiKeyScope{ApiKeyScopeRead, ApiKeyScopeDownload}))
+	assert.False(t, key.Allows("p1", []ApiKeyScope{ApiKeyScopeReview}))
+	assert.False(t, key.Allows("p2", []ApiKeyScope{ApiKeyScopeRead}))
+	assert.False(t, key.Allows("", []ApiKeyScope{}))
+
+	key.Projects = []ProjectId{ApiKeyAllProjects}
+	assert.True(t, key.Allows("p2", []ApiKeyScope{ApiKeyScopeRead}))
+}
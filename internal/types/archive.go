@@ -0,0 +1,26 @@
+package types
+
+// Limits of archives, which an archive is blocked from egress for exceeding
+const (
+	ArchiveLimitRatio   = "ratio"   // Of the total size of the members to the size of the archive
+	ArchiveLimitDepth   = "depth"   // Of archives within archives
+	ArchiveLimitMembers = "members" // Number of members
+)
+
+// Members of an archive file, including those of archives within it
+type ArchiveInspection struct {
+	FileId   FileId
+	Format   string // zip, tar or gzip
+	Members  []ArchiveMember
+	Exceeded string // Limit exceeded, if any, after which members are not listed
+}
+
+// Member of an archive
+type ArchiveMember struct {
+	Path           string // Under the path of the archive within the archive it is in, if any
+	Size           int64
+	CompressedSize int64 // -1 if not known e.g. for members of a compressed tar
+	Depth          int   // 1 for members of the archive, 2 for members of an archive within it etc.
+	MediaType      string
+	Scan           *ScanResult // nil if not scanned
+}
@@ -0,0 +1,55 @@
+package types
+
+// Kind of diff between two versions of a file
+type DiffKind string
+
+const (
+	DiffKindText  = DiffKind("text")  // Lines inserted and deleted
+	DiffKindTable = DiffKind("table") // Rows inserted and deleted, and cells modified
+)
+
+// Operation that changes a line or row of the base version of a file
+type DiffOp string
+
+const (
+	DiffOpInsert = DiffOp("insert")
+	DiffOpDelete = DiffOp("delete")
+	DiffOpModify = DiffOp("modify") // Of some of the cells of a row
+)
+
+// Changes from a base version of a file to another version. Lines and rows
+// that are unchanged are not included
+type FileDiff struct {
+	BaseFileId FileId
+	FileId     FileId
+	Kind       DiffKind
+	Lines      []LineChange // Of text
+	Rows       []RowChange  // Of a table
+}
+
+// Line of text inserted into or deleted from the base version. Line
+// numbers start at 1, and are 0 for the version the line is not in
+type LineChange struct {
+	Op       DiffOp
+	BaseLine int
+	Line     int
+	Text     string
+}
+
+// Row of a table inserted, deleted or modified. Row numbers start at 1,
+// and are 0 for the version the row is not in
+type RowChange struct {
+	Op      DiffOp
+	BaseRow int
+	Row     int
+	Cells   []string     // Of an inserted or deleted row
+	Changes []CellChange // Of a modified row
+}
+
+// Cell of a modified row whose value differs between the versions
+type CellChange struct {
+	Column int    // Starting at 1
+	Header string // Of the column, if the versions have the same header row
+	Base   string
+	Value  string
+}
@@ -9,6 +9,9 @@ var (
 	ErrInvalidObject = errors.New("invalid object")
 	ErrServer        = errors.New("server error")
 	ErrNotFound      = errors.New("not found")
+
+	ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+	ErrUnavailable         = errors.New("unavailable")
 )
 
 func NewErrInvalidObjectF(format string, objs ...any) error {
@@ -23,9 +26,36 @@ func NewErrNotFoundF(format string, objs ...any) error {
 	return newErrorWithType(fmt.Errorf(format, objs...), ErrNotFound)
 }
 
-func newErrorWithType(err any, errorType error) error {
+func NewErrRangeNotSatisfiableF(format string, objs ...any) error {
+	return newErrorWithType(fmt.Errorf(format, objs...), ErrRangeNotSatisfiable)
+}
+
+func NewErrUnavailableF(format string, objs ...any) error {
+	return newErrorWithType(fmt.Errorf(format, objs...), ErrUnavailable)
+}
+
+func newErrorWithType(err error, errorType error) error {
 	if err == nil {
 		return nil
 	}
-	return fmt.Errorf("%w: %v", errorType, err)
+	return typedError{errorType: errorType, err: err}
+}
+
+// Error of a type. It is only of that type for errors.Is, but the errors
+// it wraps can be found with errors.As e.g. the response of a failed request
+type typedError struct {
+	errorType error
+	err       error
+}
+
+func (e typedError) Error() string {
+	return e.errorType.Error() + ": " + e.err.Error()
+}
+
+func (e typedError) Unwrap() error {
+	return e.errorType
+}
+
+func (e typedError) As(target any) bool {
+	return errors.As(e.err, target)
 }
@@ -0,0 +1,24 @@
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statusError int
+
+func (e statusError) Error() string       { return "status error" }
+func (e statusError) HTTPStatusCode() int { return int(e) }
+
+func TestTypedError(t *testing.T) {
+	err := NewErrServerF("failed to get object: %w", NewErrNotFoundF("response: %w", statusError(404)))
+	assert.Equal(t, "server error: failed to get object: not found: response: status error", err.Error())
+	assert.ErrorIs(t, err, ErrServer)
+	assert.NotErrorIs(t, err, ErrNotFound) // Only of its own type
+
+	var response interface{ HTTPStatusCode() int }
+	assert.True(t, errors.As(err, &response))
+	assert.Equal(t, 404, response.HTTPStatusCode())
+}
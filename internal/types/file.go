@@ -1,7 +1,10 @@
 package types
 
 import (
+	"fmt"
 	"io"
+	"regexp"
+	"strconv"
 	"time"
 )
 
@@ -10,12 +13,115 @@ type FileId string
 
 type File struct {
 	Content io.ReadCloser
-	Size    int64 // Number of  bytes
+	Size    int64         // Number of bytes in the whole file
+	Range   *ContentRange // Part of the file in Content; nil if the whole file
 }
 
 type FileMetadata struct {
 	Name           string
 	LastModifiedAt time.Time
 	Id             FileId
-	Size           int64 // Number of  bytes
+	Size           int64  // Number of  bytes
+	MediaType      string // Detected from the content of the file, if known
+}
+
+// Single byte range requested from a file, as per the Range header of
+// RFC 9110 e.g. "bytes=0-99" is {Start: 0, Length: 100}
+type ByteRange struct {
+	Start  int64 // First byte; or if negative, the number of bytes before the end of the file
+	Length int64 // Number of bytes; or if 0, up to the end of the file
+}
+
+var byteRangeRegex = regexp.MustCompile(`^bytes=(\d*)-(\d*)$`)
+
+// Parse a Range header value. Only a single range is supported
+func ParseByteRange(value string) (*ByteRange, error) {
+	match := byteRangeRegex.FindStringSubmatch(value)
+	if match == nil || (match[1] == "" && match[2] == "") {
+		return nil, NewErrInvalidObjectF("unsupported range [%s]", value)
+	}
+	if match[1] == "" { // Suffix range e.g. bytes=-500
+		suffix, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil || suffix == 0 {
+			return nil, NewErrInvalidObjectF("invalid range [%s]", value)
+		}
+		return &ByteRange{Start: -suffix}, nil
+	}
+	start, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return nil, NewErrInvalidObjectF("invalid range [%s]", value)
+	}
+	if match[2] == "" {
+		return &ByteRange{Start: start}, nil
+	}
+	end, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil || end < start {
+		return nil, NewErrInvalidObjectF("invalid range [%s]", value)
+	}
+	return &ByteRange{Start: start, Length: end - start + 1}, nil
+}
+
+// Range header value e.g. "bytes=0-99"
+func (r ByteRange) String() string {
+	if r.Start < 0 {
+		return fmt.Sprintf("bytes=%d", r.Start)
+	}
+	if r.Length == 0 {
+		return fmt.Sprintf("bytes=%d-", r.Start)
+	}
+	return fmt.Sprintf("bytes=%d-%d", r.Start, r.Start+r.Length-1)
+}
+
+// Bytes of a file of the given size in the range. Errors if none are
+func (r ByteRange) Resolve(size int64) (ContentRange, error) {
+	first, last := r.Start, size-1
+	if first < 0 {
+		first = max(size+first, 0)
+	} else if r.Length > 0 {
+		last = min(first+r.Length-1, size-1)
+	}
+	if first >= size {
+		return ContentRange{}, NewErrRangeNotSatisfiableF("range [%v] not satisfiable for size %d", r, size)
+	}
+	return ContentRange{First: first, Last: last}, nil
+}
+
+// Inclusive range of bytes of a file, as per the Content-Range header
+// of RFC 9110 e.g. "bytes 0-99/1234" is {First: 0, Last: 99}
+type ContentRange struct {
+	First int64
+	Last  int64
+}
+
+var contentRangeRegex = regexp.MustCompile(`^bytes (\d+)-(\d+)/(\d+)$`)
+
+// Parse a Content-Range header value into the range and the total file size
+func ParseContentRange(value string) (ContentRange, int64, error) {
+	match := contentRangeRegex.FindStringSubmatch(value)
+	if match == nil {
+		return ContentRange{}, 0, fmt.Errorf("unsupported content range [%s]", value)
+	}
+	numbers := [3]int64{}
+	for i := range numbers {
+		n, err := strconv.ParseInt(match[i+1], 10, 64)
+		if err != nil {
+			return ContentRange{}, 0, fmt.Errorf("invalid content range [%s]: %w", value, err)
+		}
+		numbers[i] = n
+	}
+	cr := ContentRange{First: numbers[0], Last: numbers[1]}
+	if cr.Last < cr.First || cr.Last >= numbers[2] {
+		return ContentRange{}, 0, fmt.Errorf("invalid content range [%s]", value)
+	}
+	return cr, numbers[2], nil
+}
+
+// Number of bytes in the range
+func (r ContentRange) Length() int64 {
+	return r.Last - r.First + 1
+}
+
+// Content-Range header value for a file of the given size e.g. "bytes 0-99/1234"
+func (r ContentRange) Header(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.First, r.Last, size)
 }
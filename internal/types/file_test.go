@@ -0,0 +1,72 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteRange(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected *ByteRange
+	}{
+		{value: "bytes=0-99", expected: &ByteRange{Start: 0, Length: 100}},
+		{value: "bytes=100-", expected: &ByteRange{Start: 100}},
+		{value: "bytes=-500", expected: &ByteRange{Start: -500}},
+		{value: "bytes=5-5", expected: &ByteRange{Start: 5, Length: 1}},
+		{value: "bytes=-"},
+		{value: "bytes=-0"},
+		{value: "bytes=5-4"},
+		{value: "bytes=0-1,3-4"},
+		{value: "items=0-1"},
+		{value: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			byteRange, err := ParseByteRange(tc.value)
+			if tc.expected == nil {
+				assert.ErrorIs(t, err, ErrInvalidObject)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, byteRange)
+			assert.Equal(t, tc.value, byteRange.String())
+		})
+	}
+}
+
+func TestByteRangeResolve(t *testing.T) {
+	contentRange, err := ByteRange{Start: 2, Length: 3}.Resolve(10)
+	assert.NoError(t, err)
+	assert.Equal(t, ContentRange{First: 2, Last: 4}, contentRange)
+	assert.Equal(t, int64(3), contentRange.Length())
+
+	contentRange, err = ByteRange{Start: 8, Length: 5}.Resolve(10)
+	assert.NoError(t, err)
+	assert.Equal(t, ContentRange{First: 8, Last: 9}, contentRange)
+
+	contentRange, err = ByteRange{Start: -20}.Resolve(10)
+	assert.NoError(t, err)
+	assert.Equal(t, ContentRange{First: 0, Last: 9}, contentRange)
+
+	_, err = ByteRange{Start: 10}.Resolve(10)
+	assert.ErrorIs(t, err, ErrRangeNotSatisfiable)
+
+	_, err = ByteRange{Start: -1}.Resolve(0)
+	assert.ErrorIs(t, err, ErrRangeNotSatisfiable)
+}
+
+func TestParseContentRange(t *testing.T) {
+	contentRange, size, err := ParseContentRange("bytes 0-99/1234")
+	assert.NoError(t, err)
+	assert.Equal(t, ContentRange{First: 0, Last: 99}, contentRange)
+	assert.Equal(t, int64(1234), size)
+	assert.Equal(t, "bytes 0-99/1234", contentRange.Header(size))
+
+	for _, value := range []string{"", "bytes */1234", "bytes 5-4/10", "bytes 0-10/10", "bytes 0-1/*"} {
+		_, _, err := ParseContentRange(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -18,7 +18,9 @@ type UserId string
 type Destination string
 
 // Describes an egress related event tracked at file level
-// An egress event is either an approval, a rejection or a download
+// An egress event is either an approval, a rejection, a download,
+// a malware scan or an action taken on the file in storage after
+// a download or rejection
 type Event struct {
 	Time   time.Time
 	Action EventAction
@@ -28,10 +30,33 @@ type Event struct {
 // Describes the details of an event
 type EventDetails struct {
 	UserId      UserId
+	ApiKeyId    ApiKeyId // Set for an event caused by a request authenticated by an API key
+	DelegatorId UserId   // Set for an event caused by a service on behalf of the user
 	Destination Destination
 	Comment     string
+	BundleId    BundleId        // Set for a download in an archive of several files
+	Hashes      TransformHashes // Set for a download of a transformed file
 }
 
+// Who caused an event: the user and, if the request was authenticated
+// by an API key, the key, or if it was made by a trusted service on
+// behalf of the user, the service
+type Actor struct {
+	UserId      UserId
+	ApiKeyId    ApiKeyId
+	DelegatorId UserId
+}
+
+// SHA-256 hashes, hex encoded, of the content of a file before and after
+// it is transformed for download e.g. by stripping its metadata
+type TransformHashes struct {
+	Original    string
+	Transformed string
+}
+
+// Unique identifier of an archive of several files downloaded together
+type BundleId string
+
 // The specific action of an event
 type EventAction string
 
@@ -40,6 +65,17 @@ const (
 	EventActionApproval  EventAction = "Approval"
 	EventActionDownload  EventAction = "Download"
 	EventActionRejection EventAction = "Rejection"
+
+	// Storage actions taken after a download or rejection
+	EventActionTag    EventAction = "Tag"
+	EventActionCopy   EventAction = "Copy"
+	EventActionDelete EventAction = "Delete"
+
+	// Malware scan of the file, with the result as the comment
+	EventActionScan EventAction = "Scan"
+
+	// Preview of the content of the file, which is not a download
+	EventActionPreview EventAction = "Preview"
 )
 
 // An egress file approval, recording the approving user
@@ -92,6 +128,16 @@ func (fe FileEvents) Approvals() FileApprovals {
 	return approvals
 }
 
+// Whether the user has downloaded the file to the destination
+func (fe FileEvents) HasDownload(userId UserId, destination Destination) bool {
+	for _, e := range fe {
+		if e.Action == EventActionDownload && e.UserId == userId && e.Destination == destination {
+			return true
+		}
+	}
+	return false
+}
+
 // List of approvals granted for a file
 type FileApprovals []Approval
 
@@ -106,6 +106,15 @@ func TestFileEvents_Approvals(t *testing.T) {
 	}
 }
 
+func TestFileEvents_HasDownload(t *testing.T) {
+	events := FileEvents{approve(user1, dest1), download(user1, dest1), download(user2, dest2)}
+	assert.True(t, events.HasDownload(user1, dest1))
+	assert.True(t, events.HasDownload(user2, dest2))
+	assert.False(t, events.HasDownload(user1, dest2))
+	assert.False(t, events.HasDownload(user2, dest1))
+	assert.False(t, FileEvents{}.HasDownload(user1, dest1))
+}
+
 func approve(user UserId, dest Destination) Event {
 	return approveWithComment(user, dest, "")
 }
@@ -0,0 +1,24 @@
+package types
+
+// Kind of preview of a file
+type PreviewKind string
+
+const (
+	PreviewKindText  = PreviewKind("text")  // First lines of text
+	PreviewKindTable = PreviewKind("table") // First rows of a CSV or TSV file
+	PreviewKindImage = PreviewKind("image") // Thumbnail of an image
+	PreviewKindHex   = PreviewKind("hex")   // Hex dump of the first bytes of any other file
+)
+
+// Bounded view of the content of a file
+type FilePreview struct {
+	FileId    FileId
+	MediaType string
+	Kind      PreviewKind
+	Lines     []string   // Of text or a hex dump
+	Rows      [][]string // Of a table
+	Thumbnail []byte     // PNG no larger than the thumbnail size
+	Width     int        // Of the image, not the thumbnail
+	Height    int
+	Truncated bool // Whether the file has more content than is previewed
+}
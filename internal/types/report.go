@@ -0,0 +1,18 @@
+package types
+
+// Finding of an output check in the content of a file
+type Finding struct {
+	Check   string // Name of the check e.g. sdc
+	Type    string // Kind of finding e.g. small_count
+	Line    int    // First line of the file with the finding, from 1
+	Column  int    // Column of a table with the finding, from 1, or 0 if not a table
+	Count   int    // Number of occurrences
+	Message string
+}
+
+// Report of the output checks run on a file
+type FileReport struct {
+	FileId   FileId
+	Checks   []string // Names of the checks run on the file
+	Findings []Finding
+}
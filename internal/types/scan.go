@@ -0,0 +1,25 @@
+package types
+
+import "fmt"
+
+type ScanVerdict string
+
+const (
+	ScanVerdictClean    = ScanVerdict("clean")
+	ScanVerdictInfected = ScanVerdict("infected")
+)
+
+// Result of scanning a file for malware
+type ScanResult struct {
+	Verdict   ScanVerdict
+	Scanner   string // Name of the scanner giving the verdict e.g. clamd
+	Signature string // Malware found in an infected file
+}
+
+// Description of the result e.g. "infected: Eicar-Test-Signature (clamd)"
+func (r ScanResult) String() string {
+	if r.Verdict == ScanVerdictInfected {
+		return fmt.Sprintf("%s: %s (%s)", r.Verdict, r.Signature, r.Scanner)
+	}
+	return fmt.Sprintf("%s (%s)", r.Verdict, r.Scanner)
+}
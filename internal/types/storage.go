@@ -2,6 +2,7 @@ package types
 
 import (
 	"net/url"
+	"strings"
 )
 
 type StorageProvider string
@@ -9,6 +10,7 @@ type StorageProvider string
 const (
 	StorageProviderS3      = StorageProvider("s3")
 	StorageProviderGeneric = StorageProvider("generic")
+	StorageProviderAzBlob  = StorageProvider("azblob")
 	StorageProviderUnknown = StorageProvider("unknown")
 )
 
@@ -16,6 +18,7 @@ const (
 // e.g.
 //   - s3://example-bucket/a/path
 //   - https://127.0.0.1:443/v1
+//   - azblob://example-account/example-container
 type LocationURI url.URL
 
 func (l LocationURI) StorageProvider() StorageProvider {
@@ -24,14 +27,33 @@ func (l LocationURI) StorageProvider() StorageProvider {
 		return StorageProviderS3
 	case "http", "https":
 		return StorageProviderGeneric
+	case "azblob":
+		return StorageProviderAzBlob
 	default:
 		return StorageProviderUnknown
 	}
 }
 
+func (l LocationURI) String() string {
+	u := url.URL(l)
+	return u.String()
+}
+
 func (l LocationURI) BucketName() (string, error) {
 	if provider := l.StorageProvider(); provider != StorageProviderS3 {
 		return "", NewErrInvalidObjectF("storage provider not S3. [%v]", provider)
 	}
 	return l.Host, nil
 }
+
+// Storage account and container names of an Azure Blob Storage location
+func (l LocationURI) AccountAndContainer() (string, string, error) {
+	if provider := l.StorageProvider(); provider != StorageProviderAzBlob {
+		return "", "", NewErrInvalidObjectF("storage provider not Azure Blob. [%v]", provider)
+	}
+	container := strings.Trim(l.Path, "/")
+	if l.Host == "" || container == "" || strings.Contains(container, "/") {
+		return "", "", NewErrInvalidObjectF("azblob location must be azblob://account/container")
+	}
+	return l.Host, container, nil
+}
@@ -14,6 +14,7 @@ func TestStorageProviderFromLocation(t *testing.T) {
 		{scheme: "s3", expected: StorageProviderS3},
 		{scheme: "http", expected: StorageProviderGeneric},
 		{scheme: "https", expected: StorageProviderGeneric},
+		{scheme: "azblob", expected: StorageProviderAzBlob},
 		{scheme: "blah", expected: StorageProviderUnknown},
 	}
 
@@ -36,3 +37,23 @@ func TestBucketNameFromLocation(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, bucketName, actual)
 }
+
+func TestAccountAndContainerFromLocation(t *testing.T) {
+	s3Location := LocationURI{Scheme: "s3", Host: "bucket1"}
+	_, _, err := s3Location.AccountAndContainer()
+	assert.Error(t, err)
+
+	noContainer := LocationURI{Scheme: "azblob", Host: "account1"}
+	_, _, err = noContainer.AccountAndContainer()
+	assert.Error(t, err)
+
+	nested := LocationURI{Scheme: "azblob", Host: "account1", Path: "/container1/a"}
+	_, _, err = nested.AccountAndContainer()
+	assert.Error(t, err)
+
+	location := LocationURI{Scheme: "azblob", Host: "account1", Path: "/container1"}
+	account, container, err := location.AccountAndContainer()
+	assert.NoError(t, err)
+	assert.Equal(t, "account1", account)
+	assert.Equal(t, "container1", container)
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"slices"
+	"time"
+)
+
+// A version of a file with a name. A file that is resubmitted with the
+// same name has a new id, so is a new version of the name
+type FileVersion struct {
+	FileId    FileId
+	FirstSeen time.Time // When the file was first listed with the name
+}
+
+// Versions of a name, oldest first
+type FileVersions []FileVersion
+
+// Map of file names to their versions
+type ProjectVersions map[string]FileVersions
+
+// Id of the version of the name before the file, if there is one
+func (pv ProjectVersions) Previous(name string, fileId FileId) (FileId, bool) {
+	versions := pv[name]
+	i := slices.IndexFunc(versions, func(v FileVersion) bool { return v.FileId == fileId })
+	if i < 1 {
+		return "", false
+	}
+	return versions[i-1].FileId, true
+}
+
+// Files that are not yet recorded as a version of their name
+func (pv ProjectVersions) Unrecorded(files []FileMetadata) []FileMetadata {
+	unrecorded := []FileMetadata{}
+	for _, file := range files {
+		isRecorded := slices.ContainsFunc(pv[file.Name], func(v FileVersion) bool { return v.FileId == file.Id })
+		if !isRecorded {
+			unrecorded = append(unrecorded, file)
+		}
+	}
+	return unrecorded
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectVersions(t *testing.T) {
+	versions := ProjectVersions{
+		"results.csv": FileVersions{{FileId: "v1"}, {FileId: "v2"}},
+	}
+
+	previous, exists := versions.Previous("results.csv", "v2")
+	assert.True(t, exists)
+	assert.Equal(t, FileId("v1"), previous)
+	_, exists = versions.Previous("results.csv", "v1")
+	assert.False(t, exists)
+	_, exists = versions.Previous("other.csv", "v2")
+	assert.False(t, exists)
+
+	unrecorded := versions.Unrecorded([]FileMetadata{
+		{Name: "results.csv", Id: "v2"},
+		{Name: "results.csv", Id: "v3"},
+		{Name: "renamed.csv", Id: "v2"},
+	})
+	assert.Equal(t, []FileMetadata{{Name: "results.csv", Id: "v3"}, {Name: "renamed.csv", Id: "v2"}}, unrecorded)
+}
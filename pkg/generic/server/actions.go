@@ -0,0 +1,226 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// WithFileActions enables tagging, copying and deleting files via
+// PUT /file/tags, POST /file/copy and DELETE /file.
+// File actions are disabled by default.
+func WithFileActions() Option {
+	return func(h *Handler) {
+		h.fileActions = true
+	}
+}
+
+// DeleteFile implements DELETE /file.
+func (h *Handler) DeleteFile(ctx *gin.Context, params DeleteFileParams) {
+	if !h.allowFileActions(ctx) {
+		return
+	}
+	file, _, _, ok := h.openFile(ctx, params.Key, params.IfMatch)
+	if !ok {
+		return
+	}
+	file.Close()
+
+	root, err := os.OpenRoot(h.rootDirPath)
+	if err != nil {
+		internalServerError(ctx, err, "failed to open server root")
+		return
+	}
+	defer root.Close()
+
+	if err := root.Remove(params.Key); errors.Is(err, fs.ErrNotExist) {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Message: "file not found"})
+		return
+	} else if err != nil {
+		internalServerError(ctx, err, "failed to delete file")
+		return
+	}
+	if err := removeTags(root, params.Key); err != nil {
+		internalServerError(ctx, err, "failed to remove tags")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// PutFileTags implements PUT /file/tags.
+func (h *Handler) PutFileTags(ctx *gin.Context, params PutFileTagsParams) {
+	if !h.allowFileActions(ctx) {
+		return
+	}
+	var body TagFileRequest
+	if err := ctx.ShouldBindJSON(&body); err != nil || body.Tags == nil {
+		badRequest(ctx, "invalid request body. Must contain tags")
+		return
+	}
+	file, _, _, ok := h.openFile(ctx, params.Key, params.IfMatch)
+	if !ok {
+		return
+	}
+	file.Close()
+
+	root, err := os.OpenRoot(h.rootDirPath)
+	if err != nil {
+		internalServerError(ctx, err, "failed to open server root")
+		return
+	}
+	defer root.Close()
+
+	tags, err := readTags(root, params.Key)
+	if err != nil {
+		internalServerError(ctx, err, "failed to read tags")
+		return
+	}
+	maps.Copy(tags, body.Tags)
+	if err := writeTags(root, params.Key, tags); err != nil {
+		internalServerError(ctx, err, "failed to write tags")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// PostFileCopy implements POST /file/copy.
+func (h *Handler) PostFileCopy(ctx *gin.Context, params PostFileCopyParams) {
+	if !h.allowFileActions(ctx) {
+		return
+	}
+	var body CopyFileRequest
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		badRequest(ctx, "invalid request body. Must contain destination_key")
+		return
+	}
+	if !isValidKey(body.DestinationKey) || isReserved(body.DestinationKey) {
+		badRequest(ctx, "invalid destination key")
+		return
+	}
+	if filepath.Clean(body.DestinationKey) == filepath.Clean(params.Key) {
+		badRequest(ctx, "destination key must differ from key")
+		return
+	}
+	file, _, _, ok := h.openFile(ctx, params.Key, params.IfMatch)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	root, err := os.OpenRoot(h.rootDirPath)
+	if err != nil {
+		internalServerError(ctx, err, "failed to open server root")
+		return
+	}
+	defer root.Close()
+
+	if info, err := root.Stat(body.DestinationKey); err == nil && info.IsDir() {
+		badRequest(ctx, "destination key must refer to a file, not a directory")
+		return
+	}
+	dir := filepath.Dir(body.DestinationKey)
+	if err := root.MkdirAll(dir, 0o750); err != nil {
+		badRequest(ctx, "destination key must not be within a file")
+		return
+	}
+	err = writeAtomic(root, body.DestinationKey, func(w io.Writer) error {
+		_, err := io.Copy(w, file)
+		return err
+	})
+	if err != nil {
+		internalServerError(ctx, err, "failed to copy file")
+		return
+	}
+
+	// Tags are copied with the file, as for S3
+	tags, err := readTags(root, params.Key)
+	if err == nil && len(tags) > 0 {
+		err = writeTags(root, body.DestinationKey, tags)
+	} else if err == nil {
+		err = removeTags(root, body.DestinationKey)
+	}
+	if err != nil {
+		internalServerError(ctx, err, "failed to copy tags")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *Handler) allowFileActions(ctx *gin.Context) bool {
+	if !h.fileActions {
+		ctx.JSON(http.StatusForbidden, ErrorResponse{Message: "file actions are disabled"})
+	}
+	return h.fileActions
+}
+
+// tagsKey is the key of the file holding the tags of the file with the given key.
+func tagsKey(key string) string {
+	return filepath.Join(filepath.Dir(key), tagsPrefix+filepath.Base(key))
+}
+
+func readTags(root *os.Root, key string) (map[string]string, error) {
+	data, err := root.ReadFile(tagsKey(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func writeTags(root *os.Root, key string, tags map[string]string) error {
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(root, tagsKey(key), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func removeTags(root *os.Root, key string) error {
+	if err := root.Remove(tagsKey(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// writeAtomic writes to a temporary file alongside key, then moves it into
+// place so that a partially written file is never served.
+func writeAtomic(root *os.Root, key string, write func(w io.Writer) error) error {
+	tempKey := filepath.Join(filepath.Dir(key), uploadTempPrefix+rand.Text())
+	temp, err := root.OpenFile(tempKey, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := root.Remove(tempKey); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Error().Err(err).Str("key", tempKey).Msg("Failed to remove temporary file")
+		}
+	}()
+	err = write(temp)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return root.Rename(tempKey, key)
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveAction(t *testing.T, h *Handler, method string, url string, ifMatch string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	writer := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(writer)
+	RegisterHandlers(router, h)
+
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("If-Match", ifMatch)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	router.ServeHTTP(writer, req)
+	return writer
+}
+
+func TestDeleteFile(t *testing.T) {
+	testCases := []struct {
+		name               string
+		opts               []Option
+		ifMatch            func(h *Handler) string
+		expectedStatusCode int
+	}{
+		{
+			name:               "ok",
+			opts:               []Option{WithFileActions()},
+			ifMatch:            func(h *Handler) string { return etag(t, h, "data.txt") },
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "etag mismatch",
+			opts:               []Option{WithFileActions()},
+			ifMatch:            func(h *Handler) string { return `"other"` },
+			expectedStatusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:               "file actions disabled",
+			ifMatch:            func(h *Handler) string { return etag(t, h, "data.txt") },
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newTestHandlerWithOpts(t, map[string]string{"data.txt": "hello world"}, tc.opts...)
+			writer := serveAction(t, h, http.MethodDelete, "/file?key=data.txt", tc.ifMatch(h), "")
+
+			assert.Equal(t, tc.expectedStatusCode, writer.Code)
+			_, err := os.Stat(filepath.Join(h.rootDirPath, "data.txt"))
+			assert.Equal(t, tc.expectedStatusCode == http.StatusNoContent, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestPutFileTags(t *testing.T) {
+	h := newTestHandlerWithOpts(t, map[string]string{"a/data.txt": "hello world"}, WithFileActions())
+	eTag := etag(t, h, "a/data.txt")
+
+	writer := serveAction(t, h, http.MethodPut, "/file/tags?key=a/data.txt", eTag, `{"tags":{"status":"pending","owner":"user1"}}`)
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+	writer = serveAction(t, h, http.MethodPut, "/file/tags?key=a/data.txt", eTag, `{"tags":{"status":"downloaded"}}`)
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+
+	root, err := os.OpenRoot(h.rootDirPath)
+	require.NoError(t, err)
+	defer root.Close()
+	tags, err := readTags(root, "a/data.txt")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"status": "downloaded", "owner": "user1"}, tags)
+
+	// Tags are neither listed nor served as files
+	writer = serveAction(t, h, http.MethodGet, "/files", eTag, "")
+	var resp ListFilesResponse
+	require.NoError(t, json.NewDecoder(writer.Body).Decode(&resp))
+	assert.Equal(t, 1, resp.FileCount)
+	writer = serveAction(t, h, http.MethodGet, "/file?key=a/.tags-data.txt", eTag, "")
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+
+	writer = serveAction(t, h, http.MethodPut, "/file/tags?key=a/data.txt", eTag, `{}`)
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestPostFileCopy(t *testing.T) {
+	testCases := []struct {
+		name               string
+		body               string
+		expectedStatusCode int
+		expectedKey        string
+	}{
+		{
+			name:               "ok",
+			body:               `{"destination_key":"archive/data.txt"}`,
+			expectedStatusCode: http.StatusNoContent,
+			expectedKey:        "archive/data.txt",
+		},
+		{
+			name:               "replaces existing file",
+			body:               `{"destination_key":"existing.txt"}`,
+			expectedStatusCode: http.StatusNoContent,
+			expectedKey:        "existing.txt",
+		},
+		{
+			name:               "same key",
+			body:               `{"destination_key":"data.txt"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "path traversal",
+			body:               `{"destination_key":"../data.txt"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "reserved key",
+			body:               `{"destination_key":".tags-existing.txt"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{"data.txt": "hello world", "existing.txt": "old content"}
+			h := newTestHandlerWithOpts(t, files, WithFileActions())
+			eTag := etag(t, h, "data.txt")
+
+			writer := serveAction(t, h, http.MethodPut, "/file/tags?key=data.txt", eTag, `{"tags":{"status":"downloaded"}}`)
+			require.Equal(t, http.StatusNoContent, writer.Code)
+			writer = serveAction(t, h, http.MethodPost, "/file/copy?key=data.txt", eTag, tc.body)
+
+			assert.Equal(t, tc.expectedStatusCode, writer.Code)
+			if tc.expectedKey == "" {
+				return
+			}
+			content, err := os.ReadFile(filepath.Join(h.rootDirPath, tc.expectedKey))
+			require.NoError(t, err)
+			assert.Equal(t, "hello world", string(content))
+
+			root, err := os.OpenRoot(h.rootDirPath)
+			require.NoError(t, err)
+			defer root.Close()
+			tags, err := readTags(root, tc.expectedKey)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"status": "downloaded"}, tags)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ContentHashETagGenerator uses the SHA-256 of the file contents as the ETag.
+// Hashes are cached by path, size, modification time and inode so that
+// unchanged files are not rehashed. The cache is held in memory and, if an
+// index file is configured, persisted so that it survives restarts.
+type ContentHashETagGenerator struct {
+	mu        sync.Mutex
+	cache     map[string]contentHashEntry
+	indexPath string
+}
+
+// contentHashEntry is a cached hash, valid while the file is unchanged.
+// Entries are stored in the index file as JSON lines.
+type contentHashEntry struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"` // Unix nanoseconds
+	Inode   uint64 `json:"inode"`
+	SHA256  string `json:"sha256"` // Hex encoded
+}
+
+// ContentHashOption configures a ContentHashETagGenerator
+type ContentHashOption func(*ContentHashETagGenerator)
+
+// WithIndexFile persists cached hashes to the file at path, which should
+// be outside the served directory. New hashes are appended to it and it is
+// compacted when the generator is created.
+func WithIndexFile(path string) ContentHashOption {
+	return func(g *ContentHashETagGenerator) {
+		g.indexPath = path
+	}
+}
+
+// NewContentHashETagGenerator returns a ContentHashETagGenerator, loading
+// cached hashes of files that are unchanged from the index file if set.
+func NewContentHashETagGenerator(opts ...ContentHashOption) (*ContentHashETagGenerator, error) {
+	g := &ContentHashETagGenerator{cache: map[string]contentHashEntry{}}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.indexPath != "" {
+		if err := g.loadIndex(); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func (g *ContentHashETagGenerator) GenerateETag(path string, info fs.FileInfo) (string, error) {
+	entry := newContentHashEntry(path, info)
+	g.mu.Lock()
+	cached, exists := g.cache[path]
+	g.mu.Unlock()
+	if exists && cached.matches(entry) {
+		return fmt.Sprintf(`"%s"`, cached.SHA256), nil
+	}
+
+	hash, err := hashFile(path)
+	if err != nil {
+		return "", err
+	}
+	// The hash is only valid for info if the file did not change while hashing
+	after, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !entry.matches(newContentHashEntry(path, after)) {
+		return "", fmt.Errorf("file %s changed while hashing", path)
+	}
+	entry.SHA256 = hash
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cache[path] = entry
+	if g.indexPath != "" {
+		if err := g.appendToIndex(entry); err != nil {
+			log.Error().Err(err).Str("indexPath", g.indexPath).Msg("Failed to persist content hash")
+		}
+	}
+	return fmt.Sprintf(`"%s"`, hash), nil
+}
+
+func newContentHashEntry(path string, info fs.FileInfo) contentHashEntry {
+	return contentHashEntry{
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Inode:   inode(info),
+	}
+}
+
+// matches reports whether both entries describe the same unchanged file.
+func (e contentHashEntry) matches(other contentHashEntry) bool {
+	return e.Path == other.Path && e.Size == other.Size && e.ModTime == other.ModTime && e.Inode == other.Inode
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// loadIndex loads entries of files that are unchanged, the last entry for a
+// path taking precedence, then rewrites the index with only those entries.
+func (g *ContentHashETagGenerator) loadIndex() error {
+	file, err := os.Open(g.indexPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to open index: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := contentHashEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Warn().Err(err).Str("indexPath", g.indexPath).Msg("Skipping invalid index entry")
+			continue
+		}
+		g.cache[entry.Path] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	for path, entry := range g.cache {
+		info, err := os.Stat(path)
+		if err != nil || !entry.matches(newContentHashEntry(path, info)) {
+			delete(g.cache, path)
+		}
+	}
+	return g.writeIndex()
+}
+
+// writeIndex atomically replaces the index with the cached entries.
+func (g *ContentHashETagGenerator) writeIndex() error {
+	tmp, err := os.CreateTemp(filepath.Dir(g.indexPath), filepath.Base(g.indexPath)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	encoder := json.NewEncoder(tmp)
+	for _, entry := range g.cache {
+		if err := encoder.Encode(entry); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write index: %w", err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), g.indexPath); err != nil {
+		return fmt.Errorf("failed to replace index: %w", err)
+	}
+	return nil
+}
+
+func (g *ContentHashETagGenerator) appendToIndex(entry contentHashEntry) error {
+	file, err := os.OpenFile(g.indexPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(entry); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -136,3 +137,76 @@ func TestDefaultETagGeneratorUsedWhenNoOptionProvided(t *testing.T) {
 	assert.Equal(t, http.StatusOK, writer.Code,
 		"Handler should use DefaultETagGenerator when no custom option is provided")
 }
+
+func TestContentHashETagGenerator(t *testing.T) {
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.txt")
+	pathB := filepath.Join(dir, "b.txt")
+	require.NoError(t, os.WriteFile(pathA, []byte("hello"), 0o644))
+	require.NoError(t, os.WriteFile(pathB, []byte("world"), 0o644))
+
+	g, err := NewContentHashETagGenerator()
+	require.NoError(t, err)
+
+	etagA := generateETag(t, g, pathA)
+	assert.Equal(t, `"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"`, etagA)
+	assert.NotEqual(t, etagA, generateETag(t, g, pathB), "same size and different content")
+
+	// Identity follows content rather than modification time
+	require.NoError(t, os.Chtimes(pathA, time.Now(), time.Now().Add(time.Hour)))
+	assert.Equal(t, etagA, generateETag(t, g, pathA))
+
+	// Unchanged size, mtime and inode use the cached hash
+	info, err := os.Stat(pathA)
+	require.NoError(t, err)
+	overwriteKeepingModTime(t, pathA, "HELLO", info.ModTime())
+	assert.Equal(t, etagA, generateETag(t, g, pathA))
+}
+
+func TestContentHashETagGeneratorIndexFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	removedPath := filepath.Join(dir, "removed.txt")
+	indexPath := filepath.Join(t.TempDir(), "index.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o644))
+	require.NoError(t, os.WriteFile(removedPath, []byte("removed"), 0o644))
+
+	g, err := NewContentHashETagGenerator(WithIndexFile(indexPath))
+	require.NoError(t, err)
+	etag := generateETag(t, g, path)
+	generateETag(t, g, removedPath)
+	require.NoError(t, os.Remove(removedPath))
+
+	// A new generator uses the persisted hash of the unchanged file
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	overwriteKeepingModTime(t, path, "HELLO", info.ModTime())
+	g, err = NewContentHashETagGenerator(WithIndexFile(indexPath))
+	require.NoError(t, err)
+	assert.Equal(t, etag, generateETag(t, g, path))
+
+	// Entries of removed files are compacted away
+	index, err := os.ReadFile(indexPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(index), path)
+	assert.NotContains(t, string(index), removedPath)
+
+	// Changed files are rehashed
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Hour)))
+	assert.NotEqual(t, etag, generateETag(t, g, path))
+}
+
+func generateETag(t *testing.T, g ETagGenerator, path string) string {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	etag, err := g.GenerateETag(path, info)
+	require.NoError(t, err)
+	return etag
+}
+
+func overwriteKeepingModTime(t *testing.T, path string, content string, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
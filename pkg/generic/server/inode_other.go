@@ -0,0 +1,10 @@
+//go:build !unix
+
+package server
+
+import "io/fs"
+
+// inode returns 0 as inode numbers are unavailable on this platform.
+func inode(info fs.FileInfo) uint64 {
+	return 0
+}
@@ -2,11 +2,15 @@
 package server
 
 import (
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -20,6 +24,9 @@ import (
 const (
 	maxKeyLen    = 1024 // bytes, same limit that S3 uses
 	maxFileCount = 1000 // limit number of files returned. Should match maximum in ../../../api/storage.yaml
+
+	uploadTempPrefix = ".upload-" // prefix of files being uploaded, which are not served
+	tagsPrefix       = ".tags-"   // prefix of the files holding the tags of a file, which are not served
 )
 
 // Handler is a minimal implementation of the storage OAPI spec.
@@ -30,6 +37,12 @@ type Handler struct {
 
 	// ETagGenerator interface to generate ETags for files
 	etagGenerator ETagGenerator
+
+	// Maximum size of an uploaded file in bytes. Uploads are disabled if 0
+	maxUploadSize int64
+
+	// Whether files may be tagged, copied and deleted
+	fileActions bool
 }
 
 // Option configures a Handler
@@ -55,6 +68,14 @@ func New(rootDirPath string, opts ...Option) *Handler {
 	return h
 }
 
+// WithMaxUploadSize enables uploads via PUT /file of files up to maxSize bytes.
+// Uploads are disabled by default.
+func WithMaxUploadSize(maxSize int64) Option {
+	return func(h *Handler) {
+		h.maxUploadSize = maxSize
+	}
+}
+
 // GetFiles implements GET /files.
 func (h *Handler) GetFiles(ctx *gin.Context, params GetFilesParams) {
 	matches := []FileMetadata{}
@@ -76,7 +97,7 @@ func (h *Handler) GetFiles(ctx *gin.Context, params GetFilesParams) {
 		if err != nil {
 			return err
 		}
-		if d.IsDir() {
+		if d.IsDir() || isReserved(relPath) {
 			return nil
 		}
 		if params.Prefix != nil && !strings.HasPrefix(relPath, *params.Prefix) {
@@ -111,62 +132,221 @@ func (h *Handler) GetFiles(ctx *gin.Context, params GetFilesParams) {
 
 // GetFile implements GET /file.
 func (h *Handler) GetFile(ctx *gin.Context, params GetFileParams) {
-	if !isValidKey(params.Key) {
-		badRequest(ctx, "invalid key")
+	file, info, eTag, ok := h.openFile(ctx, params.Key, params.IfMatch)
+	if !ok {
 		return
 	}
+	defer file.Close()
 
-	requestedETag := params.IfMatch
-	if !isValidETag(requestedETag) {
-		badRequest(ctx, `invalid If-Match header. ETag must be a quoted string, e.g. "abc123"`)
+	ctx.Header("ETag", eTag)
+	ctx.Header("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+	if params.Range == nil {
+		ctx.DataFromReader(http.StatusOK, info.Size(), "application/octet-stream", file, nil)
+		return
+	}
+
+	first, last, err := parseRange(*params.Range, info.Size())
+	if errors.Is(err, errRangeNotSatisfiable) {
+		ctx.Header("Content-Range", fmt.Sprintf("bytes */%d", info.Size()))
+		ctx.JSON(http.StatusRequestedRangeNotSatisfiable, ErrorResponse{Message: "range not satisfiable"})
 		return
+	} else if err != nil {
+		badRequest(ctx, "invalid Range header. Must be a single byte range, e.g. bytes=0-99")
+		return
+	}
+	length := last - first + 1
+	ctx.DataFromReader(http.StatusPartialContent, length, "application/octet-stream",
+		io.NewSectionReader(file, first, length),
+		map[string]string{"Content-Range": fmt.Sprintf("bytes %d-%d/%d", first, last, info.Size())},
+	)
+}
+
+// HeadFile implements HEAD /file.
+func (h *Handler) HeadFile(ctx *gin.Context, params HeadFileParams) {
+	file, info, eTag, ok := h.openFile(ctx, params.Key, params.IfMatch)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	ctx.Header("ETag", eTag)
+	ctx.Header("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+	ctx.Header("Content-Length", strconv.FormatInt(info.Size(), 10))
+	ctx.Status(http.StatusOK)
+}
+
+// openFile opens the file with the given key if its ETag matches ifMatch.
+// Otherwise, an error response is set and ok is false
+func (h *Handler) openFile(ctx *gin.Context, key string, ifMatch string) (file *os.File, info fs.FileInfo, eTag string, ok bool) {
+	if !isValidKey(key) || isReserved(key) {
+		badRequest(ctx, "invalid key")
+		return nil, nil, "", false
+	}
+
+	if !isValidETag(ifMatch) {
+		badRequest(ctx, `invalid If-Match header. ETag must be a quoted string, e.g. "abc123"`)
+		return nil, nil, "", false
 	}
 
 	// Prevent rootDirPath traversal by opening in root
-	file, err := os.OpenInRoot(h.rootDirPath, params.Key)
+	file, err := os.OpenInRoot(h.rootDirPath, key)
 	if errors.Is(err, fs.ErrNotExist) {
 		ctx.JSON(http.StatusNotFound, ErrorResponse{Message: "file not found"})
-		return
+		return nil, nil, "", false
 	} else if err != nil {
 		internalServerError(ctx, err, "failed to open file")
-		return
+		return nil, nil, "", false
 	}
-	defer file.Close()
+	defer func() {
+		if !ok {
+			file.Close()
+		}
+	}()
 
-	info, err := file.Stat()
+	info, err = file.Stat()
 	if err != nil {
 		internalServerError(ctx, err, "failed to stat file")
-		return
+		return nil, nil, "", false
 	}
 	if info.IsDir() {
 		badRequest(ctx, "key must refer to a file, not a directory")
-		return
+		return nil, nil, "", false
 	}
 	filePath, err := filepath.Abs(file.Name())
 	if err != nil {
 		internalServerError(ctx, err, "failed to get absolute path of file")
-		return
+		return nil, nil, "", false
 	}
 
-	eTag, err := h.etagGenerator.GenerateETag(filePath, info)
+	eTag, err = h.etagGenerator.GenerateETag(filePath, info)
 	if err != nil {
 		internalServerError(ctx, err, "failed to compute ETag")
-		return
+		return nil, nil, "", false
 	}
 	if !isValidETag(eTag) {
 		err := InvalidETagError{ETag: eTag, Message: "ETag must be quoted"}
 		internalServerError(ctx, err, "invalid ETag")
-		return
+		return nil, nil, "", false
 	}
 
-	if eTag != requestedETag {
+	if eTag != ifMatch {
 		ctx.JSON(http.StatusPreconditionFailed, ErrorResponse{Message: "ETag mismatch"})
+		return nil, nil, "", false
+	}
+	return file, info, eTag, true
+}
+
+// PutFile implements PUT /file.
+func (h *Handler) PutFile(ctx *gin.Context, params PutFileParams) {
+	if h.maxUploadSize <= 0 {
+		ctx.JSON(http.StatusForbidden, ErrorResponse{Message: "uploads are disabled"})
+		return
+	}
+	if !isValidKey(params.Key) || isReserved(params.Key) {
+		badRequest(ctx, "invalid key")
+		return
+	}
+	createOnly := params.IfNoneMatch != nil
+	if createOnly && *params.IfNoneMatch != "*" {
+		badRequest(ctx, `invalid If-None-Match header. Only "*" is supported`)
+		return
+	}
+	if ctx.Request.ContentLength > h.maxUploadSize {
+		contentTooLarge(ctx, h.maxUploadSize)
 		return
 	}
 
-	ctx.Header("ETag", eTag)
-	ctx.Header("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
-	ctx.DataFromReader(http.StatusOK, info.Size(), "application/octet-stream", file, nil)
+	root, err := os.OpenRoot(h.rootDirPath)
+	if err != nil {
+		internalServerError(ctx, err, "failed to open server root")
+		return
+	}
+	defer root.Close()
+
+	existed := false
+	if info, err := root.Stat(params.Key); err == nil {
+		if info.IsDir() {
+			badRequest(ctx, "key must refer to a file, not a directory")
+			return
+		}
+		if createOnly {
+			ctx.JSON(http.StatusPreconditionFailed, ErrorResponse{Message: "file already exists"})
+			return
+		}
+		existed = true
+	}
+	dir := filepath.Dir(params.Key)
+	if err := root.MkdirAll(dir, 0o750); err != nil {
+		badRequest(ctx, "key must not be within a file")
+		return
+	}
+
+	// Write to a temporary file alongside the destination, then move it
+	// into place so that a partially written file is never served
+	tempKey := filepath.Join(dir, uploadTempPrefix+rand.Text())
+	temp, err := root.OpenFile(tempKey, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		internalServerError(ctx, err, "failed to create file")
+		return
+	}
+	defer func() {
+		if err := root.Remove(tempKey); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Error().Err(err).Str("key", tempKey).Msg("Failed to remove temporary file")
+		}
+	}()
+	_, err = io.Copy(temp, http.MaxBytesReader(ctx.Writer, ctx.Request.Body, h.maxUploadSize))
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		contentTooLarge(ctx, h.maxUploadSize)
+		return
+	} else if err != nil {
+		internalServerError(ctx, err, "failed to write file")
+		return
+	}
+
+	if createOnly {
+		// Linking fails if the destination exists, unlike renaming
+		err = root.Link(tempKey, params.Key)
+		if errors.Is(err, fs.ErrExist) {
+			ctx.JSON(http.StatusPreconditionFailed, ErrorResponse{Message: "file already exists"})
+			return
+		}
+	} else {
+		err = root.Rename(tempKey, params.Key)
+	}
+	if err != nil {
+		internalServerError(ctx, err, "failed to move file into place")
+		return
+	}
+	if existed { // A replaced file does not keep the tags of the previous file
+		if err := removeTags(root, params.Key); err != nil {
+			internalServerError(ctx, err, "failed to remove tags")
+			return
+		}
+	}
+
+	info, err := root.Stat(params.Key)
+	if err != nil {
+		internalServerError(ctx, err, "failed to stat file")
+		return
+	}
+	meta, err := h.fileMetadata(params.Key, info)
+	if err != nil {
+		internalServerError(ctx, err, "failed to compute ETag")
+		return
+	}
+	statusCode := http.StatusCreated
+	if existed {
+		statusCode = http.StatusOK
+	}
+	ctx.Header("ETag", meta.Etag)
+	ctx.JSON(statusCode, meta)
 }
 
 func (h *Handler) fileMetadata(key string, info fs.FileInfo) (FileMetadata, error) {
@@ -198,6 +378,54 @@ func isValidKey(key string) bool {
 	return key != "" && len(key) <= maxKeyLen && filepath.IsLocal(key) && !strings.HasSuffix(key, "/")
 }
 
+// isReserved reports whether key refers to a file used by the server itself,
+// such as one still being uploaded, which is not served.
+func isReserved(key string) bool {
+	base := filepath.Base(key)
+	return strings.HasPrefix(base, uploadTempPrefix) || strings.HasPrefix(base, tagsPrefix)
+}
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// parseRange returns the first and last byte positions of a file of the given size
+// selected by a single byte range Range header value as per RFC 9110.
+func parseRange(value string, size int64) (int64, int64, error) {
+	spec, found := strings.CutPrefix(value, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, fmt.Errorf("unsupported range %q", value)
+	}
+	startStr, endStr, found := strings.Cut(spec, "-")
+	if !found || (startStr == "" && endStr == "") {
+		return 0, 0, fmt.Errorf("invalid range %q", value)
+	}
+	if startStr == "" { // Suffix range, e.g. bytes=-500
+		suffix, err := strconv.ParseUint(endStr, 10, 63)
+		if err != nil || suffix == 0 {
+			return 0, 0, fmt.Errorf("invalid range %q", value)
+		}
+		if size == 0 {
+			return 0, 0, errRangeNotSatisfiable
+		}
+		return max(size-int64(suffix), 0), size - 1, nil
+	}
+	first, err := strconv.ParseUint(startStr, 10, 63)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q", value)
+	}
+	last := uint64(size - 1)
+	if endStr != "" {
+		end, err := strconv.ParseUint(endStr, 10, 63)
+		if err != nil || end < first {
+			return 0, 0, fmt.Errorf("invalid range %q", value)
+		}
+		last = min(end, last)
+	}
+	if int64(first) >= size {
+		return 0, 0, errRangeNotSatisfiable
+	}
+	return int64(first), int64(last), nil
+}
+
 // isValidETag reports whether s is a quoted ETag string as per RFC 7232.
 func isValidETag(s string) bool {
 	return len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"'
@@ -211,3 +439,9 @@ func internalServerError(ctx *gin.Context, err error, msg string) {
 func badRequest(ctx *gin.Context, msg string) {
 	ctx.JSON(http.StatusBadRequest, ErrorResponse{Message: msg})
 }
+
+func contentTooLarge(ctx *gin.Context, maxSize int64) {
+	ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+		Message: fmt.Sprintf("file is larger than the maximum upload size of %d bytes", maxSize),
+	})
+}
@@ -210,3 +210,275 @@ func TestGetFile(t *testing.T) {
 		})
 	}
 }
+
+func TestGetFileRange(t *testing.T) {
+	files := map[string]string{"data.txt": "hello world"}
+
+	testCases := []struct {
+		name                 string
+		rangeHeader          string
+		expectedStatusCode   int
+		expectedBody         string
+		expectedContentRange string
+	}{
+		{
+			name:                 "bounded",
+			rangeHeader:          "bytes=0-4",
+			expectedStatusCode:   http.StatusPartialContent,
+			expectedBody:         "hello",
+			expectedContentRange: "bytes 0-4/11",
+		},
+		{
+			name:                 "open ended",
+			rangeHeader:          "bytes=6-",
+			expectedStatusCode:   http.StatusPartialContent,
+			expectedBody:         "world",
+			expectedContentRange: "bytes 6-10/11",
+		},
+		{
+			name:                 "suffix",
+			rangeHeader:          "bytes=-3",
+			expectedStatusCode:   http.StatusPartialContent,
+			expectedBody:         "rld",
+			expectedContentRange: "bytes 8-10/11",
+		},
+		{
+			name:                 "end beyond file",
+			rangeHeader:          "bytes=6-100",
+			expectedStatusCode:   http.StatusPartialContent,
+			expectedBody:         "world",
+			expectedContentRange: "bytes 6-10/11",
+		},
+		{
+			name:                 "start beyond file",
+			rangeHeader:          "bytes=11-",
+			expectedStatusCode:   http.StatusRequestedRangeNotSatisfiable,
+			expectedContentRange: "bytes */11",
+		},
+		{
+			name:               "multiple ranges",
+			rangeHeader:        "bytes=0-1,3-4",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid unit",
+			rangeHeader:        "lines=0-1",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "end before start",
+			rangeHeader:        "bytes=4-1",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newTestHandler(t, files)
+
+			writer := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(writer)
+			RegisterHandlers(router, h)
+
+			req, _ := http.NewRequest(http.MethodGet, "/file?key=data.txt", nil)
+			req.Header.Set("If-Match", etag(t, h, "data.txt"))
+			req.Header.Set("Range", tc.rangeHeader)
+			router.ServeHTTP(writer, req)
+
+			assert.Equal(t, tc.expectedStatusCode, writer.Code)
+			assert.Equal(t, tc.expectedContentRange, writer.Header().Get("Content-Range"))
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, writer.Body.String())
+			}
+		})
+	}
+}
+
+func TestHeadFile(t *testing.T) {
+	h := newTestHandler(t, map[string]string{"data.txt": "hello world"})
+
+	testCases := []struct {
+		name               string
+		key                string
+		ifMatch            string
+		expectedStatusCode int
+	}{
+		{
+			name:               "ok",
+			key:                "data.txt",
+			ifMatch:            etag(t, h, "data.txt"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "etag mismatch",
+			key:                "data.txt",
+			ifMatch:            `"other"`,
+			expectedStatusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:               "not found",
+			key:                "missing.txt",
+			ifMatch:            `"other"`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "path traversal",
+			key:                "../data.txt",
+			ifMatch:            `"other"`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			writer := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(writer)
+			RegisterHandlers(router, h)
+
+			req, _ := http.NewRequest(http.MethodHead, "/file?key="+tc.key, nil)
+			req.Header.Set("If-Match", tc.ifMatch)
+			router.ServeHTTP(writer, req)
+
+			assert.Equal(t, tc.expectedStatusCode, writer.Code)
+			if tc.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, "11", writer.Header().Get("Content-Length"))
+				assert.Equal(t, tc.ifMatch, writer.Header().Get("ETag"))
+				assert.NotEmpty(t, writer.Header().Get("Last-Modified"))
+				assert.Empty(t, writer.Body.String())
+			}
+		})
+	}
+}
+
+func TestPutFile(t *testing.T) {
+	files := map[string]string{"existing.txt": "old content"}
+
+	testCases := []struct {
+		name               string
+		key                string
+		ifNoneMatch        string
+		body               string
+		maxUploadSize      int64
+		expectedStatusCode int
+		expectedContent    string
+	}{
+		{
+			name:               "create",
+			key:                "a/new.txt",
+			body:               "hello",
+			maxUploadSize:      100,
+			expectedStatusCode: http.StatusCreated,
+			expectedContent:    "hello",
+		},
+		{
+			name:               "replace",
+			key:                "existing.txt",
+			body:               "new content",
+			maxUploadSize:      100,
+			expectedStatusCode: http.StatusOK,
+			expectedContent:    "new content",
+		},
+		{
+			name:               "create only",
+			key:                "new.txt",
+			ifNoneMatch:        "*",
+			body:               "hello",
+			maxUploadSize:      100,
+			expectedStatusCode: http.StatusCreated,
+			expectedContent:    "hello",
+		},
+		{
+			name:               "create only existing",
+			key:                "existing.txt",
+			ifNoneMatch:        "*",
+			body:               "new content",
+			maxUploadSize:      100,
+			expectedStatusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:               "too large",
+			key:                "new.txt",
+			body:               "hello world",
+			maxUploadSize:      5,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:               "uploads disabled",
+			key:                "new.txt",
+			body:               "hello",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "path traversal",
+			key:                "../new.txt",
+			body:               "hello",
+			maxUploadSize:      100,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "temporary file name",
+			key:                ".upload-abc",
+			body:               "hello",
+			maxUploadSize:      100,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newTestHandlerWithOpts(t, files, WithMaxUploadSize(tc.maxUploadSize))
+
+			writer := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(writer)
+			RegisterHandlers(router, h)
+
+			req, _ := http.NewRequest(http.MethodPut, "/file?key="+tc.key, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/octet-stream")
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			router.ServeHTTP(writer, req)
+
+			assert.Equal(t, tc.expectedStatusCode, writer.Code)
+			if tc.expectedContent == "" {
+				content, err := os.ReadFile(filepath.Join(h.rootDirPath, "existing.txt"))
+				require.NoError(t, err)
+				assert.Equal(t, "old content", string(content))
+				return
+			}
+			content, err := os.ReadFile(filepath.Join(h.rootDirPath, tc.key))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedContent, string(content))
+
+			var meta FileMetadata
+			require.NoError(t, json.NewDecoder(writer.Body).Decode(&meta))
+			assert.Equal(t, tc.key, meta.Key)
+			assert.Equal(t, int64(len(tc.expectedContent)), meta.Size)
+			assert.Equal(t, etag(t, h, tc.key), meta.Etag)
+			assert.Equal(t, meta.Etag, writer.Header().Get("ETag"))
+
+			entries, err := os.ReadDir(filepath.Dir(filepath.Join(h.rootDirPath, tc.key)))
+			require.NoError(t, err)
+			for _, entry := range entries {
+				assert.False(t, isReserved(entry.Name()), "temporary file was not removed")
+			}
+		})
+	}
+}
+
+func TestPutFileTooLargeUnknownLength(t *testing.T) {
+	h := newTestHandlerWithOpts(t, nil, WithMaxUploadSize(5))
+
+	writer := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(writer)
+	RegisterHandlers(router, h)
+
+	req, _ := http.NewRequest(http.MethodPut, "/file?key=new.txt", io.MultiReader(strings.NewReader("hello world")))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, writer.Code)
+	entries, err := os.ReadDir(h.rootDirPath)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -20,36 +20,52 @@ import (
 // const string: with thousands of chunks the chained `+` fold is several
 // times slower for the Go compiler than parsing a slice literal.
 var swaggerSpec = []string{
-	"vFhtb9s4Ev4rA94BbQFZfmm2t+f91O02veCSRRC7ewfUwWIsjWxuJVIhKSdq4f9+GFKS7VhJGtxuPyWW",
-	"SM778zzUV5HootSKlLNi+lWUaLAgR8b/Ossu0CXr93NcXfILfpaSTYwsndRKTMX7u5ISRynwGsi0Abcm",
-	"yGRO8PKm0o7SV4AWSjJwdfruH5PXkxiuyFVG8cKF8iu1ymuQGUhnwzkFWyXrz9KKwJaUyExSCpL3SQtr",
-	"wpTMTwul3ZrMrbQUgQnnIpyMJ3BpKNEqlewnnKLMKQUyRpuFEpGgOyzKnMRULER6gj/GcUyT7M1CiEhI",
-	"jiucLyKhsOBlZ9nAp0JEwtBNJQ2lYupMRZGwyZoK5NS4uuS11hmpVmK7jcS/qX4gcR+VvKkIPlMNMiXl",
-	"ZFZLtdplz2mOx0jaUAwXWEOilUOpOMe3aNKFsjlanyReWRqypByU6NZgaVVwQe+FWhr9ByVuMJ68HtLK",
-	"kLXDFB3Gid20Yd9UZOpd1J+pfjTgQqpzUiu3FtNx1BP+paFM3j2QgXNpnY/Vwu1a25CLJa2ksnAr3ToU",
-	"eoN5RU+E8YD3pbcuHqvQlqOzpVaWfMP/jOkV3VRkHf/ilJPy/2JZ5jJBdn34h2X/v+4d+3dDmZiKvw13",
-	"wzQMb+3wPTfdVWMkmDzMw8+YgglGfwKpNpjLFNCsKl9DsY3EmXJkFOYzMhsy/sDv595HRe2My8YPsN6R",
-	"ME/s4K/anepKpd/Pq6ZKlIZpSTVZUNoB3UnrRGi9bv7D+H8/5w6wJwvGeVVzAJ9/eAYDr9ElGSdDHxZk",
-	"La7oeGh+aX9tKKQf2qX7I3LWdFGH5vDiM9X2hegb0t14f+rsXncL9ZKHjTN6KnO6IIeMGccek8NVDz18",
-	"Kys8gcn3vI48Mj2EqWyHIWDowVBnnfFnoOGRwRyt+73QqaehY9NzWZB1WJRwuyYVQr1FC7wN2m0xzNa6",
-	"ylPIdJ7r28CA1qFK0fBDU6Br/T1H6wYXzUb413x+2XDePVD/D6URjH+EU1rCZDR5A+M309HJdDSCDxdz",
-	"EYlwqpiKFB0NnCyoLzorv/Q020x+of38Mfsua0d234PXk5PRaM+OVO7NiYiYG2RRFWI66uwxfqzIHHVd",
-	"oBnvw/1ER6Gx+vqR+YN70j48Ruz074muwsQfBvdrVSzJcHiBg7yyIMildTGcy0IyuDgN49FoxH9xo2UK",
-	"v+hZzMHhXQiO3z4ea+S9sA/wX2fekK1yxxIgM7rwrjSkwOzmqLBPwdLBfG47R9AYrPl3Q4dHfvzGDNuW",
-	"+UVY9SJAB5RGb2RKaUvInVOsvArpOEe+4cM2kAGF222+Vx9HnJCcaL9Ux8XmDqWkMtLVM462AUmX2z1S",
-	"LypXYT4/nwVIlirTx8G+BV8rzOHt5ZlHJoQVKTIyCS1unTa4Ik9xMqGoiYUL4wuGKoUP5BaK+8yzhwXN",
-	"ZIgK0CylM2jq7pQlJp9JpTHM1+QtpmTlSnGeCu3kBh0naVn71M5e85IIlpWDBFmDc9+1SZeGvCBoK/Xe",
-	"Y1brZ7xQC/W2cmuWkoHVpnDhMwLz8xm8LObns1fsh7TtcRaWuqlqkktWjxxc4PaFchpaURnI5L/xD6N/",
-	"QsLTlbEJssCxUArLGhCcqTwfv3sbw2+8wTsBOluogz1omK4VATpv2hlUttTGQY41GZ8qQ5k2FPn3ezy9",
-	"UH4J3Mo8B0WcdUMJMReigkrhLn7qNFUElMtCKnStvlbESefan4zG8NFv00Z+8XsClMShc6XzGFsl+QBN",
-	"MnCGBoEqBk3PDJpCi0hsyNjQY6N4HI944nRJCksppuK1fxQJZiTfs0PPR9OvYkU92PSBHDRSxR4CcLgq",
-	"yJB0frySG1IQELRrybM0HHIaSG//UvepH0R2S4bdnWUbPbn26IK4vb6nqCej0SPCSyeO3MA6Q+GGsBNg",
-	"HZ8spUKv6nu0+2HW3u1lzBzoQxE1FzrvUrNu0F5dnqS9lzKmuOsNWOq0frXPhQ9fkJ7Liq1rc//qvmMX",
-	"lEoE3tbyRtslB5eclDKscrb6YKr7UJmL+NcpuOfcmg+0z/+rtZbUUPue1Oq0UCu6QoDddwU//d/uMft8",
-	"Evq8b166eRjuXS/9lpOnt3RXK94wnjy9oefis43ED9/iXt8903NvVRQ8gwGY/EDx42EnbHpB7Kr5fAHo",
-	"RdVO6nS3fRuu+ztx0cmNICeihdIG9r7wYJ63ai0DpfdUh4dBX7ZeELTPRsH9TxfPBLXn3SaPZWwPtB1q",
-	"Rf95rGWzQADdt44enPsWMOnArQ9QSDFifToO8jr6s2bhT+rP3TelkMVWNfqKB7346ZrLGVRO6ITK5GIq",
-	"hpuR2F5v/xcAAP//",
+	"7Fptb9tGEv4rg70D0haURMlurlVwH5I0To3aOSNWegeEQbEiR9Q25C6zu5SjBvrvh9klqTdKshJXPRzy",
+	"yRa5LzOzz8w+M8NPLFZ5oSRKa9jwEyu45jla1O7X5eSa23j6YsTTG3pBzxI0sRaFFUqyIXvxscDYYgI0",
+	"BiZKg50iTESG8M2HUllMvgVuoEANry+e/2NwNujCa7SlljQwkm6kktkcxASENX6dnHZF49ZSEsEUGIuJ",
+	"wAQEzRMGpsgT1E8iqewU9Z0wGID263I47w/gRmOsZCJITrjgIsMEUGulI8kChh95XmTIhixiyTn/odvt",
+	"4mDyOGIsYIL08uuzgEme07DLSceZggVM44dSaEzY0OoSA2biKeacTGPnBY01VguZssUiYJeTV0qim7nD",
+	"gNelsTBGiNh3ESMjpGKGsgtvikzxZGnN2kZS+d/4URhr4E7YaSRplJsH73H+BD7PJrv0Jg0a5ZfKoixz",
+	"NnzLvmPvghbNf8H5Do3fSPGhRJIURILSislcyHSpqVUktRY4wy5c8znESlouJKHrjuskkibjxsGDRhYa",
+	"DUoLBbdTMJjmKK3ZOORCq98xtp3+4KyHqUZjegm3vBubWa34hxL1fKn3e5zvPepcyCuUqZ2yYb9N/RuN",
+	"E/FxhwWuhLFOVwN3U2W8LcaYCukP1EN8xrMSD6ixQ/rC7c72Y/M1lynukPBWyDRDGM8tgqZxoCabJ+Rw",
+	"1bj2j/1+2IXrMrOiyDCSbpYBrhGksmDKolDaYrJxMrSD+Wc/HJx3dkHQyblXlwWdlCmUNOjC1jOevMYP",
+	"JRpLvwg+KN2/vCgyEXNSsve7IU0/rSz7d40TNmR/6y1DYs+/Nb0X5Cavq038lusWe8YT0H7TJyDkjGci",
+	"Aa7T0uGRLQL23MsxUuqK6xRPJ9oFnZkwkNG2FKC5C76Q848iL3Mofagx4g8kOS+UHoskQXk6Cf9VoHYL",
+	"k5gEF5R8TNFJVeHeoJ6hJukupUUteXbrnriVTyfnG4n1fScqOSrRfBwlAV8pe6FKmZxOqgrrmHjvTBRW",
+	"RqQrgvlg1MR9H/ZPJ9zanTPxm9fB55Wyt9wKMxF02n+FwXxsayymZqgzXjSRjgVVNHJhpXLgjpO9LWb+",
+	"sR4m14MjYDftQuQjHnzXOxuch6EjHHsC2yJgngg84JFROLhGy+kG3BkuymrXJzAI+8Q7Yo2cLMZlAoMw",
+	"pEcai4zHmKxbiQhcC09cpYf14nt54gGWdn8W5lSslPfHWMxJx5UrotCqQG2Fvz4SNFZIZ9rfiAdsKfML",
+	"zuuDjlUxX5OU63gqZtjbTzkO0Yeldm+3xFnSLTWmLcid1iG/pVGOxvA2zP5U/5qhj2BQD13V6bK6zprk",
+	"AB69x7l5xA6JXi/WJvIaDLckRnsQRV8Ang2pA9Z6zBVRpX2IivQcw1xx8CMo5taGGTf2t1wlLqvZ3nok",
+	"cjSW5wXcTVF6Ve84XeHGQj2tC7dTVWYJTFSWqTufUBnLZcI1PdQ5t7W8V9zYznU1EX4ejW6qFGqDj/0b",
+	"kwD6P8AFjmEQDh5D//EwPB+GIby8HrGA+VXZkCXcYseKHNu0c1TicIAU0jFMsyqBC4sr+whpH597jyGy",
+	"woZhs5+QFlPUW6jz3N3JsGnowAOrDY9EygmTZrcbkdC/xaqUdlu5V2U+Rk3qeWIvPMfKhLFduBK5oOBp",
+	"FfTDMKS/fKZEAj+p2y4p55kYG9Lb/boGTgqzI6lottdoiIrLFCZa5U6Uip2ygAmLuTnummgE4VrzOf2u",
+	"cowtOX6ltKU+5kd+1CMfOqDQaiYo8FdZTiMUJfK5sGQjB3g/rWaD9TSH1f0RxxsnWD2qtsMe8XTvFWB5",
+	"6v7yxDMXnt2sv99E/Ib78tRlpzxxZ94WMD4xHyU6xnJbGvIodSerq36xJfKGmk6+d22jDMalFnZ+S8dY",
+	"RX+brQqdl7bk2ejq1l/+Qk7U9ik+BQdCnsHTm0sXcjmkKFGL2PuusUrzFB39FTEG1SER4giJAbxEG0Ty",
+	"1nIbVMWMAEY8DYDuX0cjfsIMLYKqyb9x/Au4BK7Hwmqu5/U2kRzz+D3KpAujKTqZEjQilZEUBnJlxcyx",
+	"k/HcGfv2jIYEMC4txNyiNvUxVEZ0SVkN0hfuICJZqdKNZCSflnaK0lYMawjXzmgwurqFb/LR1e23JIgw",
+	"9XoGxqpCdJwJlNYp6FODSFoFdZXCX6T/6X4f/ggxaismtAUaIGW8AhysLh07ff60C7/SBJ8bqUkk1+Zw",
+	"TdxVInDrtraaS0NpNmR8jtrZSuNEaQzc+xXOGEk3BO5EloFEsrvGGIkHcAml5Ev9sUlsA8BM5I6LVAUb",
+	"ieSVBI/zsA9v3DSlxR9ujg+jXe+1wrr7pYyzDtdxx2rsVA5QwapTHTUL2Ay18TAMu/1uSO6gCpS8EGzI",
+	"ztyjgNFt7GDdc67lEEx4aiM59Hzl2vFVJ7HES1M7C3bWIp0WDVQvk2bdC+/Yq4XTt+2RdTmk11THFsHB",
+	"sVtF2MW7jXrHIDzf1trReG8SF1DOw3BXyG8W661UTtyUs8NTluUCN+P88IwmQ6YJ/cHhCS356yJg399H",
+	"obZygQuTZZ5zPV+iw4FoEbAUWy73l2ihyr3MOoPZCyXt0cQjadrqacIS0GiSyygrQkaXnlukBXEv0f4F",
+	"cDs8Z6WU2ALOcE/qqmKLtmOsRl+GXKZxDQMcC8ldcbOl7Ld+TM9XjkivFUXaE/k6BTtIVL8RXew2EQ3G",
+	"Kpl/u8pedyejx/LYWrSRe7XVLMBEcKBpNdOrYblWRkhwwsuMdt1p6jYedY/M/SQJe8DWspUvzY7GWJHx",
+	"leSoyV7qNMkr2DSWInmUxCTzIHx8Kpwv61ftBfrPR/vKeqfH/I662rNWHesEa01Ax7roqfE6+c7Y3VRl",
+	"rSW5tl4EhJ1+ODjz5bkjYfvVgb7EgT6LoJyObpz3Hx+e2lbWfjiqQjSk5ilk5nai4lo7VeJ+f7LSnDDP",
+	"ayZiCJ+U9tnAJeyqtI4W10yohaD8jDz5nyDEYdvlubTJg7KDPyUofo0lDxBLjm3RnrclUgfba1VQaTmp",
+	"XBiXPq7EgGMbimsBgKopTQQoypZM5blr07jco2rPBPcKAE1FLpKVidyF6sstk6qBfKeFtSjpBCdllsHY",
+	"1RZgjFQOyNXMa6EiWW1sFOgqkvgSgxo75YBTPdAKnmXzZlHapCWi3JSnSHk2vtLxAcWZ4ZlK5n8er1tH",
+	"9aI9jO2/Npr+oOOg/eMmnKYo8CWX7j022/yw4uEuXG+qyuMWgS819VzPj0rGyrR5oCq8bx10uaokudLf",
+	"o8dBJHfVoLrwVPqw4wr7LtLWhfSNVVxdtG7QtrmV8t2O575/efLL+j6+dVxbe7Otey//2lU3i1UhvpbN",
+	"9rmGg/mGY9Rdk9ab6Sn1Qqq+yOeVYiPZ+MGL2gncio0TOPJKrWlXGq/h77JC901kJHFtIo16j4Xdfe9Q",
+	"J+f/wz82Wl5f5B6Wp+lX99jnHo6nr3mHM2lrdfl19bUrcNcuXjZxm49Djf86dAn0ppHqG6V0Z+jVz355",
+	"llVr+E+Gl/3UA6Xl48G++qXrkcXf4wC83aBvKY2td8FdvKh7VT6yNJ/GtmR89ym61uq1Fl7rb6K3lGz5",
+	"RPozSx4PhM/lJ8hssdo2difuG8Zv39Fx+mzEI6HUGRuy3ixki3eL/w4A",
 }
 
 // decodeSpec returns the embedded OpenAPI spec as raw JSON bytes,
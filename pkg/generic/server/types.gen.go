@@ -11,6 +11,42 @@ const (
 	MtlsScopes mtlsContextKey = "mtls.Scopes"
 )
 
+// Defines values for IfNoneMatchParam.
+const (
+	IfNoneMatchParamAsterisk IfNoneMatchParam = "*"
+)
+
+// Valid indicates whether the value is a known member of the IfNoneMatchParam enum.
+func (e IfNoneMatchParam) Valid() bool {
+	switch e {
+	case IfNoneMatchParamAsterisk:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for PutFileParamsIfNoneMatch.
+const (
+	PutFileParamsIfNoneMatchAsterisk PutFileParamsIfNoneMatch = "*"
+)
+
+// Valid indicates whether the value is a known member of the PutFileParamsIfNoneMatch enum.
+func (e PutFileParamsIfNoneMatch) Valid() bool {
+	switch e {
+	case PutFileParamsIfNoneMatchAsterisk:
+		return true
+	default:
+		return false
+	}
+}
+
+// CopyFileRequest defines model for CopyFileRequest.
+type CopyFileRequest struct {
+	// DestinationKey Key of the copy
+	DestinationKey string `json:"destination_key"`
+}
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
 	// Message Descriptive error message
@@ -46,18 +82,36 @@ type ListFilesResponse struct {
 	Prefix *string `json:"prefix,omitempty"`
 }
 
+// TagFileRequest defines model for TagFileRequest.
+type TagFileRequest struct {
+	// Tags Tags to add to the file
+	Tags map[string]string `json:"tags"`
+}
+
 // IfMatchETagParam defines model for IfMatchETagParam.
 type IfMatchETagParam = string
 
+// IfNoneMatchParam defines model for IfNoneMatchParam.
+type IfNoneMatchParam string
+
 // KeyParam defines model for KeyParam.
 type KeyParam = string
 
 // PrefixParam defines model for PrefixParam.
 type PrefixParam = string
 
+// RangeParam defines model for RangeParam.
+type RangeParam = string
+
 // BadRequest defines model for BadRequest.
 type BadRequest = ErrorResponse
 
+// ContentTooLarge defines model for ContentTooLarge.
+type ContentTooLarge = ErrorResponse
+
+// Forbidden defines model for Forbidden.
+type Forbidden = ErrorResponse
+
 // InternalServerError defines model for InternalServerError.
 type InternalServerError = ErrorResponse
 
@@ -67,9 +121,27 @@ type NotFound = ErrorResponse
 // PreconditionFailed defines model for PreconditionFailed.
 type PreconditionFailed = ErrorResponse
 
+// RangeNotSatisfiable defines model for RangeNotSatisfiable.
+type RangeNotSatisfiable = ErrorResponse
+
+// Uploaded defines model for Uploaded.
+type Uploaded = FileMetadata
+
 // mtlsContextKey is the context key for mtls security scheme
 type mtlsContextKey string
 
+// DeleteFileParams defines parameters for DeleteFile.
+type DeleteFileParams struct {
+	// Key Unique key identifying the file to retrieve. May contain forward
+	// slashes to represent path segments
+	Key KeyParam `form:"key" json:"key"`
+
+	// IfMatch Expected ETag for the file (quoted) as per RFC7232. Return the
+	// file only if its ETag matches the one specified in this header;
+	// otherwise, return a 412 Precondition Failed error
+	IfMatch IfMatchETagParam `json:"If-Match"`
+}
+
 // GetFileParams defines parameters for GetFile.
 type GetFileParams struct {
 	// Key Unique key identifying the file to retrieve. May contain forward
@@ -80,6 +152,60 @@ type GetFileParams struct {
 	// file only if its ETag matches the one specified in this header;
 	// otherwise, return a 412 Precondition Failed error
 	IfMatch IfMatchETagParam `json:"If-Match"`
+
+	// Range Single byte range of the file to return as per RFC9110. Multiple
+	// ranges are not supported
+	Range *RangeParam `json:"Range,omitempty"`
+}
+
+// HeadFileParams defines parameters for HeadFile.
+type HeadFileParams struct {
+	// Key Unique key identifying the file to retrieve. May contain forward
+	// slashes to represent path segments
+	Key KeyParam `form:"key" json:"key"`
+
+	// IfMatch Expected ETag for the file (quoted) as per RFC7232. Return the
+	// file only if its ETag matches the one specified in this header;
+	// otherwise, return a 412 Precondition Failed error
+	IfMatch IfMatchETagParam `json:"If-Match"`
+}
+
+// PutFileParams defines parameters for PutFile.
+type PutFileParams struct {
+	// Key Unique key identifying the file to retrieve. May contain forward
+	// slashes to represent path segments
+	Key KeyParam `form:"key" json:"key"`
+
+	// IfNoneMatch Must be "*" if given. Upload the file only if no file exists with
+	// the given key; otherwise, return a 412 Precondition Failed error
+	IfNoneMatch *PutFileParamsIfNoneMatch `json:"If-None-Match,omitempty"`
+}
+
+// PutFileParamsIfNoneMatch defines parameters for PutFile.
+type PutFileParamsIfNoneMatch string
+
+// PostFileCopyParams defines parameters for PostFileCopy.
+type PostFileCopyParams struct {
+	// Key Unique key identifying the file to retrieve. May contain forward
+	// slashes to represent path segments
+	Key KeyParam `form:"key" json:"key"`
+
+	// IfMatch Expected ETag for the file (quoted) as per RFC7232. Return the
+	// file only if its ETag matches the one specified in this header;
+	// otherwise, return a 412 Precondition Failed error
+	IfMatch IfMatchETagParam `json:"If-Match"`
+}
+
+// PutFileTagsParams defines parameters for PutFileTags.
+type PutFileTagsParams struct {
+	// Key Unique key identifying the file to retrieve. May contain forward
+	// slashes to represent path segments
+	Key KeyParam `form:"key" json:"key"`
+
+	// IfMatch Expected ETag for the file (quoted) as per RFC7232. Return the
+	// file only if its ETag matches the one specified in this header;
+	// otherwise, return a 412 Precondition Failed error
+	IfMatch IfMatchETagParam `json:"If-Match"`
 }
 
 // GetFilesParams defines parameters for GetFiles.
@@ -87,3 +213,9 @@ type GetFilesParams struct {
 	// Prefix List files whose key begins with this value
 	Prefix *PrefixParam `form:"prefix,omitempty" json:"prefix,omitempty"`
 }
+
+// PostFileCopyJSONRequestBody defines body for PostFileCopy for application/json ContentType.
+type PostFileCopyJSONRequestBody = CopyFileRequest
+
+// PutFileTagsJSONRequestBody defines body for PutFileTags for application/json ContentType.
+type PutFileTagsJSONRequestBody = TagFileRequest
@@ -9,19 +9,24 @@
 //
 //	GET  /.well-known/openid-configuration  - minimal OIDC discovery doc
 //	GET  /.well-known/jwks.json             - JWKS with a single RSA public key
-//	POST /token                             - mint a JWT for the given claims
+//	POST /token                             - mint a JWT, or an opaque token, for the given claims
+//	POST /introspect                        - RFC 7662 introspection of minted tokens
 //	GET  /healthz                           - liveness/readiness probe
 //
 // The signing keypair is generated in-memory at startup; restarting rotates
 // the key; tests should refetch JWKS (or rely on the JWKS cache refresh)
-// after restarting this Pod
+// after restarting this Pod. Opaque tokens are also held only in memory, so
+// are forgotten on restart. Introspection does not authenticate clients
 package main
 
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -47,6 +52,7 @@ type tokenRequest struct {
 	Subject     string         `json:"sub,omitempty"`
 	Audience    string         `json:"aud,omitempty"`
 	ExtraClaims map[string]any `json:"claims,omitempty"`
+	Opaque      bool           `json:"opaque,omitempty"` // Mint an opaque token, only valid by introspection
 }
 
 // Response body of POST /token
@@ -61,6 +67,9 @@ type signer struct {
 	key    jwk.Key
 	jwks   jwk.Set
 	issuer string
+
+	mu     sync.Mutex
+	opaque map[string]map[string]any // Claims of minted opaque tokens
 }
 
 func main() {
@@ -83,6 +92,7 @@ func main() {
 	router.GET("/.well-known/openid-configuration", s.handleDiscovery)
 	router.GET("/.well-known/jwks.json", s.handleJWKS)
 	router.POST("/token", s.handleToken)
+	router.POST("/introspect", s.handleIntrospect)
 
 	server := &http.Server{
 		Addr:              serverAddr,
@@ -131,6 +141,7 @@ func newSigner(iss string) (*signer, error) {
 		key:    key,
 		jwks:   set,
 		issuer: iss,
+		opaque: map[string]map[string]any{},
 	}, nil
 }
 
@@ -139,6 +150,7 @@ func (s *signer) handleDiscovery(c *gin.Context) {
 		"issuer":                                s.issuer,
 		"jwks_uri":                              s.issuer + "/.well-known/jwks.json",
 		"token_endpoint":                        s.issuer + "/token",
+		"introspection_endpoint":                s.issuer + "/introspect",
 		"id_token_signing_alg_values_supported": []string{"RS256"},
 		"response_types_supported":              []string{"token"},
 		"subject_types_supported":               []string{"public"},
@@ -199,6 +211,11 @@ func (s *signer) handleToken(c *gin.Context) {
 		return
 	}
 
+	if req.Opaque {
+		s.handleOpaqueToken(c, token)
+		return
+	}
+
 	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256(), s.key))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to sign token")
@@ -213,6 +230,71 @@ func (s *signer) handleToken(c *gin.Context) {
 	})
 }
 
+// Responds with a random token whose claims are those of the JWT
+func (s *signer) handleOpaqueToken(c *gin.Context, token jwt.Token) {
+	claims, err := claimsOf(token)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read token claims")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		log.Error().Err(err).Msg("Failed to generate opaque token")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+	opaque := hex.EncodeToString(raw)
+	s.mu.Lock()
+	s.opaque[opaque] = claims
+	s.mu.Unlock()
+	c.JSON(http.StatusOK, tokenResponse{
+		AccessToken: opaque,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(tokenTTL.Seconds()),
+	})
+}
+
+// Responds with the claims of an opaque token minted by this server, or a
+// valid JWT signed by it, otherwise that the token is not active
+func (s *signer) handleIntrospect(c *gin.Context) {
+	token := c.PostForm("token")
+	s.mu.Lock()
+	claims, exists := s.opaque[token]
+	s.mu.Unlock()
+	if exp, ok := claims["exp"].(float64); ok && time.Now().Unix() >= int64(exp) {
+		c.JSON(http.StatusOK, gin.H{"active": false})
+		return
+	}
+	if !exists {
+		parsed, err := jwt.Parse([]byte(token), jwt.WithKeySet(s.jwks))
+		if err != nil {
+			c.JSON(http.StatusOK, gin.H{"active": false})
+			return
+		}
+		if claims, err = claimsOf(parsed); err != nil {
+			log.Error().Err(err).Msg("Failed to read token claims")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+			return
+		}
+	}
+	response := gin.H{"active": true, "token_type": "Bearer"}
+	for k, v := range claims {
+		response[k] = v
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func claimsOf(token jwt.Token) (map[string]any, error) {
+	content, err := json.Marshal(token)
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]any{}
+	err = json.Unmarshal(content, &claims)
+	return claims, err
+}
+
 func envOrDefault(key, d string) string {
 	if v := os.Getenv(key); v != "" {
 		return v